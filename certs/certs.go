@@ -0,0 +1,333 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package certs generates and inspects X.509 certificates, e.g for cluster bootstrap,
+// without parsing `openssl` output.
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	PemTypeCertificate        = "CERTIFICATE"
+	PemTypeCertificateRequest = "CERTIFICATE REQUEST"
+	PemTypePrivateKey         = "PRIVATE KEY"
+
+	// DefaultValidity is the validity of the certificates without explicit one.
+	DefaultValidity = 365 * 24 * time.Hour
+)
+
+// RequestOptions are the options of certificate signing requests and certificates.
+type RequestOptions struct {
+	Subject     pkix.Name
+	DNSNames    []string
+	IPAddresses []net.IP
+}
+
+type CertificateOptions struct {
+	RequestOptions
+
+	// NotBefore is the start of the validity, when not now.
+	NotBefore time.Time
+	// Validity is the duration of the validity, when not DefaultValidity.
+	Validity time.Duration
+	// IsCA marks the certificate as a certificate authority that can sign other certificates.
+	IsCA bool
+}
+
+// CertificateInfo is the inspected certificate.
+type CertificateInfo struct {
+	Subject      string
+	Issuer       string
+	SerialNumber string
+	NotBefore    time.Time
+	NotAfter     time.Time
+	DNSNames     []string
+	IPAddresses  []string
+	IsCA         bool
+	// Fingerprint is the hex-encoded SHA-256 fingerprint of the certificate.
+	Fingerprint string
+}
+
+// ExpiresIn returns the duration until the certificate expires, which is negative for expired certificates.
+func (info *CertificateInfo) ExpiresIn(now time.Time) time.Duration {
+	return info.NotAfter.Sub(now)
+}
+
+func (info *CertificateInfo) IsExpired(now time.Time) bool {
+	return now.After(info.NotAfter)
+}
+
+// GenerateECDSAKey generates a P-256 ECDSA private key.
+func GenerateECDSAKey() (crypto.Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	return key, stacktrace.Propagate(err, "failed to generate ECDSA key")
+}
+
+// GenerateRSAKey generates a RSA private key of `bits` size.
+func GenerateRSAKey(bits int) (crypto.Signer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	return key, stacktrace.Propagate(err, "failed to generate RSA key")
+}
+
+// EncodePrivateKey returns the PKCS #8 PEM encoding of the private key.
+func EncodePrivateKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "failed to marshal private key")
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: PemTypePrivateKey, Bytes: der}), nil
+}
+
+// ParsePrivateKey parses the PEM encoded PKCS #8, PKCS #1 RSA or EC private key.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, stacktrace.NewError("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, stacktrace.NewError("unsupported private key type %T", key)
+		}
+
+		return signer, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	return key, stacktrace.Propagate(err, "failed to parse private key")
+}
+
+// CreateCSR returns the PEM encoded certificate signing request signed with the key.
+func CreateCSR(key crypto.Signer, options *RequestOptions) ([]byte, error) {
+	template := &x509.CertificateRequest{
+		Subject:     options.Subject,
+		DNSNames:    options.DNSNames,
+		IPAddresses: options.IPAddresses,
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "failed to create certificate signing request")
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: PemTypeCertificateRequest, Bytes: der}), nil
+}
+
+// CreateSelfSigned returns the PEM encoded certificate self-signed with the key.
+func CreateSelfSigned(key crypto.Signer, options *CertificateOptions) ([]byte, error) {
+	template, err := certificateTemplate(key.Public(), options)
+	if err != nil {
+		return nil, err
+	}
+
+	return createCertificate(template, template, key.Public(), key)
+}
+
+// Sign returns the PEM encoded certificate for the public key, signed by the CA certificate and its key.
+func Sign(
+	publicKey crypto.PublicKey,
+	options *CertificateOptions,
+	caCertificate *x509.Certificate,
+	caKey crypto.Signer,
+) ([]byte, error) {
+	if !caCertificate.IsCA {
+		return nil, stacktrace.NewError("certificate %s is not a certificate authority", caCertificate.Subject)
+	}
+
+	template, err := certificateTemplate(publicKey, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return createCertificate(template, caCertificate, publicKey, caKey)
+}
+
+// ParseCertificates parses all PEM encoded certificates, e.g a chain. Other PEM blocks are skipped.
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+
+	for {
+		var block *pem.Block
+
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if block.Type != PemTypeCertificate {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "failed to parse certificate")
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	if len(certificates) == 0 {
+		return nil, stacktrace.NewError("no certificates found")
+	}
+
+	return certificates, nil
+}
+
+// Inspect returns the info of all PEM encoded certificates.
+func Inspect(data []byte) ([]*CertificateInfo, error) {
+	certificates, err := ParseCertificates(data)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*CertificateInfo, len(certificates))
+	for i, certificate := range certificates {
+		infos[i] = NewCertificateInfo(certificate)
+	}
+
+	return infos, nil
+}
+
+func NewCertificateInfo(certificate *x509.Certificate) *CertificateInfo {
+	ipAddresses := make([]string, len(certificate.IPAddresses))
+	for i, ip := range certificate.IPAddresses {
+		ipAddresses[i] = ip.String()
+	}
+
+	fingerprint := sha256.Sum256(certificate.Raw)
+
+	return &CertificateInfo{
+		Subject:      certificate.Subject.String(),
+		Issuer:       certificate.Issuer.String(),
+		SerialNumber: certificate.SerialNumber.Text(16),
+		NotBefore:    certificate.NotBefore,
+		NotAfter:     certificate.NotAfter,
+		DNSNames:     certificate.DNSNames,
+		IPAddresses:  ipAddresses,
+		IsCA:         certificate.IsCA,
+		Fingerprint:  hex.EncodeToString(fingerprint[:]),
+	}
+}
+
+// VerifyChain verifies that the first certificate of `chain` is signed, through the rest of the chain
+// as intermediates, by one of the roots and is valid at `now` for the DNS name, when present.
+func VerifyChain(chain, roots []byte, dnsName string, now time.Time) error {
+	certificates, err := ParseCertificates(chain)
+	if err != nil {
+		return stacktrace.Propagate(err, "failed to parse chain")
+	}
+
+	rootCertificates, err := ParseCertificates(roots)
+	if err != nil {
+		return stacktrace.Propagate(err, "failed to parse roots")
+	}
+
+	rootPool := x509.NewCertPool()
+	for _, root := range rootCertificates {
+		rootPool.AddCert(root)
+	}
+
+	intermediatePool := x509.NewCertPool()
+	for _, intermediate := range certificates[1:] {
+		intermediatePool.AddCert(intermediate)
+	}
+
+	_, err = certificates[0].Verify(x509.VerifyOptions{
+		DNSName:       dnsName,
+		Roots:         rootPool,
+		Intermediates: intermediatePool,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+
+	return stacktrace.Propagate(err, "failed to verify certificate %s", certificates[0].Subject)
+}
+
+func certificateTemplate(publicKey crypto.PublicKey, options *CertificateOptions) (*x509.Certificate, error) {
+	// NOTE: Serial numbers are at most 20 octets.
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 159))
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "failed to generate serial number")
+	}
+
+	notBefore := options.NotBefore
+	if notBefore.IsZero() {
+		notBefore = time.Now()
+	}
+
+	validity := options.Validity
+	if validity == 0 {
+		validity = DefaultValidity
+	}
+
+	keyUsage := x509.KeyUsageDigitalSignature
+	if _, ok := publicKey.(*rsa.PublicKey); ok {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               options.Subject,
+		DNSNames:              options.DNSNames,
+		IPAddresses:           options.IPAddresses,
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(validity),
+		KeyUsage:              keyUsage,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  options.IsCA,
+	}
+
+	if options.IsCA {
+		template.KeyUsage |= x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	}
+
+	return template, nil
+}
+
+func createCertificate(
+	template,
+	parent *x509.Certificate,
+	publicKey crypto.PublicKey,
+	signer crypto.Signer,
+) ([]byte, error) {
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, publicKey, signer)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "failed to create certificate")
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: PemTypeCertificate, Bytes: der}), nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRealClock(t *testing.T) {
+	t.Run(
+		"it tells the real time and fires the timers",
+		func(t *testing.T) {
+			t.Parallel()
+
+			clock := NewRealClock()
+			before := time.Now()
+
+			timer := clock.NewTimer(time.Millisecond)
+			<-timer.C()
+
+			assert.False(t, clock.Now().Before(before))
+			assert.True(t, clock.Since(before) >= time.Millisecond)
+			assert.False(t, timer.Stop())
+		},
+	)
+}
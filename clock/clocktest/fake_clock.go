@@ -0,0 +1,205 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clocktest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/clock"
+)
+
+var (
+	_ clock.Clock = (*FakeClock)(nil)
+	_ clock.Timer = (*fakeTimer)(nil)
+)
+
+// FakeClock is a clock.Clock that moves only when advanced, firing the timers that are due.
+// It's safe for concurrent use.
+type FakeClock struct {
+	mu          sync.Mutex
+	now         time.Time
+	timers      []*fakeTimer
+	autoAdvance bool
+	// changed is closed and replaced whenever the timers change, to wake up BlockUntil.
+	changed chan struct{}
+}
+
+// NewFakeClock creates FakeClock instance starting at `start`.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{
+		now:     start,
+		changed: make(chan struct{}),
+	}
+}
+
+// SetAutoAdvance makes the clock advance to the deadline of every new timer, so that sleeps and
+// polling loops run instantly without a goroutine advancing the clock.
+func (c *FakeClock) SetAutoAdvance(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.autoAdvance = enabled
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) clock.Timer {
+	timer := &fakeTimer{
+		clock: c,
+		c:     make(chan time.Time, 1),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.schedule(timer, d)
+
+	return timer
+}
+
+// Sleep blocks until the clock is advanced by d.
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// Advance moves the clock forward by d and fires the timers that are due.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.advanceTo(c.now.Add(d))
+}
+
+// BlockUntil blocks until there are at least n pending timers, e.g sleeping goroutines, or ctx is done.
+func (c *FakeClock) BlockUntil(ctx context.Context, n int) error {
+	for {
+		c.mu.Lock()
+		pending := len(c.timers)
+		changed := c.changed
+		c.mu.Unlock()
+
+		if pending >= n {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (c *FakeClock) schedule(timer *fakeTimer, d time.Duration) {
+	timer.deadline = c.now.Add(d)
+
+	if d <= 0 {
+		timer.fire(c.now)
+		return
+	}
+
+	c.timers = append(c.timers, timer)
+	c.notify()
+
+	if c.autoAdvance {
+		c.advanceTo(timer.deadline)
+	}
+}
+
+func (c *FakeClock) advanceTo(now time.Time) {
+	c.now = now
+
+	pending := c.timers[:0]
+
+	for _, timer := range c.timers {
+		if timer.deadline.After(now) {
+			pending = append(pending, timer)
+			continue
+		}
+
+		timer.fire(now)
+	}
+
+	c.timers = pending
+	c.notify()
+}
+
+// remove removes the pending timer and reports whether it was pending.
+func (c *FakeClock) remove(timer *fakeTimer) bool {
+	for i, pending := range c.timers {
+		if pending == timer {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.notify()
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	c        chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := t.clock.remove(t)
+	t.clock.schedule(t, d)
+
+	return active
+}
+
+func (t *fakeTimer) fire(now time.Time) {
+	select {
+	case t.c <- now:
+	default:
+	}
+}
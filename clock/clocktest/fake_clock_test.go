@@ -0,0 +1,147 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clocktest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeClock_Advance(t *testing.T) {
+	t.Run(
+		"it fires only the timers that are due",
+		func(t *testing.T) {
+			t.Parallel()
+
+			clock := NewFakeClock(start)
+			short := clock.NewTimer(time.Second)
+			long := clock.NewTimer(time.Minute)
+
+			clock.Advance(30 * time.Second)
+
+			select {
+			case fired := <-short.C():
+				assert.Equal(t, start.Add(30*time.Second), fired)
+			default:
+				t.Fatal("due timer not fired")
+			}
+
+			select {
+			case <-long.C():
+				t.Fatal("timer fired before due")
+			default:
+			}
+
+			assert.Equal(t, start.Add(30*time.Second), clock.Now())
+			assert.Equal(t, 30*time.Second, clock.Since(start))
+		},
+	)
+
+	t.Run(
+		"it wakes up a sleeping goroutine",
+		func(t *testing.T) {
+			t.Parallel()
+
+			clock := NewFakeClock(start)
+			done := make(chan struct{})
+
+			go func() {
+				clock.Sleep(time.Hour)
+				close(done)
+			}()
+
+			require.Nil(t, clock.BlockUntil(context.Background(), 1))
+			clock.Advance(time.Hour)
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("sleeping goroutine not woken up")
+			}
+		},
+	)
+}
+
+func TestFakeClock_SetAutoAdvance(t *testing.T) {
+	t.Run(
+		"it advances to the deadline of every new timer",
+		func(t *testing.T) {
+			t.Parallel()
+
+			clock := NewFakeClock(start)
+			clock.SetAutoAdvance(true)
+
+			clock.Sleep(time.Minute)
+			<-clock.After(time.Second)
+
+			assert.Equal(t, start.Add(time.Minute+time.Second), clock.Now())
+		},
+	)
+}
+
+func TestFakeClock_BlockUntil(t *testing.T) {
+	t.Run(
+		"it returns the context error when ctx is done before the timers are pending",
+		func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := NewFakeClock(start).BlockUntil(ctx, 1)
+			assert.Equal(t, context.Canceled, err)
+		},
+	)
+}
+
+func TestFakeTimer(t *testing.T) {
+	t.Run(
+		"it doesn't fire a stopped timer and fires a reset one at the new deadline",
+		func(t *testing.T) {
+			t.Parallel()
+
+			clock := NewFakeClock(start)
+			timer := clock.NewTimer(time.Second)
+
+			assert.True(t, timer.Stop())
+			assert.False(t, timer.Stop())
+
+			clock.Advance(time.Second)
+
+			select {
+			case <-timer.C():
+				t.Fatal("stopped timer fired")
+			default:
+			}
+
+			assert.False(t, timer.Reset(time.Minute))
+
+			clock.Advance(time.Minute)
+
+			select {
+			case fired := <-timer.C():
+				assert.Equal(t, start.Add(time.Minute+time.Second), fired)
+			default:
+				t.Fatal("reset timer not fired")
+			}
+		},
+	)
+}
@@ -0,0 +1,48 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"fmt"
+)
+
+// PanicError is returned for the recovered panics, e.g of the jobs run by a Pool.
+type PanicError struct {
+	value interface{}
+	stack []byte
+}
+
+// NewPanicError creates PanicError instance.
+func NewPanicError(value interface{}, stack []byte) error {
+	return &PanicError{
+		value: value,
+		stack: stack,
+	}
+}
+
+// Error returns the error message along with the stack trace of the panic.
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("recovered panic: %v\n%s", err.value, err.stack)
+}
+
+// Value returns the value passed to panic.
+func (err *PanicError) Value() interface{} {
+	return err.value
+}
+
+// Stack returns the stack trace of the panicking goroutine.
+func (err *PanicError) Stack() []byte {
+	return err.stack
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+)
+
+// ErrPoolDrained is returned when submitting a job to a drained Pool.
+var ErrPoolDrained = errors.New("pool is drained")
+
+// Job is a unit of work run by a Pool.
+type Job func(ctx context.Context) error
+
+// Pool runs the submitted jobs with a fixed number of workers.
+// The panics of the jobs are recovered and returned as PanicError along with the job errors by Drain.
+type Pool struct {
+	ctx  context.Context
+	jobs chan Job
+	wg   sync.WaitGroup
+
+	// submitMu guards drained, so that no submit starts after Drain, which waits for the started ones
+	// before closing jobs. A submit blocked on a worker is released by closing draining.
+	submitMu sync.RWMutex
+	drained  bool
+	draining chan struct{}
+	submits  sync.WaitGroup
+
+	errsMu sync.Mutex
+	errs   *errors.MultiError
+}
+
+// NewPool creates Pool instance, with `workers` workers running the jobs with ctx.
+func NewPool(ctx context.Context, workers int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+
+	pool := &Pool{
+		ctx:      ctx,
+		jobs:     make(chan Job),
+		draining: make(chan struct{}),
+	}
+
+	pool.wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+
+	return pool
+}
+
+// Submit blocks until a worker picks up the job, so that the producers are throttled by the workers.
+// Returns ErrPoolDrained when the pool is drained before a worker is free, e.g for the jobs submitted by a job,
+// or `ctx.Err()` when ctx is done before a worker is free.
+func (p *Pool) Submit(ctx context.Context, job Job) error {
+	p.submitMu.RLock()
+	if p.drained {
+		p.submitMu.RUnlock()
+		return ErrPoolDrained
+	}
+
+	p.submits.Add(1)
+	p.submitMu.RUnlock()
+
+	defer p.submits.Done()
+
+	select {
+	case p.jobs <- job:
+		return nil
+	case <-p.draining:
+		return ErrPoolDrained
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Drain stops accepting jobs, waits for the submitted jobs to finish and returns their errors
+// as errors.MultiError, or nil when all succeeded. Subsequent calls return the same errors.
+func (p *Pool) Drain() error {
+	p.submitMu.Lock()
+	first := !p.drained
+	if first {
+		p.drained = true
+		close(p.draining)
+	}
+	p.submitMu.Unlock()
+
+	if first {
+		p.submits.Wait()
+		close(p.jobs)
+	}
+
+	p.wg.Wait()
+
+	p.errsMu.Lock()
+	defer p.errsMu.Unlock()
+
+	return p.errs.ErrorOrNil()
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for job := range p.jobs {
+		err := p.run(job)
+		if err == nil {
+			continue
+		}
+
+		p.errsMu.Lock()
+		p.errs = errors.Append(p.errs, err)
+		p.errsMu.Unlock()
+	}
+}
+
+func (p *Pool) run(job Job) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = NewPanicError(value, debug.Stack())
+		}
+	}()
+
+	return job(p.ctx)
+}
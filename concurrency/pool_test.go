@@ -0,0 +1,171 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+)
+
+func TestPool(t *testing.T) {
+	t.Run(
+		"it runs all the submitted jobs with at most the workers count concurrently",
+		func(t *testing.T) {
+			t.Parallel()
+
+			var running, maxRunning, done int32
+
+			pool := NewPool(context.Background(), 2)
+
+			for i := 0; i < 10; i++ {
+				err := pool.Submit(context.Background(), func(ctx context.Context) error {
+					current := atomic.AddInt32(&running, 1)
+					for {
+						max := atomic.LoadInt32(&maxRunning)
+						if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+							break
+						}
+					}
+
+					atomic.AddInt32(&running, -1)
+					atomic.AddInt32(&done, 1)
+
+					return nil
+				})
+				require.Nil(t, err)
+			}
+
+			assert.Nil(t, pool.Drain())
+			assert.Equal(t, int32(10), atomic.LoadInt32(&done))
+			assert.True(t, atomic.LoadInt32(&maxRunning) <= 2)
+		},
+	)
+
+	t.Run(
+		"it returns the job errors and recovered panics aggregated on drain",
+		func(t *testing.T) {
+			t.Parallel()
+
+			pool := NewPool(context.Background(), 1)
+
+			require.Nil(t, pool.Submit(context.Background(), func(ctx context.Context) error {
+				return errors.New("push failed")
+			}))
+			require.Nil(t, pool.Submit(context.Background(), func(ctx context.Context) error {
+				panic("nil manifest")
+			}))
+			require.Nil(t, pool.Submit(context.Background(), func(ctx context.Context) error {
+				return nil
+			}))
+
+			err := pool.Drain()
+			require.Error(t, err)
+
+			var multiErr *errors.MultiError
+			require.True(t, errors.As(err, &multiErr))
+			require.Len(t, multiErr.Errors(), 2)
+			assert.EqualError(t, multiErr.Errors()[0], "push failed")
+
+			var panicErr *PanicError
+			require.True(t, errors.As(multiErr.Errors()[1], &panicErr))
+			assert.Equal(t, "nil manifest", panicErr.Value())
+			assert.NotEmpty(t, panicErr.Stack())
+			assert.Equal(t, err, pool.Drain())
+		},
+	)
+
+	t.Run(
+		"it rejects the jobs submitted after drain",
+		func(t *testing.T) {
+			t.Parallel()
+
+			pool := NewPool(context.Background(), 1)
+			require.Nil(t, pool.Drain())
+
+			err := pool.Submit(context.Background(), func(ctx context.Context) error {
+				return nil
+			})
+			assert.Equal(t, ErrPoolDrained, err)
+		},
+	)
+
+	t.Run(
+		"it rejects the jobs submitted by a job when drained, instead of deadlocking",
+		func(t *testing.T) {
+			t.Parallel()
+
+			pool := NewPool(context.Background(), 1)
+			started := make(chan struct{})
+			submitted := make(chan error, 1)
+
+			require.Nil(t, pool.Submit(context.Background(), func(ctx context.Context) error {
+				close(started)
+				submitted <- pool.Submit(context.Background(), func(ctx context.Context) error {
+					return nil
+				})
+
+				return nil
+			}))
+			<-started
+
+			drained := make(chan error, 1)
+			go func() {
+				drained <- pool.Drain()
+			}()
+
+			select {
+			case err := <-drained:
+				assert.Nil(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("drain deadlocked by the job submit")
+			}
+
+			assert.Equal(t, ErrPoolDrained, <-submitted)
+		},
+	)
+
+	t.Run(
+		"it returns the context error when ctx is done before a worker is free",
+		func(t *testing.T) {
+			t.Parallel()
+
+			release := make(chan struct{})
+			pool := NewPool(context.Background(), 1)
+
+			require.Nil(t, pool.Submit(context.Background(), func(ctx context.Context) error {
+				<-release
+				return nil
+			}))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := pool.Submit(ctx, func(ctx context.Context) error {
+				return nil
+			})
+			assert.Equal(t, context.Canceled, err)
+
+			close(release)
+			assert.Nil(t, pool.Drain())
+		},
+	)
+}
@@ -0,0 +1,137 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package concurrency provides a worker pool and a weighted semaphore, e.g to bound the concurrent
+// commands against a cluster.
+package concurrency
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+)
+
+// Semaphore is a weighted semaphore, e.g to bound the memory of concurrent image builds by their size.
+// The waiters acquire in FIFO order, so a large acquisition is not starved by smaller ones.
+type Semaphore struct {
+	size int64
+
+	mu      sync.Mutex
+	current int64
+	waiters list.List
+}
+
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+func NewSemaphore(size int64) *Semaphore {
+	return &Semaphore{size: size}
+}
+
+// Acquire acquires n, blocking until it's available or ctx is done, in which case it returns `ctx.Err()`.
+// Returns error when n exceeds the size of the semaphore, since it can never be acquired.
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	s.mu.Lock()
+
+	if n > s.size {
+		s.mu.Unlock()
+		return errors.Errorf("acquiring %d exceeds the semaphore size %d", n, s.size)
+	}
+
+	if s.size-s.current >= n && s.waiters.Len() == 0 {
+		s.current += n
+		s.mu.Unlock()
+
+		return nil
+	}
+
+	waiter := &semaphoreWaiter{n: n, ready: make(chan struct{})}
+	element := s.waiters.PushBack(waiter)
+	s.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		// NOTE: Acquired while ctx was done, give it back to the other waiters.
+		s.current -= n
+		s.notifyWaiters()
+	default:
+		front := s.waiters.Front() == element
+		s.waiters.Remove(element)
+
+		// NOTE: The waiters behind a removed front one might fit now.
+		if front {
+			s.notifyWaiters()
+		}
+	}
+
+	return ctx.Err()
+}
+
+// TryAcquire acquires n without blocking and reports whether it succeeded.
+func (s *Semaphore) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size-s.current < n || s.waiters.Len() > 0 {
+		return false
+	}
+
+	s.current += n
+
+	return true
+}
+
+// Release releases n. It panics when releasing more than acquired.
+func (s *Semaphore) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.current -= n
+	if s.current < 0 {
+		panic("concurrency: semaphore released more than acquired")
+	}
+
+	s.notifyWaiters()
+}
+
+func (s *Semaphore) notifyWaiters() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+
+		waiter := front.Value.(*semaphoreWaiter)
+		if s.size-s.current < waiter.n {
+			return
+		}
+
+		s.current += waiter.n
+		s.waiters.Remove(front)
+		close(waiter.ready)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemaphore_Acquire(t *testing.T) {
+	t.Run(
+		"it blocks until enough is released",
+		func(t *testing.T) {
+			t.Parallel()
+
+			semaphore := NewSemaphore(3)
+			require.Nil(t, semaphore.Acquire(context.Background(), 2))
+
+			acquired := make(chan struct{})
+			go func() {
+				_ = semaphore.Acquire(context.Background(), 2)
+				close(acquired)
+			}()
+
+			select {
+			case <-acquired:
+				t.Fatal("acquired before release")
+			case <-time.After(20 * time.Millisecond):
+			}
+
+			semaphore.Release(2)
+
+			select {
+			case <-acquired:
+			case <-time.After(time.Second):
+				t.Fatal("not acquired after release")
+			}
+		},
+	)
+
+	t.Run(
+		"it acquires in FIFO order, so that small acquisitions do not starve a large one",
+		func(t *testing.T) {
+			t.Parallel()
+
+			semaphore := NewSemaphore(2)
+			require.Nil(t, semaphore.Acquire(context.Background(), 1))
+
+			large := make(chan struct{})
+			go func() {
+				_ = semaphore.Acquire(context.Background(), 2)
+				close(large)
+			}()
+
+			timeout := time.After(time.Second)
+			for waiters(semaphore) != 1 {
+				select {
+				case <-timeout:
+					t.Fatal("large acquisition is not waiting")
+				case <-time.After(time.Millisecond):
+				}
+			}
+
+			assert.False(t, semaphore.TryAcquire(1))
+
+			semaphore.Release(1)
+			<-large
+		},
+	)
+
+	t.Run(
+		"it returns the context error and lets the next waiters acquire when ctx is done",
+		func(t *testing.T) {
+			t.Parallel()
+
+			semaphore := NewSemaphore(2)
+			require.Nil(t, semaphore.Acquire(context.Background(), 1))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			err := semaphore.Acquire(ctx, 2)
+			assert.Equal(t, context.DeadlineExceeded, err)
+			assert.True(t, semaphore.TryAcquire(1))
+		},
+	)
+
+	t.Run(
+		"it returns error when acquiring more than the size",
+		func(t *testing.T) {
+			t.Parallel()
+
+			err := NewSemaphore(2).Acquire(context.Background(), 3)
+			assert.EqualError(t, err, "acquiring 3 exceeds the semaphore size 2")
+		},
+	)
+}
+
+func TestSemaphore_Release(t *testing.T) {
+	t.Run(
+		"it panics when releasing more than acquired",
+		func(t *testing.T) {
+			t.Parallel()
+
+			assert.PanicsWithValue(t, "concurrency: semaphore released more than acquired", func() {
+				NewSemaphore(1).Release(1)
+			})
+		},
+	)
+}
+
+func waiters(semaphore *Semaphore) int {
+	semaphore.mu.Lock()
+	defer semaphore.mu.Unlock()
+
+	return semaphore.waiters.Len()
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// field is a settable leaf field of a configuration struct.
+type field struct {
+	// path is the dotted path of the field, e.g `Database.URL`, used in the errors.
+	path  string
+	value reflect.Value
+	tag   reflect.StructTag
+}
+
+// collectFields returns the exported leaf fields of value, recursing into the nested structs.
+func collectFields(value reflect.Value, prefix string) []*field {
+	var fields []*field
+
+	valueType := value.Type()
+
+	for i := 0; i < valueType.NumField(); i++ {
+		structField := valueType.Field(i)
+		if structField.PkgPath != "" {
+			continue
+		}
+
+		path := prefix + structField.Name
+
+		if structField.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectFields(value.Field(i), path+".")...)
+			continue
+		}
+
+		fields = append(
+			fields,
+			&field{
+				path:  path,
+				value: value.Field(i),
+				tag:   structField.Tag,
+			},
+		)
+	}
+
+	return fields
+}
+
+func (f *field) set(s string) error {
+	return setValue(f.value, s)
+}
+
+// setValue parses s into value. Slices are parsed from comma-separated values.
+func setValue(value reflect.Value, s string) error {
+	if value.Type() == durationType {
+		duration, err := time.ParseDuration(s)
+		if err != nil {
+			return errors.Errorf("invalid duration %q", s)
+		}
+
+		value.SetInt(int64(duration))
+
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.Errorf("invalid bool %q", s)
+		}
+
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(s, 10, value.Type().Bits())
+		if err != nil {
+			return errors.Errorf("invalid integer %q", s)
+		}
+
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(s, 10, value.Type().Bits())
+		if err != nil {
+			return errors.Errorf("invalid unsigned integer %q", s)
+		}
+
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(s, value.Type().Bits())
+		if err != nil {
+			return errors.Errorf("invalid float %q", s)
+		}
+
+		value.SetFloat(parsed)
+	case reflect.Slice:
+		items := strings.Split(s, ",")
+		slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+
+		for i, item := range items {
+			err := setValue(slice.Index(i), strings.TrimSpace(item))
+			if err != nil {
+				return err
+			}
+		}
+
+		value.Set(slice)
+	default:
+		return errors.Errorf("unsupported type %s", value.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,269 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config populates configuration structs from the `default` tags, YAML or JSON files,
+// environment variables and command line flags, in that order of precedence,
+// resolves the `file://` and `env://` references of the `secret` tags and validates the `validate` tags.
+//
+// E.g
+//
+//	type Config struct {
+//		Port     int           `yaml:"port" json:"port" env:"PORT" flag:"port" default:"8080" validate:"min=1,max=65535"`
+//		Timeout  time.Duration `yaml:"timeout" json:"timeout" env:"TIMEOUT" default:"30s"`
+//		Token    string        `yaml:"token" json:"token" env:"TOKEN" secret:"true" validate:"required"`
+//		LogLevel string        `yaml:"log_level" json:"log_level" flag:"log-level" default:"info" validate:"oneof=debug info warn error"`
+//	}
+//
+// where Token may be `file:///var/run/secrets/token` or `env://GITHUB_TOKEN`.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+const (
+	fileSecretScheme = "file://"
+	envSecretScheme  = "env://"
+)
+
+// LoaderOptions configures a Loader.
+type LoaderOptions struct {
+	// Files are the YAML (`.yaml`, `.yml`) or JSON (`.json`) files, decoded with the `yaml` or `json` tags,
+	// in order, with the later ones overriding the earlier ones.
+	Files []string
+	// EnvPrefix prefixes the `env` tag names, e.g `APP_`.
+	EnvPrefix string
+	// Args are the command line arguments of the `flag` tags, e.g `os.Args[1:]`.
+	Args []string
+}
+
+// envLookuper is implemented by the executors distinguishing the empty env from the unset one,
+// e.g os.RealOsExecutor.
+type envLookuper interface {
+	LookupEnv(key string) (string, bool)
+}
+
+// Loader loads configuration structs.
+type Loader struct {
+	osExecutor os.OsExecutor
+	options    LoaderOptions
+}
+
+func NewLoader(osExecutor os.OsExecutor, options *LoaderOptions) *Loader {
+	var opts LoaderOptions
+	if options != nil {
+		opts = *options
+	}
+
+	return &Loader{
+		osExecutor: osExecutor,
+		options:    opts,
+	}
+}
+
+// Load populates target, a pointer to struct, and returns all the loading and validation errors
+// as errors.MultiError.
+func (l *Loader) Load(target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("config target must be a pointer to struct, got %T", target)
+	}
+
+	fields := collectFields(value.Elem(), "")
+
+	var result *errors.MultiError
+
+	result = errors.Append(result, l.loadDefaults(fields))
+	result = errors.Append(result, l.loadFiles(target))
+	result = errors.Append(result, l.loadEnv(fields))
+	result = errors.Append(result, l.loadFlags(fields))
+	result = errors.Append(result, l.resolveSecrets(fields))
+
+	// NOTE: Validating fields that failed to load only adds noise.
+	if result.ErrorOrNil() != nil {
+		return result
+	}
+
+	return validate(fields)
+}
+
+func (l *Loader) loadDefaults(fields []*field) error {
+	var result *errors.MultiError
+
+	for _, f := range fields {
+		defaultValue, ok := f.tag.Lookup("default")
+		if !ok {
+			continue
+		}
+
+		err := f.set(defaultValue)
+		if err != nil {
+			result = errors.Append(result, errors.Wrapf(err, "invalid default of %s", f.path))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (l *Loader) loadFiles(target interface{}) error {
+	var result *errors.MultiError
+
+	for _, filename := range l.options.Files {
+		content, err := l.osExecutor.ReadFile(filename)
+		if err != nil {
+			result = errors.Append(result, errors.Wrapf(err, "failed to read config file %s", filename))
+			continue
+		}
+
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".yaml", ".yml":
+			err = yaml.UnmarshalStrict(content, target)
+		case ".json":
+			decoder := json.NewDecoder(bytes.NewReader(content))
+			decoder.DisallowUnknownFields()
+			err = decoder.Decode(target)
+		default:
+			err = errors.New("unsupported format, expected .yaml, .yml or .json")
+		}
+
+		if err != nil {
+			result = errors.Append(result, errors.Wrapf(err, "failed to decode config file %s", filename))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (l *Loader) loadEnv(fields []*field) error {
+	var result *errors.MultiError
+
+	for _, f := range fields {
+		name, ok := f.tag.Lookup("env")
+		if !ok {
+			continue
+		}
+
+		name = l.options.EnvPrefix + name
+
+		envValue, ok := l.lookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		err := f.set(envValue)
+		if err != nil {
+			result = errors.Append(result, errors.Wrapf(err, "invalid env %s of %s", name, f.path))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (l *Loader) loadFlags(fields []*field) error {
+	flagSet := flag.NewFlagSet("config", flag.ContinueOnError)
+	flagSet.SetOutput(ioutil.Discard)
+
+	for _, f := range fields {
+		name, ok := f.tag.Lookup("flag")
+		if !ok {
+			continue
+		}
+
+		flagSet.Var(&flagValue{field: f}, name, f.tag.Get("usage"))
+	}
+
+	err := flagSet.Parse(l.options.Args)
+	if err != nil {
+		return errors.Wrap(err, "invalid flags")
+	}
+
+	return nil
+}
+
+func (l *Loader) resolveSecrets(fields []*field) error {
+	var result *errors.MultiError
+
+	for _, f := range fields {
+		if f.tag.Get("secret") != "true" || f.value.Kind() != reflect.String {
+			continue
+		}
+
+		reference := f.value.String()
+
+		switch {
+		case strings.HasPrefix(reference, fileSecretScheme):
+			filename := strings.TrimPrefix(reference, fileSecretScheme)
+
+			content, err := l.osExecutor.ReadFile(filename)
+			if err != nil {
+				result = errors.Append(result, errors.Wrapf(err, "failed to read secret file %s of %s", filename, f.path))
+				continue
+			}
+
+			f.value.SetString(strings.TrimRight(string(content), "\r\n"))
+		case strings.HasPrefix(reference, envSecretScheme):
+			name := strings.TrimPrefix(reference, envSecretScheme)
+
+			envValue, ok := l.lookupEnv(name)
+			if !ok {
+				result = errors.Append(result, errors.Errorf("secret env %s of %s is not set", name, f.path))
+				continue
+			}
+
+			f.value.SetString(envValue)
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+// lookupEnv looks up the env with the executor, when it implements envLookuper.
+// Otherwise, the empty env is taken as unset.
+func (l *Loader) lookupEnv(name string) (string, bool) {
+	if lookuper, ok := l.osExecutor.(envLookuper); ok {
+		return lookuper.LookupEnv(name)
+	}
+
+	envValue := l.osExecutor.Getenv(name)
+
+	return envValue, envValue != ""
+}
+
+// flagValue is a flag.Value setting a field, so that only the flags present in the arguments override it.
+type flagValue struct {
+	field *field
+}
+
+func (v *flagValue) String() string {
+	return ""
+}
+
+func (v *flagValue) Set(s string) error {
+	return v.field.set(s)
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.field.value.Kind() == reflect.Bool
+}
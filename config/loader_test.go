@@ -0,0 +1,254 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/os"
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+type testDatabaseConfig struct {
+	URL      string `yaml:"url" json:"url" env:"DATABASE_URL" secret:"true" validate:"required"`
+	MaxConns int    `yaml:"max_conns" json:"max_conns" env:"DATABASE_MAX_CONNS" default:"10" validate:"min=1,max=100"`
+}
+
+type testConfig struct {
+	Name     string             `yaml:"name" json:"name" env:"NAME" default:"app"`
+	Port     int                `yaml:"port" json:"port" env:"PORT" flag:"port" default:"8080"`
+	Debug    bool               `yaml:"debug" json:"debug" flag:"debug"`
+	Timeout  time.Duration      `yaml:"timeout" json:"timeout" env:"TIMEOUT" default:"30s"`
+	Hosts    []string           `yaml:"hosts" json:"hosts" env:"HOSTS"`
+	LogLevel string             `yaml:"log_level" json:"log_level" flag:"log-level" default:"info" validate:"oneof=debug info"`
+	Database testDatabaseConfig `yaml:"database" json:"database"`
+}
+
+func TestLoader_Load(t *testing.T) {
+	t.Run(
+		"it loads defaults, files, env and flags in order of precedence",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On("ReadFile", "config.yaml").Return(
+				[]byte("port: 9000\ntimeout: 5s\nlog_level: debug\ndatabase:\n  url: postgres://file\n"),
+				nil,
+			).Once()
+			executorArg.On("ReadFile", "config.local.json").Return(
+				[]byte(`{"hosts": ["a.example.com"], "database": {"url": "postgres://local"}}`),
+				nil,
+			).Once()
+			executorArg.On("LookupEnv", "APP_PORT").Return("9100", true).Once()
+			executorArg.On("LookupEnv", "APP_HOSTS").Return("b.example.com, c.example.com", true).Once()
+			executorArg.On("LookupEnv", mock.Anything).Return("", false)
+
+			loader := NewLoader(
+				executorArg,
+				&LoaderOptions{
+					Files:     []string{"config.yaml", "config.local.json"},
+					EnvPrefix: "APP_",
+					Args:      []string{"--port", "9200", "--debug"},
+				},
+			)
+
+			var config testConfig
+
+			err := loader.Load(&config)
+			require.Nil(t, err)
+			assert.Equal(
+				t,
+				testConfig{
+					Name:     "app",
+					Port:     9200,
+					Debug:    true,
+					Timeout:  5 * time.Second,
+					Hosts:    []string{"b.example.com", "c.example.com"},
+					LogLevel: "debug",
+					Database: testDatabaseConfig{
+						URL:      "postgres://local",
+						MaxConns: 10,
+					},
+				},
+				config,
+			)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"it resolves the file and env secret references",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On("LookupEnv", "DATABASE_URL").Return("file:///run/secrets/database-url", true).Once()
+			executorArg.On("LookupEnv", "HOSTS").Return("env://LEGACY_HOSTS", true).Once()
+			executorArg.On("LookupEnv", "NAME").Return("env://LEGACY_NAME", true).Once()
+			executorArg.On("LookupEnv", mock.Anything).Return("", false)
+			executorArg.On("ReadFile", "/run/secrets/database-url").Return([]byte("postgres://secret\n"), nil).Once()
+
+			var config testConfig
+
+			err := NewLoader(executorArg, nil).Load(&config)
+			require.Nil(t, err)
+			assert.Equal(t, "postgres://secret", config.Database.URL)
+			// NOTE: Only the `secret` string fields are references, the rest are taken as is.
+			assert.Equal(t, "env://LEGACY_NAME", config.Name)
+			assert.Equal(t, []string{"env://LEGACY_HOSTS"}, config.Hosts)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"it overrides the defaults with the empty env",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On("LookupEnv", "NAME").Return("", true).Once()
+			executorArg.On("LookupEnv", "DATABASE_URL").Return("postgres://env", true).Once()
+			executorArg.On("LookupEnv", mock.Anything).Return("", false)
+
+			var config testConfig
+
+			err := NewLoader(executorArg, nil).Load(&config)
+			require.Nil(t, err)
+			assert.Equal(t, "", config.Name)
+			assert.Equal(t, 8080, config.Port)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"when the executor does not look up the env, it takes the empty env as unset",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On("Getenv", "DATABASE_URL").Return("postgres://env").Once()
+			executorArg.On("Getenv", mock.Anything).Return("")
+
+			var config testConfig
+
+			err := NewLoader(struct{ os.OsExecutor }{executorArg}, nil).Load(&config)
+			require.Nil(t, err)
+			assert.Equal(t, "app", config.Name)
+			assert.Equal(t, "postgres://env", config.Database.URL)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"it returns all the loading errors aggregated",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On("ReadFile", "config.toml").Return([]byte("port = 1"), nil).Once()
+			executorArg.On("LookupEnv", "PORT").Return("eighty", true).Once()
+			executorArg.On("LookupEnv", "TIMEOUT").Return("5 minutes", true).Once()
+			executorArg.On("LookupEnv", "DATABASE_URL").Return("env://MISSING_URL", true).Once()
+			executorArg.On("LookupEnv", mock.Anything).Return("", false)
+
+			var config testConfig
+
+			err := NewLoader(executorArg, &LoaderOptions{Files: []string{"config.toml"}}).Load(&config)
+			require.Error(t, err)
+
+			var multiErr *errors.MultiError
+			require.True(t, errors.As(err, &multiErr))
+			assert.Len(t, multiErr.Errors(), 4)
+			assert.Contains(t, err.Error(), "failed to decode config file config.toml: unsupported format")
+			assert.Contains(t, err.Error(), `invalid env PORT of Port: invalid integer "eighty"`)
+			assert.Contains(t, err.Error(), `invalid env TIMEOUT of Timeout: invalid duration "5 minutes"`)
+			assert.Contains(t, err.Error(), "secret env MISSING_URL of Database.URL is not set")
+		},
+	)
+
+	t.Run(
+		"it returns the validation errors aggregated",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On("LookupEnv", "DATABASE_MAX_CONNS").Return("0", true).Once()
+			executorArg.On("LookupEnv", mock.Anything).Return("", false)
+
+			var config testConfig
+
+			err := NewLoader(executorArg, &LoaderOptions{Args: []string{"--log-level=trace"}}).Load(&config)
+			require.Error(t, err)
+			assert.Equal(
+				t,
+				"3 errors occurred:\n"+
+					"\t* invalid LogLevel: must be one of debug, info, got \"trace\"\n"+
+					"\t* invalid Database.URL: value is required\n"+
+					"\t* invalid Database.MaxConns: must be at least 1",
+				err.Error(),
+			)
+		},
+	)
+
+	t.Run(
+		"it returns error when a file has unknown keys",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On("ReadFile", "config.json").Return([]byte(`{"prot": 9000}`), nil).Once()
+			executorArg.On("LookupEnv", mock.Anything).Return("", false)
+
+			var config testConfig
+
+			err := NewLoader(executorArg, &LoaderOptions{Files: []string{"config.json"}}).Load(&config)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `failed to decode config file config.json: json: unknown field "prot"`)
+		},
+	)
+
+	t.Run(
+		"it returns error when the flags are invalid",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On("LookupEnv", mock.Anything).Return("", false)
+
+			var config testConfig
+
+			err := NewLoader(executorArg, &LoaderOptions{Args: []string{"--verbose"}}).Load(&config)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid flags: flag provided but not defined: -verbose")
+		},
+	)
+
+	t.Run(
+		"it returns error when the target is not a pointer to struct",
+		func(t *testing.T) {
+			t.Parallel()
+
+			err := NewLoader(ostest.NewFakeOsExecutor(t), nil).Load(testConfig{})
+			require.Error(t, err)
+			assert.Equal(t, "config target must be a pointer to struct, got config.testConfig", err.Error())
+		},
+	)
+}
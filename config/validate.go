@@ -0,0 +1,136 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+)
+
+// validate checks the fields against the rules of their `validate` tags, e.g `required,min=1,max=10`:
+//
+//   - `required` rejects zero values.
+//   - `min` and `max` bound the numbers, or the length of the strings and slices.
+//   - `oneof` allows only the space-separated values, e.g `oneof=debug info`.
+func validate(fields []*field) error {
+	var result *errors.MultiError
+
+	for _, f := range fields {
+		rules, ok := f.tag.Lookup("validate")
+		if !ok {
+			continue
+		}
+
+		for _, rule := range strings.Split(rules, ",") {
+			name, argument := splitRule(rule)
+
+			err := validateRule(f.value, name, argument)
+			if err != nil {
+				result = errors.Append(result, errors.Wrapf(err, "invalid %s", f.path))
+			}
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+func splitRule(rule string) (string, string) {
+	parts := strings.SplitN(strings.TrimSpace(rule), "=", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
+
+func validateRule(value reflect.Value, name, argument string) error {
+	switch name {
+	case "required":
+		if isZero(value) {
+			return errors.New("value is required")
+		}
+	case "min", "max":
+		bound, err := parseBound(value, argument)
+		if err != nil {
+			return errors.Errorf("invalid %s rule argument %q", name, argument)
+		}
+
+		measure, unit, ok := measureOf(value)
+		if !ok {
+			return errors.Errorf("%s rule is not supported for type %s", name, value.Type())
+		}
+
+		if name == "min" && measure < bound {
+			return errors.Errorf("must be at least %s%s", argument, unit)
+		}
+
+		if name == "max" && measure > bound {
+			return errors.Errorf("must be at most %s%s", argument, unit)
+		}
+	case "oneof":
+		allowed := strings.Fields(argument)
+		if value.Kind() != reflect.String {
+			return errors.Errorf("oneof rule is not supported for type %s", value.Type())
+		}
+
+		for _, item := range allowed {
+			if value.String() == item {
+				return nil
+			}
+		}
+
+		return errors.Errorf("must be one of %s, got %q", strings.Join(allowed, ", "), value.String())
+	default:
+		return errors.Errorf("unknown validation rule %q", name)
+	}
+
+	return nil
+}
+
+// parseBound parses the argument of `min` and `max`, which is a duration, e.g `1s`, for the duration values.
+func parseBound(value reflect.Value, argument string) (float64, error) {
+	if value.Type() == durationType {
+		duration, err := time.ParseDuration(argument)
+		return float64(duration), err
+	}
+
+	return strconv.ParseFloat(argument, 64)
+}
+
+// measureOf returns the number value, or the length of the string and slice value, compared by `min` and `max`.
+func measureOf(value reflect.Value) (float64, string, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), "", true
+	case reflect.String, reflect.Slice:
+		return float64(value.Len()), " in length", true
+	default:
+		return 0, "", false
+	}
+}
+
+// isZero reports whether value is the zero value of its type.
+// NOTE: `reflect.Value.IsZero` is not available before go1.13.
+func isZero(value reflect.Value) bool {
+	return reflect.DeepEqual(value.Interface(), reflect.Zero(value.Type()).Interface())
+}
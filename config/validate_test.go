@@ -0,0 +1,75 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run(
+		"it accepts the values satisfying the rules",
+		func(t *testing.T) {
+			t.Parallel()
+
+			config := struct {
+				Name    string        `validate:"required,max=5"`
+				Tags    []string      `validate:"min=1"`
+				Ratio   float64       `validate:"min=0,max=1"`
+				Timeout time.Duration `validate:"min=1s,max=1m"`
+			}{
+				Name:    "api",
+				Tags:    []string{"blue"},
+				Ratio:   0.5,
+				Timeout: 30 * time.Second,
+			}
+
+			err := validate(collectFields(reflect.ValueOf(&config).Elem(), ""))
+			assert.Nil(t, err)
+		},
+	)
+
+	t.Run(
+		"it returns the violations of every rule",
+		func(t *testing.T) {
+			t.Parallel()
+
+			config := struct {
+				Name    string        `validate:"max=2"`
+				Tags    []string      `validate:"min=1"`
+				Timeout time.Duration `validate:"max=1m"`
+				Enabled bool          `validate:"positive"`
+			}{
+				Name:    "api",
+				Timeout: time.Hour,
+			}
+
+			err := validate(collectFields(reflect.ValueOf(&config).Elem(), ""))
+			assert.EqualError(
+				t,
+				err,
+				"4 errors occurred:\n"+
+					"\t* invalid Name: must be at most 2 in length\n"+
+					"\t* invalid Tags: must be at least 1 in length\n"+
+					"\t* invalid Timeout: must be at most 1m\n"+
+					"\t* invalid Enabled: unknown validation rule \"positive\"",
+			)
+		},
+	)
+}
@@ -0,0 +1,270 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errors creates errors which capture the stack trace of their origin,
+// so that failures bubbling up from deep call chains, e.g of executors, can be traced back.
+// The errors are compatible with the standard library `errors.Is`, `errors.As` and `errors.Unwrap`,
+// and print the causes chain along with the stack trace when formatted with `%+v`.
+package errors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Is reports whether any error in err's chain matches target. See the standard library `errors.Is`.
+// NOTE: The standard library one is not available before go1.13, this one supports go1.12 and walks
+// the `Unwrap() []error` chains of go1.20 too.
+func Is(err, target error) bool {
+	if err == nil || target == nil {
+		return err == target
+	}
+
+	return is(err, target, reflect.TypeOf(target).Comparable())
+}
+
+// As finds the first error in err's chain that matches target. See the standard library `errors.As`.
+// It panics when target is not a non-nil pointer to an interface or to a type implementing error.
+func As(err error, target interface{}) bool {
+	if target == nil {
+		panic("errors: target cannot be nil")
+	}
+
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() {
+		panic("errors: target must be a non-nil pointer")
+	}
+
+	targetType := targetValue.Type().Elem()
+	if targetType.Kind() != reflect.Interface && !targetType.Implements(errorType) {
+		panic("errors: *target must be interface or implement error")
+	}
+
+	if err == nil {
+		return false
+	}
+
+	return as(err, target, targetValue, targetType)
+}
+
+// Unwrap returns the cause of err, or nil when err has no cause. See the standard library `errors.Unwrap`.
+func Unwrap(err error) error {
+	unwrapper, ok := err.(interface{ Unwrap() error })
+	if !ok {
+		return nil
+	}
+
+	return unwrapper.Unwrap()
+}
+
+func is(err, target error, targetComparable bool) bool {
+	for {
+		if targetComparable && err == target {
+			return true
+		}
+
+		if matcher, ok := err.(interface{ Is(error) bool }); ok && matcher.Is(target) {
+			return true
+		}
+
+		switch unwrapper := err.(type) {
+		case interface{ Unwrap() error }:
+			err = unwrapper.Unwrap()
+			if err == nil {
+				return false
+			}
+		case interface{ Unwrap() []error }:
+			for _, err := range unwrapper.Unwrap() {
+				if err != nil && is(err, target, targetComparable) {
+					return true
+				}
+			}
+
+			return false
+		default:
+			return false
+		}
+	}
+}
+
+func as(err error, target interface{}, targetValue reflect.Value, targetType reflect.Type) bool {
+	for {
+		if reflect.TypeOf(err).AssignableTo(targetType) {
+			targetValue.Elem().Set(reflect.ValueOf(err))
+			return true
+		}
+
+		if matcher, ok := err.(interface{ As(interface{}) bool }); ok && matcher.As(target) {
+			return true
+		}
+
+		switch unwrapper := err.(type) {
+		case interface{ Unwrap() error }:
+			err = unwrapper.Unwrap()
+			if err == nil {
+				return false
+			}
+		case interface{ Unwrap() []error }:
+			for _, err := range unwrapper.Unwrap() {
+				if err != nil && as(err, target, targetValue, targetType) {
+					return true
+				}
+			}
+
+			return false
+		default:
+			return false
+		}
+	}
+}
+
+// New returns an error with the message and the stack trace of the caller.
+func New(message string) error {
+	return &stackError{
+		message: message,
+		stack:   callers(),
+	}
+}
+
+// Errorf returns an error with the formatted message and the stack trace of the caller.
+// Unlike `fmt.Errorf`, the `%w` verb is not supported, use Wrapf instead.
+func Errorf(format string, args ...interface{}) error {
+	return &stackError{
+		message: fmt.Sprintf(format, args...),
+		stack:   callers(),
+	}
+}
+
+// Wrap returns an error annotating err with the message and the stack trace of the caller,
+// e.g `apply manifest failed: exit status 1`. Returns nil when err is nil.
+func Wrap(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &stackError{
+		message: message,
+		cause:   err,
+		stack:   callers(),
+	}
+}
+
+// Wrapf returns an error annotating err with the formatted message and the stack trace of the caller.
+// Returns nil when err is nil.
+func Wrapf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	return &stackError{
+		message: fmt.Sprintf(format, args...),
+		cause:   err,
+		stack:   callers(),
+	}
+}
+
+// WithStack returns an error with the message of err and the stack trace of the caller,
+// e.g to add the origin to an error of a third-party package. Returns nil when err is nil,
+// and err when it already has a stack trace.
+func WithStack(err error) error {
+	if err == nil || StackTrace(err) != nil {
+		return err
+	}
+
+	return &stackError{
+		cause: err,
+		stack: callers(),
+	}
+}
+
+// StackTrace returns the stack trace of the deepest error with one in err's chain, which is the closest to
+// the origin of the failure. Returns nil when there is none.
+func StackTrace(err error) []Frame {
+	var frames []Frame
+
+	for err != nil {
+		if withStack, ok := err.(*stackError); ok {
+			frames = withStack.stack.frames()
+		}
+
+		err = Unwrap(err)
+	}
+
+	return frames
+}
+
+type stackError struct {
+	// message is blank for errors created by WithStack, which add only the stack trace to the cause.
+	message string
+	cause   error
+	stack   stack
+}
+
+func (e *stackError) Error() string {
+	switch {
+	case e.cause == nil:
+		return e.message
+	case e.message == "":
+		return e.cause.Error()
+	default:
+		return e.message + ": " + e.cause.Error()
+	}
+}
+
+func (e *stackError) Unwrap() error {
+	return e.cause
+}
+
+// Format formats the error message with `%s` and `%v`, and the causes chain along with the stack traces with `%+v`.
+func (e *stackError) Format(state fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if state.Flag('+') {
+			e.formatVerbose(state)
+			return
+		}
+
+		_, _ = io.WriteString(state, e.Error())
+	case 's':
+		_, _ = io.WriteString(state, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(state, "%q", e.Error())
+	}
+}
+
+func (e *stackError) formatVerbose(writer io.Writer) {
+	if e.message != "" {
+		_, _ = io.WriteString(writer, e.message)
+	}
+
+	for _, frame := range e.stack.frames() {
+		_, _ = fmt.Fprintf(writer, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+	}
+
+	if e.cause == nil {
+		return
+	}
+
+	_, _ = io.WriteString(writer, "\ncaused by: ")
+
+	if _, ok := e.cause.(fmt.Formatter); ok {
+		_, _ = fmt.Fprintf(writer, "%+v", e.cause)
+		return
+	}
+
+	_, _ = io.WriteString(writer, e.cause.Error())
+}
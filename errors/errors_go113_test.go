@@ -0,0 +1,35 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.13
+// +build go1.13
+
+package errors
+
+import (
+	stdErrors "errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStandardLibraryCompatibility(t *testing.T) {
+	t.Parallel()
+
+	var exitErr *fakeExitError
+	assert.True(t, stdErrors.As(deploy(), &exitErr))
+	assert.True(t, stdErrors.Is(Wrap(os.ErrNotExist, "read kubeconfig failed"), os.ErrNotExist))
+	assert.True(t, stdErrors.Is(Append(nil, New("fake error"), os.ErrNotExist), os.ErrNotExist))
+}
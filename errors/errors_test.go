@@ -0,0 +1,198 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExitError struct {
+	code int
+}
+
+func (e *fakeExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func applyManifest() error {
+	return Wrap(&fakeExitError{code: 1}, "apply manifest failed")
+}
+
+func deploy() error {
+	return Wrapf(applyManifest(), "deploy %s failed", "api")
+}
+
+type fakeJoinedError struct {
+	errs []error
+}
+
+func (e *fakeJoinedError) Error() string {
+	return "joined"
+}
+
+func (e *fakeJoinedError) Unwrap() []error {
+	return e.errs
+}
+
+var errFakeDeadline = New("deadline exceeded")
+
+type fakeTimeoutError struct{}
+
+func (e *fakeTimeoutError) Error() string {
+	return "timeout"
+}
+
+func (e *fakeTimeoutError) Is(target error) bool {
+	return target == errFakeDeadline
+}
+
+func TestIs(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Is(nil, nil))
+	assert.False(t, Is(os.ErrNotExist, nil))
+	assert.False(t, Is(nil, os.ErrNotExist))
+	assert.True(t, Is(Wrap(os.ErrNotExist, "read failed"), os.ErrNotExist))
+	assert.False(t, Is(Wrap(os.ErrNotExist, "read failed"), os.ErrPermission))
+	assert.True(t, Is(Wrap(&fakeTimeoutError{}, "apply failed"), errFakeDeadline))
+	assert.True(t, Is(&fakeJoinedError{errs: []error{nil, New("fake error"), os.ErrNotExist}}, os.ErrNotExist))
+}
+
+func TestAs(t *testing.T) {
+	t.Parallel()
+
+	var exitErr *fakeExitError
+	assert.False(t, As(nil, &exitErr))
+	assert.False(t, As(New("fake error"), &exitErr))
+
+	require.True(t, As(&fakeJoinedError{errs: []error{os.ErrNotExist, deploy()}}, &exitErr))
+	assert.Equal(t, 1, exitErr.code)
+
+	var timeoutErr interface{ Is(error) bool }
+	require.True(t, As(Wrap(&fakeTimeoutError{}, "apply failed"), &timeoutErr))
+	assert.IsType(t, &fakeTimeoutError{}, timeoutErr)
+
+	assert.Panics(t, func() { As(deploy(), nil) })
+	assert.Panics(t, func() { As(deploy(), exitErr) })
+	assert.Panics(t, func() { As(deploy(), new(string)) })
+}
+
+func TestUnwrap(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, os.ErrNotExist, Unwrap(Wrap(os.ErrNotExist, "read failed")))
+	assert.Nil(t, Unwrap(os.ErrNotExist))
+	assert.Nil(t, Unwrap(&fakeJoinedError{errs: []error{os.ErrNotExist}}))
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	actual := New("fake error")
+	assert.Equal(t, "fake error", actual.Error())
+	assert.Nil(t, Unwrap(actual))
+
+	frames := StackTrace(actual)
+	require.NotEmpty(t, frames)
+	assert.Equal(t, "github.com/sumup-oss/go-pkgs/errors.TestNew", frames[0].Function)
+	assert.True(t, strings.HasSuffix(frames[0].File, "errors_test.go"))
+}
+
+func TestErrorf(t *testing.T) {
+	t.Parallel()
+
+	actual := Errorf("unknown secret name %s", "password")
+	assert.Equal(t, "unknown secret name password", actual.Error())
+	assert.Equal(t, "github.com/sumup-oss/go-pkgs/errors.TestErrorf", StackTrace(actual)[0].Function)
+}
+
+func TestWrap(t *testing.T) {
+	t.Run(
+		"it annotates the cause and is compatible with Is and As",
+		func(t *testing.T) {
+			t.Parallel()
+
+			actual := deploy()
+			assert.Equal(t, "deploy api failed: apply manifest failed: exit status 1", actual.Error())
+
+			var exitErr *fakeExitError
+			require.True(t, As(actual, &exitErr))
+			assert.Equal(t, 1, exitErr.code)
+
+			wrapped := Wrap(os.ErrNotExist, "read kubeconfig failed")
+			assert.True(t, Is(wrapped, os.ErrNotExist))
+		},
+	)
+
+	t.Run(
+		"it returns the stack trace of the origin",
+		func(t *testing.T) {
+			t.Parallel()
+
+			frames := StackTrace(deploy())
+			require.True(t, len(frames) > 1)
+			assert.Equal(t, "github.com/sumup-oss/go-pkgs/errors.applyManifest", frames[0].Function)
+			assert.Equal(t, "github.com/sumup-oss/go-pkgs/errors.deploy", frames[1].Function)
+		},
+	)
+
+	t.Run(
+		"with nil error, it returns nil",
+		func(t *testing.T) {
+			t.Parallel()
+
+			assert.Nil(t, Wrap(nil, "ignored"))
+			assert.Nil(t, Wrapf(nil, "ignored %d", 1))
+			assert.Nil(t, WithStack(nil))
+		},
+	)
+}
+
+func TestWithStack(t *testing.T) {
+	t.Parallel()
+
+	cause := &fakeExitError{code: 2}
+
+	actual := WithStack(cause)
+	assert.Equal(t, "exit status 2", actual.Error())
+	assert.Equal(t, cause, Unwrap(actual))
+	assert.Equal(t, "github.com/sumup-oss/go-pkgs/errors.TestWithStack", StackTrace(actual)[0].Function)
+
+	assert.Equal(t, actual, WithStack(actual))
+	assert.Nil(t, StackTrace(cause))
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	actual := deploy()
+
+	assert.Equal(t, "deploy api failed: apply manifest failed: exit status 1", fmt.Sprintf("%s", actual))
+	assert.Equal(t, "deploy api failed: apply manifest failed: exit status 1", fmt.Sprintf("%v", actual))
+	assert.Equal(t, `"deploy api failed: apply manifest failed: exit status 1"`, fmt.Sprintf("%q", actual))
+
+	verbose := fmt.Sprintf("%+v", actual)
+	lines := strings.Split(verbose, "\n")
+	assert.Equal(t, "deploy api failed", lines[0])
+	assert.Equal(t, "github.com/sumup-oss/go-pkgs/errors.deploy", lines[1])
+	assert.Contains(t, verbose, "\ncaused by: apply manifest failed\ngithub.com/sumup-oss/go-pkgs/errors.applyManifest\n")
+	assert.True(t, strings.HasSuffix(verbose, "\ncaused by: exit status 1"))
+}
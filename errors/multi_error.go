@@ -0,0 +1,122 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MultiError aggregates the errors of bulk operations, e.g deleting resources in multiple namespaces,
+// so that callers get all the failures rather than just the first one.
+type MultiError struct {
+	errs []error
+}
+
+// Append returns a MultiError with the errors of err, which may be a MultiError itself, followed by errs.
+// Nil errors are skipped and MultiError ones are flattened, so that
+// `result = errors.Append(result, err)` is safe to call in a loop.
+func Append(err error, errs ...error) *MultiError {
+	result := &MultiError{}
+	result.append(err)
+
+	for _, item := range errs {
+		result.append(item)
+	}
+
+	return result
+}
+
+func (m *MultiError) append(err error) {
+	if err == nil {
+		return
+	}
+
+	if multi, ok := err.(*MultiError); ok {
+		if multi != nil {
+			m.errs = append(m.errs, multi.errs...)
+		}
+
+		return
+	}
+
+	m.errs = append(m.errs, err)
+}
+
+// ErrorOrNil returns the MultiError as error, or nil when it has no errors.
+func (m *MultiError) ErrorOrNil() error {
+	if m == nil || len(m.errs) == 0 {
+		return nil
+	}
+
+	return m
+}
+
+// Errors returns the aggregated errors, in the order they were appended.
+func (m *MultiError) Errors() []error {
+	if m == nil {
+		return nil
+	}
+
+	errs := make([]error, len(m.errs))
+	copy(errs, m.errs)
+
+	return errs
+}
+
+func (m *MultiError) Error() string {
+	if len(m.errs) == 1 {
+		return m.errs[0].Error()
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("%d errors occurred:", len(m.errs)))
+
+	for _, err := range m.errs {
+		builder.WriteString("\n\t* ")
+		builder.WriteString(strings.Replace(err.Error(), "\n", "\n\t  ", -1))
+	}
+
+	return builder.String()
+}
+
+// Unwrap returns the aggregated errors.
+func (m *MultiError) Unwrap() []error {
+	return m.Errors()
+}
+
+// Is reports whether any of the aggregated errors matches target.
+// NOTE: The standard library supports `Unwrap() []error` since go1.20, Is and As support the older versions.
+func (m *MultiError) Is(target error) bool {
+	for _, err := range m.errs {
+		if Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first of the aggregated errors that matches target.
+func (m *MultiError) As(target interface{}) bool {
+	for _, err := range m.errs {
+		if As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppend(t *testing.T) {
+	t.Run(
+		"it skips nil errors and flattens multi errors",
+		func(t *testing.T) {
+			t.Parallel()
+
+			var result *MultiError
+
+			result = Append(result, nil)
+			assert.Nil(t, result.ErrorOrNil())
+
+			first := New("delete in namespace api failed")
+			second := New("delete in namespace worker failed")
+			third := New("delete in namespace jobs failed")
+
+			result = Append(result, first)
+			result = Append(result, nil, Append(nil, second, third))
+
+			assert.Equal(t, []error{first, second, third}, result.Errors())
+			assert.Equal(t, []error{first, second, third}, result.Unwrap())
+			require.NotNil(t, result.ErrorOrNil())
+		},
+	)
+
+	t.Run(
+		"with nil multi error, it has no errors",
+		func(t *testing.T) {
+			t.Parallel()
+
+			var result *MultiError
+			assert.Nil(t, result.ErrorOrNil())
+			assert.Nil(t, result.Errors())
+			assert.Nil(t, Append(result).ErrorOrNil())
+		},
+	)
+}
+
+func TestMultiError_Error(t *testing.T) {
+	t.Parallel()
+
+	single := Append(nil, New("fake error"))
+	assert.Equal(t, "fake error", single.Error())
+
+	multiple := Append(nil, New("first error"), New("second error\nwith details"))
+	assert.Equal(
+		t,
+		"2 errors occurred:\n\t* first error\n\t* second error\n\t  with details",
+		multiple.Error(),
+	)
+}
+
+func TestMultiError_IsAs(t *testing.T) {
+	t.Parallel()
+
+	actual := Append(
+		nil,
+		New("fake error"),
+		Wrap(&fakeExitError{code: 3}, "apply failed"),
+		Wrap(os.ErrNotExist, "read failed"),
+	).ErrorOrNil()
+
+	assert.True(t, Is(actual, os.ErrNotExist))
+	assert.False(t, Is(actual, os.ErrPermission))
+
+	var exitErr *fakeExitError
+	require.True(t, As(actual, &exitErr))
+	assert.Equal(t, 3, exitErr.code)
+}
@@ -0,0 +1,59 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"runtime"
+)
+
+const maxStackDepth = 32
+
+// Frame is a function call of a stack trace.
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+type stack []uintptr
+
+// callers returns the stack trace of the caller of the function calling it.
+func callers() stack {
+	pcs := make([]uintptr, maxStackDepth)
+	// NOTE: Skips `runtime.Callers`, `callers` and the package function calling it.
+	count := runtime.Callers(3, pcs)
+
+	return pcs[:count]
+}
+
+func (s stack) frames() []Frame {
+	if len(s) == 0 {
+		return nil
+	}
+
+	result := make([]Frame, 0, len(s))
+	frames := runtime.CallersFrames(s)
+
+	for {
+		frame, more := frames.Next()
+		result = append(result, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+
+		if !more {
+			break
+		}
+	}
+
+	return result
+}
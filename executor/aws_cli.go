@@ -0,0 +1,238 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+type AwsCliOptions struct {
+	// Profile is the named profile of the shared credentials and config files.
+	Profile string
+	Region  string
+}
+
+type AwsS3Options struct {
+	Recursive bool
+	// Delete deletes files at the destination that are not in the source. Only used by sync.
+	Delete  bool
+	Exclude []string
+	Include []string
+	DryRun  bool
+}
+
+type AwsCallerIdentity struct {
+	UserID  string `json:"UserId"`
+	Account string `json:"Account"`
+	Arn     string `json:"Arn"`
+}
+
+type AwsCredentials struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	SessionToken    string    `json:"SessionToken"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+type AwsCli struct {
+	binPath         string
+	options         AwsCliOptions
+	credentials     *AwsCredentials
+	commandExecutor os.CommandExecutor
+}
+
+func NewAwsCli(executor os.CommandExecutor, options *AwsCliOptions) *AwsCli {
+	aws := &AwsCli{
+		binPath:         "aws",
+		commandExecutor: executor,
+	}
+
+	if options != nil {
+		aws.options = *options
+	}
+
+	return aws
+}
+
+// WithCredentials returns a copy of the instance that executes commands with the credentials,
+// e.g the ones of an assumed role. The profile is not used by the copy.
+func (aws *AwsCli) WithCredentials(credentials *AwsCredentials) *AwsCli {
+	clone := *aws
+	clone.options.Profile = ""
+	clone.credentials = credentials
+
+	return &clone
+}
+
+// EcrGetLoginPassword returns a password to `docker login` to ECR with `AWS` username.
+func (aws *AwsCli) EcrGetLoginPassword(ctx context.Context) (string, error) {
+	stdout, err := aws.execute(ctx, "ecr", "get-login-password")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+// S3Copy copies a local file or S3 object to another location, e.g `s3://bucket/key`.
+func (aws *AwsCli) S3Copy(ctx context.Context, source, destination string, options *AwsS3Options) error {
+	args := append([]string{"s3", "cp", source, destination}, aws.s3Arguments(options, false)...)
+
+	_, err := aws.execute(ctx, args...)
+	return err
+}
+
+// S3Sync syncs directories and S3 prefixes.
+func (aws *AwsCli) S3Sync(ctx context.Context, source, destination string, options *AwsS3Options) error {
+	args := append([]string{"s3", "sync", source, destination}, aws.s3Arguments(options, true)...)
+
+	_, err := aws.execute(ctx, args...)
+	return err
+}
+
+// EksUpdateKubeconfig configures kubeconfig to access the EKS cluster.
+// Blank kubeconfig path and role ARN are omitted.
+func (aws *AwsCli) EksUpdateKubeconfig(ctx context.Context, cluster, kubeconfigPath, roleArn string) error {
+	args := []string{"eks", "update-kubeconfig", "--name", cluster}
+
+	if kubeconfigPath != "" {
+		args = append(args, "--kubeconfig", kubeconfigPath)
+	}
+
+	if roleArn != "" {
+		args = append(args, "--role-arn", roleArn)
+	}
+
+	_, err := aws.execute(ctx, args...)
+	return err
+}
+
+func (aws *AwsCli) StsGetCallerIdentity(ctx context.Context) (*AwsCallerIdentity, error) {
+	var identity AwsCallerIdentity
+
+	err := aws.executeJSON(ctx, &identity, "sts", "get-caller-identity")
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// StsAssumeRole assumes the role and returns its temporary credentials.
+// The default duration of the role is used when `duration` is zero.
+func (aws *AwsCli) StsAssumeRole(
+	ctx context.Context,
+	roleArn,
+	sessionName string,
+	duration time.Duration,
+) (*AwsCredentials, error) {
+	args := []string{"sts", "assume-role", "--role-arn", roleArn, "--role-session-name", sessionName}
+
+	if duration > 0 {
+		args = append(args, "--duration-seconds", strconv.Itoa(int(duration.Seconds())))
+	}
+
+	var output struct {
+		Credentials AwsCredentials `json:"Credentials"`
+	}
+
+	err := aws.executeJSON(ctx, &output, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output.Credentials, nil
+}
+
+func (aws *AwsCli) s3Arguments(options *AwsS3Options, isSync bool) []string {
+	if options == nil {
+		return nil
+	}
+
+	var args []string
+
+	if options.Recursive && !isSync {
+		args = append(args, "--recursive")
+	}
+
+	if options.Delete && isSync {
+		args = append(args, "--delete")
+	}
+
+	for _, pattern := range options.Exclude {
+		args = append(args, "--exclude", pattern)
+	}
+
+	for _, pattern := range options.Include {
+		args = append(args, "--include", pattern)
+	}
+
+	if options.DryRun {
+		args = append(args, "--dryrun")
+	}
+
+	return args
+}
+
+func (aws *AwsCli) executeJSON(ctx context.Context, output interface{}, args ...string) error {
+	stdout, err := aws.execute(ctx, append(args, "--output", "json")...)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(stdout, output)
+	if err != nil {
+		return stacktrace.Propagate(err, "json decode command `aws %s` output failed", strings.Join(args[:2], " "))
+	}
+
+	return nil
+}
+
+func (aws *AwsCli) execute(ctx context.Context, args ...string) ([]byte, error) {
+	if aws.options.Profile != "" {
+		args = append(args, "--profile", aws.options.Profile)
+	}
+
+	if aws.options.Region != "" {
+		args = append(args, "--region", aws.options.Region)
+	}
+
+	stdout, stderr, err := aws.commandExecutor.ExecuteContext(ctx, aws.binPath, args, aws.env(), "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return stdout, nil
+}
+
+func (aws *AwsCli) env() []string {
+	if aws.credentials == nil {
+		return nil
+	}
+
+	return os.CommandEnv(
+		"AWS_ACCESS_KEY_ID="+aws.credentials.AccessKeyID,
+		"AWS_SECRET_ACCESS_KEY="+aws.credentials.SecretAccessKey,
+		"AWS_SESSION_TOKEN="+aws.credentials.SessionToken,
+	)
+}
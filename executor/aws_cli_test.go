@@ -0,0 +1,258 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestAwsCli_EcrGetLoginPassword(t *testing.T) {
+	t.Run(
+		"it passes profile and region and returns the trimmed password",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"aws",
+				[]string{"ecr", "get-login-password", "--profile", "deploy", "--region", "eu-west-1"},
+				[]string(nil),
+				"",
+			).Return([]byte("fakepassword\n"), []byte{}, nil)
+
+			awsInstance := NewAwsCli(executorArg, &AwsCliOptions{Profile: "deploy", Region: "eu-west-1"})
+			actual, actualErr := awsInstance.EcrGetLoginPassword(context.Background())
+			require.Nil(t, actualErr)
+			assert.Equal(t, "fakepassword", actual)
+		},
+	)
+
+	t.Run(
+		"when command fails, it returns error with stderr",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"aws",
+				[]string{"ecr", "get-login-password"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("fake stderr"), errors.New("fake error"))
+
+			awsInstance := NewAwsCli(executorArg, nil)
+			actual, actualErr := awsInstance.EcrGetLoginPassword(context.Background())
+			require.NotNil(t, actualErr)
+			assert.Equal(t, "", actual)
+			assert.Contains(t, actualErr.Error(), "fake stderr")
+		},
+	)
+}
+
+func TestAwsCli_S3(t *testing.T) {
+	t.Run(
+		"copy passes recursive and filter options",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"aws",
+				[]string{"s3", "cp", "./dist", "s3://bucket/dist", "--recursive", "--exclude", "*.map"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte{}, nil)
+
+			awsInstance := NewAwsCli(executorArg, nil)
+			actual := awsInstance.S3Copy(
+				context.Background(),
+				"./dist",
+				"s3://bucket/dist",
+				&AwsS3Options{Recursive: true, Delete: true, Exclude: []string{"*.map"}},
+			)
+			require.Nil(t, actual)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"sync passes delete option",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"aws",
+				[]string{"s3", "sync", "./dist", "s3://bucket/dist", "--delete", "--dryrun"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte{}, nil)
+
+			awsInstance := NewAwsCli(executorArg, nil)
+			actual := awsInstance.S3Sync(
+				context.Background(),
+				"./dist",
+				"s3://bucket/dist",
+				&AwsS3Options{Recursive: true, Delete: true, DryRun: true},
+			)
+			require.Nil(t, actual)
+			executorArg.AssertExpectations(t)
+		},
+	)
+}
+
+func TestAwsCli_EksUpdateKubeconfig(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"aws",
+		[]string{
+			"eks", "update-kubeconfig",
+			"--name", "production",
+			"--kubeconfig", "/tmp/kubeconfig",
+			"--role-arn", "arn:aws:iam::123456789012:role/deploy",
+			"--region", "eu-west-1",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	awsInstance := NewAwsCli(executorArg, &AwsCliOptions{Region: "eu-west-1"})
+	actual := awsInstance.EksUpdateKubeconfig(
+		context.Background(),
+		"production",
+		"/tmp/kubeconfig",
+		"arn:aws:iam::123456789012:role/deploy",
+	)
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestAwsCli_StsGetCallerIdentity(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"aws",
+		[]string{"sts", "get-caller-identity", "--output", "json"},
+		[]string(nil),
+		"",
+	).Return(
+		[]byte(`{"UserId": "AIDAFAKE", "Account": "123456789012", "Arn": "arn:aws:iam::123456789012:user/ci"}`),
+		[]byte{},
+		nil,
+	)
+
+	awsInstance := NewAwsCli(executorArg, nil)
+	actual, actualErr := awsInstance.StsGetCallerIdentity(context.Background())
+	require.Nil(t, actualErr)
+
+	expected := &AwsCallerIdentity{
+		UserID:  "AIDAFAKE",
+		Account: "123456789012",
+		Arn:     "arn:aws:iam::123456789012:user/ci",
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestAwsCli_StsAssumeRole(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"aws",
+		[]string{
+			"sts", "assume-role",
+			"--role-arn", "arn:aws:iam::123456789012:role/deploy",
+			"--role-session-name", "ci",
+			"--duration-seconds", "900",
+			"--output", "json",
+			"--profile", "ci",
+		},
+		[]string(nil),
+		"",
+	).Return(
+		[]byte(`{"Credentials": {
+			"AccessKeyId": "ASIAFAKE",
+			"SecretAccessKey": "fakesecret",
+			"SessionToken": "faketoken",
+			"Expiration": "2019-10-10T10:00:00Z"
+		}}`),
+		[]byte{},
+		nil,
+	)
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"aws",
+		[]string{"ecr", "get-login-password"},
+		mock.MatchedBy(func(env []string) bool {
+			return containsString(env, "AWS_ACCESS_KEY_ID=ASIAFAKE") &&
+				containsString(env, "AWS_SECRET_ACCESS_KEY=fakesecret") &&
+				containsString(env, "AWS_SESSION_TOKEN=faketoken")
+		}),
+		"",
+	).Return([]byte("fakepassword"), []byte{}, nil)
+
+	awsInstance := NewAwsCli(executorArg, &AwsCliOptions{Profile: "ci"})
+	actual, actualErr := awsInstance.StsAssumeRole(
+		context.Background(),
+		"arn:aws:iam::123456789012:role/deploy",
+		"ci",
+		15*time.Minute,
+	)
+	require.Nil(t, actualErr)
+	assert.Equal(t, "ASIAFAKE", actual.AccessKeyID)
+	assert.Equal(t, time.Date(2019, 10, 10, 10, 0, 0, 0, time.UTC), actual.Expiration)
+
+	password, err := awsInstance.WithCredentials(actual).EcrGetLoginPassword(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, "fakepassword", password)
+	executorArg.AssertExpectations(t)
+}
+
+func containsString(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
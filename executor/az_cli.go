@@ -0,0 +1,254 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	stdOs "os"
+	"strings"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+type AzCliOptions struct {
+	// Subscription is the subscription name or ID of the commands, when not the default one.
+	Subscription string
+	// ConfigDir is the configuration directory, when not `~/.azure`, e.g to isolate the logins of concurrent jobs.
+	ConfigDir string
+}
+
+// AzServicePrincipal are the credentials of a service principal.
+// Exactly one of ClientSecret, CertificateFile or FederatedToken authenticates it.
+type AzServicePrincipal struct {
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	// CertificateFile is a PEM file with the certificate and its private key.
+	CertificateFile string
+	// FederatedToken is an OIDC token, e.g of a CI workload identity.
+	FederatedToken string
+}
+
+type AzAksCredentialsOptions struct {
+	// Admin retrieves the cluster admin credentials instead of the user ones.
+	Admin bool
+	// File is the kubeconfig file to merge the credentials into, when not the default one.
+	File string
+	// Overwrite overwrites the existing context of the same name.
+	Overwrite bool
+}
+
+type AzAcrToken struct {
+	AccessToken string `json:"accessToken"`
+	LoginServer string `json:"loginServer"`
+}
+
+type AzAccount struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	TenantID string `json:"tenantId"`
+	State    string `json:"state"`
+	User     struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"user"`
+}
+
+type AzCli struct {
+	binPath         string
+	options         AzCliOptions
+	commandExecutor os.CommandExecutor
+}
+
+func NewAzCli(executor os.CommandExecutor, options *AzCliOptions) *AzCli {
+	az := &AzCli{
+		binPath:         "az",
+		commandExecutor: executor,
+	}
+
+	if options != nil {
+		az.options = *options
+	}
+
+	return az
+}
+
+// LoginServicePrincipal logs in with the service principal credentials.
+// The client secret and the federated token are passed as `@<file>` of a temporary file,
+// so that they are not visible in the process list or the logs of the command.
+func (az *AzCli) LoginServicePrincipal(ctx context.Context, principal *AzServicePrincipal) error {
+	args := []string{
+		"login",
+		"--service-principal",
+		"--username", principal.ClientID,
+		"--tenant", principal.TenantID,
+	}
+
+	var credentialFlag, credential string
+
+	switch {
+	case principal.FederatedToken != "":
+		credentialFlag, credential = "--federated-token", principal.FederatedToken
+	case principal.CertificateFile != "":
+		args = append(args, "--password", principal.CertificateFile)
+	case principal.ClientSecret != "":
+		credentialFlag, credential = "--password", principal.ClientSecret
+	default:
+		return stacktrace.NewError("no credentials of service principal %s", principal.ClientID)
+	}
+
+	if credential != "" {
+		credentialFile, err := writeAzCredentialFile(credential)
+		if err != nil {
+			return err
+		}
+
+		defer stdOs.Remove(credentialFile)
+
+		args = append(args, credentialFlag, "@"+credentialFile)
+	}
+
+	// NOTE: `az login` doesn't support the subscription option, so it's executed without it.
+	_, err := az.execute(ctx, append(args, "--output", "none", "--only-show-errors"))
+	return err
+}
+
+// Account returns the subscription account the commands are executed with.
+func (az *AzCli) Account(ctx context.Context) (*AzAccount, error) {
+	var account AzAccount
+
+	err := az.ExecuteJSON(ctx, &account, "account", "show")
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// AcrLogin logs docker in to the container registry, by name, e.g `myregistry`.
+func (az *AzCli) AcrLogin(ctx context.Context, registry string) error {
+	_, err := az.Execute(ctx, "acr", "login", "--name", registry)
+	return err
+}
+
+// AcrAccessToken returns an access token of the container registry, e.g for a container runtime other than docker.
+// The token is used with the `00000000-0000-0000-0000-000000000000` username.
+func (az *AzCli) AcrAccessToken(ctx context.Context, registry string) (*AzAcrToken, error) {
+	var token AzAcrToken
+
+	err := az.ExecuteJSON(ctx, &token, "acr", "login", "--name", registry, "--expose-token")
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// AksGetCredentials merges the AKS cluster credentials into kubeconfig.
+func (az *AzCli) AksGetCredentials(
+	ctx context.Context,
+	resourceGroup,
+	cluster string,
+	options *AzAksCredentialsOptions,
+) error {
+	args := []string{"aks", "get-credentials", "--resource-group", resourceGroup, "--name", cluster}
+
+	if options != nil {
+		if options.Admin {
+			args = append(args, "--admin")
+		}
+
+		if options.File != "" {
+			args = append(args, "--file", options.File)
+		}
+
+		if options.Overwrite {
+			args = append(args, "--overwrite-existing")
+		}
+	}
+
+	_, err := az.Execute(ctx, args...)
+	return err
+}
+
+// ExecuteJSON executes command with JSON formatted output and decodes it into output,
+// e.g `ExecuteJSON(ctx, &groups, "group", "list")`.
+func (az *AzCli) ExecuteJSON(ctx context.Context, output interface{}, args ...string) error {
+	stdout, err := az.Execute(ctx, append(args, "--output", "json")...)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(stdout, output)
+	if err != nil {
+		return stacktrace.Propagate(err, "json decode command `az %s` output failed", strings.Join(args, " "))
+	}
+
+	return nil
+}
+
+// Execute executes command with the subscription option and returns its stdout.
+// Warnings, e.g of deprecated commands, are not reported on stderr.
+func (az *AzCli) Execute(ctx context.Context, args ...string) ([]byte, error) {
+	args = append(args, "--only-show-errors")
+
+	if az.options.Subscription != "" {
+		args = append(args, "--subscription", az.options.Subscription)
+	}
+
+	return az.execute(ctx, args)
+}
+
+func (az *AzCli) execute(ctx context.Context, args []string) ([]byte, error) {
+	stdout, stderr, err := az.commandExecutor.ExecuteContext(ctx, az.binPath, args, az.env(), "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return stdout, nil
+}
+
+func (az *AzCli) env() []string {
+	if az.options.ConfigDir == "" {
+		return nil
+	}
+
+	return os.CommandEnv("AZURE_CONFIG_DIR=" + az.options.ConfigDir)
+}
+
+// writeAzCredentialFile writes the credential to a temporary file, readable only by the user,
+// and returns its path.
+func writeAzCredentialFile(credential string) (string, error) {
+	file, err := ioutil.TempFile("", "az-credential-*")
+	if err != nil {
+		return "", stacktrace.Propagate(err, "failed to create az credential file")
+	}
+
+	_, err = file.WriteString(credential)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = stdOs.Remove(file.Name())
+		return "", stacktrace.Propagate(err, "failed to write az credential file")
+	}
+
+	return file.Name(), nil
+}
@@ -0,0 +1,255 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	stdOs "os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+// credentialFileContains reports whether arg is `@<file>` of a file with the credential.
+func credentialFileContains(arg, credential string) bool {
+	if !strings.HasPrefix(arg, "@") {
+		return false
+	}
+
+	content, err := ioutil.ReadFile(strings.TrimPrefix(arg, "@"))
+
+	return err == nil && string(content) == credential
+}
+
+func TestAzCli_LoginServicePrincipal(t *testing.T) {
+	t.Run(
+		"with client secret, it logs in without the subscription and the secret in the args",
+		func(t *testing.T) {
+			t.Parallel()
+
+			var credentialArg string
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"az",
+				mock.MatchedBy(func(args []string) bool {
+					return len(args) == 11 &&
+						strings.Join(args[:7], " ") == "login --service-principal --username fake-client-id "+
+							"--tenant fake-tenant-id --password" &&
+						credentialFileContains(args[7], "fakesecret") &&
+						strings.Join(args[8:], " ") == "--output none --only-show-errors"
+				}),
+				mock.MatchedBy(func(env []string) bool {
+					return containsString(env, "AZURE_CONFIG_DIR=/tmp/azure")
+				}),
+				"",
+			).Run(func(args mock.Arguments) {
+				credentialArg = args.Get(2).([]string)[7]
+			}).Return([]byte{}, []byte{}, nil)
+
+			azInstance := NewAzCli(executorArg, &AzCliOptions{Subscription: "production", ConfigDir: "/tmp/azure"})
+			actual := azInstance.LoginServicePrincipal(
+				context.Background(),
+				&AzServicePrincipal{TenantID: "fake-tenant-id", ClientID: "fake-client-id", ClientSecret: "fakesecret"},
+			)
+			require.Nil(t, actual)
+			executorArg.AssertExpectations(t)
+
+			_, err := stdOs.Stat(strings.TrimPrefix(credentialArg, "@"))
+			assert.True(t, stdOs.IsNotExist(err))
+		},
+	)
+
+	t.Run(
+		"with federated token, it logs in with the token",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"az",
+				mock.MatchedBy(func(args []string) bool {
+					return len(args) == 11 &&
+						args[6] == "--federated-token" &&
+						credentialFileContains(args[7], "fake.jwt.token")
+				}),
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("AADSTS700024: Client assertion is not within its valid time range"), errors.New("exit status 1"))
+
+			azInstance := NewAzCli(executorArg, nil)
+			actual := azInstance.LoginServicePrincipal(
+				context.Background(),
+				&AzServicePrincipal{TenantID: "fake-tenant-id", ClientID: "fake-client-id", FederatedToken: "fake.jwt.token"},
+			)
+			require.NotNil(t, actual)
+			assert.Contains(t, actual.Error(), "AADSTS700024")
+		},
+	)
+
+	t.Run(
+		"without credentials, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			azInstance := NewAzCli(&ostest.FakeOsExecutor{}, nil)
+			actual := azInstance.LoginServicePrincipal(
+				context.Background(),
+				&AzServicePrincipal{TenantID: "fake-tenant-id", ClientID: "fake-client-id"},
+			)
+			require.NotNil(t, actual)
+		},
+	)
+}
+
+func TestAzCli_AcrAccessToken(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"az",
+		[]string{
+			"acr", "login", "--name", "fakeregistry", "--expose-token",
+			"--output", "json",
+			"--only-show-errors",
+			"--subscription", "production",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte(`{"accessToken": "fakeaccesstoken", "loginServer": "fakeregistry.azurecr.io"}`), []byte{}, nil)
+
+	azInstance := NewAzCli(executorArg, &AzCliOptions{Subscription: "production"})
+	actual, actualErr := azInstance.AcrAccessToken(context.Background(), "fakeregistry")
+	require.Nil(t, actualErr)
+	assert.Equal(t, &AzAcrToken{AccessToken: "fakeaccesstoken", LoginServer: "fakeregistry.azurecr.io"}, actual)
+}
+
+func TestAzCli_AcrLogin(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"az",
+		[]string{"acr", "login", "--name", "fakeregistry", "--only-show-errors"},
+		[]string(nil),
+		"",
+	).Return([]byte("Login Succeeded\n"), []byte{}, nil)
+
+	azInstance := NewAzCli(executorArg, nil)
+	actual := azInstance.AcrLogin(context.Background(), "fakeregistry")
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestAzCli_AksGetCredentials(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"az",
+		[]string{
+			"aks", "get-credentials",
+			"--resource-group", "platform",
+			"--name", "production",
+			"--admin",
+			"--file", "/tmp/kubeconfig",
+			"--overwrite-existing",
+			"--only-show-errors",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	azInstance := NewAzCli(executorArg, nil)
+	actual := azInstance.AksGetCredentials(
+		context.Background(),
+		"platform",
+		"production",
+		&AzAksCredentialsOptions{Admin: true, File: "/tmp/kubeconfig", Overwrite: true},
+	)
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestAzCli_ExecuteJSON(t *testing.T) {
+	t.Run(
+		"it decodes the output",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"az",
+				[]string{"account", "show", "--output", "json", "--only-show-errors"},
+				[]string(nil),
+				"",
+			).Return(
+				[]byte(`{"id": "fake-subscription-id", "name": "production", "tenantId": "fake-tenant-id",
+"state": "Enabled", "user": {"name": "fake-client-id", "type": "servicePrincipal"}}`),
+				[]byte{},
+				nil,
+			)
+
+			azInstance := NewAzCli(executorArg, nil)
+			actual, actualErr := azInstance.Account(context.Background())
+			require.Nil(t, actualErr)
+			assert.Equal(t, "fake-subscription-id", actual.ID)
+			assert.Equal(t, "servicePrincipal", actual.User.Type)
+		},
+	)
+
+	t.Run(
+		"when output is not JSON, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"az",
+				[]string{"group", "list", "--output", "json", "--only-show-errors"},
+				[]string(nil),
+				"",
+			).Return([]byte("not json"), []byte{}, nil)
+
+			var groups []map[string]interface{}
+
+			azInstance := NewAzCli(executorArg, nil)
+			actual := azInstance.ExecuteJSON(context.Background(), &groups, "group", "list")
+			require.NotNil(t, actual)
+			assert.Contains(t, actual.Error(), "az group list")
+		},
+	)
+}
@@ -0,0 +1,57 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+const (
+	ContainerRuntimeDocker = "docker"
+	ContainerRuntimePodman = "podman"
+)
+
+// ContainerRuntime builds, distributes and runs container images, e.g Docker or Podman.
+type ContainerRuntime interface {
+	Build(ctx context.Context, options *DockerBuildOptions) error
+	Push(ctx context.Context, image string) error
+	Pull(ctx context.Context, image string) error
+	Tag(ctx context.Context, oldImage, newImage string) error
+	Login(ctx context.Context, username, password, registryURL string) error
+	Run(ctx context.Context, options *DockerRunOptions) (string, error)
+	ImageExists(ctx context.Context, image string) (bool, error)
+}
+
+var (
+	_ ContainerRuntime = (*Docker)(nil)
+	_ ContainerRuntime = (*Podman)(nil)
+)
+
+// NewContainerRuntime returns the container runtime by name,
+// ContainerRuntimeDocker or ContainerRuntimePodman, e.g configured per CI runner.
+func NewContainerRuntime(executor os.CommandExecutor, name string) (ContainerRuntime, error) {
+	switch name {
+	case ContainerRuntimeDocker:
+		return NewDocker(executor), nil
+	case ContainerRuntimePodman:
+		return NewPodman(executor), nil
+	default:
+		return nil, stacktrace.NewError("unknown container runtime %s", name)
+	}
+}
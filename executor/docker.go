@@ -18,9 +18,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"regexp"
 	"runtime"
+	"strings"
 
 	"github.com/palantir/stacktrace"
 
@@ -30,6 +32,7 @@ import (
 var (
 	linuxIPRouteRegex  = regexp.MustCompile(`(?m)src\s+\b(?P<ip>(?:\d{1,3}\.){3}\d{1,3})\s+`)
 	darwinIPRouteRegex = regexp.MustCompile(`(?m)\s+gateway:\s+\b(?P<ip>(?:\d{1,3}\.){3}\d{1,3})\s+`)
+	pushDigestRegex    = regexp.MustCompile(`digest:\s+(?P<digest>sha256:[a-f0-9]{64})`)
 )
 
 type DockerBuildOptions struct {
@@ -39,6 +42,42 @@ type DockerBuildOptions struct {
 	Tag        string
 	Target     string
 	ContextDir string
+	// Platform is the target platform of the build, e.g `linux/amd64`.
+	Platform string
+	// Output receives the build logs while the build is running, when set.
+	Output io.Writer
+}
+
+type DockerRunOptions struct {
+	Image      string
+	Name       string
+	Command    []string
+	Entrypoint string
+	// Env is a list of `KEY=value` environment variables.
+	Env []string
+	// Volumes is a list of `source:destination[:mode]` volume mounts.
+	Volumes []string
+	// Ports is a list of `host:container` published ports.
+	Ports   []string
+	Network string
+	WorkDir string
+	User    string
+	Remove  bool
+	Detach  bool
+}
+
+// streamingCommandExecutor is implemented by command executors that can stream the command output,
+// such as os.OsExecutor.
+type streamingCommandExecutor interface {
+	ExecuteWithStreamsContext(
+		ctx context.Context,
+		cmd string,
+		arg,
+		env []string,
+		dir string,
+		stdout,
+		stderr io.Writer,
+	) error
 }
 
 type DockerNetwork struct {
@@ -67,16 +106,33 @@ func NewDocker(executor os.CommandExecutor) *Docker {
 
 func (docker *Docker) Push(ctx context.Context, image string) error {
 	args := []string{"push", image}
-	stdout, stderr, err := docker.commandExecutor.ExecuteContext(ctx, "docker", args, nil, "")
+	stdout, stderr, err := docker.commandExecutor.ExecuteContext(ctx, docker.binaryPath, args, nil, "")
 	return stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
 }
 
 func (docker *Docker) Pull(ctx context.Context, image string) error {
 	args := []string{"pull", image}
-	stdout, stderr, err := docker.commandExecutor.ExecuteContext(ctx, "docker", args, nil, "")
+	stdout, stderr, err := docker.commandExecutor.ExecuteContext(ctx, docker.binaryPath, args, nil, "")
 	return stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
 }
 
+// PushWithDigest pushes the image and returns the digest of the pushed image, e.g `sha256:<hash>`.
+func (docker *Docker) PushWithDigest(ctx context.Context, image string) (string, error) {
+	args := []string{"push", image}
+	stdout, stderr, err := docker.commandExecutor.ExecuteContext(ctx, docker.binaryPath, args, nil, "")
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	matches := pushDigestRegex.FindAllStringSubmatch(string(stdout), -1)
+	if len(matches) < 1 {
+		return "", stacktrace.NewError("no digest found in `docker push %s` output: %s", image, stdout)
+	}
+
+	// NOTE: Pushing multiple tags of a repository outputs one digest per tag, the last one is of `image`.
+	return matches[len(matches)-1][1], nil
+}
+
 func (docker *Docker) buildArgs(options *DockerBuildOptions) []string {
 	args := []string{"build", "-f", options.File, "--tag", options.Tag}
 
@@ -84,6 +140,10 @@ func (docker *Docker) buildArgs(options *DockerBuildOptions) []string {
 		args = append(args, "--target", options.Target)
 	}
 
+	if options.Platform != "" {
+		args = append(args, "--platform", options.Platform)
+	}
+
 	if options.Hosts != nil {
 		for name, address := range options.Hosts {
 			args = append(args, fmt.Sprintf("--add-host=%s:%s", name, address))
@@ -100,21 +160,118 @@ func (docker *Docker) buildArgs(options *DockerBuildOptions) []string {
 	return args
 }
 
+// Build builds the image. When `options.Output` is set and the command executor supports streaming,
+// the build logs are written to it while the build is running, otherwise after the build is done.
 func (docker *Docker) Build(ctx context.Context, options *DockerBuildOptions) error {
 	args := docker.buildArgs(options)
-	stdout, stderr, err := docker.commandExecutor.ExecuteContext(ctx, "docker", args, nil, "")
+
+	if options.Output != nil {
+		if streamingExecutor, ok := docker.commandExecutor.(streamingCommandExecutor); ok {
+			stdout := NewBufferedWriter(options.Output)
+			stderr := NewBufferedWriter(options.Output)
+
+			err := streamingExecutor.ExecuteWithStreamsContext(
+				ctx,
+				docker.binaryPath,
+				args,
+				nil,
+				"",
+				stdout,
+				stderr,
+			)
+
+			return stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr.Bytes(), stdout.Bytes())
+		}
+	}
+
+	stdout, stderr, err := docker.commandExecutor.ExecuteContext(ctx, docker.binaryPath, args, nil, "")
+	if options.Output != nil {
+		_, _ = options.Output.Write(stdout)
+		_, _ = options.Output.Write(stderr)
+	}
+
 	return stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
 }
 
+// Run runs a container and returns its stdout, which is the container ID when `options.Detach` is set.
+func (docker *Docker) Run(ctx context.Context, options *DockerRunOptions) (string, error) {
+	args := []string{"run"}
+
+	if options.Remove {
+		args = append(args, "--rm")
+	}
+
+	if options.Detach {
+		args = append(args, "--detach")
+	}
+
+	if options.Name != "" {
+		args = append(args, "--name", options.Name)
+	}
+
+	if options.Entrypoint != "" {
+		args = append(args, "--entrypoint", options.Entrypoint)
+	}
+
+	if options.Network != "" {
+		args = append(args, "--network", options.Network)
+	}
+
+	if options.WorkDir != "" {
+		args = append(args, "--workdir", options.WorkDir)
+	}
+
+	if options.User != "" {
+		args = append(args, "--user", options.User)
+	}
+
+	for _, env := range options.Env {
+		args = append(args, "--env", env)
+	}
+
+	for _, volume := range options.Volumes {
+		args = append(args, "--volume", volume)
+	}
+
+	for _, port := range options.Ports {
+		args = append(args, "--publish", port)
+	}
+
+	args = append(args, options.Image)
+	args = append(args, options.Command...)
+
+	stdout, stderr, err := docker.commandExecutor.ExecuteContext(ctx, docker.binaryPath, args, nil, "")
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+// ImageExists checks whether the image is present locally.
+func (docker *Docker) ImageExists(ctx context.Context, image string) (bool, error) {
+	args := []string{"image", "inspect", "--format", "{{.Id}}", image}
+	stdout, stderr, err := docker.commandExecutor.ExecuteContext(ctx, docker.binaryPath, args, nil, "")
+	if err != nil {
+		if strings.Contains(strings.ToLower(string(stderr)), "no such image") {
+			return false, nil
+		}
+
+		return false, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return true, nil
+}
+
 func (docker *Docker) Tag(ctx context.Context, oldImage, newImage string) error {
 	args := []string{"tag", oldImage, newImage}
-	stdout, stderr, err := docker.commandExecutor.ExecuteContext(ctx, "docker", args, nil, "")
+	stdout, stderr, err := docker.commandExecutor.ExecuteContext(ctx, docker.binaryPath, args, nil, "")
 	return stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
 }
 
 func (docker *Docker) Login(ctx context.Context, username, password, registryURL string) error {
 	args := []string{"login", "-u", username, "-p", password, registryURL}
-	stdout, stderr, err := docker.commandExecutor.ExecuteContext(ctx, "docker", args, nil, "")
+	stdout, stderr, err := docker.commandExecutor.ExecuteContext(ctx, docker.binaryPath, args, nil, "")
 	return stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
 }
 
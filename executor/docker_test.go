@@ -15,11 +15,14 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/sumup-oss/go-pkgs/os/ostest"
@@ -439,3 +442,244 @@ func TestDocker_Login(t *testing.T) {
 		assert.Contains(t, actual.Error(), string(fakeStderr))
 	})
 }
+
+func TestDocker_PushWithDigest(t *testing.T) {
+	t.Run("when pushing does not fail, it returns digest of the pushed image", func(t *testing.T) {
+		executorArg := &ostest.FakeOsExecutor{}
+		imageArg := "example:1.0.0"
+
+		fakeStdout := []byte(
+			"The push refers to repository [docker.io/library/example]\n" +
+				"5f70bf18a086: Pushed\n" +
+				"1.0.0: digest: sha256:" +
+				"4c4a5e6e1e1f2d7b0c16d8ce1c1a3e2f5a4a9d3b7c6e5f4a3b2c1d0e9f8a7b6c size: 528\n",
+		)
+		executorArg.On(
+			"ExecuteContext",
+			context.Background(),
+			"docker",
+			[]string{"push", imageArg},
+			[]string(nil),
+			"",
+		).Return(fakeStdout, []byte{}, nil)
+
+		dockerInstance := NewDocker(executorArg)
+		actual, actualErr := dockerInstance.PushWithDigest(context.Background(), imageArg)
+		require.Nil(t, actualErr)
+		assert.Equal(t, "sha256:4c4a5e6e1e1f2d7b0c16d8ce1c1a3e2f5a4a9d3b7c6e5f4a3b2c1d0e9f8a7b6c", actual)
+	})
+
+	t.Run("when push output has no digest, it returns error", func(t *testing.T) {
+		executorArg := &ostest.FakeOsExecutor{}
+		imageArg := "example:1.0.0"
+
+		executorArg.On(
+			"ExecuteContext",
+			context.Background(),
+			"docker",
+			[]string{"push", imageArg},
+			[]string(nil),
+			"",
+		).Return([]byte("fake stdout"), []byte{}, nil)
+
+		dockerInstance := NewDocker(executorArg)
+		actual, actualErr := dockerInstance.PushWithDigest(context.Background(), imageArg)
+		require.NotNil(t, actualErr)
+		assert.Equal(t, "", actual)
+		assert.Contains(t, actualErr.Error(), "no digest found")
+	})
+
+	t.Run("when pushing fails, it returns error", func(t *testing.T) {
+		executorArg := &ostest.FakeOsExecutor{}
+		imageArg := "example:1.0.0"
+
+		fakeError := errors.New("fake error")
+		fakeStderr := []byte("fake stderr")
+		executorArg.On(
+			"ExecuteContext",
+			context.Background(),
+			"docker",
+			[]string{"push", imageArg},
+			[]string(nil),
+			"",
+		).Return([]byte{}, fakeStderr, fakeError)
+
+		dockerInstance := NewDocker(executorArg)
+		_, actualErr := dockerInstance.PushWithDigest(context.Background(), imageArg)
+		require.NotNil(t, actualErr)
+		assert.Contains(t, actualErr.Error(), fakeError.Error())
+		assert.Contains(t, actualErr.Error(), string(fakeStderr))
+	})
+}
+
+func TestDocker_BuildWithOutput(t *testing.T) {
+	t.Run(
+		"when `options.Output` is present, it streams the build logs to it",
+		func(t *testing.T) {
+			executorArg := &ostest.FakeOsExecutor{}
+
+			output := &bytes.Buffer{}
+			optionsArg := &DockerBuildOptions{
+				File:       "./examplefile",
+				ContextDir: ".",
+				Tag:        "mytag",
+				Platform:   "linux/amd64",
+				Output:     output,
+			}
+
+			fakeError := errors.New("fake error")
+			executorArg.On(
+				"ExecuteWithStreamsContext",
+				context.Background(),
+				"docker",
+				[]string{
+					"build",
+					"-f",
+					optionsArg.File,
+					"--tag",
+					optionsArg.Tag,
+					"--platform",
+					optionsArg.Platform,
+					optionsArg.ContextDir,
+				},
+				[]string(nil),
+				"",
+				mock.Anything,
+				mock.Anything,
+			).Run(func(args mock.Arguments) {
+				_, _ = args.Get(5).(io.Writer).Write([]byte("Step 1/2 : FROM alpine\n"))
+				_, _ = args.Get(6).(io.Writer).Write([]byte("fake stderr"))
+			}).Return(fakeError)
+
+			dockerInstance := NewDocker(executorArg)
+			actual := dockerInstance.Build(context.Background(), optionsArg)
+			require.NotNil(t, actual)
+			assert.Contains(t, actual.Error(), fakeError.Error())
+			assert.Contains(t, actual.Error(), "fake stderr")
+			assert.Equal(t, "Step 1/2 : FROM alpine\nfake stderr", output.String())
+		},
+	)
+}
+
+func TestDocker_Run(t *testing.T) {
+	t.Run("when running succeeds, it returns trimmed stdout", func(t *testing.T) {
+		executorArg := &ostest.FakeOsExecutor{}
+
+		executorArg.On(
+			"ExecuteContext",
+			context.Background(),
+			"docker",
+			[]string{
+				"run",
+				"--rm",
+				"--detach",
+				"--name", "example",
+				"--network", "host",
+				"--env", "FOO=bar",
+				"--volume", "/tmp:/data:ro",
+				"--publish", "8080:80",
+				"nginx:latest",
+				"nginx", "-g", "daemon off;",
+			},
+			[]string(nil),
+			"",
+		).Return([]byte("fakecontainerid\n"), []byte{}, nil)
+
+		dockerInstance := NewDocker(executorArg)
+		actual, actualErr := dockerInstance.Run(
+			context.Background(),
+			&DockerRunOptions{
+				Image:   "nginx:latest",
+				Name:    "example",
+				Command: []string{"nginx", "-g", "daemon off;"},
+				Env:     []string{"FOO=bar"},
+				Volumes: []string{"/tmp:/data:ro"},
+				Ports:   []string{"8080:80"},
+				Network: "host",
+				Remove:  true,
+				Detach:  true,
+			},
+		)
+		require.Nil(t, actualErr)
+		assert.Equal(t, "fakecontainerid", actual)
+	})
+
+	t.Run("when running fails, it returns error", func(t *testing.T) {
+		executorArg := &ostest.FakeOsExecutor{}
+
+		fakeError := errors.New("fake error")
+		fakeStderr := []byte("fake stderr")
+		executorArg.On(
+			"ExecuteContext",
+			context.Background(),
+			"docker",
+			[]string{"run", "alpine"},
+			[]string(nil),
+			"",
+		).Return([]byte{}, fakeStderr, fakeError)
+
+		dockerInstance := NewDocker(executorArg)
+		actual, actualErr := dockerInstance.Run(context.Background(), &DockerRunOptions{Image: "alpine"})
+		require.NotNil(t, actualErr)
+		assert.Equal(t, "", actual)
+		assert.Contains(t, actualErr.Error(), string(fakeStderr))
+	})
+}
+
+func TestDocker_ImageExists(t *testing.T) {
+	t.Run("when image is present, it returns true", func(t *testing.T) {
+		executorArg := &ostest.FakeOsExecutor{}
+
+		executorArg.On(
+			"ExecuteContext",
+			context.Background(),
+			"docker",
+			[]string{"image", "inspect", "--format", "{{.Id}}", "alpine"},
+			[]string(nil),
+			"",
+		).Return([]byte("sha256:fake\n"), []byte{}, nil)
+
+		dockerInstance := NewDocker(executorArg)
+		actual, actualErr := dockerInstance.ImageExists(context.Background(), "alpine")
+		require.Nil(t, actualErr)
+		assert.True(t, actual)
+	})
+
+	t.Run("when image is missing, it returns false", func(t *testing.T) {
+		executorArg := &ostest.FakeOsExecutor{}
+
+		executorArg.On(
+			"ExecuteContext",
+			context.Background(),
+			"docker",
+			[]string{"image", "inspect", "--format", "{{.Id}}", "alpine"},
+			[]string(nil),
+			"",
+		).Return([]byte{}, []byte("Error: No such image: alpine"), errors.New("exit status 1"))
+
+		dockerInstance := NewDocker(executorArg)
+		actual, actualErr := dockerInstance.ImageExists(context.Background(), "alpine")
+		require.Nil(t, actualErr)
+		assert.False(t, actual)
+	})
+
+	t.Run("when inspecting fails otherwise, it returns error", func(t *testing.T) {
+		executorArg := &ostest.FakeOsExecutor{}
+
+		fakeError := errors.New("fake error")
+		executorArg.On(
+			"ExecuteContext",
+			context.Background(),
+			"docker",
+			[]string{"image", "inspect", "--format", "{{.Id}}", "alpine"},
+			[]string(nil),
+			"",
+		).Return([]byte{}, []byte("Cannot connect to the Docker daemon"), fakeError)
+
+		dockerInstance := NewDocker(executorArg)
+		actual, actualErr := dockerInstance.ImageExists(context.Background(), "alpine")
+		require.NotNil(t, actualErr)
+		assert.False(t, actual)
+		assert.Contains(t, actualErr.Error(), fakeError.Error())
+	})
+}
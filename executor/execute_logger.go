@@ -16,13 +16,19 @@ package executor
 
 import (
 	"context"
+	"io"
 	"strings"
 
+	"github.com/palantir/stacktrace"
+
 	"github.com/sumup-oss/go-pkgs/logger"
 	"github.com/sumup-oss/go-pkgs/os"
 )
 
-var _ os.OsExecutor = (*ExecuteLogger)(nil)
+var (
+	_ os.OsExecutor           = (*ExecuteLogger)(nil)
+	_ os.StdinCommandExecutor = (*ExecuteLogger)(nil)
+)
 
 // ExecuteLogger is os.OsExecutor decorator, that decorates the Execute method for real time debug logging.
 type ExecuteLogger struct {
@@ -45,10 +51,11 @@ func NewExecuteLoggerWithLogLevel(osExecutor os.OsExecutor, log logger.Logger, l
 }
 
 func (c *ExecuteLogger) Execute(cmd string, arg []string, env []string, dir string) ([]byte, []byte, error) {
-	c.log.Debugf("command# %s %s", cmd, strings.Join(arg, " "))
+	log := c.log.With("command", cmd)
+	log.Debugf("command# %s %s", cmd, strings.Join(arg, " "))
 
-	stdout := NewRealtimeWriter(c.log, c.logLevel)
-	stderr := NewRealtimeWriter(c.log, c.logLevel)
+	stdout := NewRealtimeWriter(log, c.logLevel)
+	stderr := NewRealtimeWriter(log, c.logLevel)
 
 	err := c.ExecuteWithStreams(cmd, arg, env, dir, stdout, stderr)
 
@@ -62,12 +69,36 @@ func (c *ExecuteLogger) ExecuteContext(
 	env []string,
 	dir string,
 ) ([]byte, []byte, error) {
-	c.log.Debugf("command# %s %s", cmd, strings.Join(arg, " "))
+	log := logger.WithContextFields(c.log.With("command", cmd), ctx)
 
-	stdout := NewRealtimeWriter(c.log, c.logLevel)
-	stderr := NewRealtimeWriter(c.log, c.logLevel)
+	log.Debugf("command# %s %s", cmd, strings.Join(arg, " "))
+
+	stdout := NewRealtimeWriter(log, c.logLevel)
+	stderr := NewRealtimeWriter(log, c.logLevel)
 
 	err := c.ExecuteWithStreamsContext(ctx, cmd, arg, env, dir, stdout, stderr)
 
 	return []byte(stdout.GetOutput()), []byte(stderr.GetOutput()), err
 }
+
+// ExecuteWithStdinContext logs the executed command. Its stdin and output are not logged,
+// since they are usually secrets, e.g manifests of decrypted secrets.
+func (c *ExecuteLogger) ExecuteWithStdinContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+	stdin io.Reader,
+) ([]byte, []byte, error) {
+	stdinExecutor, ok := c.OsExecutor.(os.StdinCommandExecutor)
+	if !ok {
+		return nil, nil, stacktrace.NewError("command executor does not support executing with stdin")
+	}
+
+	log := logger.WithContextFields(c.log.With("command", cmd), ctx)
+
+	log.Debugf("command# %s %s", cmd, strings.Join(arg, " "))
+
+	return stdinExecutor.ExecuteWithStdinContext(ctx, cmd, arg, env, dir, stdin)
+}
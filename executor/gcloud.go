@@ -0,0 +1,130 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+type GcloudOptions struct {
+	// Project is the default project of the commands, when present.
+	Project string
+	// Account is the account to execute the commands as, when present.
+	Account string
+}
+
+type Gcloud struct {
+	binPath         string
+	options         GcloudOptions
+	commandExecutor os.CommandExecutor
+}
+
+func NewGcloud(executor os.CommandExecutor, options *GcloudOptions) *Gcloud {
+	gcloud := &Gcloud{
+		binPath:         "gcloud",
+		commandExecutor: executor,
+	}
+
+	if options != nil {
+		gcloud.options = *options
+	}
+
+	return gcloud
+}
+
+// ActivateServiceAccount authorizes access with the service account JSON key file.
+func (gcloud *Gcloud) ActivateServiceAccount(ctx context.Context, keyFile string) error {
+	_, err := gcloud.Execute(ctx, "auth", "activate-service-account", "--key-file", keyFile)
+	return err
+}
+
+// GetClusterCredentials configures kubeconfig to access the GKE cluster.
+// `location` is either a zone or a region.
+func (gcloud *Gcloud) GetClusterCredentials(ctx context.Context, cluster, location string) error {
+	args := []string{"container", "clusters", "get-credentials", cluster}
+
+	// NOTE: Zones end with a letter suffix, e.g `europe-west1-b`, while regions with a digit.
+	if isGcloudZone(location) {
+		args = append(args, "--zone", location)
+	} else {
+		args = append(args, "--region", location)
+	}
+
+	_, err := gcloud.Execute(ctx, args...)
+	return err
+}
+
+// ConfigureDockerAuth registers gcloud as docker credential helper for the registry hosts,
+// e.g `europe-docker.pkg.dev`.
+func (gcloud *Gcloud) ConfigureDockerAuth(ctx context.Context, hosts ...string) error {
+	if len(hosts) == 0 {
+		return stacktrace.NewError("no registry hosts to configure docker auth for")
+	}
+
+	_, err := gcloud.Execute(ctx, "auth", "configure-docker", strings.Join(hosts, ","))
+	return err
+}
+
+// ExecuteJSON executes command with JSON formatted output and decodes it into output,
+// e.g `ExecuteJSON(ctx, &clusters, "container", "clusters", "list")`.
+func (gcloud *Gcloud) ExecuteJSON(ctx context.Context, output interface{}, args ...string) error {
+	stdout, err := gcloud.Execute(ctx, append(args, "--format", "json")...)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(stdout, output)
+	if err != nil {
+		return stacktrace.Propagate(err, "json decode command `gcloud %s` output failed", strings.Join(args, " "))
+	}
+
+	return nil
+}
+
+// Execute executes command non-interactively with the project and account options and returns its stdout.
+func (gcloud *Gcloud) Execute(ctx context.Context, args ...string) ([]byte, error) {
+	args = append(args, "--quiet")
+
+	if gcloud.options.Project != "" {
+		args = append(args, "--project", gcloud.options.Project)
+	}
+
+	if gcloud.options.Account != "" {
+		args = append(args, "--account", gcloud.options.Account)
+	}
+
+	stdout, stderr, err := gcloud.commandExecutor.ExecuteContext(ctx, gcloud.binPath, args, nil, "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return stdout, nil
+}
+
+func isGcloudZone(location string) bool {
+	if location == "" {
+		return false
+	}
+
+	last := location[len(location)-1]
+
+	return last >= 'a' && last <= 'z'
+}
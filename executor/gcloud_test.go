@@ -0,0 +1,189 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestGcloud_ActivateServiceAccount(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"gcloud",
+		[]string{
+			"auth", "activate-service-account", "--key-file", "/tmp/key.json",
+			"--quiet",
+			"--project", "fake-project",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	gcloudInstance := NewGcloud(executorArg, &GcloudOptions{Project: "fake-project"})
+	actual := gcloudInstance.ActivateServiceAccount(context.Background(), "/tmp/key.json")
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestGcloud_GetClusterCredentials(t *testing.T) {
+	t.Run(
+		"with zone location, it passes zone",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gcloud",
+				[]string{"container", "clusters", "get-credentials", "production", "--zone", "europe-west1-b", "--quiet"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte{}, nil)
+
+			gcloudInstance := NewGcloud(executorArg, nil)
+			actual := gcloudInstance.GetClusterCredentials(context.Background(), "production", "europe-west1-b")
+			require.Nil(t, actual)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"with region location, it passes region",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gcloud",
+				[]string{"container", "clusters", "get-credentials", "production", "--region", "europe-west1", "--quiet"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("fake stderr"), errors.New("fake error"))
+
+			gcloudInstance := NewGcloud(executorArg, nil)
+			actual := gcloudInstance.GetClusterCredentials(context.Background(), "production", "europe-west1")
+			require.NotNil(t, actual)
+			assert.Contains(t, actual.Error(), "fake stderr")
+		},
+	)
+}
+
+func TestGcloud_ConfigureDockerAuth(t *testing.T) {
+	t.Run(
+		"it configures all hosts at once",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gcloud",
+				[]string{"auth", "configure-docker", "europe-docker.pkg.dev,us-docker.pkg.dev", "--quiet"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte{}, nil)
+
+			gcloudInstance := NewGcloud(executorArg, nil)
+			actual := gcloudInstance.ConfigureDockerAuth(
+				context.Background(),
+				"europe-docker.pkg.dev",
+				"us-docker.pkg.dev",
+			)
+			require.Nil(t, actual)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"without hosts, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			gcloudInstance := NewGcloud(&ostest.FakeOsExecutor{}, nil)
+			actual := gcloudInstance.ConfigureDockerAuth(context.Background())
+			require.NotNil(t, actual)
+		},
+	)
+}
+
+func TestGcloud_ExecuteJSON(t *testing.T) {
+	t.Run(
+		"it decodes JSON output",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gcloud",
+				[]string{"container", "clusters", "list", "--format", "json", "--quiet", "--account", "ci@example.com"},
+				[]string(nil),
+				"",
+			).Return([]byte(`[{"name": "production", "location": "europe-west1"}]`), []byte{}, nil)
+
+			var actual []struct {
+				Name     string `json:"name"`
+				Location string `json:"location"`
+			}
+
+			gcloudInstance := NewGcloud(executorArg, &GcloudOptions{Account: "ci@example.com"})
+			actualErr := gcloudInstance.ExecuteJSON(context.Background(), &actual, "container", "clusters", "list")
+			require.Nil(t, actualErr)
+			require.Len(t, actual, 1)
+			assert.Equal(t, "production", actual[0].Name)
+			assert.Equal(t, "europe-west1", actual[0].Location)
+		},
+	)
+
+	t.Run(
+		"when output is not JSON, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gcloud",
+				[]string{"config", "list", "--format", "json", "--quiet"},
+				[]string(nil),
+				"",
+			).Return([]byte("[core]"), []byte{}, nil)
+
+			var actual map[string]interface{}
+
+			gcloudInstance := NewGcloud(executorArg, nil)
+			actualErr := gcloudInstance.ExecuteJSON(context.Background(), &actual, "config", "list")
+			require.NotNil(t, actualErr)
+			assert.Contains(t, actualErr.Error(), "json decode command `gcloud config list` output failed")
+		},
+	)
+}
@@ -0,0 +1,322 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+const (
+	ghReleaseFields     = "name,tagName,url,isDraft,isPrerelease,createdAt,assets"
+	ghPullRequestFields = "number,title,url,state,isDraft,headRefName,baseRefName"
+	ghWorkflowRunFields = "databaseId,name,status,conclusion,headBranch,event,url,createdAt"
+)
+
+type GhOptions struct {
+	// Repo is the repository, e.g `OWNER/REPO`, when not the one of the working directory.
+	Repo string
+	// Token is the authentication token, when not the one of the gh configuration or `GH_TOKEN`.
+	Token string
+	// Host is the GitHub Enterprise host, e.g `github.example.com`.
+	Host string
+}
+
+type GhReleaseOptions struct {
+	Tag   string
+	Title string
+	Notes string
+	// NotesFile is a file with the release notes, used instead of Notes.
+	NotesFile string
+	// GenerateNotes generates the release notes from the pull requests since the previous release.
+	GenerateNotes bool
+	// Target is the branch or commit the tag is created from, when the tag doesn't exist.
+	Target     string
+	Draft      bool
+	Prerelease bool
+	// Assets are the files to upload, e.g `dist/app.tar.gz` or `dist/app.tar.gz#Display name`.
+	Assets []string
+}
+
+type GhRelease struct {
+	Name         string            `json:"name"`
+	TagName      string            `json:"tagName"`
+	URL          string            `json:"url"`
+	IsDraft      bool              `json:"isDraft"`
+	IsPrerelease bool              `json:"isPrerelease"`
+	CreatedAt    time.Time         `json:"createdAt"`
+	Assets       []*GhReleaseAsset `json:"assets"`
+}
+
+type GhReleaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	Size int64  `json:"size"`
+}
+
+type GhPullRequestOptions struct {
+	Title string
+	Body  string
+	// Base is the branch to merge into, when not the default branch.
+	Base string
+	// Head is the branch with the changes, when not the current branch.
+	Head      string
+	Draft     bool
+	Labels    []string
+	Reviewers []string
+}
+
+type GhPullRequest struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	State       string `json:"state"`
+	IsDraft     bool   `json:"isDraft"`
+	HeadRefName string `json:"headRefName"`
+	BaseRefName string `json:"baseRefName"`
+}
+
+type GhWorkflowRun struct {
+	DatabaseID int64     `json:"databaseId"`
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	Conclusion string    `json:"conclusion"`
+	HeadBranch string    `json:"headBranch"`
+	Event      string    `json:"event"`
+	URL        string    `json:"url"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Gh wraps the GitHub CLI.
+type Gh struct {
+	binPath         string
+	options         GhOptions
+	commandExecutor os.CommandExecutor
+}
+
+func NewGh(executor os.CommandExecutor, options *GhOptions) *Gh {
+	gh := &Gh{
+		binPath:         "gh",
+		commandExecutor: executor,
+	}
+
+	if options != nil {
+		gh.options = *options
+	}
+
+	return gh
+}
+
+// CreateRelease creates the release, uploads its assets and returns it.
+func (gh *Gh) CreateRelease(ctx context.Context, options *GhReleaseOptions) (*GhRelease, error) {
+	args := append([]string{"release", "create", options.Tag}, options.Assets...)
+
+	if options.Title != "" {
+		args = append(args, "--title", options.Title)
+	}
+
+	switch {
+	case options.NotesFile != "":
+		args = append(args, "--notes-file", options.NotesFile)
+	case options.Notes != "":
+		args = append(args, "--notes", options.Notes)
+	}
+
+	if options.GenerateNotes {
+		args = append(args, "--generate-notes")
+	}
+
+	if options.Target != "" {
+		args = append(args, "--target", options.Target)
+	}
+
+	if options.Draft {
+		args = append(args, "--draft")
+	}
+
+	if options.Prerelease {
+		args = append(args, "--prerelease")
+	}
+
+	_, err := gh.Execute(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return gh.ViewRelease(ctx, options.Tag)
+}
+
+// ViewRelease returns the release of the tag.
+func (gh *Gh) ViewRelease(ctx context.Context, tag string) (*GhRelease, error) {
+	var release GhRelease
+
+	err := gh.ExecuteJSON(ctx, &release, ghReleaseFields, "release", "view", tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return &release, nil
+}
+
+// UploadReleaseAssets uploads the files to the release of the tag, replacing the existing ones when `clobber` is set.
+func (gh *Gh) UploadReleaseAssets(ctx context.Context, tag string, clobber bool, assets ...string) error {
+	if len(assets) == 0 {
+		return stacktrace.NewError("no assets to upload")
+	}
+
+	args := append([]string{"release", "upload", tag}, assets...)
+	if clobber {
+		args = append(args, "--clobber")
+	}
+
+	_, err := gh.Execute(ctx, args...)
+	return err
+}
+
+// CreatePullRequest creates the pull request and returns it.
+func (gh *Gh) CreatePullRequest(ctx context.Context, options *GhPullRequestOptions) (*GhPullRequest, error) {
+	// NOTE: The body is required in non-interactive mode, even when blank.
+	args := []string{"pr", "create", "--title", options.Title, "--body", options.Body}
+
+	if options.Base != "" {
+		args = append(args, "--base", options.Base)
+	}
+
+	if options.Head != "" {
+		args = append(args, "--head", options.Head)
+	}
+
+	if options.Draft {
+		args = append(args, "--draft")
+	}
+
+	for _, label := range options.Labels {
+		args = append(args, "--label", label)
+	}
+
+	for _, reviewer := range options.Reviewers {
+		args = append(args, "--reviewer", reviewer)
+	}
+
+	stdout, err := gh.Execute(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: The URL of the created pull request is the last line of the output.
+	lines := strings.Split(strings.TrimSpace(string(stdout)), "\n")
+
+	return gh.ViewPullRequest(ctx, strings.TrimSpace(lines[len(lines)-1]))
+}
+
+// ViewPullRequest returns the pull request by number, URL or branch.
+func (gh *Gh) ViewPullRequest(ctx context.Context, pullRequest string) (*GhPullRequest, error) {
+	var result GhPullRequest
+
+	err := gh.ExecuteJSON(ctx, &result, ghPullRequestFields, "pr", "view", pullRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// DispatchWorkflow triggers the `workflow_dispatch` event of the workflow, by name, ID or file name,
+// on the ref with the inputs.
+func (gh *Gh) DispatchWorkflow(ctx context.Context, workflow, ref string, inputs map[string]string) error {
+	args := []string{"workflow", "run", workflow}
+	if ref != "" {
+		args = append(args, "--ref", ref)
+	}
+
+	for _, key := range sortedKeys(inputs) {
+		args = append(args, "--raw-field", fmt.Sprintf("%s=%s", key, inputs[key]))
+	}
+
+	_, err := gh.Execute(ctx, args...)
+	return err
+}
+
+// ListWorkflowRuns returns the latest runs of the workflow, newest first, e.g to follow a dispatched one.
+func (gh *Gh) ListWorkflowRuns(ctx context.Context, workflow string, limit int) ([]*GhWorkflowRun, error) {
+	args := []string{"run", "list", "--workflow", workflow}
+	if limit > 0 {
+		args = append(args, "--limit", strconv.Itoa(limit))
+	}
+
+	var runs []*GhWorkflowRun
+
+	err := gh.ExecuteJSON(ctx, &runs, ghWorkflowRunFields, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// ExecuteJSON executes command with the JSON output of the fields, e.g `number,url`, and decodes it into output.
+func (gh *Gh) ExecuteJSON(ctx context.Context, output interface{}, fields string, args ...string) error {
+	stdout, err := gh.Execute(ctx, append(args, "--json", fields)...)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(stdout, output)
+	if err != nil {
+		return stacktrace.Propagate(err, "json decode command `gh %s` output failed", strings.Join(args, " "))
+	}
+
+	return nil
+}
+
+// Execute executes command with the repository, token and host options and returns its stdout.
+func (gh *Gh) Execute(ctx context.Context, args ...string) ([]byte, error) {
+	if gh.options.Repo != "" {
+		args = append(args, "--repo", gh.options.Repo)
+	}
+
+	stdout, stderr, err := gh.commandExecutor.ExecuteContext(ctx, gh.binPath, args, gh.env(), "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return stdout, nil
+}
+
+func (gh *Gh) env() []string {
+	var env []string
+
+	if gh.options.Token != "" {
+		if gh.options.Host == "" {
+			env = append(env, "GH_TOKEN="+gh.options.Token)
+		} else {
+			env = append(env, "GH_ENTERPRISE_TOKEN="+gh.options.Token)
+		}
+	}
+
+	if gh.options.Host != "" {
+		env = append(env, "GH_HOST="+gh.options.Host)
+	}
+
+	return os.CommandEnv(env...)
+}
@@ -0,0 +1,295 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestGh_CreateRelease(t *testing.T) {
+	t.Run(
+		"it creates the release with the assets and returns it",
+		func(t *testing.T) {
+			t.Parallel()
+
+			tokenEnv := mock.MatchedBy(func(env []string) bool {
+				return containsString(env, "GH_TOKEN=faketoken")
+			})
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gh",
+				[]string{
+					"release", "create", "v1.2.0", "dist/app_linux_amd64.tar.gz", "dist/checksums.txt",
+					"--title", "v1.2.0",
+					"--generate-notes",
+					"--target", "main",
+					"--prerelease",
+					"--repo", "sumup-oss/go-pkgs",
+				},
+				tokenEnv,
+				"",
+			).Return([]byte("https://github.com/sumup-oss/go-pkgs/releases/tag/v1.2.0\n"), []byte{}, nil)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gh",
+				[]string{
+					"release", "view", "v1.2.0",
+					"--json", "name,tagName,url,isDraft,isPrerelease,createdAt,assets",
+					"--repo", "sumup-oss/go-pkgs",
+				},
+				tokenEnv,
+				"",
+			).Return(
+				[]byte(`{
+  "name": "v1.2.0",
+  "tagName": "v1.2.0",
+  "url": "https://github.com/sumup-oss/go-pkgs/releases/tag/v1.2.0",
+  "isDraft": false,
+  "isPrerelease": true,
+  "createdAt": "2019-10-01T12:30:00Z",
+  "assets": [{"name": "checksums.txt", "url": "https://github.com/fake/checksums.txt", "size": 120}]
+}`),
+				[]byte{},
+				nil,
+			)
+
+			ghInstance := NewGh(executorArg, &GhOptions{Repo: "sumup-oss/go-pkgs", Token: "faketoken"})
+			actual, actualErr := ghInstance.CreateRelease(
+				context.Background(),
+				&GhReleaseOptions{
+					Tag:           "v1.2.0",
+					Title:         "v1.2.0",
+					GenerateNotes: true,
+					Target:        "main",
+					Prerelease:    true,
+					Assets:        []string{"dist/app_linux_amd64.tar.gz", "dist/checksums.txt"},
+				},
+			)
+			require.Nil(t, actualErr)
+			assert.Equal(t, "v1.2.0", actual.TagName)
+			assert.True(t, actual.IsPrerelease)
+			require.Len(t, actual.Assets, 1)
+			assert.Equal(t, int64(120), actual.Assets[0].Size)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"when creating fails, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gh",
+				[]string{"release", "create", "v1.2.0", "--notes-file", "CHANGELOG.md"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("HTTP 422: Validation Failed"), errors.New("exit status 1"))
+
+			ghInstance := NewGh(executorArg, nil)
+			actual, actualErr := ghInstance.CreateRelease(
+				context.Background(),
+				&GhReleaseOptions{Tag: "v1.2.0", Notes: "ignored", NotesFile: "CHANGELOG.md"},
+			)
+			require.NotNil(t, actualErr)
+			assert.Nil(t, actual)
+			assert.Contains(t, actualErr.Error(), "Validation Failed")
+		},
+	)
+}
+
+func TestGh_UploadReleaseAssets(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"gh",
+		[]string{"release", "upload", "v1.2.0", "dist/sbom.json", "--clobber"},
+		mock.MatchedBy(func(env []string) bool {
+			return containsString(env, "GH_ENTERPRISE_TOKEN=faketoken") &&
+				containsString(env, "GH_HOST=github.example.com")
+		}),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	ghInstance := NewGh(executorArg, &GhOptions{Token: "faketoken", Host: "github.example.com"})
+	actual := ghInstance.UploadReleaseAssets(context.Background(), "v1.2.0", true, "dist/sbom.json")
+	require.Nil(t, actual)
+
+	actual = ghInstance.UploadReleaseAssets(context.Background(), "v1.2.0", true)
+	require.NotNil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestGh_CreatePullRequest(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"gh",
+		[]string{
+			"pr", "create",
+			"--title", "Bump api to 1.2.0",
+			"--body", "",
+			"--base", "main",
+			"--head", "bump-api",
+			"--draft",
+			"--label", "dependencies",
+			"--reviewer", "sumup-oss/platform",
+		},
+		[]string(nil),
+		"",
+	).Return(
+		[]byte("Creating draft pull request for bump-api into main\n\nhttps://github.com/sumup-oss/deploy/pull/42\n"),
+		[]byte{},
+		nil,
+	)
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"gh",
+		[]string{
+			"pr", "view", "https://github.com/sumup-oss/deploy/pull/42",
+			"--json", "number,title,url,state,isDraft,headRefName,baseRefName",
+		},
+		[]string(nil),
+		"",
+	).Return(
+		[]byte(`{
+  "number": 42,
+  "title": "Bump api to 1.2.0",
+  "url": "https://github.com/sumup-oss/deploy/pull/42",
+  "state": "OPEN",
+  "isDraft": true,
+  "headRefName": "bump-api",
+  "baseRefName": "main"
+}`),
+		[]byte{},
+		nil,
+	)
+
+	ghInstance := NewGh(executorArg, nil)
+	actual, actualErr := ghInstance.CreatePullRequest(
+		context.Background(),
+		&GhPullRequestOptions{
+			Title:     "Bump api to 1.2.0",
+			Base:      "main",
+			Head:      "bump-api",
+			Draft:     true,
+			Labels:    []string{"dependencies"},
+			Reviewers: []string{"sumup-oss/platform"},
+		},
+	)
+	require.Nil(t, actualErr)
+	assert.Equal(
+		t,
+		&GhPullRequest{
+			Number:      42,
+			Title:       "Bump api to 1.2.0",
+			URL:         "https://github.com/sumup-oss/deploy/pull/42",
+			State:       "OPEN",
+			IsDraft:     true,
+			HeadRefName: "bump-api",
+			BaseRefName: "main",
+		},
+		actual,
+	)
+}
+
+func TestGh_DispatchWorkflow(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"gh",
+		[]string{
+			"workflow", "run", "deploy.yml",
+			"--ref", "main",
+			"--raw-field", "environment=production",
+			"--raw-field", "version=1.2.0",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	ghInstance := NewGh(executorArg, nil)
+	actual := ghInstance.DispatchWorkflow(
+		context.Background(),
+		"deploy.yml",
+		"main",
+		map[string]string{"version": "1.2.0", "environment": "production"},
+	)
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestGh_ListWorkflowRuns(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"gh",
+		[]string{
+			"run", "list", "--workflow", "deploy.yml", "--limit", "1",
+			"--json", "databaseId,name,status,conclusion,headBranch,event,url,createdAt",
+		},
+		[]string(nil),
+		"",
+	).Return(
+		[]byte(`[{
+  "databaseId": 6123456789,
+  "name": "deploy",
+  "status": "completed",
+  "conclusion": "success",
+  "headBranch": "main",
+  "event": "workflow_dispatch",
+  "url": "https://github.com/sumup-oss/deploy/actions/runs/6123456789",
+  "createdAt": "2019-10-01T12:30:00Z"
+}]`),
+		[]byte{},
+		nil,
+	)
+
+	ghInstance := NewGh(executorArg, nil)
+	actual, actualErr := ghInstance.ListWorkflowRuns(context.Background(), "deploy.yml", 1)
+	require.Nil(t, actualErr)
+	require.Len(t, actual, 1)
+	assert.Equal(t, int64(6123456789), actual[0].DatabaseID)
+	assert.Equal(t, "success", actual[0].Conclusion)
+	assert.Equal(t, "workflow_dispatch", actual[0].Event)
+}
@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/sumup-oss/go-pkgs/os"
@@ -449,3 +450,273 @@ func (git *Git) Config(ctx context.Context, key, value string) error {
 
 	return nil
 }
+
+type GitCloneOptions struct {
+	// Depth creates a shallow clone with history truncated to the number of commits, when positive.
+	Depth int
+	// Branch is the branch or tag to check out instead of the remote HEAD.
+	Branch       string
+	SingleBranch bool
+	NoCheckout   bool
+}
+
+// GitDescription is the parsed output of `git describe --tags --long`.
+type GitDescription struct {
+	Tag string
+	// CommitsAhead is the number of commits on top of the tag.
+	CommitsAhead int
+	// Hash is the abbreviated hash of the described commit.
+	Hash  string
+	Dirty bool
+}
+
+// GitStatusEntry is a changed path of `git status --porcelain` output.
+type GitStatusEntry struct {
+	// IndexStatus and WorktreeStatus are the porcelain status codes, e.g `M`, `A`, `D`, `R`, `?`.
+	IndexStatus    byte
+	WorktreeStatus byte
+	Path           string
+	// OriginalPath is the path before a rename or copy.
+	OriginalPath string
+}
+
+func (entry *GitStatusEntry) IsUntracked() bool {
+	return entry.IndexStatus == '?' && entry.WorktreeStatus == '?'
+}
+
+// WithCredentials returns a copy of the instance that authenticates HTTP(S) remotes with username and password,
+// e.g an access token. The credentials are passed through environment variables to an inline
+// credential helper, so they never show up in process arguments.
+// It requires git 2.31 or later.
+func (git *Git) WithCredentials(username, password string) *Git {
+	clone := *git
+	clone.env = git.withEnv(
+		"GIT_USERNAME="+username,
+		"GIT_PASSWORD="+password,
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=credential.helper",
+		`GIT_CONFIG_VALUE_0=!f() { echo "username=${GIT_USERNAME}"; echo "password=${GIT_PASSWORD}"; }; f`,
+	)
+
+	return &clone
+}
+
+// WithAskpass returns a copy of the instance that asks for credentials through the askpass helper program
+// and never prompts on the terminal.
+func (git *Git) WithAskpass(helperPath string) *Git {
+	clone := *git
+	clone.env = git.withEnv("GIT_ASKPASS="+helperPath, "GIT_TERMINAL_PROMPT=0")
+
+	return &clone
+}
+
+// withEnv returns a copy of the env with `env` added to it, or the current process environment when none is set.
+// NOTE: The env is copied, so that appending to it never modifies the env of other instances.
+func (git *Git) withEnv(env ...string) []string {
+	if len(git.env) > 0 {
+		return append(append([]string(nil), git.env...), env...)
+	}
+
+	return os.CommandEnv(env...)
+}
+
+func (git *Git) CloneWithOptions(ctx context.Context, options *GitCloneOptions) error {
+	args := []string{"clone"}
+
+	if options.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(options.Depth))
+	}
+
+	if options.Branch != "" {
+		args = append(args, "--branch", options.Branch)
+	}
+
+	if options.SingleBranch {
+		args = append(args, "--single-branch")
+	}
+
+	if options.NoCheckout {
+		args = append(args, "--no-checkout")
+	}
+
+	args = append(args, git.url, git.dir)
+
+	_, stderr, err := git.commandExecutor.ExecuteContext(ctx, git.binPath, args, git.env, "")
+	if err != nil {
+		return fmt.Errorf("%s. Stderr: %s", err, stderr)
+	}
+
+	return nil
+}
+
+// Checkout checks out a branch, tag or commit.
+func (git *Git) Checkout(ctx context.Context, ref string) error {
+	_, stderr, err := git.commandExecutor.ExecuteContext(
+		ctx,
+		git.binPath,
+		[]string{"-C", git.dir, "checkout", ref},
+		git.env,
+		"",
+	)
+	if err != nil {
+		return fmt.Errorf("%s. Stderr: %s", err, stderr)
+	}
+
+	return nil
+}
+
+// FetchTags fetches all tags of the remote, replacing local tags that were moved.
+func (git *Git) FetchTags(ctx context.Context) error {
+	_, stderr, err := git.commandExecutor.ExecuteContext(
+		ctx,
+		git.binPath,
+		[]string{"-C", git.dir, "fetch", "--tags", "--force"},
+		git.env,
+		"",
+	)
+	if err != nil {
+		return fmt.Errorf("%s. Stderr: %s", err, stderr)
+	}
+
+	return nil
+}
+
+// RevParse returns the commit hash that `rev` resolves to.
+func (git *Git) RevParse(ctx context.Context, rev string) (string, error) {
+	stdout, stderr, err := git.commandExecutor.ExecuteContext(
+		ctx,
+		git.binPath,
+		[]string{"-C", git.dir, "rev-parse", "--verify", rev + "^{commit}"},
+		git.env,
+		"",
+	)
+	if err != nil {
+		return "", fmt.Errorf("%s. Stderr: %s", err, stderr)
+	}
+
+	return strings.Trim(string(stdout), "\n\r "), nil
+}
+
+// DescribeTags describes `rev`, or HEAD when blank, relative to the most recent reachable tag.
+// Only tags matching `match` glob are considered, when present.
+func (git *Git) DescribeTags(ctx context.Context, rev, match string) (*GitDescription, error) {
+	args := []string{"-C", git.dir, "describe", "--tags", "--long"}
+
+	if match != "" {
+		args = append(args, "--match", match)
+	}
+
+	if rev == "" {
+		args = append(args, "--dirty")
+	} else {
+		args = append(args, rev)
+	}
+
+	stdout, stderr, err := git.commandExecutor.ExecuteContext(ctx, git.binPath, args, git.env, "")
+	if err != nil {
+		return nil, fmt.Errorf("%s. Stderr: %s", err, stderr)
+	}
+
+	return parseGitDescription(strings.Trim(string(stdout), "\n\r "))
+}
+
+func parseGitDescription(output string) (*GitDescription, error) {
+	description := &GitDescription{}
+
+	if strings.HasSuffix(output, "-dirty") {
+		description.Dirty = true
+		output = strings.TrimSuffix(output, "-dirty")
+	}
+
+	// NOTE: Tags may contain dashes, so split the `<tag>-<commits>-g<hash>` format from the right.
+	hashIndex := strings.LastIndex(output, "-g")
+	if hashIndex < 0 {
+		return nil, fmt.Errorf("failed to parse git describe output %s", output)
+	}
+
+	description.Hash = output[hashIndex+2:]
+
+	commitsIndex := strings.LastIndex(output[:hashIndex], "-")
+	if commitsIndex < 0 {
+		return nil, fmt.Errorf("failed to parse git describe output %s", output)
+	}
+
+	commitsAhead, err := strconv.Atoi(output[commitsIndex+1 : hashIndex])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse git describe output %s. Err: %s", output, err)
+	}
+
+	description.CommitsAhead = commitsAhead
+	description.Tag = output[:commitsIndex]
+
+	return description, nil
+}
+
+// CommitAndPush stages all changes, commits them with message and pushes to destination,
+// e.g `origin master`. It does nothing when there are no changes.
+func (git *Git) CommitAndPush(ctx context.Context, message, destination string) error {
+	entries, err := git.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	steps := [][]string{
+		{"-C", git.dir, "add", "--all"},
+		{"-C", git.dir, "commit", "-m", message},
+		append([]string{"-C", git.dir, "push"}, strings.Split(destination, " ")...),
+	}
+
+	for _, args := range steps {
+		_, stderr, err := git.commandExecutor.ExecuteContext(ctx, git.binPath, args, git.env, "")
+		if err != nil {
+			return fmt.Errorf("failed to execute git %s. Err: %s. Stderr: %s", args[2], err, stderr)
+		}
+	}
+
+	return nil
+}
+
+// Status returns the changed paths of the working tree.
+func (git *Git) Status(ctx context.Context) ([]*GitStatusEntry, error) {
+	stdout, stderr, err := git.commandExecutor.ExecuteContext(
+		ctx,
+		git.binPath,
+		[]string{"-C", git.dir, "status", "--porcelain", "-z"},
+		git.env,
+		"",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s. Stderr: %s", err, stderr)
+	}
+
+	entries := make([]*GitStatusEntry, 0)
+
+	// NOTE: With `-z` paths are NUL-terminated and not quoted,
+	// and the original path of a rename or copy is the next record.
+	records := strings.Split(string(stdout), "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 4 {
+			continue
+		}
+
+		entry := &GitStatusEntry{
+			IndexStatus:    record[0],
+			WorktreeStatus: record[1],
+			Path:           record[3:],
+		}
+
+		if (entry.IndexStatus == 'R' || entry.IndexStatus == 'C') && i+1 < len(records) {
+			i++
+			entry.OriginalPath = records[i]
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
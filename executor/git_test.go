@@ -0,0 +1,247 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestGit_CloneWithOptions(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"git",
+		[]string{"clone", "--depth", "1", "--branch", "v1.0.0", "--single-branch", "git@example.com:repo.git", "/tmp/repo"},
+		[]string{},
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	gitInstance := NewGit(executorArg, "git@example.com:repo.git", "/tmp/repo", nil)
+	actual := gitInstance.CloneWithOptions(
+		context.Background(),
+		&GitCloneOptions{Depth: 1, Branch: "v1.0.0", SingleBranch: true},
+	)
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestGit_WithCredentials(t *testing.T) {
+	t.Parallel()
+
+	env := make([]string, 1, 10)
+	env[0] = "HOME=/tmp"
+
+	gitInstance := NewGit(&ostest.FakeOsExecutor{}, "https://example.com/repo.git", "/tmp/repo", env)
+	actual := gitInstance.WithCredentials("user", "token")
+
+	assert.Equal(t, "HOME=/tmp", actual.env[0])
+	assert.Contains(t, actual.env, "GIT_USERNAME=user")
+	assert.Contains(t, actual.env, "GIT_PASSWORD=token")
+	assert.Contains(t, actual.env, "GIT_CONFIG_KEY_0=credential.helper")
+
+	// NOTE: The instance and its env are not modified, even when the env has spare capacity.
+	other := gitInstance.WithCredentials("other", "other-token")
+	assert.Equal(t, []string{"HOME=/tmp"}, gitInstance.env)
+	assert.Contains(t, actual.env, "GIT_USERNAME=user")
+	assert.Contains(t, other.env, "GIT_USERNAME=other")
+}
+
+func TestGit_WithAskpass(t *testing.T) {
+	t.Parallel()
+
+	gitInstance := NewGit(&ostest.FakeOsExecutor{}, "https://example.com/repo.git", "/tmp/repo", nil)
+	actual := gitInstance.WithAskpass("/usr/local/bin/askpass")
+
+	assert.Contains(t, actual.env, "GIT_ASKPASS=/usr/local/bin/askpass")
+	assert.Contains(t, actual.env, "GIT_TERMINAL_PROMPT=0")
+	// NOTE: The current process environment is kept, otherwise git would run without `PATH`.
+	assert.True(t, len(actual.env) > 2)
+	assert.Empty(t, gitInstance.env)
+}
+
+func TestGit_RevParse(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"git",
+		[]string{"-C", "/tmp/repo", "rev-parse", "--verify", "v1.0.0^{commit}"},
+		[]string{},
+		"",
+	).Return([]byte("0123456789abcdef\n"), []byte{}, nil)
+
+	gitInstance := NewGit(executorArg, "", "/tmp/repo", nil)
+	actual, actualErr := gitInstance.RevParse(context.Background(), "v1.0.0")
+	require.Nil(t, actualErr)
+	assert.Equal(t, "0123456789abcdef", actual)
+}
+
+func TestGit_DescribeTags(t *testing.T) {
+	t.Run(
+		"with blank rev, it describes HEAD including dirty state",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"git",
+				[]string{"-C", "/tmp/repo", "describe", "--tags", "--long", "--match", "v*", "--dirty"},
+				[]string{},
+				"",
+			).Return([]byte("v1.2.0-rc-1-3-gabc1234-dirty\n"), []byte{}, nil)
+
+			gitInstance := NewGit(executorArg, "", "/tmp/repo", nil)
+			actual, actualErr := gitInstance.DescribeTags(context.Background(), "", "v*")
+			require.Nil(t, actualErr)
+
+			expected := &GitDescription{Tag: "v1.2.0-rc-1", CommitsAhead: 3, Hash: "abc1234", Dirty: true}
+			assert.Equal(t, expected, actual)
+		},
+	)
+
+	t.Run(
+		"when output is not in long format, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"git",
+				[]string{"-C", "/tmp/repo", "describe", "--tags", "--long", "HEAD~1"},
+				[]string{},
+				"",
+			).Return([]byte("v1.2.0\n"), []byte{}, nil)
+
+			gitInstance := NewGit(executorArg, "", "/tmp/repo", nil)
+			actual, actualErr := gitInstance.DescribeTags(context.Background(), "HEAD~1", "")
+			require.NotNil(t, actualErr)
+			assert.Nil(t, actual)
+		},
+	)
+}
+
+func TestGit_Status(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"git",
+		[]string{"-C", "/tmp/repo", "status", "--porcelain", "-z"},
+		[]string{},
+		"",
+	).Return([]byte(" M main.go\x00R  new name.go\x00old.go\x00?? notes.txt\x00"), []byte{}, nil)
+
+	gitInstance := NewGit(executorArg, "", "/tmp/repo", nil)
+	actual, actualErr := gitInstance.Status(context.Background())
+	require.Nil(t, actualErr)
+
+	expected := []*GitStatusEntry{
+		{IndexStatus: ' ', WorktreeStatus: 'M', Path: "main.go"},
+		{IndexStatus: 'R', WorktreeStatus: ' ', Path: "new name.go", OriginalPath: "old.go"},
+		{IndexStatus: '?', WorktreeStatus: '?', Path: "notes.txt"},
+	}
+	assert.Equal(t, expected, actual)
+	assert.True(t, actual[2].IsUntracked())
+}
+
+func TestGit_CommitAndPush(t *testing.T) {
+	t.Run(
+		"when there are no changes, it does not commit",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"git",
+				[]string{"-C", "/tmp/repo", "status", "--porcelain", "-z"},
+				[]string{},
+				"",
+			).Return([]byte{}, []byte{}, nil)
+
+			gitInstance := NewGit(executorArg, "", "/tmp/repo", nil)
+			actual := gitInstance.CommitAndPush(context.Background(), "message", "origin master")
+			require.Nil(t, actual)
+			executorArg.AssertNumberOfCalls(t, "ExecuteContext", 1)
+		},
+	)
+
+	t.Run(
+		"when there are changes, it adds, commits and pushes them",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"git",
+				[]string{"-C", "/tmp/repo", "status", "--porcelain", "-z"},
+				[]string{},
+				"",
+			).Return([]byte("?? notes.txt\x00"), []byte{}, nil)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"git",
+				[]string{"-C", "/tmp/repo", "add", "--all"},
+				[]string{},
+				"",
+			).Return([]byte{}, []byte{}, nil)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"git",
+				[]string{"-C", "/tmp/repo", "commit", "-m", "message"},
+				[]string{},
+				"",
+			).Return([]byte{}, []byte{}, nil)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"git",
+				[]string{"-C", "/tmp/repo", "push", "origin", "master"},
+				[]string{},
+				"",
+			).Return([]byte{}, []byte("rejected"), errors.New("fake error"))
+
+			gitInstance := NewGit(executorArg, "", "/tmp/repo", nil)
+			actual := gitInstance.CommitAndPush(context.Background(), "message", "origin master")
+			require.NotNil(t, actual)
+			assert.Contains(t, actual.Error(), "failed to execute git push")
+			assert.Contains(t, actual.Error(), "rejected")
+			executorArg.AssertExpectations(t)
+		},
+	)
+}
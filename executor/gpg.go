@@ -0,0 +1,303 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+const gpgStatusPrefix = "[GNUPG:] "
+
+type GpgOptions struct {
+	// Homedir is the GnuPG home directory, when not the default `~/.gnupg`.
+	Homedir string
+	// LocalUser is the key to sign with, when not the default key.
+	LocalUser string
+	// Passphrase unlocks the secret key without prompting. It's written to the gpg stdin,
+	// so the command executor must implement os.StdinCommandExecutor.
+	Passphrase string
+}
+
+// GpgVerification is the result of a signature verification.
+type GpgVerification struct {
+	Valid bool
+	// KeyID is the long ID of the signing key.
+	KeyID string
+	// Fingerprint is the fingerprint of the signing key. Only present for good signatures.
+	Fingerprint string
+	UserID      string
+	// Status is the status keyword of the signature, e.g `GOODSIG`, `BADSIG`, `EXPKEYSIG` or `NO_PUBKEY`.
+	Status string
+}
+
+type Gpg struct {
+	binPath         string
+	options         GpgOptions
+	commandExecutor os.CommandExecutor
+}
+
+func NewGpg(executor os.CommandExecutor, options *GpgOptions) *Gpg {
+	gpg := &Gpg{
+		binPath:         "gpg",
+		commandExecutor: executor,
+	}
+
+	if options != nil {
+		gpg.options = *options
+	}
+
+	return gpg
+}
+
+// Sign signs the file and writes the signature to output. The signature is detached when `detached` is set,
+// otherwise output is the signed file. The signature is ASCII armored when `armor` is set.
+func (gpg *Gpg) Sign(ctx context.Context, path, output string, detached, armor bool) error {
+	args := []string{"--output", output}
+
+	if gpg.options.LocalUser != "" {
+		args = append(args, "--local-user", gpg.options.LocalUser)
+	}
+
+	if armor {
+		args = append(args, "--armor")
+	}
+
+	if detached {
+		args = append(args, "--detach-sign")
+	} else {
+		args = append(args, "--sign")
+	}
+
+	_, err := gpg.executeWithPassphrase(ctx, append(args, path)...)
+	return stacktrace.Propagate(err, "signing %s failed", path)
+}
+
+// Verify verifies the detached signature of the data file, or the signed file when `dataPath` is blank.
+// An invalid signature is not an error, but a verification that's not valid.
+func (gpg *Gpg) Verify(ctx context.Context, signaturePath, dataPath string) (*GpgVerification, error) {
+	args := []string{"--status-fd", "1", "--verify", signaturePath}
+	if dataPath != "" {
+		args = append(args, dataPath)
+	}
+
+	stdout, stderr, err := gpg.commandExecutor.ExecuteContext(ctx, gpg.binPath, gpg.arguments(args...), nil, "")
+
+	verification := parseGpgVerification(stdout)
+	if verification.Status != "" {
+		return verification, nil
+	}
+
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "verifying %s failed. Stderr: %s", signaturePath, stderr)
+	}
+
+	return nil, stacktrace.NewError("verifying %s failed, no signature found. Stderr: %s", signaturePath, stderr)
+}
+
+// Encrypt encrypts the file for the recipients and writes it to output.
+// The encrypted file is ASCII armored when `armor` is set.
+func (gpg *Gpg) Encrypt(ctx context.Context, path, output string, recipients []string, armor bool) error {
+	if len(recipients) == 0 {
+		return stacktrace.NewError("no recipients to encrypt %s for", path)
+	}
+
+	args := []string{"--output", output}
+
+	for _, recipient := range recipients {
+		args = append(args, "--recipient", recipient)
+	}
+
+	if armor {
+		args = append(args, "--armor")
+	}
+
+	args = append(args, "--encrypt", path)
+
+	stdout, stderr, err := gpg.commandExecutor.ExecuteContext(ctx, gpg.binPath, gpg.arguments(args...), nil, "")
+	return stacktrace.Propagate(err, "encrypting %s failed. Stderr: %s, Stdout: %s", path, stderr, stdout)
+}
+
+// Decrypt decrypts the file and writes it to output.
+func (gpg *Gpg) Decrypt(ctx context.Context, path, output string) error {
+	_, err := gpg.executeWithPassphrase(ctx, "--output", output, "--decrypt", path)
+	return stacktrace.Propagate(err, "decrypting %s failed", path)
+}
+
+// ImportKey imports the public or secret keys and returns the fingerprints of the imported keys.
+// The keys are written to the gpg stdin, so the command executor must implement os.StdinCommandExecutor.
+func (gpg *Gpg) ImportKey(ctx context.Context, key []byte) ([]string, error) {
+	stdinExecutor, ok := gpg.commandExecutor.(os.StdinCommandExecutor)
+	if !ok {
+		return nil, stacktrace.NewError("command executor does not support executing with stdin")
+	}
+
+	stdout, stderr, err := stdinExecutor.ExecuteWithStdinContext(
+		ctx,
+		gpg.binPath,
+		gpg.arguments("--status-fd", "1", "--import"),
+		nil,
+		"",
+		bytes.NewReader(key),
+	)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "importing key failed. Stderr: %s", stderr)
+	}
+
+	var fingerprints []string
+
+	for _, fields := range parseGpgStatus(stdout) {
+		// NOTE: `IMPORT_OK <reason> <fingerprint>`
+		if fields[0] == "IMPORT_OK" && len(fields) > 2 {
+			fingerprints = append(fingerprints, fields[2])
+		}
+	}
+
+	return fingerprints, nil
+}
+
+// ExportKey exports the public key, or the secret key when `secret` is set.
+// The key is ASCII armored when `armor` is set.
+func (gpg *Gpg) ExportKey(ctx context.Context, keyID string, secret, armor bool) ([]byte, error) {
+	var args []string
+
+	if armor {
+		args = append(args, "--armor")
+	}
+
+	if secret {
+		args = append(args, "--export-secret-keys", keyID)
+		return gpg.executeWithPassphrase(ctx, args...)
+	}
+
+	args = append(args, "--export", keyID)
+
+	stdout, stderr, err := gpg.commandExecutor.ExecuteContext(ctx, gpg.binPath, gpg.arguments(args...), nil, "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "exporting key %s failed. Stderr: %s", keyID, stderr)
+	}
+
+	if len(stdout) == 0 {
+		return nil, stacktrace.NewError("key %s not found", keyID)
+	}
+
+	return stdout, nil
+}
+
+func (gpg *Gpg) executeWithPassphrase(ctx context.Context, args ...string) ([]byte, error) {
+	if gpg.options.Passphrase == "" {
+		stdout, stderr, err := gpg.commandExecutor.ExecuteContext(ctx, gpg.binPath, gpg.arguments(args...), nil, "")
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Stderr: %s", stderr)
+		}
+
+		return stdout, nil
+	}
+
+	stdinExecutor, ok := gpg.commandExecutor.(os.StdinCommandExecutor)
+	if !ok {
+		return nil, stacktrace.NewError("command executor does not support executing with stdin")
+	}
+
+	// NOTE: The loopback pinentry mode reads the passphrase from the file descriptor instead of prompting.
+	args = append([]string{"--pinentry-mode", "loopback", "--passphrase-fd", "0"}, args...)
+
+	stdout, stderr, err := stdinExecutor.ExecuteWithStdinContext(
+		ctx,
+		gpg.binPath,
+		gpg.arguments(args...),
+		nil,
+		"",
+		strings.NewReader(gpg.options.Passphrase+"\n"),
+	)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s", stderr)
+	}
+
+	return stdout, nil
+}
+
+func (gpg *Gpg) arguments(args ...string) []string {
+	base := []string{"--batch", "--yes", "--no-tty"}
+
+	if gpg.options.Homedir != "" {
+		base = append(base, "--homedir", gpg.options.Homedir)
+	}
+
+	return append(base, args...)
+}
+
+func parseGpgVerification(status []byte) *GpgVerification {
+	verification := &GpgVerification{}
+
+	for _, fields := range parseGpgStatus(status) {
+		switch fields[0] {
+		case "GOODSIG", "BADSIG", "EXPSIG", "EXPKEYSIG", "REVKEYSIG":
+			verification.Status = fields[0]
+			verification.Valid = fields[0] == "GOODSIG"
+
+			if len(fields) > 1 {
+				verification.KeyID = fields[1]
+			}
+
+			if len(fields) > 2 {
+				verification.UserID = strings.Join(fields[2:], " ")
+			}
+		case "ERRSIG", "NO_PUBKEY":
+			// NOTE: ERRSIG is followed by NO_PUBKEY when the key is missing, keep the more specific one.
+			if verification.Status == "" || verification.Status == "ERRSIG" {
+				verification.Status = fields[0]
+			}
+
+			if len(fields) > 1 {
+				verification.KeyID = fields[1]
+			}
+		case "VALIDSIG":
+			if len(fields) > 1 {
+				verification.Fingerprint = fields[1]
+			}
+		}
+	}
+
+	return verification
+}
+
+// parseGpgStatus returns the fields of the `--status-fd` lines.
+func parseGpgStatus(status []byte) [][]string {
+	var lines [][]string
+
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, gpgStatusPrefix) {
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(line, gpgStatusPrefix))
+		if len(fields) == 0 {
+			continue
+		}
+
+		lines = append(lines, fields)
+	}
+
+	return lines
+}
@@ -0,0 +1,312 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestGpg_Sign(t *testing.T) {
+	t.Run(
+		"with passphrase, it writes it to stdin in loopback pinentry mode",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"gpg",
+				[]string{
+					"--batch", "--yes", "--no-tty",
+					"--homedir", "/tmp/gnupg",
+					"--pinentry-mode", "loopback",
+					"--passphrase-fd", "0",
+					"--output", "app.tar.gz.asc",
+					"--local-user", "release@example.com",
+					"--armor",
+					"--detach-sign",
+					"app.tar.gz",
+				},
+				[]string(nil),
+				"",
+				strings.NewReader("fakepassphrase\n"),
+			).Return([]byte{}, []byte{}, nil)
+
+			gpgInstance := NewGpg(
+				executorArg,
+				&GpgOptions{Homedir: "/tmp/gnupg", LocalUser: "release@example.com", Passphrase: "fakepassphrase"},
+			)
+			actualErr := gpgInstance.Sign(context.Background(), "app.tar.gz", "app.tar.gz.asc", true, true)
+			require.Nil(t, actualErr)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"without passphrase, it signs without stdin",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gpg",
+				[]string{"--batch", "--yes", "--no-tty", "--output", "app.gpg", "--sign", "app"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("no default secret key"), errors.New("exit status 2"))
+
+			gpgInstance := NewGpg(executorArg, nil)
+			actualErr := gpgInstance.Sign(context.Background(), "app", "app.gpg", false, false)
+			require.NotNil(t, actualErr)
+			assert.Contains(t, actualErr.Error(), "no default secret key")
+		},
+	)
+}
+
+func TestGpg_Verify(t *testing.T) {
+	t.Run(
+		"with good signature, it returns valid verification",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gpg",
+				[]string{"--batch", "--yes", "--no-tty", "--status-fd", "1", "--verify", "app.tar.gz.asc", "app.tar.gz"},
+				[]string(nil),
+				"",
+			).Return(
+				[]byte("[GNUPG:] NEWSIG\n"+
+					"[GNUPG:] GOODSIG 0123456789ABCDEF Release Bot <release@example.com>\n"+
+					"[GNUPG:] VALIDSIG FAKEFINGERPRINT 2019-10-10 1570701600 0 4 0 1 10 00 FAKEFINGERPRINT\n"),
+				[]byte{},
+				nil,
+			)
+
+			gpgInstance := NewGpg(executorArg, nil)
+			actual, actualErr := gpgInstance.Verify(context.Background(), "app.tar.gz.asc", "app.tar.gz")
+			require.Nil(t, actualErr)
+
+			expected := &GpgVerification{
+				Valid:       true,
+				KeyID:       "0123456789ABCDEF",
+				Fingerprint: "FAKEFINGERPRINT",
+				UserID:      "Release Bot <release@example.com>",
+				Status:      "GOODSIG",
+			}
+			assert.Equal(t, expected, actual)
+		},
+	)
+
+	t.Run(
+		"with missing public key, it returns invalid verification",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gpg",
+				[]string{"--batch", "--yes", "--no-tty", "--status-fd", "1", "--verify", "app.gpg"},
+				[]string(nil),
+				"",
+			).Return(
+				[]byte("[GNUPG:] ERRSIG 0123456789ABCDEF 1 10 00 1570701600 9 -\n"+
+					"[GNUPG:] NO_PUBKEY 0123456789ABCDEF\n"),
+				[]byte{},
+				errors.New("exit status 2"),
+			)
+
+			gpgInstance := NewGpg(executorArg, nil)
+			actual, actualErr := gpgInstance.Verify(context.Background(), "app.gpg", "")
+			require.Nil(t, actualErr)
+			assert.False(t, actual.Valid)
+			assert.Equal(t, "NO_PUBKEY", actual.Status)
+			assert.Equal(t, "0123456789ABCDEF", actual.KeyID)
+		},
+	)
+
+	t.Run(
+		"when there is no signature, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gpg",
+				[]string{"--batch", "--yes", "--no-tty", "--status-fd", "1", "--verify", "app.tar.gz"},
+				[]string(nil),
+				"",
+			).Return([]byte("[GNUPG:] NODATA 1\n"), []byte("no valid OpenPGP data found"), errors.New("exit status 2"))
+
+			gpgInstance := NewGpg(executorArg, nil)
+			actual, actualErr := gpgInstance.Verify(context.Background(), "app.tar.gz", "")
+			require.NotNil(t, actualErr)
+			assert.Nil(t, actual)
+			assert.Contains(t, actualErr.Error(), "no valid OpenPGP data found")
+		},
+	)
+}
+
+func TestGpg_Encrypt(t *testing.T) {
+	t.Parallel()
+
+	executorArg := ostest.NewFakeOsExecutor(t)
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"gpg",
+		[]string{
+			"--batch", "--yes", "--no-tty",
+			"--output", "secrets.asc",
+			"--recipient", "ops@example.com",
+			"--recipient", "0123456789ABCDEF",
+			"--armor",
+			"--encrypt", "secrets.txt",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	gpgInstance := NewGpg(executorArg, nil)
+	actualErr := gpgInstance.Encrypt(
+		context.Background(),
+		"secrets.txt",
+		"secrets.asc",
+		[]string{"ops@example.com", "0123456789ABCDEF"},
+		true,
+	)
+	require.Nil(t, actualErr)
+
+	actualErr = gpgInstance.Encrypt(context.Background(), "secrets.txt", "secrets.asc", nil, true)
+	require.NotNil(t, actualErr)
+}
+
+func TestGpg_Decrypt(t *testing.T) {
+	t.Parallel()
+
+	executorArg := ostest.NewFakeOsExecutor(t)
+	executorArg.On(
+		"ExecuteWithStdinContext",
+		context.Background(),
+		"gpg",
+		[]string{
+			"--batch", "--yes", "--no-tty",
+			"--pinentry-mode", "loopback",
+			"--passphrase-fd", "0",
+			"--output", "secrets.txt",
+			"--decrypt", "secrets.asc",
+		},
+		[]string(nil),
+		"",
+		strings.NewReader("fakepassphrase\n"),
+	).Return([]byte{}, []byte("Bad passphrase"), errors.New("exit status 2"))
+
+	gpgInstance := NewGpg(executorArg, &GpgOptions{Passphrase: "fakepassphrase"})
+	actualErr := gpgInstance.Decrypt(context.Background(), "secrets.asc", "secrets.txt")
+	require.NotNil(t, actualErr)
+	assert.Contains(t, actualErr.Error(), "Bad passphrase")
+}
+
+func TestGpg_ImportKey(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----\nfake\n-----END PGP PUBLIC KEY BLOCK-----\n")
+
+	executorArg := ostest.NewFakeOsExecutor(t)
+	executorArg.On(
+		"ExecuteWithStdinContext",
+		context.Background(),
+		"gpg",
+		[]string{"--batch", "--yes", "--no-tty", "--status-fd", "1", "--import"},
+		[]string(nil),
+		"",
+		bytes.NewReader(key),
+	).Return(
+		[]byte("[GNUPG:] IMPORTED 0123456789ABCDEF Release Bot\n"+
+			"[GNUPG:] IMPORT_OK 1 FAKEFINGERPRINT\n"+
+			"[GNUPG:] IMPORT_RES 1 0 1 0 0 0 0 0 0 0 0 0 0 0 0\n"),
+		[]byte{},
+		nil,
+	)
+
+	gpgInstance := NewGpg(executorArg, nil)
+	actual, actualErr := gpgInstance.ImportKey(context.Background(), key)
+	require.Nil(t, actualErr)
+	assert.Equal(t, []string{"FAKEFINGERPRINT"}, actual)
+}
+
+func TestGpg_ExportKey(t *testing.T) {
+	t.Run(
+		"it returns the exported public key",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gpg",
+				[]string{"--batch", "--yes", "--no-tty", "--armor", "--export", "release@example.com"},
+				[]string(nil),
+				"",
+			).Return([]byte("-----BEGIN PGP PUBLIC KEY BLOCK-----"), []byte{}, nil)
+
+			gpgInstance := NewGpg(executorArg, nil)
+			actual, actualErr := gpgInstance.ExportKey(context.Background(), "release@example.com", false, true)
+			require.Nil(t, actualErr)
+			assert.Equal(t, "-----BEGIN PGP PUBLIC KEY BLOCK-----", string(actual))
+		},
+	)
+
+	t.Run(
+		"when key is not found, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"gpg",
+				[]string{"--batch", "--yes", "--no-tty", "--export", "missing@example.com"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("WARNING: nothing exported"), nil)
+
+			gpgInstance := NewGpg(executorArg, nil)
+			actual, actualErr := gpgInstance.ExportKey(context.Background(), "missing@example.com", false, false)
+			require.NotNil(t, actualErr)
+			assert.Nil(t, actual)
+		},
+	)
+}
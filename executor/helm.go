@@ -15,8 +15,11 @@
 package executor
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/elliotchance/orderedmap"
 
@@ -43,8 +46,8 @@ func (h *Helm) ResetExecutor(commandExecutor os.CommandExecutor) os.CommandExecu
 	return old
 }
 
-// GetManifest returns content of a "helm template" substituted manifest.
-// Values and string values are maps of string keys to string values.
+// GetManifest returns content of a Helm 3 "helm template" substituted manifest,
+// i.e `helm template <name> <location>`. Values and string values are maps of string keys to string values.
 func (h *Helm) GetManifest(
 	location string,
 	name string,
@@ -54,8 +57,8 @@ func (h *Helm) GetManifest(
 ) (string, error) {
 	cmdArgs := []string{
 		"template",
-		"--name",
 		name,
+		location,
 		"--kube-version",
 		h.kubeVersion,
 		"--namespace",
@@ -86,8 +89,6 @@ func (h *Helm) GetManifest(
 		)
 	}
 
-	cmdArgs = append(cmdArgs, location)
-
 	stdout, stderr, err := h.commandExecutor.Execute(
 		h.binPath,
 		cmdArgs,
@@ -134,3 +135,231 @@ func (h *Helm) Package(dir, dest string) (string, error) {
 
 	return string(stdout), nil
 }
+
+type (
+	// HelmReleaseOptions are the options of the release commands. Blank options are omitted.
+	HelmReleaseOptions struct {
+		Namespace string
+		// ChartVersion is the version constraint of the chart, e.g `^1.2.0`.
+		ChartVersion string
+		// Values and string values are maps of string keys to string values, passed as `--set`
+		// and `--set-string` respectively.
+		Values       *orderedmap.OrderedMap
+		StringValues *orderedmap.OrderedMap
+		ValuesFiles  []string
+		Wait         bool
+		// Atomic rolls back the changes on failed install or upgrade and implies `Wait`.
+		Atomic  bool
+		Timeout time.Duration
+		// Install installs the release on upgrade when it does not exist.
+		Install bool
+	}
+
+	HelmRelease struct {
+		Name      string           `json:"name"`
+		Namespace string           `json:"namespace"`
+		Version   int              `json:"version"`
+		Info      HelmReleaseInfo  `json:"info"`
+		Chart     HelmReleaseChart `json:"chart"`
+		Manifest  string           `json:"manifest"`
+	}
+
+	HelmReleaseInfo struct {
+		FirstDeployed string `json:"first_deployed"`
+		LastDeployed  string `json:"last_deployed"`
+		Description   string `json:"description"`
+		Status        string `json:"status"`
+		Notes         string `json:"notes"`
+	}
+
+	HelmReleaseChart struct {
+		Metadata HelmChartMetadata `json:"metadata"`
+	}
+
+	HelmChartMetadata struct {
+		Name       string `json:"name"`
+		Version    string `json:"version"`
+		AppVersion string `json:"appVersion"`
+	}
+
+	// HelmReleaseListItem is a release of the `helm list` output.
+	HelmReleaseListItem struct {
+		Name       string `json:"name"`
+		Namespace  string `json:"namespace"`
+		Revision   string `json:"revision"`
+		Updated    string `json:"updated"`
+		Status     string `json:"status"`
+		Chart      string `json:"chart"`
+		AppVersion string `json:"app_version"`
+	}
+)
+
+// Install installs the chart as release `name`.
+func (h *Helm) Install(name, chart string, options *HelmReleaseOptions) (*HelmRelease, error) {
+	args := []string{"install", name, chart}
+	args = append(args, h.releaseArguments(options)...)
+
+	return h.executeRelease(args)
+}
+
+// Upgrade upgrades the release `name` to the chart.
+func (h *Helm) Upgrade(name, chart string, options *HelmReleaseOptions) (*HelmRelease, error) {
+	args := []string{"upgrade", name, chart}
+	if options != nil && options.Install {
+		args = append(args, "--install")
+	}
+
+	args = append(args, h.releaseArguments(options)...)
+
+	return h.executeRelease(args)
+}
+
+// Rollback rolls back the release `name` to `revision`, or to the previous revision when it's zero.
+// Only the namespace, wait and timeout options are used.
+func (h *Helm) Rollback(name string, revision int, options *HelmReleaseOptions) error {
+	args := []string{"rollback", name}
+	if revision > 0 {
+		args = append(args, strconv.Itoa(revision))
+	}
+
+	if options != nil {
+		args = append(args, h.namespaceArguments(options.Namespace)...)
+		args = append(args, h.waitArguments(options)...)
+	}
+
+	_, stderr, err := h.commandExecutor.Execute(h.binPath, args, nil, "")
+	if err != nil {
+		return fmt.Errorf("%s. STDERR: %s", err, stderr)
+	}
+
+	return nil
+}
+
+// Uninstall uninstalls the release `name`.
+func (h *Helm) Uninstall(name, namespace string) error {
+	args := []string{"uninstall", name}
+	args = append(args, h.namespaceArguments(namespace)...)
+
+	_, stderr, err := h.commandExecutor.Execute(h.binPath, args, nil, "")
+	if err != nil {
+		return fmt.Errorf("%s. STDERR: %s", err, stderr)
+	}
+
+	return nil
+}
+
+// Status returns the release `name`.
+func (h *Helm) Status(name, namespace string) (*HelmRelease, error) {
+	args := []string{"status", name}
+	args = append(args, h.namespaceArguments(namespace)...)
+
+	return h.executeRelease(args)
+}
+
+// ListReleases returns the releases in namespace, or in all namespaces when namespace is blank.
+func (h *Helm) ListReleases(namespace string) ([]*HelmReleaseListItem, error) {
+	args := []string{"list", "-o", "json"}
+	if namespace == "" {
+		args = append(args, "--all-namespaces")
+	} else {
+		args = append(args, h.namespaceArguments(namespace)...)
+	}
+
+	stdout, stderr, err := h.commandExecutor.Execute(h.binPath, args, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("%s. STDERR: %s", err, stderr)
+	}
+
+	var releases []*HelmReleaseListItem
+
+	err = json.Unmarshal(stdout, &releases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse helm list output: %s", err)
+	}
+
+	return releases, nil
+}
+
+func (h *Helm) executeRelease(args []string) (*HelmRelease, error) {
+	args = append(args, "-o", "json")
+
+	stdout, stderr, err := h.commandExecutor.Execute(h.binPath, args, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("%s. STDERR: %s", err, stderr)
+	}
+
+	var release HelmRelease
+
+	err = json.Unmarshal(stdout, &release)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse helm %s output: %s", args[0], err)
+	}
+
+	return &release, nil
+}
+
+func (h *Helm) releaseArguments(options *HelmReleaseOptions) []string {
+	if options == nil {
+		return nil
+	}
+
+	args := h.namespaceArguments(options.Namespace)
+
+	if options.ChartVersion != "" {
+		args = append(args, "--version", options.ChartVersion)
+	}
+
+	for _, valuesFile := range options.ValuesFiles {
+		args = append(args, "--values", valuesFile)
+	}
+
+	args = append(args, h.setArguments(options.Values, false)...)
+	args = append(args, h.setArguments(options.StringValues, true)...)
+
+	return append(args, h.waitArguments(options)...)
+}
+
+func (h *Helm) namespaceArguments(namespace string) []string {
+	if namespace == "" {
+		return nil
+	}
+
+	return []string{"--namespace", namespace}
+}
+
+func (h *Helm) waitArguments(options *HelmReleaseOptions) []string {
+	var args []string
+
+	if options.Wait {
+		args = append(args, "--wait")
+	}
+
+	if options.Atomic {
+		args = append(args, "--atomic")
+	}
+
+	if options.Timeout > 0 {
+		args = append(args, "--timeout", options.Timeout.String())
+	}
+
+	return args
+}
+
+func (h *Helm) setArguments(values *orderedmap.OrderedMap, isString bool) []string {
+	if values == nil {
+		return nil
+	}
+
+	var args []string
+
+	for _, key := range values.Keys() {
+		value, _ := values.Get(key)
+		if value == nil {
+			continue
+		}
+
+		args = append(args, h.prepareSetArgument(key.(string), value.(string), isString)...)
+	}
+
+	return args
+}
@@ -15,8 +15,10 @@
 package executor
 
 import (
+	"fmt"
 	"github.com/elliotchance/orderedmap"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,9 +32,17 @@ func hasHelmBinary(executor os.OsExecutor) error {
 		return err
 	}
 
-	// HACK: Since we only support helm ~ 2.9.x
-	_, _, err = executor.Execute("helm --version | grep 2.9", nil, nil, "")
-	return err
+	stdout, _, err := executor.Execute("helm", []string{"version", "--short"}, nil, "")
+	if err != nil {
+		return err
+	}
+
+	// NOTE: `GetManifest` uses the Helm 3 `template` syntax.
+	if !strings.HasPrefix(string(stdout), "v3.") {
+		return fmt.Errorf("unsupported helm version %s", stdout)
+	}
+
+	return nil
 }
 
 func TestHelm_GetManifest_Integration(t *testing.T) {
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"github.com/elliotchance/orderedmap"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -65,15 +66,14 @@ func TestHelm_GetManifest(t *testing.T) {
 
 			expectedCmdArgs := []string{
 				"template",
-				"--name",
 				nameArg,
+				locationArg,
 				"--kube-version",
 				helmInstance.kubeVersion,
 				"--namespace",
 				namespaceArg,
 				"--set",
 				"excludedAttributes=request",
-				locationArg,
 			}
 			osExecutor.On(
 				"Execute",
@@ -122,15 +122,14 @@ func TestHelm_GetManifest(t *testing.T) {
 
 			expectedCmdArgs := []string{
 				"template",
-				"--name",
 				nameArg,
+				locationArg,
 				"--kube-version",
 				helmInstance.kubeVersion,
 				"--namespace",
 				namespaceArg,
 				"--set-string",
 				`excludedAttributes=request.headers.cookie\,request.headers.authorization\,request.headers.proxyAuthorization\,request.headers.setCookie*\,request.headers.x*\,response.headers.cookie\,response.headers.authorization\,response.headers.proxyAuthorization\,response.headers.setCookie*\,response.headers.x*`,
-				locationArg,
 			}
 			osExecutor.On(
 				"Execute",
@@ -180,8 +179,8 @@ func TestHelm_GetManifest(t *testing.T) {
 
 			expectedCmdArgs := []string{
 				"template",
-				"--name",
 				nameArg,
+				locationArg,
 				"--kube-version",
 				helmInstance.kubeVersion,
 				"--namespace",
@@ -190,7 +189,6 @@ func TestHelm_GetManifest(t *testing.T) {
 				`excludedAttributes=request.headers.cookie\,request.headers.authorization\,request.headers.proxyAuthorization\,request.headers.setCookie*\,request.headers.x*\,response.headers.cookie\,response.headers.authorization\,response.headers.proxyAuthorization\,response.headers.setCookie*\,response.headers.x*`,
 				"--set-string",
 				`someTest=true`,
-				locationArg,
 			}
 			osExecutor.On(
 				"Execute",
@@ -269,3 +267,317 @@ func TestHelm_Package(t *testing.T) {
 		},
 	)
 }
+
+func TestHelm_Install(t *testing.T) {
+	t.Run(
+		"it passes release options as arguments and returns the parsed release",
+		func(t *testing.T) {
+			t.Parallel()
+
+			osExecutor := ostest.NewFakeOsExecutor(t)
+
+			helmInstance := NewHelm(osExecutor)
+
+			values := orderedmap.NewOrderedMap()
+			values.Set("image.tag", "1.0.0")
+
+			stringValues := orderedmap.NewOrderedMap()
+			stringValues.Set("hosts", "a,b")
+
+			fakeStdout := []byte(`{
+				"name": "app",
+				"namespace": "apps",
+				"version": 1,
+				"info": {"status": "deployed", "notes": "fakeNotes"},
+				"chart": {"metadata": {"name": "app-chart", "version": "1.2.0", "appVersion": "1.0.0"}}
+			}`)
+			osExecutor.On(
+				"Execute",
+				helmInstance.binPath,
+				[]string{
+					"install", "app", "repo/app-chart",
+					"--namespace", "apps",
+					"--version", "^1.2.0",
+					"--values", "values.yaml",
+					"--set", "image.tag=1.0.0",
+					"--set-string", "hosts=a\\,b",
+					"--wait",
+					"--atomic",
+					"--timeout", "5m0s",
+					"-o", "json",
+				},
+				[]string(nil),
+				"",
+			).Return(fakeStdout, nil, nil)
+
+			actual, actualErr := helmInstance.Install(
+				"app",
+				"repo/app-chart",
+				&HelmReleaseOptions{
+					Namespace:    "apps",
+					ChartVersion: "^1.2.0",
+					Values:       values,
+					StringValues: stringValues,
+					ValuesFiles:  []string{"values.yaml"},
+					Wait:         true,
+					Atomic:       true,
+					Timeout:      5 * time.Minute,
+				},
+			)
+			require.Nil(t, actualErr)
+
+			assert.Equal(t, "app", actual.Name)
+			assert.Equal(t, "apps", actual.Namespace)
+			assert.Equal(t, 1, actual.Version)
+			assert.Equal(t, "deployed", actual.Info.Status)
+			assert.Equal(t, "fakeNotes", actual.Info.Notes)
+			assert.Equal(t, "app-chart", actual.Chart.Metadata.Name)
+			assert.Equal(t, "1.0.0", actual.Chart.Metadata.AppVersion)
+			osExecutor.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"when error occurs, it returns stderr of executed command as part of error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			osExecutor := ostest.NewFakeOsExecutor(t)
+
+			helmInstance := NewHelm(osExecutor)
+
+			fakeStderr := []byte("fakeStderr")
+			fakeErr := errors.New("fakeErr")
+
+			osExecutor.On(
+				"Execute",
+				helmInstance.binPath,
+				[]string{"install", "app", "repo/app-chart", "-o", "json"},
+				[]string(nil),
+				"",
+			).Return(nil, fakeStderr, fakeErr)
+
+			actual, actualErr := helmInstance.Install("app", "repo/app-chart", nil)
+			assert.Nil(t, actual)
+			assert.Equal(t, fmt.Sprintf("%s. STDERR: %s", fakeErr, fakeStderr), actualErr.Error())
+		},
+	)
+
+	t.Run(
+		"when output is not valid JSON, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			osExecutor := ostest.NewFakeOsExecutor(t)
+
+			helmInstance := NewHelm(osExecutor)
+
+			osExecutor.On(
+				"Execute",
+				helmInstance.binPath,
+				[]string{"install", "app", "repo/app-chart", "-o", "json"},
+				[]string(nil),
+				"",
+			).Return([]byte("NAME: app"), nil, nil)
+
+			actual, actualErr := helmInstance.Install("app", "repo/app-chart", nil)
+			assert.Nil(t, actual)
+			assert.Contains(t, actualErr.Error(), "failed to parse helm install output")
+		},
+	)
+}
+
+func TestHelm_Upgrade(t *testing.T) {
+	t.Parallel()
+
+	osExecutor := ostest.NewFakeOsExecutor(t)
+
+	helmInstance := NewHelm(osExecutor)
+
+	osExecutor.On(
+		"Execute",
+		helmInstance.binPath,
+		[]string{"upgrade", "app", "repo/app-chart", "--install", "--namespace", "apps", "--wait", "-o", "json"},
+		[]string(nil),
+		"",
+	).Return([]byte(`{"name": "app", "version": 2, "info": {"status": "deployed"}}`), nil, nil)
+
+	actual, actualErr := helmInstance.Upgrade(
+		"app",
+		"repo/app-chart",
+		&HelmReleaseOptions{Namespace: "apps", Wait: true, Install: true},
+	)
+	require.Nil(t, actualErr)
+
+	assert.Equal(t, 2, actual.Version)
+	assert.Equal(t, "deployed", actual.Info.Status)
+	osExecutor.AssertExpectations(t)
+}
+
+func TestHelm_Rollback(t *testing.T) {
+	t.Run(
+		"with revision, it rolls back to the revision",
+		func(t *testing.T) {
+			t.Parallel()
+
+			osExecutor := ostest.NewFakeOsExecutor(t)
+
+			helmInstance := NewHelm(osExecutor)
+
+			osExecutor.On(
+				"Execute",
+				helmInstance.binPath,
+				[]string{"rollback", "app", "3", "--namespace", "apps", "--wait", "--timeout", "1m0s"},
+				[]string(nil),
+				"",
+			).Return(nil, nil, nil)
+
+			actualErr := helmInstance.Rollback(
+				"app",
+				3,
+				&HelmReleaseOptions{Namespace: "apps", Wait: true, Timeout: time.Minute},
+			)
+			require.Nil(t, actualErr)
+			osExecutor.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"without revision, it rolls back to the previous revision",
+		func(t *testing.T) {
+			t.Parallel()
+
+			osExecutor := ostest.NewFakeOsExecutor(t)
+
+			helmInstance := NewHelm(osExecutor)
+
+			fakeStderr := []byte("fakeStderr")
+			fakeErr := errors.New("fakeErr")
+
+			osExecutor.On(
+				"Execute",
+				helmInstance.binPath,
+				[]string{"rollback", "app"},
+				[]string(nil),
+				"",
+			).Return(nil, fakeStderr, fakeErr)
+
+			actualErr := helmInstance.Rollback("app", 0, nil)
+			assert.Equal(t, fmt.Sprintf("%s. STDERR: %s", fakeErr, fakeStderr), actualErr.Error())
+		},
+	)
+}
+
+func TestHelm_Uninstall(t *testing.T) {
+	t.Parallel()
+
+	osExecutor := ostest.NewFakeOsExecutor(t)
+
+	helmInstance := NewHelm(osExecutor)
+
+	osExecutor.On(
+		"Execute",
+		helmInstance.binPath,
+		[]string{"uninstall", "app", "--namespace", "apps"},
+		[]string(nil),
+		"",
+	).Return(nil, nil, nil)
+
+	actualErr := helmInstance.Uninstall("app", "apps")
+	require.Nil(t, actualErr)
+	osExecutor.AssertExpectations(t)
+}
+
+func TestHelm_Status(t *testing.T) {
+	t.Parallel()
+
+	osExecutor := ostest.NewFakeOsExecutor(t)
+
+	helmInstance := NewHelm(osExecutor)
+
+	osExecutor.On(
+		"Execute",
+		helmInstance.binPath,
+		[]string{"status", "app", "--namespace", "apps", "-o", "json"},
+		[]string(nil),
+		"",
+	).Return([]byte(`{"name": "app", "info": {"status": "failed", "description": "fakeDescription"}}`), nil, nil)
+
+	actual, actualErr := helmInstance.Status("app", "apps")
+	require.Nil(t, actualErr)
+
+	assert.Equal(t, "failed", actual.Info.Status)
+	assert.Equal(t, "fakeDescription", actual.Info.Description)
+	osExecutor.AssertExpectations(t)
+}
+
+func TestHelm_ListReleases(t *testing.T) {
+	t.Run(
+		"with namespace, it lists the releases of the namespace",
+		func(t *testing.T) {
+			t.Parallel()
+
+			osExecutor := ostest.NewFakeOsExecutor(t)
+
+			helmInstance := NewHelm(osExecutor)
+
+			fakeStdout := []byte(`[{
+				"name": "app",
+				"namespace": "apps",
+				"revision": "2",
+				"updated": "2019-10-10 10:00:00.000000000 +0000 UTC",
+				"status": "deployed",
+				"chart": "app-chart-1.2.0",
+				"app_version": "1.0.0"
+			}]`)
+			osExecutor.On(
+				"Execute",
+				helmInstance.binPath,
+				[]string{"list", "-o", "json", "--namespace", "apps"},
+				[]string(nil),
+				"",
+			).Return(fakeStdout, nil, nil)
+
+			actual, actualErr := helmInstance.ListReleases("apps")
+			require.Nil(t, actualErr)
+
+			expected := []*HelmReleaseListItem{
+				{
+					Name:       "app",
+					Namespace:  "apps",
+					Revision:   "2",
+					Updated:    "2019-10-10 10:00:00.000000000 +0000 UTC",
+					Status:     "deployed",
+					Chart:      "app-chart-1.2.0",
+					AppVersion: "1.0.0",
+				},
+			}
+			assert.Equal(t, expected, actual)
+			osExecutor.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"without namespace, it lists the releases of all namespaces",
+		func(t *testing.T) {
+			t.Parallel()
+
+			osExecutor := ostest.NewFakeOsExecutor(t)
+
+			helmInstance := NewHelm(osExecutor)
+
+			osExecutor.On(
+				"Execute",
+				helmInstance.binPath,
+				[]string{"list", "-o", "json", "--all-namespaces"},
+				[]string(nil),
+				"",
+			).Return([]byte("[]"), nil, nil)
+
+			actual, actualErr := helmInstance.ListReleases("")
+			require.Nil(t, actualErr)
+			assert.Empty(t, actual)
+			osExecutor.AssertExpectations(t)
+		},
+	)
+}
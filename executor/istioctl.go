@@ -0,0 +1,337 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+const (
+	IstioAnalysisLevelError   = "Error"
+	IstioAnalysisLevelWarning = "Warning"
+	IstioAnalysisLevelInfo    = "Info"
+)
+
+type IstioctlOptions struct {
+	// Kubeconfig is the kubeconfig file path, when not the default one.
+	Kubeconfig string
+	// Context is the kubeconfig context, when not the current one.
+	Context string
+	// IstioNamespace is the namespace of the istio control plane, when not `istio-system`.
+	IstioNamespace string
+}
+
+type IstioInstallOptions struct {
+	// Profile is the installation configuration profile, e.g `default` or `minimal`.
+	Profile string
+	// Revision is the control plane revision, used for canary upgrades.
+	Revision string
+	// Files are IstioOperator manifest files.
+	Files []string
+	// Set overrides IstioOperator values, e.g `meshConfig.accessLogFile` => `/dev/stdout`.
+	Set map[string]string
+	// Verify verifies the installation after it's applied.
+	Verify bool
+}
+
+type IstioProxyStatus struct {
+	Name    string
+	Cluster string
+	CDS     string
+	LDS     string
+	EDS     string
+	RDS     string
+	ECDS    string
+	Istiod  string
+	Version string
+}
+
+// IsSynced returns whether all xDS resources of the proxy are synced with istiod.
+func (status *IstioProxyStatus) IsSynced() bool {
+	for _, value := range []string{status.CDS, status.LDS, status.EDS, status.RDS, status.ECDS} {
+		// NOTE: Resources which are not used by the proxy are reported as `NOT SENT` or are missing.
+		if value == "" || value == "NOT SENT" || value == "IGNORED" {
+			continue
+		}
+
+		if !strings.HasPrefix(value, "SYNCED") {
+			return false
+		}
+	}
+
+	return true
+}
+
+type IstioAnalysisMessage struct {
+	Code             string `json:"code"`
+	Level            string `json:"level"`
+	Message          string `json:"message"`
+	Origin           string `json:"origin"`
+	Reference        string `json:"reference"`
+	DocumentationURL string `json:"documentationUrl"`
+}
+
+type IstioVersion struct {
+	Client       string
+	ControlPlane []string
+	DataPlane    []string
+}
+
+type istioctlVersionOutput struct {
+	ClientVersion *struct {
+		Version string `json:"version"`
+	} `json:"clientVersion"`
+	MeshVersion []struct {
+		Component string `json:"Component"`
+		Info      struct {
+			Version string `json:"version"`
+		} `json:"Info"`
+	} `json:"meshVersion"`
+	DataPlaneVersion []struct {
+		ID           string `json:"ID"`
+		IstioVersion string `json:"IstioVersion"`
+	} `json:"dataPlaneVersion"`
+}
+
+type Istioctl struct {
+	binPath         string
+	options         IstioctlOptions
+	commandExecutor os.CommandExecutor
+}
+
+func NewIstioctl(executor os.CommandExecutor, options *IstioctlOptions) *Istioctl {
+	istioctl := &Istioctl{
+		binPath:         "istioctl",
+		commandExecutor: executor,
+	}
+
+	if options != nil {
+		istioctl.options = *options
+	}
+
+	return istioctl
+}
+
+// Install installs the istio control plane.
+func (istioctl *Istioctl) Install(ctx context.Context, options *IstioInstallOptions) error {
+	_, err := istioctl.Execute(ctx, append([]string{"install", "-y"}, istioInstallArgs(options)...)...)
+	return err
+}
+
+// Upgrade upgrades the istio control plane in place.
+func (istioctl *Istioctl) Upgrade(ctx context.Context, options *IstioInstallOptions) error {
+	_, err := istioctl.Execute(ctx, append([]string{"upgrade", "-y"}, istioInstallArgs(options)...)...)
+	return err
+}
+
+// ProxyStatus returns the xDS sync status of the proxies in the mesh.
+func (istioctl *Istioctl) ProxyStatus(ctx context.Context) ([]*IstioProxyStatus, error) {
+	stdout, err := istioctl.Execute(ctx, "proxy-status")
+	if err != nil {
+		return nil, err
+	}
+
+	rows := parseIstioctlTable(string(stdout))
+	statuses := make([]*IstioProxyStatus, 0, len(rows))
+
+	for _, row := range rows {
+		statuses = append(
+			statuses,
+			&IstioProxyStatus{
+				Name:    row["NAME"],
+				Cluster: row["CLUSTER"],
+				CDS:     row["CDS"],
+				LDS:     row["LDS"],
+				EDS:     row["EDS"],
+				RDS:     row["RDS"],
+				ECDS:    row["ECDS"],
+				Istiod:  row["ISTIOD"],
+				Version: row["VERSION"],
+			},
+		)
+	}
+
+	return statuses, nil
+}
+
+// Analyze analyzes the mesh configuration of the namespace, or of all namespaces when namespace is empty,
+// and returns the findings.
+func (istioctl *Istioctl) Analyze(ctx context.Context, namespace string) ([]*IstioAnalysisMessage, error) {
+	args := []string{"analyze", "-o", "json"}
+	if namespace == "" {
+		args = append(args, "--all-namespaces")
+	} else {
+		args = append(args, "--namespace", namespace)
+	}
+
+	stdout, stderr, err := istioctl.commandExecutor.ExecuteContext(ctx, istioctl.binPath, istioctl.globalArgs(args), nil, "")
+
+	var messages []*IstioAnalysisMessage
+
+	// NOTE: `istioctl analyze` exits with non-zero code when it finds errors,
+	// so the output is decoded regardless and the error is returned only when there are no findings.
+	if len(strings.TrimSpace(string(stdout))) > 0 {
+		decodeErr := json.Unmarshal(stdout, &messages)
+		if decodeErr != nil && err == nil {
+			return nil, stacktrace.Propagate(decodeErr, "json decode command `istioctl analyze` output failed")
+		}
+	}
+
+	if err != nil && len(messages) == 0 {
+		return nil, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return messages, nil
+}
+
+// Version returns the versions of the client, control plane components and data plane proxies.
+func (istioctl *Istioctl) Version(ctx context.Context) (*IstioVersion, error) {
+	stdout, err := istioctl.Execute(ctx, "version", "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var output istioctlVersionOutput
+
+	err = json.Unmarshal(stdout, &output)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "json decode command `istioctl version` output failed")
+	}
+
+	version := &IstioVersion{}
+	if output.ClientVersion != nil {
+		version.Client = output.ClientVersion.Version
+	}
+
+	for _, component := range output.MeshVersion {
+		version.ControlPlane = append(version.ControlPlane, component.Info.Version)
+	}
+
+	for _, proxy := range output.DataPlaneVersion {
+		version.DataPlane = append(version.DataPlane, proxy.IstioVersion)
+	}
+
+	return version, nil
+}
+
+// Execute executes command with the kubeconfig and namespace options and returns its stdout.
+func (istioctl *Istioctl) Execute(ctx context.Context, args ...string) ([]byte, error) {
+	stdout, stderr, err := istioctl.commandExecutor.ExecuteContext(ctx, istioctl.binPath, istioctl.globalArgs(args), nil, "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return stdout, nil
+}
+
+func (istioctl *Istioctl) globalArgs(args []string) []string {
+	if istioctl.options.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", istioctl.options.Kubeconfig)
+	}
+
+	if istioctl.options.Context != "" {
+		args = append(args, "--context", istioctl.options.Context)
+	}
+
+	if istioctl.options.IstioNamespace != "" {
+		args = append(args, "--istioNamespace", istioctl.options.IstioNamespace)
+	}
+
+	return args
+}
+
+func istioInstallArgs(options *IstioInstallOptions) []string {
+	var args []string
+	if options == nil {
+		return args
+	}
+
+	for _, file := range options.Files {
+		args = append(args, "-f", file)
+	}
+
+	if options.Profile != "" {
+		args = append(args, "--set", "profile="+options.Profile)
+	}
+
+	if options.Revision != "" {
+		args = append(args, "--revision", options.Revision)
+	}
+
+	for _, key := range sortedKeys(options.Set) {
+		args = append(args, "--set", fmt.Sprintf("%s=%s", key, options.Set[key]))
+	}
+
+	if options.Verify {
+		args = append(args, "--verify")
+	}
+
+	return args
+}
+
+// parseIstioctlTable parses column aligned table output into rows keyed by the header columns.
+// Column boundaries are taken from the header, since values may contain spaces, e.g `NOT SENT`.
+func parseIstioctlTable(output string) []map[string]string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) < 2 {
+		return nil
+	}
+
+	header := lines[0]
+	names := strings.Fields(header)
+	starts := make([]int, 0, len(names))
+	offset := 0
+
+	for _, name := range names {
+		index := strings.Index(header[offset:], name) + offset
+		starts = append(starts, index)
+		offset = index + len(name)
+	}
+
+	rows := make([]map[string]string, 0, len(lines)-1)
+
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		row := make(map[string]string, len(names))
+
+		for i, name := range names {
+			start := starts[i]
+			if start >= len(line) {
+				continue
+			}
+
+			end := len(line)
+			if i+1 < len(starts) && starts[i+1] < end {
+				end = starts[i+1]
+			}
+
+			row[name] = strings.TrimSpace(line[start:end])
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
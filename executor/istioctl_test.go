@@ -0,0 +1,228 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestIstioctl_Install(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"istioctl",
+		[]string{
+			"install", "-y",
+			"-f", "operator.yaml",
+			"--set", "profile=minimal",
+			"--revision", "1-20",
+			"--set", "meshConfig.accessLogFile=/dev/stdout",
+			"--set", "values.global.proxy.resources.requests.cpu=50m",
+			"--verify",
+			"--context", "staging",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	istioctlInstance := NewIstioctl(executorArg, &IstioctlOptions{Context: "staging"})
+	actual := istioctlInstance.Install(
+		context.Background(),
+		&IstioInstallOptions{
+			Profile:  "minimal",
+			Revision: "1-20",
+			Files:    []string{"operator.yaml"},
+			Set: map[string]string{
+				"values.global.proxy.resources.requests.cpu": "50m",
+				"meshConfig.accessLogFile":                   "/dev/stdout",
+			},
+			Verify: true,
+		},
+	)
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestIstioctl_Upgrade(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"istioctl",
+		[]string{"upgrade", "-y", "-f", "operator.yaml", "--istioNamespace", "mesh-system"},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte("no control plane"), errors.New("exit status 1"))
+
+	istioctlInstance := NewIstioctl(executorArg, &IstioctlOptions{IstioNamespace: "mesh-system"})
+	actual := istioctlInstance.Upgrade(context.Background(), &IstioInstallOptions{Files: []string{"operator.yaml"}})
+	require.NotNil(t, actual)
+	assert.Contains(t, actual.Error(), "no control plane")
+}
+
+func TestIstioctl_ProxyStatus(t *testing.T) {
+	t.Parallel()
+
+	output := `NAME                              CLUSTER        CDS        LDS        EDS          RDS          ECDS         ISTIOD                      VERSION
+api-7d4b9c5f8-x2x9k.default       Kubernetes     SYNCED     SYNCED     SYNCED       SYNCED       NOT SENT     istiod-5c9f6d8b7-abcde      1.20.0
+worker-5f6d7c8b9-q8w7e.jobs       Kubernetes     SYNCED     STALE      SYNCED       NOT SENT     NOT SENT     istiod-5c9f6d8b7-abcde      1.19.3
+`
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"istioctl",
+		[]string{"proxy-status"},
+		[]string(nil),
+		"",
+	).Return([]byte(output), []byte{}, nil)
+
+	istioctlInstance := NewIstioctl(executorArg, nil)
+	actual, actualErr := istioctlInstance.ProxyStatus(context.Background())
+	require.Nil(t, actualErr)
+	require.Len(t, actual, 2)
+
+	assert.Equal(
+		t,
+		&IstioProxyStatus{
+			Name:    "api-7d4b9c5f8-x2x9k.default",
+			Cluster: "Kubernetes",
+			CDS:     "SYNCED",
+			LDS:     "SYNCED",
+			EDS:     "SYNCED",
+			RDS:     "SYNCED",
+			ECDS:    "NOT SENT",
+			Istiod:  "istiod-5c9f6d8b7-abcde",
+			Version: "1.20.0",
+		},
+		actual[0],
+	)
+	assert.True(t, actual[0].IsSynced())
+
+	assert.Equal(t, "STALE", actual[1].LDS)
+	assert.Equal(t, "NOT SENT", actual[1].RDS)
+	assert.Equal(t, "1.19.3", actual[1].Version)
+	assert.False(t, actual[1].IsSynced())
+}
+
+func TestIstioctl_Analyze(t *testing.T) {
+	t.Run(
+		"when analysis finds errors, it returns the findings despite the exit code",
+		func(t *testing.T) {
+			t.Parallel()
+
+			output := `[
+  {
+    "code": "IST0101",
+    "documentationUrl": "https://istio.io/latest/docs/reference/config/analysis/ist0101/",
+    "level": "Error",
+    "message": "Referenced gateway not found: \"ingress\"",
+    "origin": "VirtualService default/api",
+    "reference": "VirtualService default/api"
+  }
+]`
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"istioctl",
+				[]string{"analyze", "-o", "json", "--namespace", "default"},
+				[]string(nil),
+				"",
+			).Return([]byte(output), []byte("Error: Analyzers found issues"), errors.New("exit status 79"))
+
+			istioctlInstance := NewIstioctl(executorArg, nil)
+			actual, actualErr := istioctlInstance.Analyze(context.Background(), "default")
+			require.Nil(t, actualErr)
+			require.Len(t, actual, 1)
+			assert.Equal(t, "IST0101", actual[0].Code)
+			assert.Equal(t, IstioAnalysisLevelError, actual[0].Level)
+			assert.Equal(t, "VirtualService default/api", actual[0].Origin)
+		},
+	)
+
+	t.Run(
+		"when analysis fails without findings, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"istioctl",
+				[]string{"analyze", "-o", "json", "--all-namespaces"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("connection refused"), errors.New("exit status 1"))
+
+			istioctlInstance := NewIstioctl(executorArg, nil)
+			actual, actualErr := istioctlInstance.Analyze(context.Background(), "")
+			require.NotNil(t, actualErr)
+			assert.Nil(t, actual)
+			assert.Contains(t, actualErr.Error(), "connection refused")
+		},
+	)
+}
+
+func TestIstioctl_Version(t *testing.T) {
+	t.Parallel()
+
+	output := `{
+  "clientVersion": {"version": "1.20.1", "revision": "abc", "golang_version": "go1.21.5", "status": "Clean"},
+  "meshVersion": [{"Component": "pilot", "Revision": "default", "Info": {"version": "1.20.0"}}],
+  "dataPlaneVersion": [
+    {"ID": "api-7d4b9c5f8-x2x9k.default", "IstioVersion": "1.20.0"},
+    {"ID": "worker-5f6d7c8b9-q8w7e.jobs", "IstioVersion": "1.19.3"}
+  ]
+}`
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"istioctl",
+		[]string{"version", "-o", "json"},
+		[]string(nil),
+		"",
+	).Return([]byte(output), []byte{}, nil)
+
+	istioctlInstance := NewIstioctl(executorArg, nil)
+	actual, actualErr := istioctlInstance.Version(context.Background())
+	require.Nil(t, actualErr)
+	assert.Equal(
+		t,
+		&IstioVersion{
+			Client:       "1.20.1",
+			ControlPlane: []string{"1.20.0"},
+			DataPlane:    []string{"1.20.0", "1.19.3"},
+		},
+		actual,
+	)
+}
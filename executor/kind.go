@@ -0,0 +1,139 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+type KindCreateClusterOptions struct {
+	// ConfigFile is the kind cluster configuration file, e.g with multiple nodes or port mappings.
+	ConfigFile string
+	// NodeImage is the node image, e.g `kindest/node:v1.27.3`, when not the kind default one.
+	NodeImage string
+	// Wait waits for the control plane to be ready up to the duration, when positive.
+	Wait time.Duration
+	// Kubeconfig is the kubeconfig file to add the cluster to, when not the default one.
+	Kubeconfig string
+}
+
+// Kind provisions ephemeral kubernetes clusters in docker containers, e.g for end-to-end tests.
+type Kind struct {
+	binPath         string
+	commandExecutor os.CommandExecutor
+}
+
+func NewKind(executor os.CommandExecutor) *Kind {
+	return &Kind{
+		binPath:         "kind",
+		commandExecutor: executor,
+	}
+}
+
+func (kind *Kind) CreateCluster(ctx context.Context, name string, options *KindCreateClusterOptions) error {
+	args := []string{"create", "cluster", "--name", name}
+
+	if options != nil {
+		if options.ConfigFile != "" {
+			args = append(args, "--config", options.ConfigFile)
+		}
+
+		if options.NodeImage != "" {
+			args = append(args, "--image", options.NodeImage)
+		}
+
+		if options.Wait > 0 {
+			args = append(args, "--wait", options.Wait.String())
+		}
+
+		if options.Kubeconfig != "" {
+			args = append(args, "--kubeconfig", options.Kubeconfig)
+		}
+	}
+
+	_, err := kind.execute(ctx, args...)
+	return err
+}
+
+func (kind *Kind) DeleteCluster(ctx context.Context, name string) error {
+	_, err := kind.execute(ctx, "delete", "cluster", "--name", name)
+	return err
+}
+
+// GetClusters returns the names of the existing clusters.
+func (kind *Kind) GetClusters(ctx context.Context) ([]string, error) {
+	stdout, err := kind.execute(ctx, "get", "clusters")
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []string
+
+	for _, line := range strings.Split(string(stdout), "\n") {
+		line = strings.TrimSpace(line)
+		// NOTE: kind reports `No kind clusters found.` on stderr, but older versions on stdout.
+		if line == "" || strings.HasPrefix(line, "No kind clusters") {
+			continue
+		}
+
+		clusters = append(clusters, line)
+	}
+
+	return clusters, nil
+}
+
+// LoadImage loads the local docker images into the cluster nodes, so that pods can use them without a registry.
+func (kind *Kind) LoadImage(ctx context.Context, name string, images ...string) error {
+	if len(images) == 0 {
+		return stacktrace.NewError("no images to load")
+	}
+
+	args := append([]string{"load", "docker-image"}, images...)
+	args = append(args, "--name", name)
+
+	_, err := kind.execute(ctx, args...)
+	return err
+}
+
+// ExportKubeconfig adds the cluster to the kubeconfig file, or the default one when blank, and sets it as current context.
+func (kind *Kind) ExportKubeconfig(ctx context.Context, name, kubeconfig string) error {
+	args := []string{"export", "kubeconfig", "--name", name}
+	if kubeconfig != "" {
+		args = append(args, "--kubeconfig", kubeconfig)
+	}
+
+	_, err := kind.execute(ctx, args...)
+	return err
+}
+
+// Kubeconfig returns the kubeconfig content of the cluster.
+func (kind *Kind) Kubeconfig(ctx context.Context, name string) ([]byte, error) {
+	return kind.execute(ctx, "get", "kubeconfig", "--name", name)
+}
+
+func (kind *Kind) execute(ctx context.Context, args ...string) ([]byte, error) {
+	stdout, stderr, err := kind.commandExecutor.ExecuteContext(ctx, kind.binPath, args, nil, "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return stdout, nil
+}
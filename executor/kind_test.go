@@ -0,0 +1,167 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestKind_CreateCluster(t *testing.T) {
+	t.Run(
+		"it passes the config, node image, wait and kubeconfig",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"kind",
+				[]string{
+					"create", "cluster", "--name", "e2e",
+					"--config", "testdata/kind.yaml",
+					"--image", "kindest/node:v1.27.3",
+					"--wait", "2m0s",
+					"--kubeconfig", "/tmp/e2e.kubeconfig",
+				},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte{}, nil)
+
+			kindInstance := NewKind(executorArg)
+			actual := kindInstance.CreateCluster(
+				context.Background(),
+				"e2e",
+				&KindCreateClusterOptions{
+					ConfigFile: "testdata/kind.yaml",
+					NodeImage:  "kindest/node:v1.27.3",
+					Wait:       2 * time.Minute,
+					Kubeconfig: "/tmp/e2e.kubeconfig",
+				},
+			)
+			require.Nil(t, actual)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"when cluster exists, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"kind",
+				[]string{"create", "cluster", "--name", "e2e"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte(`node(s) already exist for a cluster with the name "e2e"`), errors.New("exit status 1"))
+
+			kindInstance := NewKind(executorArg)
+			actual := kindInstance.CreateCluster(context.Background(), "e2e", nil)
+			require.NotNil(t, actual)
+			assert.Contains(t, actual.Error(), "already exist")
+		},
+	)
+}
+
+func TestKind_GetClusters(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"kind",
+		[]string{"get", "clusters"},
+		[]string(nil),
+		"",
+	).Return([]byte("e2e\nkind\n"), []byte{}, nil)
+
+	kindInstance := NewKind(executorArg)
+	actual, actualErr := kindInstance.GetClusters(context.Background())
+	require.Nil(t, actualErr)
+	assert.Equal(t, []string{"e2e", "kind"}, actual)
+}
+
+func TestKind_LoadImage(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"kind",
+		[]string{"load", "docker-image", "api:dev", "worker:dev", "--name", "e2e"},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	kindInstance := NewKind(executorArg)
+	actual := kindInstance.LoadImage(context.Background(), "e2e", "api:dev", "worker:dev")
+	require.Nil(t, actual)
+
+	actual = kindInstance.LoadImage(context.Background(), "e2e")
+	require.NotNil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestKind_ExportKubeconfig(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"kind",
+		[]string{"export", "kubeconfig", "--name", "e2e", "--kubeconfig", "/tmp/e2e.kubeconfig"},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	kindInstance := NewKind(executorArg)
+	actual := kindInstance.ExportKubeconfig(context.Background(), "e2e", "/tmp/e2e.kubeconfig")
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestKind_DeleteCluster(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"kind",
+		[]string{"delete", "cluster", "--name", "e2e"},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	kindInstance := NewKind(executorArg)
+	actual := kindInstance.DeleteCluster(context.Background(), "e2e")
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
@@ -2,15 +2,21 @@ package executor
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/sumup-oss/go-pkgs/clock"
+	pkgErrors "github.com/sumup-oss/go-pkgs/errors"
 	pkgOs "github.com/sumup-oss/go-pkgs/os"
+	"github.com/sumup-oss/go-pkgs/progress"
 )
 
 type (
@@ -39,6 +45,10 @@ type (
 		Status string `json:"status"`
 	}
 	kubernetesJob struct {
+		Spec struct {
+			// Completions is nil for the work queue jobs, that complete when any of their pods succeeds.
+			Completions *int `json:"completions"`
+		} `json:"spec"`
 		Status struct {
 			Conditions []kubernetesJobCondition `json:"conditions"`
 			Active     int                      `json:"active"`
@@ -114,6 +124,9 @@ type (
 		GlobalOptions            map[string]string
 		commandString            string
 		kubernetesInternalDomain string
+		ctx                      context.Context
+		progress                 progress.Reporter
+		clock                    clock.Clock
 	}
 )
 
@@ -133,6 +146,7 @@ func NewKubectl(
 		GlobalOptions:            globalOptions,
 		commandString:            "kubectl",
 		kubernetesInternalDomain: kubernetesInternalDomain,
+		clock:                    clock.NewRealClock(),
 	}
 }
 
@@ -142,6 +156,48 @@ func (k *Kubectl) ResetExecutor(commandExecutor pkgOs.CommandExecutor) pkgOs.Com
 	return old
 }
 
+// WithContext returns a shallow copy of the kubectl executor, that executes the commands with `ctx`.
+// The context cancellation stops the commands and the values of `ctx`, e.g correlation ID,
+// are available to the command executor.
+func (k *Kubectl) WithContext(ctx context.Context) *Kubectl {
+	kubectl := *k
+	kubectl.ctx = ctx
+
+	return &kubectl
+}
+
+// WithProgress returns a shallow copy of the kubectl executor, that reports the progress of
+// the rollout and job waiters to `reporter`. By default, the reporter of the context is used.
+func (k *Kubectl) WithProgress(reporter progress.Reporter) *Kubectl {
+	kubectl := *k
+	kubectl.progress = reporter
+
+	return &kubectl
+}
+
+// WithClock returns a shallow copy of the kubectl executor, that polls and times out the job waiter with `clk`,
+// e.g clocktest.FakeClock in tests.
+func (k *Kubectl) WithClock(clk clock.Clock) *Kubectl {
+	kubectl := *k
+	kubectl.clock = clk
+
+	return &kubectl
+}
+
+func (k *Kubectl) reportProgress(update progress.Update) {
+	update.Time = time.Now()
+
+	k.progressReporter().Report(update)
+}
+
+func (k *Kubectl) progressReporter() progress.Reporter {
+	if k.progress != nil {
+		return k.progress
+	}
+
+	return progress.FromContext(k.ctx)
+}
+
 func (k *Kubectl) compileCommand() []string {
 	var options = make([]string, len(k.GlobalOptions)/2)
 
@@ -154,6 +210,11 @@ func (k *Kubectl) compileCommand() []string {
 
 func (k *Kubectl) executeCommand(args []string, env []string) ([]byte, []byte, error) {
 	args = append(args, k.compileCommand()...)
+
+	if k.ctx != nil {
+		return k.commandExecutor.ExecuteContext(k.ctx, k.commandString, args, env, "")
+	}
+
 	return k.commandExecutor.Execute(k.commandString, args, env, "")
 }
 
@@ -168,6 +229,42 @@ func (k *Kubectl) Apply(manifest string, namespace string) error {
 	return err
 }
 
+// ApplyData applies the manifest content by piping it to kubectl stdin, so that it's never written to disk,
+// e.g decrypted secrets. The command executor must implement os.StdinCommandExecutor.
+func (k *Kubectl) ApplyData(manifest []byte, namespace string) error {
+	stdinExecutor, ok := k.commandExecutor.(pkgOs.StdinCommandExecutor)
+	if !ok {
+		return fmt.Errorf("command executor does not support executing with stdin")
+	}
+
+	commandArgs := []string{"apply", "-f", "-"}
+
+	if namespace != "" {
+		commandArgs = append(commandArgs, "-n", namespace)
+	}
+
+	commandArgs = append(commandArgs, k.compileCommand()...)
+
+	ctx := k.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	_, stderr, err := stdinExecutor.ExecuteWithStdinContext(
+		ctx,
+		k.commandString,
+		commandArgs,
+		nil,
+		"",
+		bytes.NewReader(manifest),
+	)
+	if err != nil {
+		return fmt.Errorf("%s. STDERR: %s", err, stderr)
+	}
+
+	return nil
+}
+
 func (k *Kubectl) Delete(manifest string) error {
 	commandArgs := append([]string{"delete", "--force"}, "-f", manifest)
 	_, _, err := k.executeCommand(commandArgs, nil)
@@ -472,40 +569,213 @@ func (k *Kubectl) ApplyService(service *KubernetesService) error {
 }
 
 func (k *Kubectl) RolloutStatus(timeout time.Duration, resource, namespace string) error {
+	k.reportProgress(progress.Update{
+		Task:    resource,
+		Step:    "rollout",
+		Percent: progress.UnknownPercent,
+		Message: fmt.Sprintf("waiting for rollout in namespace %s", namespace),
+	})
+
 	commandArgs := []string{"-n", namespace, "rollout", "status", resource, "--timeout", timeout.String()}
-	_, _, err := k.executeCommand(commandArgs, nil)
-	return err
+
+	var err error
+
+	// NOTE: The output is streamed only when there is a reporter of the rollout progress,
+	// which is updated on every status line, e.g `2 of 4 updated replicas are available...`.
+	streamingExecutor, ok := k.commandExecutor.(streamingCommandExecutor)
+	if _, isNop := k.progressReporter().(*progress.Nop); ok && !isNop {
+		err = k.streamRolloutStatus(streamingExecutor, commandArgs, resource)
+	} else {
+		_, _, err = k.executeCommand(commandArgs, nil)
+	}
+
+	if err != nil {
+		k.reportProgress(progress.Update{
+			Task:    resource,
+			Step:    "rollout",
+			Percent: progress.UnknownPercent,
+			Message: "rollout failed",
+		})
+
+		return err
+	}
+
+	k.reportProgress(progress.Update{Task: resource, Step: "rollout", Percent: 100, Message: "rollout complete"})
+
+	return nil
+}
+
+func (k *Kubectl) streamRolloutStatus(
+	streamingExecutor streamingCommandExecutor,
+	args []string,
+	resource string,
+) error {
+	args = append(args, k.compileCommand()...)
+
+	ctx := k.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var lastMessage string
+
+	stdout := newLineWriter(func(line string) {
+		update, ok := parseRolloutStatusLine(line)
+		if !ok || update.Message == lastMessage {
+			return
+		}
+
+		lastMessage = update.Message
+		update.Task = resource
+		k.reportProgress(update)
+	})
+
+	var stderr bytes.Buffer
+
+	err := streamingExecutor.ExecuteWithStreamsContext(ctx, k.commandString, args, nil, "", stdout, &stderr)
+	stdout.Flush()
+
+	if err != nil {
+		return fmt.Errorf("rollout status of %s failed, err: %v, stderr: %s", resource, err, stderr.Bytes())
+	}
+
+	return nil
+}
+
+// rolloutStatusReplicasRegex matches the replicas counts of the rollout status, e.g `2 of 4 updated replicas`
+// or `1 out of 3 new replicas`.
+var rolloutStatusReplicasRegex = regexp.MustCompile(`(\d+) (?:out )?of (\d+) `)
+
+// parseRolloutStatusLine parses the waiting lines of `kubectl rollout status`, e.g
+// `Waiting for deployment "web" rollout to finish: 2 of 4 updated replicas are available...`.
+func parseRolloutStatusLine(line string) (progress.Update, bool) {
+	if !strings.HasPrefix(line, "Waiting for ") {
+		return progress.Update{}, false
+	}
+
+	message := line
+	if idx := strings.Index(line, "rollout to finish: "); idx >= 0 {
+		message = line[idx+len("rollout to finish: "):]
+	}
+
+	update := progress.Update{
+		Step:    "rollout",
+		Percent: progress.UnknownPercent,
+		Message: strings.TrimSuffix(message, "..."),
+	}
+
+	matches := rolloutStatusReplicasRegex.FindStringSubmatch(message)
+	if matches != nil {
+		done, _ := strconv.Atoi(matches[1])
+		total, _ := strconv.Atoi(matches[2])
+
+		if total > 0 {
+			update.Percent = float64(done) * 100 / float64(total)
+		}
+	}
+
+	return update, true
+}
+
+// WaitForJob polls the job status every `pollInterval` until the job completes or fails,
+// reporting the progress of the job, i.e. the succeeded out of the required completions.
+// Returns error when the timeout is exceeded.
+func (k *Kubectl) WaitForJob(timeout, pollInterval time.Duration, name, namespace string) (KubernetesJobStatus, error) {
+	task := "job/" + name
+	deadline := k.clock.Now().Add(timeout)
+
+	for {
+		job, err := k.getJob(name, namespace)
+		if err != nil {
+			return KubernetesJobStatusUnknown, err
+		}
+
+		status := job.status()
+
+		switch status {
+		case KubernetesJobStatusComplete:
+			k.reportProgress(progress.Update{Task: task, Step: "job", Percent: 100, Message: "job complete"})
+			return status, nil
+		case KubernetesJobStatusFailed:
+			k.reportProgress(progress.Update{
+				Task:    task,
+				Step:    "job",
+				Percent: progress.UnknownPercent,
+				Message: "job failed",
+			})
+
+			return status, nil
+		}
+
+		if !k.clock.Now().Add(pollInterval).Before(deadline) {
+			return status, fmt.Errorf("timed out waiting for job %s in namespace %s", name, namespace)
+		}
+
+		update := progress.Update{
+			Task:    task,
+			Step:    "job",
+			Percent: progress.UnknownPercent,
+			Message: fmt.Sprintf("waiting for job in namespace %s", namespace),
+		}
+
+		if completions := job.Spec.Completions; completions != nil && *completions > 0 {
+			update.Percent = float64(job.Status.Succeeded) * 100 / float64(*completions)
+			update.Message = fmt.Sprintf(
+				"%d of %d completions succeeded, %d active",
+				job.Status.Succeeded,
+				*completions,
+				job.Status.Active,
+			)
+		}
+
+		k.reportProgress(update)
+
+		k.clock.Sleep(pollInterval)
+	}
 }
 
 func (k *Kubectl) JobStatus(name, namespace string) (KubernetesJobStatus, error) {
+	job, err := k.getJob(name, namespace)
+	if err != nil {
+		return KubernetesJobStatusUnknown, err
+	}
+
+	return job.status(), nil
+}
+
+func (k *Kubectl) getJob(name, namespace string) (*kubernetesJob, error) {
 	commandArgs := []string{"-n", namespace, "get", "job", name, "-o", "json"}
 	stdout, _, err := k.executeCommand(commandArgs, nil)
 	if err != nil {
-		return KubernetesJobStatusUnknown, err
+		return nil, err
 	}
 
 	var job kubernetesJob
 
 	err = json.Unmarshal(stdout, &job)
 	if err != nil {
-		return KubernetesJobStatusUnknown, err
+		return nil, err
 	}
 
+	return &job, nil
+}
+
+func (job *kubernetesJob) status() KubernetesJobStatus {
 	for _, cond := range job.Status.Conditions {
 		if cond.Type == kubernetesJobConditionComplete && cond.Status == kubernetesConditionStatusTrue {
-			return KubernetesJobStatusComplete, nil
+			return KubernetesJobStatusComplete
 		}
 
 		if cond.Type == kubernetesJobConditionFailed && cond.Status == kubernetesConditionStatusTrue {
-			return KubernetesJobStatusFailed, nil
+			return KubernetesJobStatusFailed
 		}
 	}
 
 	if job.Status.Active > 0 {
-		return KubernetesJobStatusActive, nil
+		return KubernetesJobStatusActive
 	}
 
-	return KubernetesJobStatusUnknown, nil
+	return KubernetesJobStatusUnknown
 }
 
 func (k *Kubectl) DeleteResource(namespace, resourceType, resourceName string) error {
@@ -544,3 +814,17 @@ func (k *Kubectl) DeleteAllResourcesByLabel(namespace string, labels map[string]
 
 	return nil
 }
+
+// DeleteAllResourcesByLabelInNamespaces deletes the resources with the labels in every namespace,
+// e.g of a multi-tenant preview environment. It continues on failure and returns all the failures
+// as errors.MultiError.
+func (k *Kubectl) DeleteAllResourcesByLabelInNamespaces(namespaces []string, labels map[string]string) error {
+	var result *pkgErrors.MultiError
+
+	for _, namespace := range namespaces {
+		err := k.DeleteAllResourcesByLabel(namespace, labels)
+		result = pkgErrors.Append(result, pkgErrors.Wrapf(err, "namespace %s", namespace))
+	}
+
+	return result.ErrorOrNil()
+}
@@ -0,0 +1,87 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+// +build e2e
+
+package executor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/executor"
+	"github.com/sumup-oss/go-pkgs/testutils"
+)
+
+func TestKubectl_E2E(t *testing.T) {
+	cluster, teardown := testutils.NewKindClusterFromEnv(t)
+	defer teardown()
+
+	kubectl := cluster.Kubectl()
+
+	t.Run("it reaches the cluster", func(t *testing.T) {
+		err := kubectl.ClusterInfo()
+		require.NoError(t, err)
+	})
+
+	t.Run("it applies and reads a service", func(t *testing.T) {
+		namespace, deleteNamespace := cluster.CreateNamespace(t)
+		defer deleteNamespace()
+
+		service := &executor.KubernetesService{
+			APIVersion: "v1",
+			Kind:       "Service",
+			Metadata: &executor.KubernetesServiceMetadata{
+				Name:      "e2e",
+				Namespace: namespace,
+				Labels:    map[string]string{"app": "e2e"},
+			},
+			Spec: &executor.KubernetesServiceSpec{
+				Type:     "ClusterIP",
+				Selector: map[string]string{"app": "e2e"},
+				Ports: []*executor.KubernetesServiceSpecPort{
+					{Name: "http", Port: "80", Protocol: "TCP", TargetPort: "8080"},
+				},
+			},
+		}
+
+		err := kubectl.ApplyService(service)
+		require.NoError(t, err)
+
+		actual, err := kubectl.GetService("e2e", namespace)
+		require.NoError(t, err)
+		assert.Equal(t, "e2e", actual.Metadata.Name)
+
+		port, err := kubectl.GetServicePort(namespace, "e2e", "http")
+		require.NoError(t, err)
+		assert.Equal(t, "80", port)
+
+		err = kubectl.DeleteAllResourcesByLabel(namespace, map[string]string{"app": "e2e"})
+		require.NoError(t, err)
+	})
+
+	t.Run("it applies and deletes a configmap", func(t *testing.T) {
+		namespace, deleteNamespace := cluster.CreateNamespace(t)
+		defer deleteNamespace()
+
+		err := kubectl.ApplyConfigmap("e2e", namespace, map[string]string{"foo": "bar"})
+		require.NoError(t, err)
+
+		err = kubectl.DeleteResource(namespace, "configmap", "e2e")
+		require.NoError(t, err)
+	})
+}
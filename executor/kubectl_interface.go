@@ -8,6 +8,7 @@ import (
 
 type KubectlInterface interface {
 	Apply(manifest string, namespace string) error
+	ApplyData(manifest []byte, namespace string) error
 	Delete(manifest string) error
 	Create(manifest string) error
 	ClusterInfo() error
@@ -24,8 +25,17 @@ type KubectlInterface interface {
 	GetIngresses(namespace string) ([]*KubernetesIngress, error)
 	RolloutStatus(timeout time.Duration, resource, namespace string) error
 	JobStatus(name, namespace string) (KubernetesJobStatus, error)
+	WaitForJob(timeout, pollInterval time.Duration, name, namespace string) (KubernetesJobStatus, error)
 	DeleteResource(namespace, resourceType, resourceName string) error
 	DeleteAllResources(namespace, resourceType string) error
 	DeleteAllResourcesByLabel(namespace string, labels map[string]string) error
+	DeleteAllResourcesByLabelInNamespaces(namespaces []string, labels map[string]string) error
 	ResetExecutor(commandExecutor pkgOs.CommandExecutor) pkgOs.CommandExecutor
 }
+
+// ManifestApplier applies manifest content, e.g Kubectl.
+type ManifestApplier interface {
+	ApplyData(manifest []byte, namespace string) error
+}
+
+var _ ManifestApplier = (*Kubectl)(nil)
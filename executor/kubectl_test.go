@@ -1,12 +1,22 @@
 package executor
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/sumup-oss/go-pkgs/clock/clocktest"
+	"github.com/sumup-oss/go-pkgs/logger"
+	pkgOs "github.com/sumup-oss/go-pkgs/os"
 	"github.com/sumup-oss/go-pkgs/os/ostest"
+	"github.com/sumup-oss/go-pkgs/progress"
+	"github.com/sumup-oss/go-pkgs/retry"
 )
 
 func TestKubectl_RolloutStatus(t *testing.T) {
@@ -301,3 +311,311 @@ func TestKubectl_DeleteAllResourcesByLabel(t *testing.T) {
 		},
 	)
 }
+
+func TestKubectl_DeleteAllResourcesByLabelInNamespaces(t *testing.T) {
+	t.Run(
+		"when deleting fails in some namespaces, it continues and returns all the failures",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executor := ostest.NewFakeOsExecutor(t)
+
+			for _, namespace := range []string{"tenant-a", "tenant-b", "tenant-c"} {
+				call := executor.On(
+					"Execute",
+					"kubectl",
+					[]string{"-n", namespace, "delete", "all,ing", "-l", "preview=pr-42"},
+					[]string(nil),
+					"",
+				)
+
+				if namespace == "tenant-b" {
+					call.Return([]byte{}, []byte{}, nil)
+				} else {
+					call.Return([]byte{}, []byte("forbidden"), errors.New("exit status 1"))
+				}
+			}
+
+			kubectl := NewKubectl(executor, "", "")
+
+			actualErr := kubectl.DeleteAllResourcesByLabelInNamespaces(
+				[]string{"tenant-a", "tenant-b", "tenant-c"},
+				map[string]string{"preview": "pr-42"},
+			)
+			require.NotNil(t, actualErr)
+			assert.Contains(t, actualErr.Error(), "2 errors occurred")
+			assert.Contains(t, actualErr.Error(), "namespace tenant-a: deleting resources failed")
+			assert.Contains(t, actualErr.Error(), "namespace tenant-c: deleting resources failed")
+			assert.NotContains(t, actualErr.Error(), "tenant-b")
+
+			executor.AssertExpectations(t)
+		},
+	)
+}
+
+func TestKubectl_WithContext(t *testing.T) {
+	t.Run("it executes the commands with the context", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		executor := ostest.NewFakeOsExecutor(t)
+		executor.On(
+			"ExecuteContext",
+			ctx,
+			"kubectl",
+			[]string{"cluster-info"},
+			[]string(nil),
+			"",
+		).Return([]byte(nil), []byte(nil), nil)
+
+		kubectl := NewKubectl(executor, "", "svc.cluster.local")
+
+		err := kubectl.WithContext(ctx).ClusterInfo()
+		require.NoError(t, err)
+
+		executor.AssertExpectations(t)
+	})
+}
+
+func TestKubectl_WithProgress(t *testing.T) {
+	t.Run("it reports the rollout progress", func(t *testing.T) {
+		t.Parallel()
+
+		executor := ostest.NewFakeOsExecutor(t)
+		executor.On(
+			"ExecuteWithStreamsContext",
+			mock.Anything,
+			"kubectl",
+			[]string{"-n", "default", "rollout", "status", "deployment/foo", "--timeout", "5s"},
+			[]string(nil),
+			"",
+			mock.Anything,
+			mock.Anything,
+		).Run(func(args mock.Arguments) {
+			stdout := args.Get(5).(io.Writer)
+			_, _ = io.WriteString(
+				stdout,
+				"Waiting for deployment \"foo\" rollout to finish: 2 of 4 updated replicas are available...\n"+
+					"Waiting for deployment \"foo\" rollout to finish: 2 of 4 updated replicas are available...\n"+
+					"Waiting for deployment \"foo\" rollout to finish: 3 of 4 updated",
+			)
+			_, _ = io.WriteString(
+				stdout,
+				" replicas are available...\ndeployment \"foo\" successfully rolled out\n",
+			)
+		}).Return(nil)
+
+		var buffer bytes.Buffer
+		kubectl := NewKubectl(executor, "", "svc.cluster.local").WithProgress(progress.NewConsole(&buffer))
+
+		err := kubectl.RolloutStatus(5*time.Second, "deployment/foo", "default")
+		require.NoError(t, err)
+
+		assert.Equal(
+			t,
+			"[deployment/foo] rollout: waiting for rollout in namespace default\n"+
+				"[deployment/foo] rollout 50%: 2 of 4 updated replicas are available\n"+
+				"[deployment/foo] rollout 75%: 3 of 4 updated replicas are available\n"+
+				"[deployment/foo] rollout 100%: rollout complete\n",
+			buffer.String(),
+		)
+	})
+
+	t.Run("when the rollout fails, it reports the failure and returns error", func(t *testing.T) {
+		t.Parallel()
+
+		executor := ostest.NewFakeOsExecutor(t)
+		executor.On(
+			"ExecuteWithStreamsContext",
+			mock.Anything,
+			"kubectl",
+			[]string{"-n", "default", "rollout", "status", "deployment/foo", "--timeout", "5s"},
+			[]string(nil),
+			"",
+			mock.Anything,
+			mock.Anything,
+		).Return(errors.New("exit status 1"))
+
+		var buffer bytes.Buffer
+		kubectl := NewKubectl(executor, "", "svc.cluster.local").WithProgress(progress.NewConsole(&buffer))
+
+		err := kubectl.RolloutStatus(5*time.Second, "deployment/foo", "default")
+		require.Error(t, err)
+
+		assert.Equal(
+			t,
+			"[deployment/foo] rollout: waiting for rollout in namespace default\n"+
+				"[deployment/foo] rollout: rollout failed\n",
+			buffer.String(),
+		)
+	})
+}
+
+func TestKubectl_WaitForJob(t *testing.T) {
+	jobArgs := []string{"-n", "default", "get", "job", "foo", "-o", "json"}
+
+	t.Run("it polls the job status until the job completes", func(t *testing.T) {
+		t.Parallel()
+
+		executor := ostest.NewFakeOsExecutor(t)
+		executor.On("ExecuteContext", mock.Anything, "kubectl", jobArgs, []string(nil), "").
+			Return([]byte(`{"status": {"active": 1}}`), []byte(nil), nil).
+			Once()
+		executor.On("ExecuteContext", mock.Anything, "kubectl", jobArgs, []string(nil), "").
+			Return([]byte(`{"status": {"conditions": [{"type": "Complete", "status": "True"}]}}`), []byte(nil), nil).
+			Once()
+
+		var buffer bytes.Buffer
+		kubectl := NewKubectl(executor, "", "svc.cluster.local")
+		ctx := progress.NewContext(context.Background(), progress.NewConsole(&buffer))
+
+		status, err := kubectl.WithContext(ctx).WaitForJob(time.Second, time.Millisecond, "foo", "default")
+		require.NoError(t, err)
+
+		assert.Equal(t, KubernetesJobStatusComplete, status)
+		assert.Equal(
+			t,
+			"[job/foo] job: waiting for job in namespace default\n[job/foo] job 100%: job complete\n",
+			buffer.String(),
+		)
+		executor.AssertExpectations(t)
+	})
+
+	t.Run("when the job has completions, it reports the percent of succeeded completions", func(t *testing.T) {
+		t.Parallel()
+
+		executor := ostest.NewFakeOsExecutor(t)
+		executor.On("ExecuteContext", mock.Anything, "kubectl", jobArgs, []string(nil), "").
+			Return([]byte(`{"spec": {"completions": 4}, "status": {"active": 2, "succeeded": 1}}`), []byte(nil), nil).
+			Once()
+		executor.On("ExecuteContext", mock.Anything, "kubectl", jobArgs, []string(nil), "").
+			Return([]byte(`{"spec": {"completions": 4}, "status": {"active": 1, "succeeded": 3}}`), []byte(nil), nil).
+			Once()
+		executor.On("ExecuteContext", mock.Anything, "kubectl", jobArgs, []string(nil), "").
+			Return(
+				[]byte(`{"spec": {"completions": 4}, "status": {"conditions": [{"type": "Complete", "status": "True"}]}}`),
+				[]byte(nil),
+				nil,
+			).
+			Once()
+
+		var buffer bytes.Buffer
+		kubectl := NewKubectl(executor, "", "svc.cluster.local")
+		ctx := progress.NewContext(context.Background(), progress.NewConsole(&buffer))
+
+		status, err := kubectl.WithContext(ctx).WaitForJob(time.Second, time.Millisecond, "foo", "default")
+		require.NoError(t, err)
+
+		assert.Equal(t, KubernetesJobStatusComplete, status)
+		assert.Equal(
+			t,
+			"[job/foo] job 25%: 1 of 4 completions succeeded, 2 active\n"+
+				"[job/foo] job 75%: 3 of 4 completions succeeded, 1 active\n"+
+				"[job/foo] job 100%: job complete\n",
+			buffer.String(),
+		)
+		executor.AssertExpectations(t)
+	})
+
+	t.Run("when the timeout is exceeded, it returns error", func(t *testing.T) {
+		t.Parallel()
+
+		executor := ostest.NewFakeOsExecutor(t)
+		executor.On("Execute", "kubectl", jobArgs, []string(nil), "").
+			Return([]byte(`{"status": {"active": 1}}`), []byte(nil), nil)
+
+		fakeClock := clocktest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+		fakeClock.SetAutoAdvance(true)
+
+		kubectl := NewKubectl(executor, "", "svc.cluster.local").WithClock(fakeClock)
+
+		status, err := kubectl.WaitForJob(time.Minute, 10*time.Second, "foo", "default")
+		assert.EqualError(t, err, "timed out waiting for job foo in namespace default")
+		assert.Equal(t, KubernetesJobStatusActive, status)
+		executor.AssertNumberOfCalls(t, "Execute", 6)
+	})
+}
+
+func TestKubectl_ApplyData(t *testing.T) {
+	t.Run(
+		"it pipes the manifest to kubectl stdin",
+		func(t *testing.T) {
+			t.Parallel()
+			executor := ostest.NewFakeOsExecutor(t)
+
+			manifest := []byte("apiVersion: v1\nkind: Secret\n")
+			executor.On(
+				"ExecuteWithStdinContext",
+				mock.Anything,
+				"kubectl",
+				[]string{"apply", "-f", "-", "-n", "default"},
+				[]string(nil),
+				"",
+				bytes.NewReader(manifest),
+			).Return([]byte("secret/example configured"), []byte(nil), nil)
+
+			kubectl := NewKubectl(executor, "", "svc.cluster.local")
+
+			err := kubectl.ApplyData(manifest, "default")
+			require.NoError(t, err)
+			executor.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"when the executor is decorated, it pipes the manifest to kubectl stdin",
+		func(t *testing.T) {
+			t.Parallel()
+			executor := ostest.NewFakeOsExecutor(t)
+
+			manifest := []byte("apiVersion: v1\nkind: Secret\n")
+			executor.On(
+				"ExecuteWithStdinContext",
+				mock.Anything,
+				"kubectl",
+				[]string{"apply", "-f", "-", "-n", "default"},
+				[]string(nil),
+				"",
+				bytes.NewReader(manifest),
+			).Return([]byte("secret/example configured"), []byte(nil), nil)
+
+			var stdout, logs bytes.Buffer
+			executor.On("Stdout").Return(&stdout)
+			executor.On("Stderr").Return(&bytes.Buffer{})
+
+			log := logger.NewLogrusLogger()
+			log.SetOutput(&logs)
+			log.SetLevel(logger.DebugLevel)
+
+			decorated := NewExecuteLogger(
+				NewRealtimeStdoutExecutor(NewRetryExecutor(executor, retry.Policy{MaxAttempts: 3}, nil)),
+				log,
+			)
+			kubectl := NewKubectl(decorated, "", "svc.cluster.local")
+
+			err := kubectl.ApplyData(manifest, "default")
+			require.NoError(t, err)
+			executor.AssertExpectations(t)
+
+			assert.Equal(t, "secret/example configured", stdout.String())
+			assert.Contains(t, logs.String(), "command# kubectl apply -f - -n default")
+			assert.NotContains(t, logs.String(), "kind: Secret")
+		},
+	)
+
+	t.Run(
+		"when executor does not support stdin, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			// NOTE: Embedding the interface hides the stdin support of the fake.
+			executor := struct{ pkgOs.CommandExecutor }{ostest.NewFakeOsExecutor(t)}
+			kubectl := NewKubectl(executor, "", "svc.cluster.local")
+
+			err := kubectl.ApplyData([]byte("kind: Secret"), "")
+			require.Error(t, err)
+		},
+	)
+}
@@ -0,0 +1,113 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+type KustomizeBuildOptions struct {
+	// EnableHelm enables the inflation of the `helmCharts` field.
+	EnableHelm bool
+	// HelmCommand is the helm binary used by the helm chart inflation, when not `helm`.
+	HelmCommand string
+	// EnableAlphaPlugins enables the kustomize plugins.
+	EnableAlphaPlugins bool
+	// EnableExec enables the exec function and plugins. Only used along with EnableAlphaPlugins.
+	EnableExec bool
+	// LoadRestrictorNone allows loading files outside of the kustomization root.
+	LoadRestrictorNone bool
+}
+
+type Kustomize struct {
+	binPath         string
+	commandExecutor os.CommandExecutor
+}
+
+func NewKustomize(executor os.CommandExecutor) *Kustomize {
+	return &Kustomize{
+		binPath:         "kustomize",
+		commandExecutor: executor,
+	}
+}
+
+// Build returns the rendered manifests of the kustomization in dir.
+func (kustomize *Kustomize) Build(ctx context.Context, dir string, options *KustomizeBuildOptions) ([]byte, error) {
+	args := []string{"build", dir}
+
+	if options != nil {
+		if options.EnableHelm {
+			args = append(args, "--enable-helm")
+
+			if options.HelmCommand != "" {
+				args = append(args, "--helm-command", options.HelmCommand)
+			}
+		}
+
+		if options.EnableAlphaPlugins {
+			args = append(args, "--enable-alpha-plugins")
+
+			if options.EnableExec {
+				args = append(args, "--enable-exec")
+			}
+		}
+
+		if options.LoadRestrictorNone {
+			args = append(args, "--load-restrictor", "LoadRestrictionsNone")
+		}
+	}
+
+	stdout, stderr, err := kustomize.commandExecutor.ExecuteContext(ctx, kustomize.binPath, args, nil, "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "kustomize build %s failed. Stderr: %s", dir, stderr)
+	}
+
+	return stdout, nil
+}
+
+// BuildAndApply renders the kustomization in dir and applies it with applier, e.g Kubectl,
+// without writing the manifests to disk.
+func (kustomize *Kustomize) BuildAndApply(
+	ctx context.Context,
+	dir string,
+	options *KustomizeBuildOptions,
+	applier ManifestApplier,
+	namespace string,
+) error {
+	manifests, err := kustomize.Build(ctx, dir, options)
+	if err != nil {
+		return err
+	}
+
+	err = applier.ApplyData(manifests, namespace)
+	return stacktrace.Propagate(err, "applying kustomization %s failed", dir)
+}
+
+// EditSetImage sets the images of the kustomization in dir,
+// e.g `nginx=nginx:1.17`, `nginx=registry.example.com/nginx@sha256:<hash>` or `nginx:1.17`.
+func (kustomize *Kustomize) EditSetImage(ctx context.Context, dir string, images ...string) error {
+	if len(images) == 0 {
+		return stacktrace.NewError("no images to set")
+	}
+
+	args := append([]string{"edit", "set", "image"}, images...)
+
+	stdout, stderr, err := kustomize.commandExecutor.ExecuteContext(ctx, kustomize.binPath, args, nil, dir)
+	return stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+}
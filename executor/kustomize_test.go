@@ -0,0 +1,144 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestKustomize_Build(t *testing.T) {
+	t.Run(
+		"it passes the plugin and helm flags and returns the rendered manifests",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"kustomize",
+				[]string{
+					"build", "overlays/production",
+					"--enable-helm",
+					"--helm-command", "helm3",
+					"--enable-alpha-plugins",
+					"--enable-exec",
+					"--load-restrictor", "LoadRestrictionsNone",
+				},
+				[]string(nil),
+				"",
+			).Return([]byte("kind: Deployment\n"), []byte{}, nil)
+
+			kustomizeInstance := NewKustomize(executorArg)
+			actual, actualErr := kustomizeInstance.Build(
+				context.Background(),
+				"overlays/production",
+				&KustomizeBuildOptions{
+					EnableHelm:         true,
+					HelmCommand:        "helm3",
+					EnableAlphaPlugins: true,
+					EnableExec:         true,
+					LoadRestrictorNone: true,
+				},
+			)
+			require.Nil(t, actualErr)
+			assert.Equal(t, "kind: Deployment\n", string(actual))
+		},
+	)
+
+	t.Run(
+		"when build fails, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"kustomize",
+				[]string{"build", "overlays/production"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("missing resource"), errors.New("exit status 1"))
+
+			kustomizeInstance := NewKustomize(executorArg)
+			actual, actualErr := kustomizeInstance.Build(context.Background(), "overlays/production", nil)
+			require.NotNil(t, actualErr)
+			assert.Nil(t, actual)
+			assert.Contains(t, actualErr.Error(), "missing resource")
+		},
+	)
+}
+
+func TestKustomize_BuildAndApply(t *testing.T) {
+	t.Parallel()
+
+	executorArg := ostest.NewFakeOsExecutor(t)
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"kustomize",
+		[]string{"build", "overlays/production"},
+		[]string(nil),
+		"",
+	).Return([]byte("kind: Deployment\n"), []byte{}, nil)
+
+	applier := &fakeManifestApplier{}
+	applier.On("ApplyData", []byte("kind: Deployment\n"), "production").Return(nil)
+
+	kustomizeInstance := NewKustomize(executorArg)
+	actualErr := kustomizeInstance.BuildAndApply(
+		context.Background(),
+		"overlays/production",
+		nil,
+		applier,
+		"production",
+	)
+	require.Nil(t, actualErr)
+	applier.AssertExpectations(t)
+}
+
+func TestKustomize_EditSetImage(t *testing.T) {
+	t.Parallel()
+
+	executorArg := ostest.NewFakeOsExecutor(t)
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"kustomize",
+		[]string{"edit", "set", "image", "api=registry.example.com/api:1.2.0", "worker=registry.example.com/worker:1.2.0"},
+		[]string(nil),
+		"overlays/production",
+	).Return([]byte{}, []byte{}, nil)
+
+	kustomizeInstance := NewKustomize(executorArg)
+	actualErr := kustomizeInstance.EditSetImage(
+		context.Background(),
+		"overlays/production",
+		"api=registry.example.com/api:1.2.0",
+		"worker=registry.example.com/worker:1.2.0",
+	)
+	require.Nil(t, actualErr)
+
+	actualErr = kustomizeInstance.EditSetImage(context.Background(), "overlays/production")
+	require.NotNil(t, actualErr)
+}
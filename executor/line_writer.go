@@ -0,0 +1,65 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"io"
+)
+
+var _ io.Writer = (*lineWriter)(nil)
+
+// lineWriter calls onLine with every line written to it.
+type lineWriter struct {
+	buffer bytes.Buffer
+	onLine func(line string)
+}
+
+func newLineWriter(onLine func(line string)) *lineWriter {
+	return &lineWriter{onLine: onLine}
+}
+
+func (w *lineWriter) Write(data []byte) (int, error) {
+	w.buffer.Write(data)
+
+	for {
+		line, err := w.buffer.ReadBytes('\n')
+		if err != nil {
+			// NOTE: Keep the incomplete line until the rest of it is written.
+			w.buffer.Write(line)
+			break
+		}
+
+		w.handleLine(line)
+	}
+
+	return len(data), nil
+}
+
+// Flush handles the last line when it's not newline-terminated.
+func (w *lineWriter) Flush() {
+	line := w.buffer.Bytes()
+	w.buffer.Reset()
+	w.handleLine(line)
+}
+
+func (w *lineWriter) handleLine(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+
+	w.onLine(string(line))
+}
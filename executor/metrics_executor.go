@@ -0,0 +1,148 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+var (
+	_ os.OsExecutor           = (*MetricsExecutor)(nil)
+	_ os.StdinCommandExecutor = (*MetricsExecutor)(nil)
+)
+
+// UnknownExitCode is the exit code of the commands that failed without exiting, e.g when the binary is not found.
+const UnknownExitCode = -1
+
+// CommandMetricsSink receives the command metrics, e.g to export them as Prometheus metrics.
+type CommandMetricsSink interface {
+	// ObserveCommand is called when a command finishes, with the base name of its binary, e.g `kubectl`.
+	ObserveCommand(binary string, exitCode int, duration time.Duration)
+}
+
+// MetricsExecutor is os.OsExecutor decorator, that emits the exit code and duration of the commands
+// of the Execute methods to the sink.
+type MetricsExecutor struct {
+	os.OsExecutor
+
+	sink CommandMetricsSink
+}
+
+func NewMetricsExecutor(osExecutor os.OsExecutor, sink CommandMetricsSink) *MetricsExecutor {
+	return &MetricsExecutor{
+		OsExecutor: osExecutor,
+		sink:       sink,
+	}
+}
+
+func (c *MetricsExecutor) Execute(cmd string, arg []string, env []string, dir string) ([]byte, []byte, error) {
+	start := time.Now()
+	stdout, stderr, err := c.OsExecutor.Execute(cmd, arg, env, dir)
+	c.observe(cmd, start, err)
+
+	return stdout, stderr, err
+}
+
+func (c *MetricsExecutor) ExecuteContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+) ([]byte, []byte, error) {
+	start := time.Now()
+	stdout, stderr, err := c.OsExecutor.ExecuteContext(ctx, cmd, arg, env, dir)
+	c.observe(cmd, start, err)
+
+	return stdout, stderr, err
+}
+
+func (c *MetricsExecutor) ExecuteWithStdinContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+	stdin io.Reader,
+) ([]byte, []byte, error) {
+	stdinExecutor, ok := c.OsExecutor.(os.StdinCommandExecutor)
+	if !ok {
+		return nil, nil, stacktrace.NewError("command executor does not support executing with stdin")
+	}
+
+	start := time.Now()
+	stdout, stderr, err := stdinExecutor.ExecuteWithStdinContext(ctx, cmd, arg, env, dir, stdin)
+	c.observe(cmd, start, err)
+
+	return stdout, stderr, err
+}
+
+func (c *MetricsExecutor) ExecuteWithStreams(
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+	stdout io.Writer,
+	stderr io.Writer,
+) error {
+	start := time.Now()
+	err := c.OsExecutor.ExecuteWithStreams(cmd, arg, env, dir, stdout, stderr)
+	c.observe(cmd, start, err)
+
+	return err
+}
+
+func (c *MetricsExecutor) ExecuteWithStreamsContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+	stdout io.Writer,
+	stderr io.Writer,
+) error {
+	start := time.Now()
+	err := c.OsExecutor.ExecuteWithStreamsContext(ctx, cmd, arg, env, dir, stdout, stderr)
+	c.observe(cmd, start, err)
+
+	return err
+}
+
+func (c *MetricsExecutor) observe(cmd string, start time.Time, err error) {
+	c.sink.ObserveCommand(filepath.Base(cmd), exitCode(err), time.Since(start))
+}
+
+// exitCode returns the exit code of the command failed with err, 0 when err is nil
+// or UnknownExitCode when the command didn't exit.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	exitErr, ok := stacktrace.RootCause(err).(*exec.ExitError)
+	if !ok {
+		return UnknownExitCode
+	}
+
+	return exitErr.ExitCode()
+}
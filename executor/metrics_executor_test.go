@@ -0,0 +1,139 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/palantir/stacktrace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+type recordedCommand struct {
+	binary   string
+	exitCode int
+}
+
+type recordingCommandMetricsSink struct {
+	commands []recordedCommand
+}
+
+func (s *recordingCommandMetricsSink) ObserveCommand(binary string, exitCode int, duration time.Duration) {
+	s.commands = append(s.commands, recordedCommand{binary: binary, exitCode: exitCode})
+}
+
+func TestMetricsExecutor_Execute(t *testing.T) {
+	t.Run(
+		"it observes the binary base name and exit code of the commands",
+		func(t *testing.T) {
+			t.Parallel()
+
+			exitErr := exec.Command("sh", "-c", "exit 3").Run()
+			require.Error(t, exitErr)
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"Execute",
+				"/usr/local/bin/kubectl",
+				[]string{"get", "pods"},
+				[]string(nil),
+				"",
+			).Return([]byte("api-0"), []byte{}, nil).Once()
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"helm",
+				[]string{"upgrade"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("failed"), stacktrace.Propagate(exitErr, "executing command failed")).Once()
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"terraform",
+				[]string{"plan"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte{}, errors.New("executable file not found in $PATH")).Once()
+
+			sink := &recordingCommandMetricsSink{}
+			metricsExecutor := NewMetricsExecutor(executorArg, sink)
+
+			stdout, _, err := metricsExecutor.Execute("/usr/local/bin/kubectl", []string{"get", "pods"}, nil, "")
+			require.Nil(t, err)
+			assert.Equal(t, "api-0", string(stdout))
+
+			_, _, err = metricsExecutor.ExecuteContext(context.Background(), "helm", []string{"upgrade"}, nil, "")
+			require.Error(t, err)
+
+			_, _, err = metricsExecutor.ExecuteContext(context.Background(), "terraform", []string{"plan"}, nil, "")
+			require.Error(t, err)
+
+			assert.Equal(
+				t,
+				[]recordedCommand{
+					{binary: "kubectl", exitCode: 0},
+					{binary: "helm", exitCode: 3},
+					{binary: "terraform", exitCode: UnknownExitCode},
+				},
+				sink.commands,
+			)
+			executorArg.AssertExpectations(t)
+		},
+	)
+}
+
+func TestMetricsExecutor_ExecuteWithStdinContext(t *testing.T) {
+	t.Run(
+		"it observes the commands executed with stdin",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"kubectl",
+				[]string{"apply", "-f", "-"},
+				[]string(nil),
+				"",
+				strings.NewReader("manifest"),
+			).Return([]byte("applied"), []byte{}, nil).Once()
+
+			sink := &recordingCommandMetricsSink{}
+			metricsExecutor := NewMetricsExecutor(executorArg, sink)
+
+			_, _, err := metricsExecutor.ExecuteWithStdinContext(
+				context.Background(),
+				"kubectl",
+				[]string{"apply", "-f", "-"},
+				nil,
+				"",
+				strings.NewReader("manifest"),
+			)
+			require.Nil(t, err)
+			assert.Equal(t, []recordedCommand{{binary: "kubectl", exitCode: 0}}, sink.commands)
+			executorArg.AssertExpectations(t)
+		},
+	)
+}
@@ -0,0 +1,111 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+type MinikubeStartOptions struct {
+	// Driver is the driver of the cluster nodes, e.g `docker` or `kvm2`, when not the minikube default one.
+	Driver            string
+	KubernetesVersion string
+	// Nodes is the count of the cluster nodes, when positive.
+	Nodes int
+	CPUs  int
+	// Memory is the memory of every node, e.g `4g`.
+	Memory string
+}
+
+// Minikube provisions local kubernetes clusters, identified by their profile name.
+type Minikube struct {
+	binPath         string
+	commandExecutor os.CommandExecutor
+}
+
+func NewMinikube(executor os.CommandExecutor) *Minikube {
+	return &Minikube{
+		binPath:         "minikube",
+		commandExecutor: executor,
+	}
+}
+
+func (minikube *Minikube) CreateCluster(ctx context.Context, profile string, options *MinikubeStartOptions) error {
+	args := []string{"start", "--profile", profile}
+
+	if options != nil {
+		if options.Driver != "" {
+			args = append(args, "--driver", options.Driver)
+		}
+
+		if options.KubernetesVersion != "" {
+			args = append(args, "--kubernetes-version", options.KubernetesVersion)
+		}
+
+		if options.Nodes > 0 {
+			args = append(args, "--nodes", strconv.Itoa(options.Nodes))
+		}
+
+		if options.CPUs > 0 {
+			args = append(args, "--cpus", strconv.Itoa(options.CPUs))
+		}
+
+		if options.Memory != "" {
+			args = append(args, "--memory", options.Memory)
+		}
+	}
+
+	return minikube.execute(ctx, nil, args...)
+}
+
+func (minikube *Minikube) DeleteCluster(ctx context.Context, profile string) error {
+	return minikube.execute(ctx, nil, "delete", "--profile", profile)
+}
+
+// LoadImage loads the local images into the cluster nodes, so that pods can use them without a registry.
+func (minikube *Minikube) LoadImage(ctx context.Context, profile string, images ...string) error {
+	if len(images) == 0 {
+		return stacktrace.NewError("no images to load")
+	}
+
+	for _, image := range images {
+		err := minikube.execute(ctx, nil, "image", "load", image, "--profile", profile)
+		if err != nil {
+			return stacktrace.Propagate(err, "load image %s failed", image)
+		}
+	}
+
+	return nil
+}
+
+// ExportKubeconfig adds the cluster to the kubeconfig file, or the default one when blank, and sets it as current context.
+func (minikube *Minikube) ExportKubeconfig(ctx context.Context, profile, kubeconfig string) error {
+	var env []string
+	if kubeconfig != "" {
+		env = os.CommandEnv("KUBECONFIG=" + kubeconfig)
+	}
+
+	return minikube.execute(ctx, env, "update-context", "--profile", profile)
+}
+
+func (minikube *Minikube) execute(ctx context.Context, env []string, args ...string) error {
+	stdout, stderr, err := minikube.commandExecutor.ExecuteContext(ctx, minikube.binPath, args, env, "")
+	return stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+}
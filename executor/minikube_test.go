@@ -0,0 +1,131 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestMinikube_CreateCluster(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"minikube",
+		[]string{
+			"start", "--profile", "e2e",
+			"--driver", "docker",
+			"--kubernetes-version", "v1.27.3",
+			"--nodes", "2",
+			"--cpus", "2",
+			"--memory", "4g",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	minikubeInstance := NewMinikube(executorArg)
+	actual := minikubeInstance.CreateCluster(
+		context.Background(),
+		"e2e",
+		&MinikubeStartOptions{
+			Driver:            "docker",
+			KubernetesVersion: "v1.27.3",
+			Nodes:             2,
+			CPUs:              2,
+			Memory:            "4g",
+		},
+	)
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestMinikube_LoadImage(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"minikube",
+		[]string{"image", "load", "api:dev", "--profile", "e2e"},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"minikube",
+		[]string{"image", "load", "worker:dev", "--profile", "e2e"},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte("image not found"), errors.New("exit status 1"))
+
+	minikubeInstance := NewMinikube(executorArg)
+	actual := minikubeInstance.LoadImage(context.Background(), "e2e", "api:dev", "worker:dev")
+	require.NotNil(t, actual)
+	assert.Contains(t, actual.Error(), "worker:dev")
+	executorArg.AssertExpectations(t)
+}
+
+func TestMinikube_ExportKubeconfig(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"minikube",
+		[]string{"update-context", "--profile", "e2e"},
+		mock.MatchedBy(func(env []string) bool {
+			return containsString(env, "KUBECONFIG=/tmp/e2e.kubeconfig")
+		}),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	minikubeInstance := NewMinikube(executorArg)
+	actual := minikubeInstance.ExportKubeconfig(context.Background(), "e2e", "/tmp/e2e.kubeconfig")
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestMinikube_DeleteCluster(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"minikube",
+		[]string{"delete", "--profile", "e2e"},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	minikubeInstance := NewMinikube(executorArg)
+	actual := minikubeInstance.DeleteCluster(context.Background(), "e2e")
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+// Podman is a daemonless and rootless ContainerRuntime.
+// Its CLI is compatible with the Docker one, so the commands are the Docker ones unless stated otherwise.
+type Podman struct {
+	docker *Docker
+}
+
+func NewPodman(executor os.CommandExecutor) *Podman {
+	return &Podman{
+		docker: &Docker{
+			binaryPath:      "podman",
+			commandExecutor: executor,
+		},
+	}
+}
+
+func (podman *Podman) Build(ctx context.Context, options *DockerBuildOptions) error {
+	return podman.docker.Build(ctx, options)
+}
+
+func (podman *Podman) Push(ctx context.Context, image string) error {
+	return podman.docker.Push(ctx, image)
+}
+
+func (podman *Podman) Pull(ctx context.Context, image string) error {
+	return podman.docker.Pull(ctx, image)
+}
+
+func (podman *Podman) Tag(ctx context.Context, oldImage, newImage string) error {
+	return podman.docker.Tag(ctx, oldImage, newImage)
+}
+
+func (podman *Podman) Login(ctx context.Context, username, password, registryURL string) error {
+	return podman.docker.Login(ctx, username, password, registryURL)
+}
+
+func (podman *Podman) Run(ctx context.Context, options *DockerRunOptions) (string, error) {
+	return podman.docker.Run(ctx, options)
+}
+
+// ImageExists checks whether the image is present locally.
+func (podman *Podman) ImageExists(ctx context.Context, image string) (bool, error) {
+	args := []string{"image", "exists", image}
+	stdout, stderr, err := podman.docker.commandExecutor.ExecuteContext(ctx, podman.docker.binaryPath, args, nil, "")
+	if err != nil {
+		// NOTE: `podman image exists` exits with 1 when the image is missing, other exit codes are failures.
+		if exitCode(err) == 1 {
+			return false, nil
+		}
+
+		return false, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return true, nil
+}
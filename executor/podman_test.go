@@ -0,0 +1,206 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestNewContainerRuntime(t *testing.T) {
+	executorArg := &ostest.FakeOsExecutor{}
+
+	actual, actualErr := NewContainerRuntime(executorArg, ContainerRuntimeDocker)
+	require.Nil(t, actualErr)
+	assert.IsType(t, &Docker{}, actual)
+
+	actual, actualErr = NewContainerRuntime(executorArg, ContainerRuntimePodman)
+	require.Nil(t, actualErr)
+	assert.IsType(t, &Podman{}, actual)
+
+	actual, actualErr = NewContainerRuntime(executorArg, "containerd")
+	require.NotNil(t, actualErr)
+	assert.Nil(t, actual)
+}
+
+func TestPodman_Build(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"podman",
+		[]string{"build", "-f", "Dockerfile", "--tag", "api:dev", "--platform", "linux/arm64", "."},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	podmanInstance := NewPodman(executorArg)
+	actual := podmanInstance.Build(
+		context.Background(),
+		&DockerBuildOptions{
+			File:       "Dockerfile",
+			Tag:        "api:dev",
+			Platform:   "linux/arm64",
+			ContextDir: ".",
+		},
+	)
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestPodman_Push(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"podman",
+		[]string{"push", "registry.example.com/api:dev"},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte("unauthorized"), errors.New("exit status 125"))
+
+	podmanInstance := NewPodman(executorArg)
+	actual := podmanInstance.Push(context.Background(), "registry.example.com/api:dev")
+	require.NotNil(t, actual)
+	assert.Contains(t, actual.Error(), "unauthorized")
+}
+
+func TestPodman_Run(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"podman",
+		[]string{"run", "--rm", "--env", "GREETING=hello", "alpine:3", "echo", "hello"},
+		[]string(nil),
+		"",
+	).Return([]byte("hello\n"), []byte{}, nil)
+
+	podmanInstance := NewPodman(executorArg)
+	actual, actualErr := podmanInstance.Run(
+		context.Background(),
+		&DockerRunOptions{
+			Image:   "alpine:3",
+			Command: []string{"echo", "hello"},
+			Env:     []string{"GREETING=hello"},
+			Remove:  true,
+		},
+	)
+	require.Nil(t, actualErr)
+	assert.Equal(t, "hello", actual)
+}
+
+func TestPodman_ImageExists(t *testing.T) {
+	t.Run(
+		"when image is present, it returns true",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"podman",
+				[]string{"image", "exists", "api:dev"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte{}, nil)
+
+			podmanInstance := NewPodman(executorArg)
+			actual, actualErr := podmanInstance.ImageExists(context.Background(), "api:dev")
+			require.Nil(t, actualErr)
+			assert.True(t, actual)
+		},
+	)
+
+	t.Run(
+		"when image is missing, it returns false",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"podman",
+				[]string{"image", "exists", "api:dev"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte{}, exec.Command("sh", "-c", "exit 1").Run())
+
+			podmanInstance := NewPodman(executorArg)
+			actual, actualErr := podmanInstance.ImageExists(context.Background(), "api:dev")
+			require.Nil(t, actualErr)
+			assert.False(t, actual)
+		},
+	)
+
+	t.Run(
+		"when the command fails without output, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"podman",
+				[]string{"image", "exists", "api:dev"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte{}, errors.New("exec: \"podman\": executable file not found in $PATH"))
+
+			podmanInstance := NewPodman(executorArg)
+			actual, actualErr := podmanInstance.ImageExists(context.Background(), "api:dev")
+			require.NotNil(t, actualErr)
+			assert.False(t, actual)
+		},
+	)
+
+	t.Run(
+		"when checking fails, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"podman",
+				[]string{"image", "exists", "api:dev"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("Error: cannot connect to storage"), errors.New("exit status 125"))
+
+			podmanInstance := NewPodman(executorArg)
+			actual, actualErr := podmanInstance.ImageExists(context.Background(), "api:dev")
+			require.NotNil(t, actualErr)
+			assert.False(t, actual)
+		},
+	)
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"io"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+	"github.com/sumup-oss/go-pkgs/ratelimit"
+)
+
+var (
+	_ os.OsExecutor           = (*RateLimitExecutor)(nil)
+	_ os.StdinCommandExecutor = (*RateLimitExecutor)(nil)
+)
+
+// RateLimitKeyFunc returns the rate limit key of a command, e.g the tenant of its kubeconfig.
+type RateLimitKeyFunc func(cmd string, arg []string) string
+
+// RateLimitExecutor is os.OsExecutor decorator, that throttles the commands of the Execute methods
+// per key with the keyed limiter, e.g kubectl commands against a busy API server or registry pushes.
+type RateLimitExecutor struct {
+	os.OsExecutor
+
+	limiter *ratelimit.Keyed
+	key     RateLimitKeyFunc
+}
+
+// NewRateLimitExecutor creates RateLimitExecutor instance, that limits the commands per key,
+// or per command binary when key is nil.
+func NewRateLimitExecutor(osExecutor os.OsExecutor, limiter *ratelimit.Keyed, key RateLimitKeyFunc) *RateLimitExecutor {
+	if key == nil {
+		key = func(cmd string, _ []string) string {
+			return cmd
+		}
+	}
+
+	return &RateLimitExecutor{
+		OsExecutor: osExecutor,
+		limiter:    limiter,
+		key:        key,
+	}
+}
+
+func (c *RateLimitExecutor) Execute(cmd string, arg []string, env []string, dir string) ([]byte, []byte, error) {
+	return c.ExecuteContext(context.Background(), cmd, arg, env, dir)
+}
+
+func (c *RateLimitExecutor) ExecuteContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+) ([]byte, []byte, error) {
+	err := c.wait(ctx, cmd, arg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.OsExecutor.ExecuteContext(ctx, cmd, arg, env, dir)
+}
+
+func (c *RateLimitExecutor) ExecuteWithStdinContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+	stdin io.Reader,
+) ([]byte, []byte, error) {
+	stdinExecutor, ok := c.OsExecutor.(os.StdinCommandExecutor)
+	if !ok {
+		return nil, nil, stacktrace.NewError("command executor does not support executing with stdin")
+	}
+
+	err := c.wait(ctx, cmd, arg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return stdinExecutor.ExecuteWithStdinContext(ctx, cmd, arg, env, dir, stdin)
+}
+
+func (c *RateLimitExecutor) ExecuteWithStreams(
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+	stdout io.Writer,
+	stderr io.Writer,
+) error {
+	return c.ExecuteWithStreamsContext(context.Background(), cmd, arg, env, dir, stdout, stderr)
+}
+
+func (c *RateLimitExecutor) ExecuteWithStreamsContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+	stdout io.Writer,
+	stderr io.Writer,
+) error {
+	err := c.wait(ctx, cmd, arg)
+	if err != nil {
+		return err
+	}
+
+	return c.OsExecutor.ExecuteWithStreamsContext(ctx, cmd, arg, env, dir, stdout, stderr)
+}
+
+func (c *RateLimitExecutor) wait(ctx context.Context, cmd string, arg []string) error {
+	err := c.limiter.Wait(ctx, c.key(cmd, arg))
+	return stacktrace.Propagate(err, "rate limit of command %s not acquired", cmd)
+}
@@ -0,0 +1,166 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+	"github.com/sumup-oss/go-pkgs/ratelimit"
+)
+
+func TestRateLimitExecutor_Execute(t *testing.T) {
+	t.Run(
+		"it executes the command when the limit of its binary allows",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"kubectl",
+				[]string{"get", "pods"},
+				[]string(nil),
+				"",
+			).Return([]byte("api-0"), []byte{}, nil).Once()
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"docker",
+				[]string{"push", "example"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte{}, nil).Once()
+
+			limiter := ratelimit.NewKeyed(func(string) ratelimit.Limiter {
+				return ratelimit.NewSlidingWindow(1, time.Hour)
+			})
+
+			rateLimitExecutor := NewRateLimitExecutor(executorArg, limiter, nil)
+			stdout, _, err := rateLimitExecutor.Execute("kubectl", []string{"get", "pods"}, nil, "")
+			require.Nil(t, err)
+			assert.Equal(t, "api-0", string(stdout))
+
+			_, _, err = rateLimitExecutor.Execute("docker", []string{"push", "example"}, nil, "")
+			require.Nil(t, err)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"it returns error without executing the command when the context is done before the limit allows",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			limiter := ratelimit.NewKeyed(func(string) ratelimit.Limiter {
+				return ratelimit.NewSlidingWindow(0, time.Hour)
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			rateLimitExecutor := NewRateLimitExecutor(executorArg, limiter, nil)
+			_, _, err := rateLimitExecutor.ExecuteContext(ctx, "kubectl", []string{"get", "pods"}, nil, "")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "rate limit of command kubectl not acquired")
+			executorArg.AssertNotCalled(t, "ExecuteContext")
+		},
+	)
+}
+
+func TestRateLimitExecutor_ExecuteWithStdinContext(t *testing.T) {
+	t.Run(
+		"it limits per key and executes the command with stdin",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"kubectl",
+				[]string{"--context", "tenant-a", "apply", "-f", "-"},
+				[]string(nil),
+				"",
+				strings.NewReader("manifest"),
+			).Return([]byte("applied"), []byte{}, nil).Once()
+
+			var keys []string
+
+			limiter := ratelimit.NewKeyed(func(key string) ratelimit.Limiter {
+				keys = append(keys, key)
+				return ratelimit.NewTokenBucket(1, 1)
+			})
+
+			rateLimitExecutor := NewRateLimitExecutor(executorArg, limiter, func(_ string, arg []string) string {
+				return arg[1]
+			})
+
+			stdout, _, err := rateLimitExecutor.ExecuteWithStdinContext(
+				context.Background(),
+				"kubectl",
+				[]string{"--context", "tenant-a", "apply", "-f", "-"},
+				nil,
+				"",
+				strings.NewReader("manifest"),
+			)
+			require.Nil(t, err)
+			assert.Equal(t, "applied", string(stdout))
+			assert.Equal(t, []string{"tenant-a"}, keys)
+			executorArg.AssertExpectations(t)
+		},
+	)
+}
+
+func TestRateLimitExecutor_ExecuteWithStreams(t *testing.T) {
+	t.Run(
+		"it executes the command with streams when the limit allows",
+		func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteWithStreamsContext",
+				context.Background(),
+				"docker",
+				[]string{"build", "."},
+				[]string(nil),
+				"",
+				&stdout,
+				&stderr,
+			).Return(nil).Once()
+
+			limiter := ratelimit.NewKeyed(func(string) ratelimit.Limiter {
+				return ratelimit.NewTokenBucket(1, 1)
+			})
+
+			rateLimitExecutor := NewRateLimitExecutor(executorArg, limiter, nil)
+			err := rateLimitExecutor.ExecuteWithStreams("docker", []string{"build", "."}, nil, "", &stdout, &stderr)
+			require.Nil(t, err)
+			executorArg.AssertExpectations(t)
+		},
+	)
+}
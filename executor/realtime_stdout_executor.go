@@ -16,12 +16,18 @@ package executor
 
 import (
 	"bytes"
+	"context"
 	"io"
 
+	"github.com/palantir/stacktrace"
+
 	"github.com/sumup-oss/go-pkgs/os"
 )
 
-var _ os.OsExecutor = (*RealtimeStdoutExecutor)(nil)
+var (
+	_ os.OsExecutor           = (*RealtimeStdoutExecutor)(nil)
+	_ os.StdinCommandExecutor = (*RealtimeStdoutExecutor)(nil)
+)
 
 // RealtimeStdoutExecutor is os.OsExecutor decorator, that decorates the Execute method by writing
 // executed commands stdout and stderr to executor's Stdout and Stderr.
@@ -51,6 +57,29 @@ func (executor *RealtimeStdoutExecutor) Execute(
 	return stdout.Bytes(), stderr.Bytes(), err
 }
 
+// ExecuteWithStdinContext executes a command with stdin and writes its stdout and stderr
+// to executor's Stdout and Stderr once it exits.
+func (executor *RealtimeStdoutExecutor) ExecuteWithStdinContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+	stdin io.Reader,
+) ([]byte, []byte, error) {
+	stdinExecutor, ok := executor.OsExecutor.(os.StdinCommandExecutor)
+	if !ok {
+		return nil, nil, stacktrace.NewError("command executor does not support executing with stdin")
+	}
+
+	stdout, stderr, err := stdinExecutor.ExecuteWithStdinContext(ctx, cmd, arg, env, dir, stdin)
+
+	_, _ = executor.Stdout().Write(stdout)
+	_, _ = executor.Stderr().Write(stderr)
+
+	return stdout, stderr, err
+}
+
 // BufferedWriter is a writer that decorates an writer, by buffering a copy of all written bytes.
 type BufferedWriter struct {
 	writer io.Writer
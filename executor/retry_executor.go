@@ -0,0 +1,118 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+	"github.com/sumup-oss/go-pkgs/retry"
+)
+
+var (
+	_ os.OsExecutor           = (*RetryExecutor)(nil)
+	_ os.StdinCommandExecutor = (*RetryExecutor)(nil)
+)
+
+// RetryFilter classifies the failed commands that can be retried, e.g by `connection refused` in stderr.
+type RetryFilter func(stdout, stderr []byte, err error) bool
+
+// RetryExecutor is os.OsExecutor decorator, that retries the failed commands of the Execute methods
+// with the retry policy, e.g kubectl commands against an API server that is being upgraded.
+// Only idempotent commands should be executed with it.
+type RetryExecutor struct {
+	os.OsExecutor
+
+	policy retry.Policy
+	filter RetryFilter
+}
+
+// NewRetryExecutor creates RetryExecutor instance, that retries the commands failed per filter,
+// or all failed commands when filter is nil.
+func NewRetryExecutor(osExecutor os.OsExecutor, policy retry.Policy, filter RetryFilter) *RetryExecutor {
+	return &RetryExecutor{
+		OsExecutor: osExecutor,
+		policy:     policy,
+		filter:     filter,
+	}
+}
+
+func (c *RetryExecutor) Execute(cmd string, arg []string, env []string, dir string) ([]byte, []byte, error) {
+	return c.ExecuteContext(context.Background(), cmd, arg, env, dir)
+}
+
+// ExecuteContext executes the command until it succeeds, its failure is not retryable or the policy limits
+// are exceeded, and returns the output and error of the last execution.
+func (c *RetryExecutor) ExecuteContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+) ([]byte, []byte, error) {
+	var stdout, stderr []byte
+
+	var err error
+
+	_ = retry.Do(ctx, c.policy, func(ctx context.Context) error {
+		stdout, stderr, err = c.OsExecutor.ExecuteContext(ctx, cmd, arg, env, dir)
+		if err != nil && c.filter != nil && !c.filter(stdout, stderr, err) {
+			return retry.Permanent(err)
+		}
+
+		return err
+	})
+
+	return stdout, stderr, err
+}
+
+// ExecuteWithStdinContext executes the command like ExecuteContext. The stdin is read once beforehand,
+// so that every execution reads it from the start.
+func (c *RetryExecutor) ExecuteWithStdinContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+	stdin io.Reader,
+) ([]byte, []byte, error) {
+	stdinExecutor, ok := c.OsExecutor.(os.StdinCommandExecutor)
+	if !ok {
+		return nil, nil, stacktrace.NewError("command executor does not support executing with stdin")
+	}
+
+	input, err := ioutil.ReadAll(stdin)
+	if err != nil {
+		return nil, nil, stacktrace.Propagate(err, "failed to read stdin")
+	}
+
+	var stdout, stderr []byte
+
+	_ = retry.Do(ctx, c.policy, func(ctx context.Context) error {
+		stdout, stderr, err = stdinExecutor.ExecuteWithStdinContext(ctx, cmd, arg, env, dir, bytes.NewReader(input))
+		if err != nil && c.filter != nil && !c.filter(stdout, stderr, err) {
+			return retry.Permanent(err)
+		}
+
+		return err
+	})
+
+	return stdout, stderr, err
+}
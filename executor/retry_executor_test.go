@@ -0,0 +1,170 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+	"github.com/sumup-oss/go-pkgs/retry"
+)
+
+func TestRetryExecutor_Execute(t *testing.T) {
+	t.Run(
+		"it retries the failed command until it succeeds",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"kubectl",
+				[]string{"get", "pods"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("connection refused"), errors.New("exit status 1")).Twice()
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"kubectl",
+				[]string{"get", "pods"},
+				[]string(nil),
+				"",
+			).Return([]byte("api-0"), []byte{}, nil).Once()
+
+			retryExecutor := NewRetryExecutor(executorArg, retry.Policy{Backoff: retry.Constant(time.Millisecond)}, nil)
+			stdout, stderr, err := retryExecutor.Execute("kubectl", []string{"get", "pods"}, nil, "")
+			require.Nil(t, err)
+			assert.Equal(t, "api-0", string(stdout))
+			assert.Empty(t, stderr)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"when the failure is not retryable per filter, it returns the output of the failed command",
+		func(t *testing.T) {
+			t.Parallel()
+
+			fakeErr := errors.New("exit status 1")
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"kubectl",
+				[]string{"apply", "-f", "deployment.yaml"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("error validating data"), fakeErr).Once()
+
+			retryExecutor := NewRetryExecutor(
+				executorArg,
+				retry.Policy{MaxAttempts: 3},
+				func(stdout, stderr []byte, err error) bool {
+					return bytes.Contains(stderr, []byte("connection refused"))
+				},
+			)
+			stdout, stderr, err := retryExecutor.Execute("kubectl", []string{"apply", "-f", "deployment.yaml"}, nil, "")
+			assert.Equal(t, fakeErr, err)
+			assert.Empty(t, stdout)
+			assert.Equal(t, "error validating data", string(stderr))
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"when max attempts are exceeded, it returns the error of the last command",
+		func(t *testing.T) {
+			t.Parallel()
+
+			fakeErr := errors.New("exit status 1")
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"kubectl",
+				[]string{"get", "pods"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("connection refused"), fakeErr).Times(3)
+
+			retryExecutor := NewRetryExecutor(executorArg, retry.Policy{MaxAttempts: 3}, nil)
+			_, stderr, err := retryExecutor.Execute("kubectl", []string{"get", "pods"}, nil, "")
+			assert.Equal(t, fakeErr, err)
+			assert.Equal(t, "connection refused", string(stderr))
+			executorArg.AssertExpectations(t)
+		},
+	)
+}
+
+func TestRetryExecutor_ExecuteWithStdinContext(t *testing.T) {
+	t.Run(
+		"it retries the failed command with the whole stdin",
+		func(t *testing.T) {
+			t.Parallel()
+
+			stdin := []byte("kind: Secret")
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"kubectl",
+				[]string{"apply", "-f", "-"},
+				[]string(nil),
+				"",
+				bytes.NewReader(stdin),
+			).Run(func(args mock.Arguments) {
+				// NOTE: Consume the stdin, like the command would do.
+				_, _ = ioutil.ReadAll(args.Get(5).(io.Reader))
+			}).Return([]byte{}, []byte("connection refused"), errors.New("exit status 1")).Once()
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"kubectl",
+				[]string{"apply", "-f", "-"},
+				[]string(nil),
+				"",
+				bytes.NewReader(stdin),
+			).Return([]byte("secret/example configured"), []byte{}, nil).Once()
+
+			retryExecutor := NewRetryExecutor(executorArg, retry.Policy{Backoff: retry.Constant(time.Millisecond)}, nil)
+			stdout, _, err := retryExecutor.ExecuteWithStdinContext(
+				context.Background(),
+				"kubectl",
+				[]string{"apply", "-f", "-"},
+				nil,
+				"",
+				bytes.NewReader(stdin),
+			)
+			require.Nil(t, err)
+			assert.Equal(t, "secret/example configured", string(stdout))
+			executorArg.AssertExpectations(t)
+		},
+	)
+}
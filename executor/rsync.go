@@ -0,0 +1,198 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+// rsyncStatRegex matches the `--stats` lines, e.g `Number of created files: 2 (reg: 2)`.
+var rsyncStatRegex = regexp.MustCompile(`^([A-Za-z ]+): ([\d,]+)`)
+
+type RsyncOptions struct {
+	// Include and Exclude are filter patterns, e.g `*.tar.gz` or `/cache/`.
+	// Includes take precedence, since rsync applies the first matching pattern.
+	Include []string
+	Exclude []string
+	// Delete deletes the files of the destination which don't exist in the source.
+	Delete bool
+	// BandwidthLimit is the maximum transfer rate in KiB per second, when positive.
+	BandwidthLimit int
+	Compress       bool
+	// Checksum compares the files by checksum instead of modification time and size.
+	Checksum bool
+	DryRun   bool
+	// Ssh is the ssh transport of the remote paths. It's required by Upload and Download.
+	Ssh *SshOptions
+}
+
+type RsyncStats struct {
+	Files               int64
+	CreatedFiles        int64
+	DeletedFiles        int64
+	TransferredFiles    int64
+	TotalFileSize       int64
+	TransferredFileSize int64
+	LiteralDataSize     int64
+	MatchedDataSize     int64
+	BytesSent           int64
+	BytesReceived       int64
+}
+
+type Rsync struct {
+	binPath         string
+	commandExecutor os.CommandExecutor
+}
+
+func NewRsync(executor os.CommandExecutor) *Rsync {
+	return &Rsync{
+		binPath:         "rsync",
+		commandExecutor: executor,
+	}
+}
+
+// Upload synchronizes the local path to the remote path of the ssh host.
+func (rsync *Rsync) Upload(ctx context.Context, localPath, remotePath string, options *RsyncOptions) (*RsyncStats, error) {
+	if options == nil || options.Ssh == nil {
+		return nil, stacktrace.NewError("no ssh options to upload with")
+	}
+
+	return rsync.Sync(ctx, localPath, NewSsh(nil, options.Ssh).remoteAddress(remotePath), options)
+}
+
+// Download synchronizes the remote path of the ssh host to the local path.
+func (rsync *Rsync) Download(ctx context.Context, remotePath, localPath string, options *RsyncOptions) (*RsyncStats, error) {
+	if options == nil || options.Ssh == nil {
+		return nil, stacktrace.NewError("no ssh options to download with")
+	}
+
+	return rsync.Sync(ctx, NewSsh(nil, options.Ssh).remoteAddress(remotePath), localPath, options)
+}
+
+// Sync synchronizes the source to the destination in archive mode and returns the transfer statistics.
+// Same as rsync, a trailing slash of the source copies its contents instead of the directory itself.
+func (rsync *Rsync) Sync(ctx context.Context, source, destination string, options *RsyncOptions) (*RsyncStats, error) {
+	args := []string{"--archive", "--stats"}
+
+	var env []string
+
+	if options != nil {
+		for _, pattern := range options.Include {
+			args = append(args, "--include", pattern)
+		}
+
+		for _, pattern := range options.Exclude {
+			args = append(args, "--exclude", pattern)
+		}
+
+		if options.Delete {
+			args = append(args, "--delete")
+		}
+
+		if options.BandwidthLimit > 0 {
+			args = append(args, "--bwlimit", strconv.Itoa(options.BandwidthLimit))
+		}
+
+		if options.Compress {
+			args = append(args, "--compress")
+		}
+
+		if options.Checksum {
+			args = append(args, "--checksum")
+		}
+
+		if options.DryRun {
+			args = append(args, "--dry-run")
+		}
+
+		if options.Ssh != nil {
+			ssh := NewSsh(nil, options.Ssh)
+			args = append(args, "--rsh", rsyncRemoteShell(ssh))
+			env = ssh.env()
+		}
+	}
+
+	args = append(args, source, destination)
+
+	stdout, stderr, err := rsync.commandExecutor.ExecuteContext(ctx, rsync.binPath, args, env, "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return parseRsyncStats(stdout), nil
+}
+
+// rsyncRemoteShell compiles the ssh command rsync connects with.
+func rsyncRemoteShell(ssh *Ssh) string {
+	words := []string{ssh.sshBinPath}
+
+	for _, option := range ssh.connectionOptions() {
+		words = append(words, shellQuote(option))
+	}
+
+	if ssh.options.Port > 0 {
+		words = append(words, "-p", strconv.Itoa(ssh.options.Port))
+	}
+
+	return strings.Join(words, " ")
+}
+
+func parseRsyncStats(output []byte) *RsyncStats {
+	stats := &RsyncStats{}
+
+	fields := map[string]*int64{
+		"Number of files":                     &stats.Files,
+		"Number of created files":             &stats.CreatedFiles,
+		"Number of deleted files":             &stats.DeletedFiles,
+		"Number of regular files transferred": &stats.TransferredFiles,
+		// NOTE: rsync before 3.1 reports the transferred files under this name.
+		"Number of files transferred": &stats.TransferredFiles,
+		"Total file size":             &stats.TotalFileSize,
+		"Total transferred file size": &stats.TransferredFileSize,
+		"Literal data":                &stats.LiteralDataSize,
+		"Matched data":                &stats.MatchedDataSize,
+		"Total bytes sent":            &stats.BytesSent,
+		"Total bytes received":        &stats.BytesReceived,
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		match := rsyncStatRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+
+		field, ok := fields[match[1]]
+		if !ok {
+			continue
+		}
+
+		// NOTE: rsync 3.1+ formats the numbers with thousands separators.
+		value, err := strconv.ParseInt(strings.Replace(match[2], ",", "", -1), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		*field = value
+	}
+
+	return stats
+}
@@ -0,0 +1,218 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+const fakeRsyncStatsOutput = `
+Number of files: 1,205 (reg: 1,180, dir: 25)
+Number of created files: 12 (reg: 12)
+Number of deleted files: 3 (reg: 3)
+Number of regular files transferred: 15
+Total file size: 52,428,800 bytes
+Total transferred file size: 1,048,576 bytes
+Literal data: 524,288 bytes
+Matched data: 524,288 bytes
+File list size: 32,768
+File list generation time: 0.001 seconds
+File list transfer time: 0.000 seconds
+Total bytes sent: 560,000
+Total bytes received: 1,024
+
+sent 560,000 bytes  received 1,024 bytes  374,016.00 bytes/sec
+total size is 52,428,800  speedup is 93.45
+`
+
+func TestRsync_Sync(t *testing.T) {
+	t.Run(
+		"it passes the filters and options and parses the transfer statistics",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"rsync",
+				[]string{
+					"--archive", "--stats",
+					"--include", "*.tar.gz",
+					"--exclude", "*",
+					"--delete",
+					"--bwlimit", "1024",
+					"--compress",
+					"--checksum",
+					"--dry-run",
+					"dist/", "/srv/artifacts",
+				},
+				[]string(nil),
+				"",
+			).Return([]byte(fakeRsyncStatsOutput), []byte{}, nil)
+
+			rsyncInstance := NewRsync(executorArg)
+			actual, actualErr := rsyncInstance.Sync(
+				context.Background(),
+				"dist/",
+				"/srv/artifacts",
+				&RsyncOptions{
+					Include:        []string{"*.tar.gz"},
+					Exclude:        []string{"*"},
+					Delete:         true,
+					BandwidthLimit: 1024,
+					Compress:       true,
+					Checksum:       true,
+					DryRun:         true,
+				},
+			)
+			require.Nil(t, actualErr)
+			assert.Equal(
+				t,
+				&RsyncStats{
+					Files:               1205,
+					CreatedFiles:        12,
+					DeletedFiles:        3,
+					TransferredFiles:    15,
+					TotalFileSize:       52428800,
+					TransferredFileSize: 1048576,
+					LiteralDataSize:     524288,
+					MatchedDataSize:     524288,
+					BytesSent:           560000,
+					BytesReceived:       1024,
+				},
+				actual,
+			)
+		},
+	)
+
+	t.Run(
+		"when sync fails, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"rsync",
+				[]string{"--archive", "--stats", "dist/", "/srv/artifacts"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("rsync: change_dir \"dist\" failed"), errors.New("exit status 23"))
+
+			rsyncInstance := NewRsync(executorArg)
+			actual, actualErr := rsyncInstance.Sync(context.Background(), "dist/", "/srv/artifacts", nil)
+			require.NotNil(t, actualErr)
+			assert.Nil(t, actual)
+			assert.Contains(t, actualErr.Error(), "change_dir")
+		},
+	)
+}
+
+func TestRsync_Upload(t *testing.T) {
+	t.Run(
+		"it syncs to the ssh host over the ssh transport",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"rsync",
+				[]string{
+					"--archive", "--stats",
+					"--rsh", "ssh -o BatchMode=yes -o StrictHostKeyChecking=accept-new -o ConnectTimeout=5 " +
+						"-i '/home/deploy/.ssh/id ed25519' -o IdentitiesOnly=yes -p 2222",
+					"dist/", "deploy@edge-1.example.com:/srv/artifacts",
+				},
+				[]string(nil),
+				"",
+			).Return([]byte(fakeRsyncStatsOutput), []byte{}, nil)
+
+			rsyncInstance := NewRsync(executorArg)
+			actual, actualErr := rsyncInstance.Upload(
+				context.Background(),
+				"dist/",
+				"/srv/artifacts",
+				&RsyncOptions{
+					Ssh: &SshOptions{
+						Host:            "edge-1.example.com",
+						User:            "deploy",
+						Port:            2222,
+						IdentityFile:    "/home/deploy/.ssh/id ed25519",
+						HostKeyChecking: SshHostKeyCheckingAcceptNew,
+						ConnectTimeout:  5 * time.Second,
+					},
+				},
+			)
+			require.Nil(t, actualErr)
+			assert.Equal(t, int64(15), actual.TransferredFiles)
+		},
+	)
+
+	t.Run(
+		"without ssh options, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			rsyncInstance := NewRsync(&ostest.FakeOsExecutor{})
+			actual, actualErr := rsyncInstance.Upload(context.Background(), "dist/", "/srv/artifacts", nil)
+			require.NotNil(t, actualErr)
+			assert.Nil(t, actual)
+		},
+	)
+}
+
+func TestRsync_Download(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"rsync",
+		[]string{
+			"--archive", "--stats",
+			"--exclude", "*.tmp",
+			"--rsh", "ssh -o BatchMode=yes -o StrictHostKeyChecking=yes",
+			"edge-1.example.com:/var/log/agent/", "logs/",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte("Number of files transferred: 4\n"), []byte{}, nil)
+
+	rsyncInstance := NewRsync(executorArg)
+	actual, actualErr := rsyncInstance.Download(
+		context.Background(),
+		"/var/log/agent/",
+		"logs/",
+		&RsyncOptions{
+			Exclude: []string{"*.tmp"},
+			Ssh:     &SshOptions{Host: "edge-1.example.com"},
+		},
+	)
+	require.Nil(t, actualErr)
+	assert.Equal(t, int64(4), actual.TransferredFiles)
+}
@@ -0,0 +1,363 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	stdOs "os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+// skopeoTransports are the image reference transports, e.g `oci:/path/to/layout:tag`.
+var skopeoTransports = []string{
+	"docker://",
+	"docker-archive:",
+	"docker-daemon:",
+	"oci:",
+	"oci-archive:",
+	"dir:",
+	"containers-storage:",
+}
+
+// SkopeoRegistryOptions are the access options of an image registry.
+type SkopeoRegistryOptions struct {
+	// Username and Password are the registry credentials, instead of AuthFile.
+	// They are passed by a temporary auth file, so that they are not visible in the process list
+	// or the logs of the command.
+	Username string
+	Password string
+	// AuthFile is a registry auth file, e.g `~/.docker/config.json`.
+	AuthFile string
+	// Insecure skips the TLS verification and allows plain HTTP.
+	Insecure bool
+}
+
+type SkopeoCopyOptions struct {
+	Source      *SkopeoRegistryOptions
+	Destination *SkopeoRegistryOptions
+	// All copies all images of a multi-arch image, instead of the one of the platform.
+	All bool
+	// OS, Arch and Variant select the platform image of a multi-arch image, when not the current platform.
+	OS      string
+	Arch    string
+	Variant string
+	// PreserveDigests fails the copy when it would change the image digest, e.g by conversion.
+	PreserveDigests bool
+	// RetryTimes retries the copy on network failures, when positive.
+	RetryTimes int
+}
+
+type SkopeoImageInfo struct {
+	Name         string            `json:"Name"`
+	Digest       string            `json:"Digest"`
+	RepoTags     []string          `json:"RepoTags"`
+	Created      *time.Time        `json:"Created"`
+	Labels       map[string]string `json:"Labels"`
+	Architecture string            `json:"Architecture"`
+	Os           string            `json:"Os"`
+	Layers       []string          `json:"Layers"`
+	Env          []string          `json:"Env"`
+}
+
+// SkopeoManifest is an image manifest or, for multi-arch images, an image index.
+type SkopeoManifest struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	MediaType     string `json:"mediaType"`
+	// Manifests are the platform images of an image index.
+	Manifests []*SkopeoManifestDescriptor `json:"manifests"`
+	Config    *SkopeoManifestDescriptor   `json:"config"`
+	Layers    []*SkopeoManifestDescriptor `json:"layers"`
+}
+
+type SkopeoManifestDescriptor struct {
+	MediaType string          `json:"mediaType"`
+	Digest    string          `json:"digest"`
+	Size      int64           `json:"size"`
+	Platform  *SkopeoPlatform `json:"platform"`
+}
+
+type SkopeoPlatform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant"`
+}
+
+// IsIndex returns whether the manifest is an image index of a multi-arch image.
+func (manifest *SkopeoManifest) IsIndex() bool {
+	return len(manifest.Manifests) > 0
+}
+
+// Skopeo operates on images in registries without pulling them locally.
+// Image references without a transport are registry ones, e.g `registry.example.com/api:1.0.0`.
+type Skopeo struct {
+	binPath         string
+	commandExecutor os.CommandExecutor
+}
+
+func NewSkopeo(executor os.CommandExecutor) *Skopeo {
+	return &Skopeo{
+		binPath:         "skopeo",
+		commandExecutor: executor,
+	}
+}
+
+// Copy copies the image between registries, e.g to promote it from staging to production.
+func (skopeo *Skopeo) Copy(ctx context.Context, source, destination string, options *SkopeoCopyOptions) error {
+	var args []string
+
+	if options == nil {
+		options = &SkopeoCopyOptions{}
+	}
+
+	// NOTE: The platform overrides are global options, so they precede the command.
+	if options.OS != "" {
+		args = append(args, "--override-os", options.OS)
+	}
+
+	if options.Arch != "" {
+		args = append(args, "--override-arch", options.Arch)
+	}
+
+	if options.Variant != "" {
+		args = append(args, "--override-variant", options.Variant)
+	}
+
+	sourceArgs, cleanupSource, err := skopeoRegistryArgs("--src-", source, options.Source)
+	if err != nil {
+		return err
+	}
+
+	defer cleanupSource()
+
+	destinationArgs, cleanupDestination, err := skopeoRegistryArgs("--dest-", destination, options.Destination)
+	if err != nil {
+		return err
+	}
+
+	defer cleanupDestination()
+
+	args = append(args, "copy")
+	args = append(args, sourceArgs...)
+	args = append(args, destinationArgs...)
+
+	if options.All {
+		args = append(args, "--all")
+	}
+
+	if options.PreserveDigests {
+		args = append(args, "--preserve-digests")
+	}
+
+	if options.RetryTimes > 0 {
+		args = append(args, "--retry-times", strconv.Itoa(options.RetryTimes))
+	}
+
+	args = append(args, skopeoReference(source), skopeoReference(destination))
+
+	_, err = skopeo.execute(ctx, args...)
+	return err
+}
+
+// Inspect returns the image configuration and digest.
+func (skopeo *Skopeo) Inspect(ctx context.Context, image string, options *SkopeoRegistryOptions) (*SkopeoImageInfo, error) {
+	registryArgs, cleanup, err := skopeoRegistryArgs("--", image, options)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cleanup()
+
+	args := append([]string{"inspect"}, registryArgs...)
+	args = append(args, skopeoReference(image))
+
+	stdout, err := skopeo.execute(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var info SkopeoImageInfo
+
+	err = json.Unmarshal(stdout, &info)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "json decode command `skopeo inspect %s` output failed", image)
+	}
+
+	return &info, nil
+}
+
+// InspectManifest returns the raw image manifest, e.g to list the platforms of a multi-arch image.
+func (skopeo *Skopeo) InspectManifest(
+	ctx context.Context,
+	image string,
+	options *SkopeoRegistryOptions,
+) (*SkopeoManifest, error) {
+	registryArgs, cleanup, err := skopeoRegistryArgs("--", image, options)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cleanup()
+
+	args := append([]string{"inspect", "--raw"}, registryArgs...)
+	args = append(args, skopeoReference(image))
+
+	stdout, err := skopeo.execute(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest SkopeoManifest
+
+	err = json.Unmarshal(stdout, &manifest)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "json decode command `skopeo inspect --raw %s` output failed", image)
+	}
+
+	return &manifest, nil
+}
+
+// DeleteTag deletes the image tag from the registry.
+// Registries delete the image manifest, so the rest of the tags of the same image may be deleted too.
+func (skopeo *Skopeo) DeleteTag(ctx context.Context, image string, options *SkopeoRegistryOptions) error {
+	registryArgs, cleanup, err := skopeoRegistryArgs("--", image, options)
+	if err != nil {
+		return err
+	}
+
+	defer cleanup()
+
+	args := append([]string{"delete"}, registryArgs...)
+	args = append(args, skopeoReference(image))
+
+	_, err = skopeo.execute(ctx, args...)
+	return err
+}
+
+func (skopeo *Skopeo) execute(ctx context.Context, args ...string) ([]byte, error) {
+	stdout, stderr, err := skopeo.commandExecutor.ExecuteContext(ctx, skopeo.binPath, args, nil, "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return stdout, nil
+}
+
+// skopeoRegistryArgs returns the registry access options of the image with the prefix, e.g `--src-` for
+// `--src-authfile`, along with the func removing the temporary auth file of the credentials, if any.
+func skopeoRegistryArgs(prefix, image string, options *SkopeoRegistryOptions) ([]string, func(), error) {
+	var args []string
+
+	cleanup := func() {}
+
+	if options == nil {
+		return args, cleanup, nil
+	}
+
+	authFile := options.AuthFile
+
+	registry := skopeoRegistry(image)
+	if options.Username != "" && registry != "" {
+		file, err := writeSkopeoAuthFile(registry, options.Username, options.Password)
+		if err != nil {
+			return nil, cleanup, err
+		}
+
+		authFile = file
+		cleanup = func() {
+			_ = stdOs.Remove(file)
+		}
+	}
+
+	if authFile != "" {
+		args = append(args, prefix+"authfile", authFile)
+	}
+
+	if options.Insecure {
+		args = append(args, prefix+"tls-verify=false")
+	}
+
+	return args, cleanup, nil
+}
+
+// skopeoRegistry returns the registry host of the image, e.g `docker.io` for `alpine:3`,
+// or blank string when the image is not in a registry, e.g `oci:/path/to/layout:tag`.
+func skopeoRegistry(image string) string {
+	reference := skopeoReference(image)
+	if !strings.HasPrefix(reference, "docker://") {
+		return ""
+	}
+
+	name := strings.TrimPrefix(reference, "docker://")
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 1 || (!strings.ContainsAny(parts[0], ".:") && parts[0] != "localhost") {
+		return "docker.io"
+	}
+
+	return parts[0]
+}
+
+// writeSkopeoAuthFile writes the credentials of the registry to a temporary auth file,
+// readable only by the user, and returns its path.
+func writeSkopeoAuthFile(registry, username, password string) (string, error) {
+	auth := map[string]map[string]map[string]string{
+		"auths": {
+			registry: {
+				"auth": base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+			},
+		},
+	}
+
+	content, err := json.Marshal(auth)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "failed to marshal skopeo auth file")
+	}
+
+	file, err := ioutil.TempFile("", "skopeo-auth-*.json")
+	if err != nil {
+		return "", stacktrace.Propagate(err, "failed to create skopeo auth file")
+	}
+
+	_, err = file.Write(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = stdOs.Remove(file.Name())
+		return "", stacktrace.Propagate(err, "failed to write skopeo auth file")
+	}
+
+	return file.Name(), nil
+}
+
+func skopeoReference(image string) string {
+	for _, transport := range skopeoTransports {
+		if strings.HasPrefix(image, transport) {
+			return image
+		}
+	}
+
+	return "docker://" + image
+}
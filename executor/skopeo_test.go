@@ -0,0 +1,231 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+// skopeoAuthFileEquals reports whether the auth file has the content.
+func skopeoAuthFileEquals(path, content string) bool {
+	actual, err := ioutil.ReadFile(path)
+	return err == nil && string(actual) == content
+}
+
+func TestSkopeo_Copy(t *testing.T) {
+	t.Run(
+		"it passes the platform, credentials and copy options",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"skopeo",
+				mock.MatchedBy(func(args []string) bool {
+					return len(args) == 17 &&
+						strings.Join(args[:10], " ") == "--override-os linux --override-arch arm64 "+
+							"--override-variant v8 copy --src-authfile /run/containers/auth.json --dest-authfile" &&
+						skopeoAuthFileEquals(args[10], `{"auths":{"registry.internal:5000":{"auth":"ZGVwbG95OnNlY3JldA=="}}}`) &&
+						strings.Join(args[11:], " ") == "--dest-tls-verify=false --preserve-digests --retry-times 3 "+
+							"docker://staging.example.com/api:1.0.0 docker://registry.internal:5000/api:1.0.0"
+				}),
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte{}, nil)
+
+			skopeoInstance := NewSkopeo(executorArg)
+			actual := skopeoInstance.Copy(
+				context.Background(),
+				"staging.example.com/api:1.0.0",
+				"docker://registry.internal:5000/api:1.0.0",
+				&SkopeoCopyOptions{
+					Source:          &SkopeoRegistryOptions{AuthFile: "/run/containers/auth.json"},
+					Destination:     &SkopeoRegistryOptions{Username: "deploy", Password: "secret", Insecure: true},
+					OS:              "linux",
+					Arch:            "arm64",
+					Variant:         "v8",
+					PreserveDigests: true,
+					RetryTimes:      3,
+				},
+			)
+			require.Nil(t, actual)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"with all, it copies all platform images",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"skopeo",
+				[]string{
+					"copy", "--all",
+					"docker://staging.example.com/api:1.0.0",
+					"oci-archive:/tmp/api.tar",
+				},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("manifest unknown"), errors.New("exit status 1"))
+
+			skopeoInstance := NewSkopeo(executorArg)
+			actual := skopeoInstance.Copy(
+				context.Background(),
+				"staging.example.com/api:1.0.0",
+				"oci-archive:/tmp/api.tar",
+				&SkopeoCopyOptions{All: true},
+			)
+			require.NotNil(t, actual)
+			assert.Contains(t, actual.Error(), "manifest unknown")
+		},
+	)
+}
+
+func TestSkopeo_Inspect(t *testing.T) {
+	t.Parallel()
+
+	output := `{
+    "Name": "registry.example.com/api",
+    "Digest": "sha256:6a92cd1fcdc8d8cdec60f33dda4db2cb1fcdcacf3410a8e05b3741f44a9b5998",
+    "RepoTags": ["1.0.0", "latest"],
+    "Created": "2019-10-01T12:30:00.123456789Z",
+    "Labels": {"org.opencontainers.image.revision": "abc123"},
+    "Architecture": "amd64",
+    "Os": "linux",
+    "Layers": ["sha256:31e352740f534f9ad170f75378a84fe453d6156e40700b882d737a8f4a6988a3"],
+    "Env": ["PATH=/usr/local/bin:/usr/bin"]
+}`
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"skopeo",
+		mock.MatchedBy(func(args []string) bool {
+			return len(args) == 4 &&
+				args[1] == "--authfile" &&
+				skopeoAuthFileEquals(args[2], `{"auths":{"registry.example.com":{"auth":"ZGVwbG95OnNlY3JldA=="}}}`) &&
+				args[3] == "docker://registry.example.com/api:1.0.0"
+		}),
+		[]string(nil),
+		"",
+	).Return([]byte(output), []byte{}, nil)
+
+	skopeoInstance := NewSkopeo(executorArg)
+	actual, actualErr := skopeoInstance.Inspect(
+		context.Background(),
+		"registry.example.com/api:1.0.0",
+		&SkopeoRegistryOptions{Username: "deploy", Password: "secret"},
+	)
+	require.Nil(t, actualErr)
+	assert.Equal(t, "sha256:6a92cd1fcdc8d8cdec60f33dda4db2cb1fcdcacf3410a8e05b3741f44a9b5998", actual.Digest)
+	assert.Equal(t, []string{"1.0.0", "latest"}, actual.RepoTags)
+	assert.Equal(t, "abc123", actual.Labels["org.opencontainers.image.revision"])
+	assert.Equal(t, "amd64", actual.Architecture)
+	assert.Equal(t, 2019, actual.Created.Year())
+}
+
+func TestSkopeo_InspectManifest(t *testing.T) {
+	t.Parallel()
+
+	output := `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.oci.image.index.v1+json",
+  "manifests": [
+    {
+      "mediaType": "application/vnd.oci.image.manifest.v1+json",
+      "digest": "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+      "size": 1024,
+      "platform": {"architecture": "amd64", "os": "linux"}
+    },
+    {
+      "mediaType": "application/vnd.oci.image.manifest.v1+json",
+      "digest": "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+      "size": 1024,
+      "platform": {"architecture": "arm64", "os": "linux", "variant": "v8"}
+    }
+  ]
+}`
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"skopeo",
+		[]string{"inspect", "--raw", "docker://registry.example.com/api:1.0.0"},
+		[]string(nil),
+		"",
+	).Return([]byte(output), []byte{}, nil)
+
+	skopeoInstance := NewSkopeo(executorArg)
+	actual, actualErr := skopeoInstance.InspectManifest(context.Background(), "registry.example.com/api:1.0.0", nil)
+	require.Nil(t, actualErr)
+	assert.True(t, actual.IsIndex())
+	require.Len(t, actual.Manifests, 2)
+	assert.Equal(
+		t,
+		&SkopeoPlatform{Architecture: "arm64", OS: "linux", Variant: "v8"},
+		actual.Manifests[1].Platform,
+	)
+}
+
+func TestSkopeo_DeleteTag(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"skopeo",
+		[]string{"delete", "--authfile", "auth.json", "docker://registry.example.com/api:pr-42"},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	skopeoInstance := NewSkopeo(executorArg)
+	actual := skopeoInstance.DeleteTag(
+		context.Background(),
+		"registry.example.com/api:pr-42",
+		&SkopeoRegistryOptions{AuthFile: "auth.json"},
+	)
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestSkopeoRegistry(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "docker.io", skopeoRegistry("alpine:3"))
+	assert.Equal(t, "docker.io", skopeoRegistry("library/alpine:3"))
+	assert.Equal(t, "localhost", skopeoRegistry("localhost/api:1.0.0"))
+	assert.Equal(t, "registry.internal:5000", skopeoRegistry("docker://registry.internal:5000/api:1.0.0"))
+	assert.Equal(t, "", skopeoRegistry("oci-archive:/tmp/api.tar"))
+}
@@ -0,0 +1,318 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	stdOs "os"
+	"path/filepath"
+	"strings"
+
+	"github.com/palantir/stacktrace"
+	"gopkg.in/yaml.v2"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+const (
+	SopsFormatYAML   = "yaml"
+	SopsFormatJSON   = "json"
+	SopsFormatDotenv = "dotenv"
+	SopsFormatBinary = "binary"
+)
+
+// SopsOptions configure the keys of sops. The encryption keys are only needed when there are no
+// matching creation rules in `.sops.yaml`.
+type SopsOptions struct {
+	KmsArns         []string
+	AgeRecipients   []string
+	PgpFingerprints []string
+	// AgeKeyFile is the path of the age identities used for decryption, when present.
+	AgeKeyFile string
+	// ConfigPath is the path of the `.sops.yaml` config, when present.
+	ConfigPath string
+}
+
+type Sops struct {
+	binPath         string
+	options         SopsOptions
+	commandExecutor os.CommandExecutor
+}
+
+// NewSops creates Sops instance. Encrypting and decrypting in-memory data requires the command executor
+// to implement os.StdinCommandExecutor.
+func NewSops(executor os.CommandExecutor, options *SopsOptions) *Sops {
+	sops := &Sops{
+		binPath:         "sops",
+		commandExecutor: executor,
+	}
+
+	if options != nil {
+		sops.options = *options
+	}
+
+	return sops
+}
+
+// DecryptFile returns the decrypted content of the file.
+func (sops *Sops) DecryptFile(ctx context.Context, path string) ([]byte, error) {
+	stdout, stderr, err := sops.commandExecutor.ExecuteContext(
+		ctx,
+		sops.binPath,
+		sops.arguments("--decrypt", path),
+		sops.env(),
+		"",
+	)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "decrypting %s failed. Stderr: %s", path, stderr)
+	}
+
+	return stdout, nil
+}
+
+// EncryptFile returns the encrypted content of the file. The file is not modified.
+func (sops *Sops) EncryptFile(ctx context.Context, path string) ([]byte, error) {
+	stdout, stderr, err := sops.commandExecutor.ExecuteContext(
+		ctx,
+		sops.binPath,
+		sops.arguments(append(sops.encryptionArguments(), path)...),
+		sops.env(),
+		"",
+	)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "encrypting %s failed. Stderr: %s", path, stderr)
+	}
+
+	return stdout, nil
+}
+
+// Decrypt returns the decrypted data of `format`, e.g SopsFormatYAML.
+func (sops *Sops) Decrypt(ctx context.Context, data []byte, format string) ([]byte, error) {
+	return sops.executeWithStdin(
+		ctx,
+		data,
+		"--decrypt",
+		"--input-type", format,
+		"--output-type", format,
+		"/dev/stdin",
+	)
+}
+
+// Encrypt returns the encrypted data of `format`, e.g SopsFormatYAML.
+// The creation rules are matched against `filename`, when present.
+func (sops *Sops) Encrypt(ctx context.Context, data []byte, format, filename string) ([]byte, error) {
+	args := append(sops.encryptionArguments(), "--input-type", format, "--output-type", format)
+	if filename != "" {
+		args = append(args, "--filename-override", filename)
+	}
+
+	return sops.executeWithStdin(ctx, data, append(args, "/dev/stdin")...)
+}
+
+// EditInPlace decrypts the file, passes its content to `edit` and encrypts the edited content back
+// to the file, without writing the decrypted content to disk.
+// The file is encrypted with its own keys and key groups, not the configured keys or creation rules,
+// and it's replaced atomically, so that it's never left partially written.
+func (sops *Sops) EditInPlace(ctx context.Context, path string, edit func(plaintext []byte) ([]byte, error)) error {
+	encrypted, err := ioutil.ReadFile(path)
+	if err != nil {
+		return stacktrace.Propagate(err, "failed to read %s", path)
+	}
+
+	format := SopsFormatFromPath(path)
+
+	metadata, err := parseSopsMetadata(encrypted, format)
+	if err != nil {
+		return stacktrace.Propagate(err, "failed to read the keys of %s", path)
+	}
+
+	plaintext, err := sops.DecryptFile(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	edited, err := edit(plaintext)
+	if err != nil {
+		return stacktrace.Propagate(err, "editing %s failed", path)
+	}
+
+	if bytes.Equal(plaintext, edited) {
+		return nil
+	}
+
+	encrypted, err = sops.encryptWithMetadata(ctx, edited, format, metadata)
+	if err != nil {
+		return stacktrace.Propagate(err, "encrypting %s failed", path)
+	}
+
+	return writeFileAtomically(path, encrypted)
+}
+
+// encryptWithMetadata encrypts the data with the keys of the metadata of another file,
+// by passing them as the only creation rule of a temporary config.
+func (sops *Sops) encryptWithMetadata(
+	ctx context.Context,
+	data []byte,
+	format string,
+	metadata *sopsMetadata,
+) ([]byte, error) {
+	config, err := yaml.Marshal(metadata.config())
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "failed to marshal sops config")
+	}
+
+	configFile, err := ioutil.TempFile("", "sops-*.yaml")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "failed to create sops config")
+	}
+
+	defer stdOs.Remove(configFile.Name())
+
+	_, err = configFile.Write(config)
+	if closeErr := configFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "failed to write sops config")
+	}
+
+	return sops.executeWithStdin(
+		ctx,
+		data,
+		"--config", configFile.Name(),
+		"--encrypt",
+		"--input-type", format,
+		"--output-type", format,
+		"/dev/stdin",
+	)
+}
+
+// writeFileAtomically writes the data to a temporary file next to `path` and renames it to `path`,
+// keeping the mode of the file.
+func writeFileAtomically(path string, data []byte) error {
+	info, err := stdOs.Stat(path)
+	if err != nil {
+		return stacktrace.Propagate(err, "failed to stat %s", path)
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return stacktrace.Propagate(err, "failed to create temporary file for %s", path)
+	}
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Chmod(info.Mode())
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = stdOs.Rename(file.Name(), path)
+	}
+
+	if err != nil {
+		_ = stdOs.Remove(file.Name())
+		return stacktrace.Propagate(err, "failed to write %s", path)
+	}
+
+	return nil
+}
+
+// ApplyDecrypted decrypts the manifest file and applies it with applier, e.g Kubectl,
+// without writing the decrypted content to disk.
+func (sops *Sops) ApplyDecrypted(ctx context.Context, path string, applier ManifestApplier, namespace string) error {
+	manifest, err := sops.DecryptFile(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	err = applier.ApplyData(manifest, namespace)
+	return stacktrace.Propagate(err, "applying decrypted %s failed", path)
+}
+
+// SopsFormatFromPath returns the format of the file by its extension, the same way sops does.
+func SopsFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return SopsFormatYAML
+	case ".json":
+		return SopsFormatJSON
+	case ".env":
+		return SopsFormatDotenv
+	default:
+		return SopsFormatBinary
+	}
+}
+
+func (sops *Sops) executeWithStdin(ctx context.Context, data []byte, args ...string) ([]byte, error) {
+	stdinExecutor, ok := sops.commandExecutor.(os.StdinCommandExecutor)
+	if !ok {
+		return nil, stacktrace.NewError("command executor does not support executing with stdin")
+	}
+
+	stdout, stderr, err := stdinExecutor.ExecuteWithStdinContext(
+		ctx,
+		sops.binPath,
+		sops.arguments(args...),
+		sops.env(),
+		"",
+		bytes.NewReader(data),
+	)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s", stderr)
+	}
+
+	return stdout, nil
+}
+
+func (sops *Sops) encryptionArguments() []string {
+	args := []string{"--encrypt"}
+
+	if len(sops.options.KmsArns) > 0 {
+		args = append(args, "--kms", strings.Join(sops.options.KmsArns, ","))
+	}
+
+	if len(sops.options.AgeRecipients) > 0 {
+		args = append(args, "--age", strings.Join(sops.options.AgeRecipients, ","))
+	}
+
+	if len(sops.options.PgpFingerprints) > 0 {
+		args = append(args, "--pgp", strings.Join(sops.options.PgpFingerprints, ","))
+	}
+
+	return args
+}
+
+func (sops *Sops) arguments(args ...string) []string {
+	if sops.options.ConfigPath == "" {
+		return args
+	}
+
+	return append([]string{"--config", sops.options.ConfigPath}, args...)
+}
+
+func (sops *Sops) env() []string {
+	if sops.options.AgeKeyFile == "" {
+		return nil
+	}
+
+	return os.CommandEnv("SOPS_AGE_KEY_FILE=" + sops.options.AgeKeyFile)
+}
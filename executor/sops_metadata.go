@@ -0,0 +1,273 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/palantir/stacktrace"
+	"gopkg.in/yaml.v2"
+)
+
+// sopsMetadata is the `sops` metadata of an encrypted file, that has the keys the data key is encrypted with.
+// The keys are either in key groups, or when there's a single group, at the top level of the metadata.
+type sopsMetadata struct {
+	sopsKeyGroup `yaml:",inline"`
+
+	KeyGroups         []sopsKeyGroup `yaml:"key_groups"`
+	ShamirThreshold   int            `yaml:"shamir_threshold"`
+	UnencryptedSuffix string         `yaml:"unencrypted_suffix"`
+	EncryptedSuffix   string         `yaml:"encrypted_suffix"`
+	UnencryptedRegex  string         `yaml:"unencrypted_regex"`
+	EncryptedRegex    string         `yaml:"encrypted_regex"`
+}
+
+type sopsKeyGroup struct {
+	Kms    []sopsKmsKey `yaml:"kms"`
+	GcpKms []struct {
+		ResourceID string `yaml:"resource_id"`
+	} `yaml:"gcp_kms"`
+	AzureKv []struct {
+		VaultURL string `yaml:"vault_url"`
+		Name     string `yaml:"name"`
+		Version  string `yaml:"version"`
+	} `yaml:"azure_kv"`
+	HcVault []struct {
+		VaultAddress string `yaml:"vault_address"`
+		EnginePath   string `yaml:"engine_path"`
+		KeyName      string `yaml:"key_name"`
+	} `yaml:"hc_vault"`
+	Age []struct {
+		Recipient string `yaml:"recipient"`
+	} `yaml:"age"`
+	Pgp []struct {
+		Fingerprint string `yaml:"fp"`
+	} `yaml:"pgp"`
+}
+
+type sopsKmsKey struct {
+	Arn        string            `yaml:"arn"`
+	Role       string            `yaml:"role,omitempty"`
+	Context    map[string]string `yaml:"context,omitempty"`
+	AwsProfile string            `yaml:"aws_profile,omitempty"`
+}
+
+// sopsConfig is a `.sops.yaml` config with a single creation rule, that matches every file.
+type sopsConfig struct {
+	CreationRules []sopsCreationRule `yaml:"creation_rules"`
+}
+
+type sopsCreationRule struct {
+	KeyGroups         []sopsConfigKeyGroup `yaml:"key_groups"`
+	ShamirThreshold   int                  `yaml:"shamir_threshold,omitempty"`
+	UnencryptedSuffix string               `yaml:"unencrypted_suffix,omitempty"`
+	EncryptedSuffix   string               `yaml:"encrypted_suffix,omitempty"`
+	UnencryptedRegex  string               `yaml:"unencrypted_regex,omitempty"`
+	EncryptedRegex    string               `yaml:"encrypted_regex,omitempty"`
+}
+
+type sopsConfigKeyGroup struct {
+	Kms           []sopsKmsKey           `yaml:"kms,omitempty"`
+	GcpKms        []sopsConfigGcpKmsKey  `yaml:"gcp_kms,omitempty"`
+	AzureKeyVault []sopsConfigAzureKvKey `yaml:"azure_keyvault,omitempty"`
+	HcVault       []string               `yaml:"hc_vault,omitempty"`
+	Age           []string               `yaml:"age,omitempty"`
+	Pgp           []string               `yaml:"pgp,omitempty"`
+}
+
+type sopsConfigGcpKmsKey struct {
+	ResourceID string `yaml:"resource_id"`
+}
+
+type sopsConfigAzureKvKey struct {
+	VaultURL string `yaml:"vaultUrl"`
+	Key      string `yaml:"key"`
+	Version  string `yaml:"version"`
+}
+
+// parseSopsMetadata returns the metadata of the encrypted file content of `format`.
+func parseSopsMetadata(data []byte, format string) (*sopsMetadata, error) {
+	var err error
+
+	// NOTE: The metadata of dotenv files is flattened to `sops_`-prefixed variables,
+	// the other formats, including the JSON of binary files, are YAML documents.
+	if format == SopsFormatDotenv {
+		data, err = unflattenSopsDotenvMetadata(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var document struct {
+		Sops *sopsMetadata `yaml:"sops"`
+	}
+
+	err = yaml.Unmarshal(data, &document)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "failed to parse sops metadata")
+	}
+
+	if document.Sops == nil {
+		return nil, stacktrace.NewError("no sops metadata found")
+	}
+
+	return document.Sops, nil
+}
+
+// config returns a config, that encrypts files with the same keys and settings as the metadata.
+func (metadata *sopsMetadata) config() *sopsConfig {
+	groups := metadata.KeyGroups
+	if len(groups) == 0 {
+		groups = []sopsKeyGroup{metadata.sopsKeyGroup}
+	}
+
+	rule := sopsCreationRule{
+		ShamirThreshold:   metadata.ShamirThreshold,
+		UnencryptedSuffix: metadata.UnencryptedSuffix,
+		EncryptedSuffix:   metadata.EncryptedSuffix,
+		UnencryptedRegex:  metadata.UnencryptedRegex,
+		EncryptedRegex:    metadata.EncryptedRegex,
+	}
+
+	for _, group := range groups {
+		configGroup := sopsConfigKeyGroup{Kms: group.Kms}
+
+		for _, key := range group.GcpKms {
+			configGroup.GcpKms = append(configGroup.GcpKms, sopsConfigGcpKmsKey{ResourceID: key.ResourceID})
+		}
+
+		for _, key := range group.AzureKv {
+			configGroup.AzureKeyVault = append(
+				configGroup.AzureKeyVault,
+				sopsConfigAzureKvKey{VaultURL: key.VaultURL, Key: key.Name, Version: key.Version},
+			)
+		}
+
+		for _, key := range group.HcVault {
+			configGroup.HcVault = append(
+				configGroup.HcVault,
+				fmt.Sprintf("%s/v1/%s/keys/%s", key.VaultAddress, key.EnginePath, key.KeyName),
+			)
+		}
+
+		for _, key := range group.Age {
+			configGroup.Age = append(configGroup.Age, key.Recipient)
+		}
+
+		for _, key := range group.Pgp {
+			configGroup.Pgp = append(configGroup.Pgp, key.Fingerprint)
+		}
+
+		rule.KeyGroups = append(rule.KeyGroups, configGroup)
+	}
+
+	return &sopsConfig{CreationRules: []sopsCreationRule{rule}}
+}
+
+// unflattenSopsDotenvMetadata converts the flattened metadata variables of a dotenv file,
+// e.g `sops_kms__list_0__map_arn=...`, to a YAML document with `sops` metadata.
+func unflattenSopsDotenvMetadata(data []byte) ([]byte, error) {
+	metadata := map[string]interface{}{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "sops_") {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(line, "sops_"), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		var value interface{} = parts[1]
+		if number, err := strconv.Atoi(parts[1]); err == nil {
+			value = number
+		}
+
+		setFlattenedValue(metadata, strings.Split(parts[0], "__"), value)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "failed to read dotenv sops metadata")
+	}
+
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+
+	document, err := yaml.Marshal(map[string]interface{}{"sops": unflattenLists(metadata)})
+	return document, stacktrace.Propagate(err, "failed to convert dotenv sops metadata")
+}
+
+func setFlattenedValue(node map[string]interface{}, keys []string, value interface{}) {
+	key := strings.TrimPrefix(keys[0], "map_")
+
+	if len(keys) == 1 {
+		node[key] = value
+		return
+	}
+
+	child, ok := node[key].(map[string]interface{})
+	if !ok {
+		child = map[string]interface{}{}
+		node[key] = child
+	}
+
+	setFlattenedValue(child, keys[1:], value)
+}
+
+// unflattenLists converts the nodes with `list_<index>` keys to lists.
+func unflattenLists(value interface{}) interface{} {
+	node, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+
+	indexes := make([]int, 0, len(node))
+	isList := len(node) > 0
+
+	for key, child := range node {
+		node[key] = unflattenLists(child)
+
+		index, err := strconv.Atoi(strings.TrimPrefix(key, "list_"))
+		if err != nil || !strings.HasPrefix(key, "list_") {
+			isList = false
+			continue
+		}
+
+		indexes = append(indexes, index)
+	}
+
+	if !isList {
+		return node
+	}
+
+	sort.Ints(indexes)
+
+	list := make([]interface{}, 0, len(indexes))
+	for _, index := range indexes {
+		list = append(list, node[fmt.Sprintf("list_%d", index)])
+	}
+
+	return list
+}
@@ -0,0 +1,411 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+type fakeManifestApplier struct {
+	mock.Mock
+}
+
+func (f *fakeManifestApplier) ApplyData(manifest []byte, namespace string) error {
+	args := f.Called(manifest, namespace)
+	return args.Error(0)
+}
+
+func TestSops_DecryptFile(t *testing.T) {
+	t.Run(
+		"it passes the config and returns decrypted content",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"sops",
+				[]string{"--config", "/tmp/.sops.yaml", "--decrypt", "secrets.yaml"},
+				[]string(nil),
+				"",
+			).Return([]byte("password: secret\n"), []byte{}, nil)
+
+			sopsInstance := NewSops(executorArg, &SopsOptions{ConfigPath: "/tmp/.sops.yaml"})
+			actual, actualErr := sopsInstance.DecryptFile(context.Background(), "secrets.yaml")
+			require.Nil(t, actualErr)
+			assert.Equal(t, "password: secret\n", string(actual))
+		},
+	)
+
+	t.Run(
+		"with age key file, it passes it through env",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"sops",
+				[]string{"--decrypt", "secrets.yaml"},
+				mock.MatchedBy(func(env []string) bool {
+					return containsString(env, "SOPS_AGE_KEY_FILE=/tmp/keys.txt")
+				}),
+				"",
+			).Return([]byte{}, []byte("fake stderr"), errors.New("fake error"))
+
+			sopsInstance := NewSops(executorArg, &SopsOptions{AgeKeyFile: "/tmp/keys.txt"})
+			actual, actualErr := sopsInstance.DecryptFile(context.Background(), "secrets.yaml")
+			require.NotNil(t, actualErr)
+			assert.Nil(t, actual)
+			assert.Contains(t, actualErr.Error(), "fake stderr")
+		},
+	)
+}
+
+func TestSops_EncryptFile(t *testing.T) {
+	t.Parallel()
+
+	executorArg := ostest.NewFakeOsExecutor(t)
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"sops",
+		[]string{
+			"--encrypt",
+			"--kms", "arn:aws:kms:eu-west-1:123456789012:key/a,arn:aws:kms:eu-west-1:123456789012:key/b",
+			"--age", "age1fake",
+			"secrets.yaml",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte("password: ENC[fake]\n"), []byte{}, nil)
+
+	sopsInstance := NewSops(
+		executorArg,
+		&SopsOptions{
+			KmsArns: []string{
+				"arn:aws:kms:eu-west-1:123456789012:key/a",
+				"arn:aws:kms:eu-west-1:123456789012:key/b",
+			},
+			AgeRecipients: []string{"age1fake"},
+		},
+	)
+	actual, actualErr := sopsInstance.EncryptFile(context.Background(), "secrets.yaml")
+	require.Nil(t, actualErr)
+	assert.Equal(t, "password: ENC[fake]\n", string(actual))
+}
+
+func TestSops_Decrypt(t *testing.T) {
+	t.Parallel()
+
+	executorArg := ostest.NewFakeOsExecutor(t)
+	executorArg.On(
+		"ExecuteWithStdinContext",
+		context.Background(),
+		"sops",
+		[]string{"--decrypt", "--input-type", "json", "--output-type", "json", "/dev/stdin"},
+		[]string(nil),
+		"",
+		bytes.NewReader([]byte(`{"password": "ENC[fake]"}`)),
+	).Return([]byte(`{"password": "secret"}`), []byte{}, nil)
+
+	sopsInstance := NewSops(executorArg, nil)
+	actual, actualErr := sopsInstance.Decrypt(context.Background(), []byte(`{"password": "ENC[fake]"}`), SopsFormatJSON)
+	require.Nil(t, actualErr)
+	assert.Equal(t, `{"password": "secret"}`, string(actual))
+}
+
+func TestSops_EditInPlace(t *testing.T) {
+	t.Run(
+		"it encrypts the edited content back to the file with the key groups of the file",
+		func(t *testing.T) {
+			t.Parallel()
+
+			dir, err := ioutil.TempDir("", "sops")
+			require.Nil(t, err)
+
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "secrets.yaml")
+			content := "password: ENC[old]\n" +
+				"sops:\n" +
+				"  key_groups:\n" +
+				"  - kms:\n" +
+				"    - arn: arn:aws:kms:eu-west-1:123:key/abc\n" +
+				"      enc: ENC[kms]\n" +
+				"  - age:\n" +
+				"    - recipient: age1example\n" +
+				"      enc: ENC[age]\n" +
+				"  shamir_threshold: 2\n" +
+				"  encrypted_regex: ^password$\n"
+			require.Nil(t, ioutil.WriteFile(path, []byte(content), 0640))
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"sops",
+				[]string{"--decrypt", path},
+				[]string(nil),
+				"",
+			).Return([]byte("password: old\n"), []byte{}, nil)
+
+			var config string
+
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"sops",
+				mock.MatchedBy(func(args []string) bool {
+					return len(args) == 8 &&
+						args[0] == "--config" &&
+						assert.ObjectsAreEqual(
+							[]string{"--encrypt", "--input-type", "yaml", "--output-type", "yaml", "/dev/stdin"},
+							args[2:],
+						)
+				}),
+				[]string(nil),
+				"",
+				bytes.NewReader([]byte("password: new\n")),
+			).Run(func(args mock.Arguments) {
+				data, err := ioutil.ReadFile(args.Get(2).([]string)[1])
+				require.Nil(t, err)
+
+				config = string(data)
+			}).Return([]byte("password: ENC[new]\n"), []byte{}, nil)
+
+			sopsInstance := NewSops(executorArg, &SopsOptions{KmsArns: []string{"arn:aws:kms:eu-west-1:123:key/other"}})
+			actualErr := sopsInstance.EditInPlace(
+				context.Background(),
+				path,
+				func(plaintext []byte) ([]byte, error) {
+					return bytes.Replace(plaintext, []byte("old"), []byte("new"), 1), nil
+				},
+			)
+			require.Nil(t, actualErr)
+
+			assert.Equal(
+				t,
+				"creation_rules:\n"+
+					"- key_groups:\n"+
+					"  - kms:\n"+
+					"    - arn: arn:aws:kms:eu-west-1:123:key/abc\n"+
+					"  - age:\n"+
+					"    - age1example\n"+
+					"  shamir_threshold: 2\n"+
+					"  encrypted_regex: ^password$\n",
+				config,
+			)
+
+			actual, err := ioutil.ReadFile(path)
+			require.Nil(t, err)
+			assert.Equal(t, "password: ENC[new]\n", string(actual))
+
+			info, err := os.Stat(path)
+			require.Nil(t, err)
+			assert.Equal(t, os.FileMode(0640), info.Mode())
+
+			// NOTE: The file is replaced by renaming a temporary file, that is not left behind.
+			files, err := ioutil.ReadDir(dir)
+			require.Nil(t, err)
+			assert.Len(t, files, 1)
+		},
+	)
+
+	t.Run(
+		"when the file is dotenv, it encrypts with the keys of the flattened metadata",
+		func(t *testing.T) {
+			t.Parallel()
+
+			dir, err := ioutil.TempDir("", "sops")
+			require.Nil(t, err)
+
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "secrets.env")
+			content := "PASSWORD=ENC[old]\n" +
+				"sops_pgp__list_0__map_fp=FBC7B9E2A4F9289AC0C1D4843D16CEE4A27381B4\n" +
+				"sops_pgp__list_0__map_enc=ENC[pgp]\n" +
+				"sops_age__list_0__map_recipient=age1first\n" +
+				"sops_age__list_1__map_recipient=age1second\n" +
+				"sops_version=3.7.3\n"
+			require.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"sops",
+				[]string{"--decrypt", path},
+				[]string(nil),
+				"",
+			).Return([]byte("PASSWORD=old\n"), []byte{}, nil)
+
+			var config string
+
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"sops",
+				mock.Anything,
+				[]string(nil),
+				"",
+				bytes.NewReader([]byte("PASSWORD=new\n")),
+			).Run(func(args mock.Arguments) {
+				data, err := ioutil.ReadFile(args.Get(2).([]string)[1])
+				require.Nil(t, err)
+
+				config = string(data)
+			}).Return([]byte("PASSWORD=ENC[new]\n"), []byte{}, nil)
+
+			sopsInstance := NewSops(executorArg, nil)
+			actualErr := sopsInstance.EditInPlace(
+				context.Background(),
+				path,
+				func(plaintext []byte) ([]byte, error) {
+					return []byte("PASSWORD=new\n"), nil
+				},
+			)
+			require.Nil(t, actualErr)
+
+			assert.Equal(
+				t,
+				"creation_rules:\n"+
+					"- key_groups:\n"+
+					"  - age:\n"+
+					"    - age1first\n"+
+					"    - age1second\n"+
+					"    pgp:\n"+
+					"    - FBC7B9E2A4F9289AC0C1D4843D16CEE4A27381B4\n",
+				config,
+			)
+		},
+	)
+
+	t.Run(
+		"when the file has no sops metadata, it returns error without decrypting",
+		func(t *testing.T) {
+			t.Parallel()
+
+			dir, err := ioutil.TempDir("", "sops")
+			require.Nil(t, err)
+
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "secrets.yaml")
+			require.Nil(t, ioutil.WriteFile(path, []byte("password: plain\n"), 0600))
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+
+			sopsInstance := NewSops(executorArg, nil)
+			actualErr := sopsInstance.EditInPlace(
+				context.Background(),
+				path,
+				func(plaintext []byte) ([]byte, error) {
+					return plaintext, nil
+				},
+			)
+			require.Error(t, actualErr)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"when content is not changed, it does not encrypt",
+		func(t *testing.T) {
+			t.Parallel()
+
+			dir, err := ioutil.TempDir("", "sops")
+			require.Nil(t, err)
+
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "secrets.yaml")
+			content := "password: ENC[old]\nsops:\n  age:\n  - recipient: age1example\n"
+			require.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"sops",
+				[]string{"--decrypt", path},
+				[]string(nil),
+				"",
+			).Return([]byte("password: old\n"), []byte{}, nil)
+
+			sopsInstance := NewSops(executorArg, nil)
+			actualErr := sopsInstance.EditInPlace(
+				context.Background(),
+				path,
+				func(plaintext []byte) ([]byte, error) {
+					return plaintext, nil
+				},
+			)
+			require.Nil(t, actualErr)
+			executorArg.AssertExpectations(t)
+
+			actual, err := ioutil.ReadFile(path)
+			require.Nil(t, err)
+			assert.Equal(t, content, string(actual))
+		},
+	)
+}
+
+func TestSops_ApplyDecrypted(t *testing.T) {
+	t.Parallel()
+
+	executorArg := ostest.NewFakeOsExecutor(t)
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"sops",
+		[]string{"--decrypt", "secret.yaml"},
+		[]string(nil),
+		"",
+	).Return([]byte("kind: Secret\n"), []byte{}, nil)
+
+	applier := &fakeManifestApplier{}
+	applier.On("ApplyData", []byte("kind: Secret\n"), "default").Return(nil)
+
+	sopsInstance := NewSops(executorArg, nil)
+	actualErr := sopsInstance.ApplyDecrypted(context.Background(), "secret.yaml", applier, "default")
+	require.Nil(t, actualErr)
+	applier.AssertExpectations(t)
+}
+
+func TestSopsFormatFromPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, SopsFormatYAML, SopsFormatFromPath("secrets.yml"))
+	assert.Equal(t, SopsFormatYAML, SopsFormatFromPath("/tmp/secrets.YAML"))
+	assert.Equal(t, SopsFormatJSON, SopsFormatFromPath("secrets.json"))
+	assert.Equal(t, SopsFormatDotenv, SopsFormatFromPath(".env"))
+	assert.Equal(t, SopsFormatBinary, SopsFormatFromPath("id_rsa"))
+}
@@ -0,0 +1,257 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+var (
+	_ os.CommandExecutor       = (*Ssh)(nil)
+	_ streamingCommandExecutor = (*Ssh)(nil)
+
+	shellSafeRegex = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+)
+
+const (
+	SshHostKeyCheckingStrict    = "yes"
+	SshHostKeyCheckingAcceptNew = "accept-new"
+	SshHostKeyCheckingOff       = "no"
+)
+
+type SshOptions struct {
+	Host string
+	User string
+	// Port is the port of the host, when not the default 22.
+	Port int
+	// IdentityFile is the private key to authenticate with. Only this key is offered when `UseAgent` is not set.
+	IdentityFile string
+	// UseAgent authenticates with the keys of the ssh agent at `AgentSocket`, or `SSH_AUTH_SOCK` when blank.
+	UseAgent    bool
+	AgentSocket string
+	// KnownHostsFile is the known hosts file, when not the default `~/.ssh/known_hosts`.
+	KnownHostsFile string
+	// HostKeyChecking is one of the SshHostKeyChecking values, SshHostKeyCheckingStrict when blank.
+	HostKeyChecking string
+	// ConnectTimeout is the timeout of establishing the connection, when present.
+	ConnectTimeout time.Duration
+}
+
+// Ssh runs commands on a remote host over ssh and copies files with scp.
+// It's an os.CommandExecutor, so that other executors, e.g Docker, can run on the remote host.
+type Ssh struct {
+	sshBinPath      string
+	scpBinPath      string
+	options         SshOptions
+	commandExecutor os.CommandExecutor
+}
+
+func NewSsh(executor os.CommandExecutor, options *SshOptions) *Ssh {
+	ssh := &Ssh{
+		sshBinPath:      "ssh",
+		scpBinPath:      "scp",
+		commandExecutor: executor,
+	}
+
+	if options != nil {
+		ssh.options = *options
+	}
+
+	return ssh
+}
+
+// Execute executes the command on the remote host in `dir`, when present, with `env` added to
+// the environment of the remote user.
+func (ssh *Ssh) Execute(cmd string, arg, env []string, dir string) ([]byte, []byte, error) {
+	return ssh.commandExecutor.Execute(ssh.sshBinPath, ssh.sshArguments(cmd, arg, env, dir), ssh.env(), "")
+}
+
+func (ssh *Ssh) ExecuteContext(
+	ctx context.Context,
+	cmd string,
+	arg,
+	env []string,
+	dir string,
+) ([]byte, []byte, error) {
+	return ssh.commandExecutor.ExecuteContext(
+		ctx,
+		ssh.sshBinPath,
+		ssh.sshArguments(cmd, arg, env, dir),
+		ssh.env(),
+		"",
+	)
+}
+
+// ExecuteWithStreamsContext executes the command on the remote host and streams its output.
+// The command executor must support streaming.
+func (ssh *Ssh) ExecuteWithStreamsContext(
+	ctx context.Context,
+	cmd string,
+	arg,
+	env []string,
+	dir string,
+	stdout,
+	stderr io.Writer,
+) error {
+	streamingExecutor, ok := ssh.commandExecutor.(streamingCommandExecutor)
+	if !ok {
+		return stacktrace.NewError("command executor does not support executing with streams")
+	}
+
+	return streamingExecutor.ExecuteWithStreamsContext(
+		ctx,
+		ssh.sshBinPath,
+		ssh.sshArguments(cmd, arg, env, dir),
+		ssh.env(),
+		"",
+		stdout,
+		stderr,
+	)
+}
+
+// Upload copies the local path to the remote path.
+func (ssh *Ssh) Upload(ctx context.Context, localPath, remotePath string, recursive bool) error {
+	return ssh.copy(ctx, localPath, ssh.remoteAddress(remotePath), recursive)
+}
+
+// Download copies the remote path to the local path.
+func (ssh *Ssh) Download(ctx context.Context, remotePath, localPath string, recursive bool) error {
+	return ssh.copy(ctx, ssh.remoteAddress(remotePath), localPath, recursive)
+}
+
+func (ssh *Ssh) copy(ctx context.Context, source, destination string, recursive bool) error {
+	args := ssh.connectionOptions()
+
+	if ssh.options.Port > 0 {
+		args = append(args, "-P", strconv.Itoa(ssh.options.Port))
+	}
+
+	if recursive {
+		args = append(args, "-r")
+	}
+
+	args = append(args, source, destination)
+
+	stdout, stderr, err := ssh.commandExecutor.ExecuteContext(ctx, ssh.scpBinPath, args, ssh.env(), "")
+	return stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+}
+
+func (ssh *Ssh) sshArguments(cmd string, arg, env []string, dir string) []string {
+	args := ssh.connectionOptions()
+
+	if ssh.options.Port > 0 {
+		args = append(args, "-p", strconv.Itoa(ssh.options.Port))
+	}
+
+	args = append(args, ssh.destination(), "--", remoteCommand(cmd, arg, env, dir))
+
+	return args
+}
+
+func (ssh *Ssh) connectionOptions() []string {
+	// NOTE: Batch mode fails instead of prompting for passwords or passphrases.
+	args := []string{"-o", "BatchMode=yes"}
+
+	hostKeyChecking := ssh.options.HostKeyChecking
+	if hostKeyChecking == "" {
+		hostKeyChecking = SshHostKeyCheckingStrict
+	}
+
+	args = append(args, "-o", "StrictHostKeyChecking="+hostKeyChecking)
+
+	if ssh.options.KnownHostsFile != "" {
+		args = append(args, "-o", "UserKnownHostsFile="+ssh.options.KnownHostsFile)
+	}
+
+	if ssh.options.ConnectTimeout > 0 {
+		// NOTE: ssh accepts the timeout in whole seconds only.
+		seconds := int((ssh.options.ConnectTimeout + time.Second - 1) / time.Second)
+		args = append(args, "-o", fmt.Sprintf("ConnectTimeout=%d", seconds))
+	}
+
+	if ssh.options.IdentityFile != "" {
+		args = append(args, "-i", ssh.options.IdentityFile)
+
+		if !ssh.options.UseAgent {
+			args = append(args, "-o", "IdentitiesOnly=yes")
+		}
+	}
+
+	return args
+}
+
+func (ssh *Ssh) destination() string {
+	if ssh.options.User == "" {
+		return ssh.options.Host
+	}
+
+	return ssh.options.User + "@" + ssh.options.Host
+}
+
+func (ssh *Ssh) remoteAddress(remotePath string) string {
+	return ssh.destination() + ":" + remotePath
+}
+
+func (ssh *Ssh) env() []string {
+	if !ssh.options.UseAgent || ssh.options.AgentSocket == "" {
+		return nil
+	}
+
+	return os.CommandEnv("SSH_AUTH_SOCK=" + ssh.options.AgentSocket)
+}
+
+// remoteCommand compiles the shell command executed by the remote user shell.
+func remoteCommand(cmd string, arg, env []string, dir string) string {
+	words := make([]string, 0, len(arg)+len(env)+2)
+
+	if len(env) > 0 {
+		words = append(words, "env")
+
+		for _, variable := range env {
+			words = append(words, shellQuote(variable))
+		}
+	}
+
+	words = append(words, shellQuote(cmd))
+
+	for _, argument := range arg {
+		words = append(words, shellQuote(argument))
+	}
+
+	command := strings.Join(words, " ")
+	if dir == "" {
+		return command
+	}
+
+	return "cd " + shellQuote(dir) + " && " + command
+}
+
+func shellQuote(value string) string {
+	if shellSafeRegex.MatchString(value) {
+		return value
+	}
+
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,218 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestNewSsh(t *testing.T) {
+	t.Run(
+		"when options are nil, it uses the default options",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+
+			actual := NewSsh(executorArg, nil)
+			assert.Equal(t, SshOptions{}, actual.options)
+			assert.Nil(t, actual.env())
+		},
+	)
+}
+
+func TestSsh_ExecuteContext(t *testing.T) {
+	t.Run(
+		"it executes the quoted command on the remote host with the connection options",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"ssh",
+				[]string{
+					"-o", "BatchMode=yes",
+					"-o", "StrictHostKeyChecking=accept-new",
+					"-o", "UserKnownHostsFile=/tmp/known_hosts",
+					"-o", "ConnectTimeout=5",
+					"-i", "/tmp/id_ed25519",
+					"-o", "IdentitiesOnly=yes",
+					"-p", "2222",
+					"deploy@build.example.com",
+					"--",
+					"cd '/srv/my app' && env 'GREETING=hello world' echo 'it'\\''s' done",
+				},
+				[]string(nil),
+				"",
+			).Return([]byte("it's done\n"), []byte{}, nil)
+
+			sshInstance := NewSsh(
+				executorArg,
+				&SshOptions{
+					Host:            "build.example.com",
+					User:            "deploy",
+					Port:            2222,
+					IdentityFile:    "/tmp/id_ed25519",
+					KnownHostsFile:  "/tmp/known_hosts",
+					HostKeyChecking: SshHostKeyCheckingAcceptNew,
+					ConnectTimeout:  4500 * time.Millisecond,
+				},
+			)
+			actualStdout, _, actualErr := sshInstance.ExecuteContext(
+				context.Background(),
+				"echo",
+				[]string{"it's", "done"},
+				[]string{"GREETING=hello world"},
+				"/srv/my app",
+			)
+			require.Nil(t, actualErr)
+			assert.Equal(t, "it's done\n", string(actualStdout))
+		},
+	)
+
+	t.Run(
+		"with agent, it offers all keys and passes the agent socket",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"Execute",
+				"ssh",
+				[]string{
+					"-o", "BatchMode=yes",
+					"-o", "StrictHostKeyChecking=yes",
+					"-i", "/tmp/id_ed25519",
+					"build.example.com",
+					"--",
+					"uptime",
+				},
+				mock.MatchedBy(func(env []string) bool {
+					return containsString(env, "SSH_AUTH_SOCK=/tmp/agent.sock")
+				}),
+				"",
+			).Return([]byte{}, []byte("Host key verification failed."), errors.New("exit status 255"))
+
+			sshInstance := NewSsh(
+				executorArg,
+				&SshOptions{
+					Host:         "build.example.com",
+					IdentityFile: "/tmp/id_ed25519",
+					UseAgent:     true,
+					AgentSocket:  "/tmp/agent.sock",
+				},
+			)
+			_, actualStderr, actualErr := sshInstance.Execute("uptime", nil, nil, "")
+			require.NotNil(t, actualErr)
+			assert.Equal(t, "Host key verification failed.", string(actualStderr))
+		},
+	)
+}
+
+func TestSsh_ExecuteWithStreamsContext(t *testing.T) {
+	t.Parallel()
+
+	executorArg := ostest.NewFakeOsExecutor(t)
+	executorArg.On(
+		"ExecuteWithStreamsContext",
+		context.Background(),
+		"ssh",
+		[]string{"-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=yes", "build.example.com", "--", "make build"},
+		[]string(nil),
+		"",
+		mock.Anything,
+		mock.Anything,
+	).Run(func(args mock.Arguments) {
+		_, _ = args.Get(5).(io.Writer).Write([]byte("building\n"))
+	}).Return(nil)
+
+	var stdout, stderr bytes.Buffer
+
+	sshInstance := NewSsh(executorArg, &SshOptions{Host: "build.example.com"})
+	actualErr := sshInstance.ExecuteWithStreamsContext(
+		context.Background(),
+		"make",
+		[]string{"build"},
+		nil,
+		"",
+		&stdout,
+		&stderr,
+	)
+	require.Nil(t, actualErr)
+	assert.Equal(t, "building\n", stdout.String())
+}
+
+func TestSsh_Upload(t *testing.T) {
+	t.Parallel()
+
+	executorArg := ostest.NewFakeOsExecutor(t)
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"scp",
+		[]string{
+			"-o", "BatchMode=yes",
+			"-o", "StrictHostKeyChecking=yes",
+			"-P", "2222",
+			"-r",
+			"./dist",
+			"deploy@build.example.com:/srv/app",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	sshInstance := NewSsh(executorArg, &SshOptions{Host: "build.example.com", User: "deploy", Port: 2222})
+	actualErr := sshInstance.Upload(context.Background(), "./dist", "/srv/app", true)
+	require.Nil(t, actualErr)
+	executorArg.AssertExpectations(t)
+}
+
+func TestSsh_Download(t *testing.T) {
+	t.Parallel()
+
+	executorArg := ostest.NewFakeOsExecutor(t)
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"scp",
+		[]string{
+			"-o", "BatchMode=yes",
+			"-o", "StrictHostKeyChecking=yes",
+			"build.example.com:/var/log/app.log",
+			"/tmp/app.log",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte("No such file or directory"), errors.New("exit status 1"))
+
+	sshInstance := NewSsh(executorArg, &SshOptions{Host: "build.example.com"})
+	actualErr := sshInstance.Download(context.Background(), "/var/log/app.log", "/tmp/app.log", false)
+	require.NotNil(t, actualErr)
+	assert.Contains(t, actualErr.Error(), "No such file or directory")
+}
@@ -0,0 +1,311 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+const systemdTimestampLayout = "Mon 2006-01-02 15:04:05 MST"
+
+type SystemctlOptions struct {
+	// User manages the units of the calling user's service manager, instead of the system one.
+	User bool
+}
+
+type SystemdUnitStatus struct {
+	ID            string
+	Description   string
+	LoadState     string
+	ActiveState   string
+	SubState      string
+	UnitFileState string
+	MainPID       int
+	// ExecMainStatus is the exit status of the main process, when it exited.
+	ExecMainStatus       int
+	NRestarts            int
+	ActiveEnterTimestamp time.Time
+	// Properties contains all properties reported by `systemctl show`.
+	Properties map[string]string
+}
+
+// IsActive returns whether the unit is active, e.g started and running.
+func (status *SystemdUnitStatus) IsActive() bool {
+	return status.ActiveState == "active"
+}
+
+// IsFailed returns whether the unit is in failed state.
+func (status *SystemdUnitStatus) IsFailed() bool {
+	return status.ActiveState == "failed"
+}
+
+type JournalOptions struct {
+	// Lines limits the entries to the last lines, when positive.
+	Lines int
+	// Since and Until limit the entries' time range, e.g `2019-10-01 12:00:00`, `-1h` or `today`.
+	Since string
+	Until string
+	// Priority limits the entries to the priority and higher ones, e.g `err` or `3`.
+	Priority string
+}
+
+type JournalEntry struct {
+	Timestamp time.Time
+	Priority  int
+	PID       int
+	Message   string
+}
+
+type journalctlEntry struct {
+	RealtimeTimestamp string          `json:"__REALTIME_TIMESTAMP"`
+	Priority          string          `json:"PRIORITY"`
+	PID               string          `json:"_PID"`
+	Message           json.RawMessage `json:"MESSAGE"`
+}
+
+type Systemctl struct {
+	binPath           string
+	journalctlBinPath string
+	options           SystemctlOptions
+	commandExecutor   os.CommandExecutor
+}
+
+func NewSystemctl(executor os.CommandExecutor, options *SystemctlOptions) *Systemctl {
+	systemctl := &Systemctl{
+		binPath:           "systemctl",
+		journalctlBinPath: "journalctl",
+		commandExecutor:   executor,
+	}
+
+	if options != nil {
+		systemctl.options = *options
+	}
+
+	return systemctl
+}
+
+func (systemctl *Systemctl) Start(ctx context.Context, unit string) error {
+	_, err := systemctl.execute(ctx, "start", unit)
+	return err
+}
+
+func (systemctl *Systemctl) Stop(ctx context.Context, unit string) error {
+	_, err := systemctl.execute(ctx, "stop", unit)
+	return err
+}
+
+func (systemctl *Systemctl) Restart(ctx context.Context, unit string) error {
+	_, err := systemctl.execute(ctx, "restart", unit)
+	return err
+}
+
+// Enable enables the unit to be started on boot, and starts it right away when `now` is true.
+func (systemctl *Systemctl) Enable(ctx context.Context, unit string, now bool) error {
+	args := []string{"enable", unit}
+	if now {
+		args = append(args, "--now")
+	}
+
+	_, err := systemctl.execute(ctx, args...)
+	return err
+}
+
+// Disable disables the unit to be started on boot, and stops it right away when `now` is true.
+func (systemctl *Systemctl) Disable(ctx context.Context, unit string, now bool) error {
+	args := []string{"disable", unit}
+	if now {
+		args = append(args, "--now")
+	}
+
+	_, err := systemctl.execute(ctx, args...)
+	return err
+}
+
+// DaemonReload reloads the unit files, e.g after they were changed.
+func (systemctl *Systemctl) DaemonReload(ctx context.Context) error {
+	_, err := systemctl.execute(ctx, "daemon-reload")
+	return err
+}
+
+// Status returns the unit status, parsed from its `systemctl show` properties.
+// Unlike `systemctl status`, it doesn't fail for inactive or failed units.
+func (systemctl *Systemctl) Status(ctx context.Context, unit string) (*SystemdUnitStatus, error) {
+	stdout, err := systemctl.execute(ctx, "show", unit, "--no-pager")
+	if err != nil {
+		return nil, err
+	}
+
+	properties := parseSystemctlProperties(stdout)
+	status := &SystemdUnitStatus{
+		ID:            properties["Id"],
+		Description:   properties["Description"],
+		LoadState:     properties["LoadState"],
+		ActiveState:   properties["ActiveState"],
+		SubState:      properties["SubState"],
+		UnitFileState: properties["UnitFileState"],
+		Properties:    properties,
+	}
+
+	status.MainPID, _ = strconv.Atoi(properties["MainPID"])
+	status.ExecMainStatus, _ = strconv.Atoi(properties["ExecMainStatus"])
+	status.NRestarts, _ = strconv.Atoi(properties["NRestarts"])
+
+	if value := properties["ActiveEnterTimestamp"]; value != "" && value != "n/a" {
+		timestamp, err := time.Parse(systemdTimestampLayout, value)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "parse unit %s active enter timestamp failed", unit)
+		}
+
+		status.ActiveEnterTimestamp = timestamp
+	}
+
+	return status, nil
+}
+
+// Journal returns the unit journal entries, oldest first.
+func (systemctl *Systemctl) Journal(ctx context.Context, unit string, options *JournalOptions) ([]*JournalEntry, error) {
+	args := []string{"--unit", unit, "--output", "json", "--no-pager"}
+
+	if systemctl.options.User {
+		args = append(args, "--user")
+	}
+
+	if options != nil {
+		if options.Lines > 0 {
+			args = append(args, "--lines", strconv.Itoa(options.Lines))
+		}
+
+		if options.Since != "" {
+			args = append(args, "--since", options.Since)
+		}
+
+		if options.Until != "" {
+			args = append(args, "--until", options.Until)
+		}
+
+		if options.Priority != "" {
+			args = append(args, "--priority", options.Priority)
+		}
+	}
+
+	stdout, stderr, err := systemctl.commandExecutor.ExecuteContext(ctx, systemctl.journalctlBinPath, args, nil, "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return parseJournalEntries(stdout)
+}
+
+func (systemctl *Systemctl) execute(ctx context.Context, args ...string) ([]byte, error) {
+	if systemctl.options.User {
+		args = append(args, "--user")
+	}
+
+	stdout, stderr, err := systemctl.commandExecutor.ExecuteContext(ctx, systemctl.binPath, args, nil, "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return stdout, nil
+}
+
+func parseSystemctlProperties(output []byte) map[string]string {
+	properties := make(map[string]string)
+
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		properties[parts[0]] = parts[1]
+	}
+
+	return properties
+}
+
+// parseJournalEntries parses `journalctl --output json` output, which is a JSON object per line.
+func parseJournalEntries(output []byte) ([]*JournalEntry, error) {
+	var entries []*JournalEntry
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	// NOTE: Journal entries may be longer than the default 64KB token limit.
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var raw journalctlEntry
+
+		err := json.Unmarshal(line, &raw)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "json decode journal entry failed")
+		}
+
+		entry := &JournalEntry{
+			Message: decodeJournalMessage(raw.Message),
+		}
+		entry.Priority, _ = strconv.Atoi(raw.Priority)
+		entry.PID, _ = strconv.Atoi(raw.PID)
+
+		micros, err := strconv.ParseInt(raw.RealtimeTimestamp, 10, 64)
+		if err == nil {
+			entry.Timestamp = time.Unix(0, micros*int64(time.Microsecond)).UTC()
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, stacktrace.Propagate(scanner.Err(), "read journal entries failed")
+}
+
+// decodeJournalMessage decodes the message, which journalctl encodes as an array of bytes
+// when it's not valid UTF-8 or contains control characters.
+func decodeJournalMessage(raw json.RawMessage) string {
+	var message string
+
+	err := json.Unmarshal(raw, &message)
+	if err == nil {
+		return message
+	}
+
+	// NOTE: `[]byte` decodes from a base64 string, so decode the array as ints instead.
+	var ints []int
+
+	err = json.Unmarshal(raw, &ints)
+	if err != nil {
+		return ""
+	}
+
+	data := make([]byte, 0, len(ints))
+	for _, value := range ints {
+		data = append(data, byte(value))
+	}
+
+	return string(data)
+}
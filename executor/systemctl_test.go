@@ -0,0 +1,183 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestSystemctl_Enable(t *testing.T) {
+	t.Run(
+		"with now, it enables and starts the unit",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"systemctl",
+				[]string{"enable", "agent.service", "--now"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte{}, nil)
+
+			systemctlInstance := NewSystemctl(executorArg, nil)
+			actual := systemctlInstance.Enable(context.Background(), "agent.service", true)
+			require.Nil(t, actual)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"with user option, it manages the user unit",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"systemctl",
+				[]string{"enable", "agent.service", "--user"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("Unit file agent.service does not exist."), errors.New("exit status 1"))
+
+			systemctlInstance := NewSystemctl(executorArg, &SystemctlOptions{User: true})
+			actual := systemctlInstance.Enable(context.Background(), "agent.service", false)
+			require.NotNil(t, actual)
+			assert.Contains(t, actual.Error(), "does not exist")
+		},
+	)
+}
+
+func TestSystemctl_Restart(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"systemctl",
+		[]string{"restart", "agent.service"},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	systemctlInstance := NewSystemctl(executorArg, nil)
+	actual := systemctlInstance.Restart(context.Background(), "agent.service")
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestSystemctl_Status(t *testing.T) {
+	t.Parallel()
+
+	output := `Type=simple
+Restart=on-failure
+MainPID=1234
+NRestarts=2
+ExecMainStatus=0
+Id=agent.service
+Description=Deploy agent
+LoadState=loaded
+ActiveState=active
+SubState=running
+UnitFileState=enabled
+ActiveEnterTimestamp=Tue 2019-10-01 12:30:00 UTC
+Environment=GREETING=hello
+`
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"systemctl",
+		[]string{"show", "agent.service", "--no-pager"},
+		[]string(nil),
+		"",
+	).Return([]byte(output), []byte{}, nil)
+
+	systemctlInstance := NewSystemctl(executorArg, nil)
+	actual, actualErr := systemctlInstance.Status(context.Background(), "agent.service")
+	require.Nil(t, actualErr)
+
+	assert.Equal(t, "agent.service", actual.ID)
+	assert.Equal(t, "Deploy agent", actual.Description)
+	assert.Equal(t, "loaded", actual.LoadState)
+	assert.Equal(t, "running", actual.SubState)
+	assert.Equal(t, "enabled", actual.UnitFileState)
+	assert.Equal(t, 1234, actual.MainPID)
+	assert.Equal(t, 2, actual.NRestarts)
+	assert.Equal(t, time.Date(2019, 10, 1, 12, 30, 0, 0, time.UTC), actual.ActiveEnterTimestamp.UTC())
+	assert.Equal(t, "GREETING=hello", actual.Properties["Environment"])
+	assert.True(t, actual.IsActive())
+	assert.False(t, actual.IsFailed())
+}
+
+func TestSystemctl_Journal(t *testing.T) {
+	t.Parallel()
+
+	output := `{"__REALTIME_TIMESTAMP":"1569933000000000","PRIORITY":"6","_PID":"1234","MESSAGE":"agent started"}
+{"__REALTIME_TIMESTAMP":"1569933001500000","PRIORITY":"3","_PID":"1234","MESSAGE":[102,97,105,108,101,100,27]}
+`
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"journalctl",
+		[]string{
+			"--unit", "agent.service", "--output", "json", "--no-pager",
+			"--lines", "50",
+			"--since", "-1h",
+			"--priority", "info",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte(output), []byte{}, nil)
+
+	systemctlInstance := NewSystemctl(executorArg, nil)
+	actual, actualErr := systemctlInstance.Journal(
+		context.Background(),
+		"agent.service",
+		&JournalOptions{Lines: 50, Since: "-1h", Priority: "info"},
+	)
+	require.Nil(t, actualErr)
+	require.Len(t, actual, 2)
+
+	assert.Equal(
+		t,
+		&JournalEntry{
+			Timestamp: time.Date(2019, 10, 1, 12, 30, 0, 0, time.UTC),
+			Priority:  6,
+			PID:       1234,
+			Message:   "agent started",
+		},
+		actual[0],
+	)
+	assert.Equal(t, 3, actual[1].Priority)
+	assert.Equal(t, "failed\x1b", actual[1].Message)
+	assert.Equal(t, time.Date(2019, 10, 1, 12, 30, 1, 500000000, time.UTC), actual[1].Timestamp)
+}
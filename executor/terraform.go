@@ -0,0 +1,421 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+const (
+	TerraformActionCreate  = "create"
+	TerraformActionUpdate  = "update"
+	TerraformActionDelete  = "delete"
+	TerraformActionReplace = "replace"
+	TerraformActionRead    = "read"
+	TerraformActionNoop    = "noop"
+)
+
+type TerraformInitOptions struct {
+	// BackendConfig are `key=value` backend configuration pairs.
+	BackendConfig map[string]string
+	// BackendConfigFiles are paths of backend configuration files.
+	BackendConfigFiles []string
+	Upgrade            bool
+	Reconfigure        bool
+}
+
+type TerraformPlanOptions struct {
+	Vars     map[string]string
+	VarFiles []string
+	Targets  []string
+	// Out is the path to save the plan to, so that it can be applied with `TerraformApplyOptions.PlanFile`.
+	Out     string
+	Destroy bool
+	// OnMessage is called with every machine-readable message while the command is running,
+	// when set.
+	OnMessage func(*TerraformMessage)
+}
+
+type TerraformApplyOptions struct {
+	// PlanFile is the path of a saved plan. Vars, var files and targets are ignored when present.
+	PlanFile string
+	Vars     map[string]string
+	VarFiles []string
+	Targets  []string
+	// OnMessage is called with every machine-readable message while the command is running,
+	// when set.
+	OnMessage func(*TerraformMessage)
+}
+
+// TerraformMessage is a message of the `-json` machine-readable UI output.
+type TerraformMessage struct {
+	Level      string                      `json:"@level"`
+	Message    string                      `json:"@message"`
+	Type       string                      `json:"type"`
+	Change     *TerraformMessageChange     `json:"change,omitempty"`
+	Changes    *TerraformChangeCounts      `json:"changes,omitempty"`
+	Diagnostic *TerraformDiagnostic        `json:"diagnostic,omitempty"`
+	Hook       *TerraformMessageHook       `json:"hook,omitempty"`
+	Outputs    map[string]*TerraformOutput `json:"outputs,omitempty"`
+}
+
+type TerraformMessageChange struct {
+	Resource TerraformResource `json:"resource"`
+	Action   string            `json:"action"`
+}
+
+type TerraformMessageHook struct {
+	Resource TerraformResource `json:"resource"`
+	Action   string            `json:"action"`
+}
+
+type TerraformResource struct {
+	Address string `json:"addr"`
+	Module  string `json:"module"`
+	Type    string `json:"resource_type"`
+	Name    string `json:"resource_name"`
+}
+
+type TerraformChangeCounts struct {
+	Add       int    `json:"add"`
+	Change    int    `json:"change"`
+	Remove    int    `json:"remove"`
+	Operation string `json:"operation"`
+}
+
+type TerraformDiagnostic struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail"`
+}
+
+type TerraformOutput struct {
+	Sensitive bool            `json:"sensitive"`
+	Type      json.RawMessage `json:"type"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// TerraformChangeSummary is the summary of the changes planned or applied.
+// The addresses are of the resources per action, replaced resources are only in `Replaces`.
+type TerraformChangeSummary struct {
+	Creates     []string
+	Updates     []string
+	Deletes     []string
+	Replaces    []string
+	Counts      TerraformChangeCounts
+	Diagnostics []*TerraformDiagnostic
+}
+
+func (summary *TerraformChangeSummary) HasChanges() bool {
+	return summary.Counts.Add+summary.Counts.Change+summary.Counts.Remove > 0
+}
+
+type Terraform struct {
+	binPath         string
+	dir             string
+	commandExecutor os.CommandExecutor
+}
+
+// NewTerraform creates Terraform instance executing in the `dir` configuration directory.
+func NewTerraform(executor os.CommandExecutor, dir string) *Terraform {
+	return &Terraform{
+		binPath:         "terraform",
+		dir:             dir,
+		commandExecutor: executor,
+	}
+}
+
+func (terraform *Terraform) Init(ctx context.Context, options *TerraformInitOptions) error {
+	args := []string{"init", "-input=false", "-no-color"}
+
+	if options != nil {
+		for _, key := range sortedKeys(options.BackendConfig) {
+			args = append(args, fmt.Sprintf("-backend-config=%s=%s", key, options.BackendConfig[key]))
+		}
+
+		for _, file := range options.BackendConfigFiles {
+			args = append(args, "-backend-config="+file)
+		}
+
+		if options.Upgrade {
+			args = append(args, "-upgrade")
+		}
+
+		if options.Reconfigure {
+			args = append(args, "-reconfigure")
+		}
+	}
+
+	stdout, stderr, err := terraform.commandExecutor.ExecuteContext(ctx, terraform.binPath, args, nil, terraform.dir)
+	return stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+}
+
+// SelectWorkspace selects the workspace, creating it when it does not exist and `create` is set.
+func (terraform *Terraform) SelectWorkspace(ctx context.Context, name string, create bool) error {
+	_, stderr, err := terraform.commandExecutor.ExecuteContext(
+		ctx,
+		terraform.binPath,
+		[]string{"workspace", "select", "-no-color", name},
+		nil,
+		terraform.dir,
+	)
+	if err == nil {
+		return nil
+	}
+
+	if !create {
+		return stacktrace.Propagate(err, "Stderr: %s", stderr)
+	}
+
+	stdout, stderr, err := terraform.commandExecutor.ExecuteContext(
+		ctx,
+		terraform.binPath,
+		[]string{"workspace", "new", "-no-color", name},
+		nil,
+		terraform.dir,
+	)
+
+	return stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+}
+
+// Plan plans the changes and returns their summary.
+func (terraform *Terraform) Plan(ctx context.Context, options *TerraformPlanOptions) (*TerraformChangeSummary, error) {
+	if options == nil {
+		options = &TerraformPlanOptions{}
+	}
+
+	args := []string{"plan", "-input=false", "-json"}
+
+	if options.Destroy {
+		args = append(args, "-destroy")
+	}
+
+	if options.Out != "" {
+		args = append(args, "-out="+options.Out)
+	}
+
+	args = append(args, terraformVarArguments(options.Vars, options.VarFiles, options.Targets)...)
+
+	return terraform.executeJSON(ctx, args, options.OnMessage)
+}
+
+// Apply applies the changes without asking for approval and returns their summary.
+func (terraform *Terraform) Apply(ctx context.Context, options *TerraformApplyOptions) (*TerraformChangeSummary, error) {
+	return terraform.apply(ctx, []string{"apply", "-input=false", "-auto-approve", "-json"}, options)
+}
+
+// Destroy destroys the managed resources without asking for approval and returns the summary.
+func (terraform *Terraform) Destroy(ctx context.Context, options *TerraformApplyOptions) (*TerraformChangeSummary, error) {
+	return terraform.apply(ctx, []string{"apply", "-destroy", "-input=false", "-auto-approve", "-json"}, options)
+}
+
+// Output returns the root module outputs. Sensitive values are included.
+func (terraform *Terraform) Output(ctx context.Context) (map[string]*TerraformOutput, error) {
+	stdout, stderr, err := terraform.commandExecutor.ExecuteContext(
+		ctx,
+		terraform.binPath,
+		[]string{"output", "-json"},
+		nil,
+		terraform.dir,
+	)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	outputs := make(map[string]*TerraformOutput)
+
+	err = json.Unmarshal(stdout, &outputs)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "json decode command `terraform output -json` output failed")
+	}
+
+	return outputs, nil
+}
+
+func (terraform *Terraform) apply(
+	ctx context.Context,
+	args []string,
+	options *TerraformApplyOptions,
+) (*TerraformChangeSummary, error) {
+	if options == nil {
+		options = &TerraformApplyOptions{}
+	}
+
+	if options.PlanFile != "" {
+		args = append(args, options.PlanFile)
+	} else {
+		args = append(args, terraformVarArguments(options.Vars, options.VarFiles, options.Targets)...)
+	}
+
+	return terraform.executeJSON(ctx, args, options.OnMessage)
+}
+
+// executeJSON executes command with `-json` output and summarizes its messages.
+// The messages are streamed when the command executor supports it.
+func (terraform *Terraform) executeJSON(
+	ctx context.Context,
+	args []string,
+	onMessage func(*TerraformMessage),
+) (*TerraformChangeSummary, error) {
+	decoder := newTerraformMessageDecoder(onMessage)
+	writer := newLineWriter(decoder.handleLine)
+
+	var err error
+
+	var stderr []byte
+
+	if streamingExecutor, ok := terraform.commandExecutor.(streamingCommandExecutor); ok {
+		var stderrBuffer bytes.Buffer
+
+		err = streamingExecutor.ExecuteWithStreamsContext(
+			ctx,
+			terraform.binPath,
+			args,
+			nil,
+			terraform.dir,
+			writer,
+			&stderrBuffer,
+		)
+		stderr = stderrBuffer.Bytes()
+	} else {
+		var stdout []byte
+
+		stdout, stderr, err = terraform.commandExecutor.ExecuteContext(ctx, terraform.binPath, args, nil, terraform.dir)
+		_, _ = writer.Write(stdout)
+	}
+
+	writer.Flush()
+
+	if err != nil {
+		return nil, stacktrace.Propagate(
+			err,
+			"Stderr: %s, Diagnostics: %s",
+			stderr,
+			formatTerraformDiagnostics(decoder.summary.Diagnostics),
+		)
+	}
+
+	return decoder.summary, nil
+}
+
+func terraformVarArguments(vars map[string]string, varFiles, targets []string) []string {
+	var args []string
+
+	for _, key := range sortedKeys(vars) {
+		args = append(args, "-var", fmt.Sprintf("%s=%s", key, vars[key]))
+	}
+
+	for _, file := range varFiles {
+		args = append(args, "-var-file="+file)
+	}
+
+	for _, target := range targets {
+		args = append(args, "-target="+target)
+	}
+
+	return args
+}
+
+func formatTerraformDiagnostics(diagnostics []*TerraformDiagnostic) string {
+	var buffer bytes.Buffer
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity != "error" {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(&buffer, "[%s: %s]", diagnostic.Summary, diagnostic.Detail)
+	}
+
+	return buffer.String()
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// terraformMessageDecoder decodes the lines of newline-delimited JSON messages into a summary.
+type terraformMessageDecoder struct {
+	onMessage func(*TerraformMessage)
+	summary   *TerraformChangeSummary
+}
+
+func newTerraformMessageDecoder(onMessage func(*TerraformMessage)) *terraformMessageDecoder {
+	return &terraformMessageDecoder{
+		onMessage: onMessage,
+		summary:   &TerraformChangeSummary{},
+	}
+}
+
+func (d *terraformMessageDecoder) handleLine(line string) {
+	var message TerraformMessage
+
+	// NOTE: Skip lines that are not messages, e.g output of provisioners or plugins.
+	err := json.Unmarshal([]byte(line), &message)
+	if err != nil {
+		return
+	}
+
+	d.handleMessage(&message)
+
+	if d.onMessage != nil {
+		d.onMessage(&message)
+	}
+}
+
+func (d *terraformMessageDecoder) handleMessage(message *TerraformMessage) {
+	switch message.Type {
+	case "planned_change":
+		if message.Change == nil {
+			return
+		}
+
+		address := message.Change.Resource.Address
+
+		switch message.Change.Action {
+		case TerraformActionCreate:
+			d.summary.Creates = append(d.summary.Creates, address)
+		case TerraformActionUpdate:
+			d.summary.Updates = append(d.summary.Updates, address)
+		case TerraformActionDelete:
+			d.summary.Deletes = append(d.summary.Deletes, address)
+		case TerraformActionReplace:
+			d.summary.Replaces = append(d.summary.Replaces, address)
+		}
+	case "change_summary":
+		if message.Changes != nil {
+			d.summary.Counts = *message.Changes
+		}
+	case "diagnostic":
+		if message.Diagnostic != nil {
+			d.summary.Diagnostics = append(d.summary.Diagnostics, message.Diagnostic)
+		}
+	}
+}
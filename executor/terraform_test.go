@@ -0,0 +1,272 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+const fakeTerraformPlanOutput = `{"@level":"info","@message":"Terraform 1.5.0","type":"version"}
+{"@level":"info","@message":"aws_s3_bucket.logs: Plan to create","type":"planned_change","change":{"resource":{"addr":"aws_s3_bucket.logs","module":"","resource_type":"aws_s3_bucket","resource_name":"logs"},"action":"create"}}
+{"@level":"info","@message":"aws_instance.web: Plan to replace","type":"planned_change","change":{"resource":{"addr":"aws_instance.web","module":"","resource_type":"aws_instance","resource_name":"web"},"action":"replace"}}
+{"@level":"info","@message":"module.dns.aws_route53_record.www: Plan to update","type":"planned_change","change":{"resource":{"addr":"module.dns.aws_route53_record.www","module":"module.dns","resource_type":"aws_route53_record","resource_name":"www"},"action":"update"}}
+{"@level":"info","@message":"Plan: 2 to add, 1 to change, 1 to destroy.","type":"change_summary","changes":{"add":2,"change":1,"remove":1,"operation":"plan"}}
+`
+
+func TestTerraform_Init(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"terraform",
+		[]string{
+			"init",
+			"-input=false",
+			"-no-color",
+			"-backend-config=bucket=state",
+			"-backend-config=key=app.tfstate",
+			"-backend-config=backend.hcl",
+			"-reconfigure",
+		},
+		[]string(nil),
+		"/tmp/infra",
+	).Return([]byte{}, []byte{}, nil)
+
+	terraformInstance := NewTerraform(executorArg, "/tmp/infra")
+	actual := terraformInstance.Init(
+		context.Background(),
+		&TerraformInitOptions{
+			BackendConfig:      map[string]string{"key": "app.tfstate", "bucket": "state"},
+			BackendConfigFiles: []string{"backend.hcl"},
+			Reconfigure:        true,
+		},
+	)
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestTerraform_SelectWorkspace(t *testing.T) {
+	t.Run(
+		"when workspace does not exist and create is set, it creates it",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"terraform",
+				[]string{"workspace", "select", "-no-color", "staging"},
+				[]string(nil),
+				"/tmp/infra",
+			).Return([]byte{}, []byte("Workspace \"staging\" doesn't exist."), errors.New("exit status 1"))
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"terraform",
+				[]string{"workspace", "new", "-no-color", "staging"},
+				[]string(nil),
+				"/tmp/infra",
+			).Return([]byte{}, []byte{}, nil)
+
+			terraformInstance := NewTerraform(executorArg, "/tmp/infra")
+			actual := terraformInstance.SelectWorkspace(context.Background(), "staging", true)
+			require.Nil(t, actual)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"when workspace does not exist and create is not set, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"terraform",
+				[]string{"workspace", "select", "-no-color", "staging"},
+				[]string(nil),
+				"/tmp/infra",
+			).Return([]byte{}, []byte("Workspace \"staging\" doesn't exist."), errors.New("exit status 1"))
+
+			terraformInstance := NewTerraform(executorArg, "/tmp/infra")
+			actual := terraformInstance.SelectWorkspace(context.Background(), "staging", false)
+			require.NotNil(t, actual)
+			assert.Contains(t, actual.Error(), "doesn't exist")
+		},
+	)
+}
+
+func TestTerraform_Plan(t *testing.T) {
+	t.Run(
+		"it streams the messages and returns the summary of planned changes",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteWithStreamsContext",
+				context.Background(),
+				"terraform",
+				[]string{
+					"plan",
+					"-input=false",
+					"-json",
+					"-out=plan.tfplan",
+					"-var",
+					"env=staging",
+					"-var",
+					"region=eu-west-1",
+					"-var-file=staging.tfvars",
+				},
+				[]string(nil),
+				"/tmp/infra",
+				mock.Anything,
+				mock.Anything,
+			).Run(func(args mock.Arguments) {
+				stdout := args.Get(5).(io.Writer)
+				// NOTE: Write in chunks that split messages, as a running process would.
+				_, _ = stdout.Write([]byte(fakeTerraformPlanOutput[:100]))
+				_, _ = stdout.Write([]byte(fakeTerraformPlanOutput[100:]))
+			}).Return(nil)
+
+			var messageTypes []string
+
+			terraformInstance := NewTerraform(executorArg, "/tmp/infra")
+			actual, actualErr := terraformInstance.Plan(
+				context.Background(),
+				&TerraformPlanOptions{
+					Vars:     map[string]string{"region": "eu-west-1", "env": "staging"},
+					VarFiles: []string{"staging.tfvars"},
+					Out:      "plan.tfplan",
+					OnMessage: func(message *TerraformMessage) {
+						messageTypes = append(messageTypes, message.Type)
+					},
+				},
+			)
+			require.Nil(t, actualErr)
+
+			assert.Equal(t, []string{"aws_s3_bucket.logs"}, actual.Creates)
+			assert.Equal(t, []string{"module.dns.aws_route53_record.www"}, actual.Updates)
+			assert.Empty(t, actual.Deletes)
+			assert.Equal(t, []string{"aws_instance.web"}, actual.Replaces)
+			assert.Equal(t, TerraformChangeCounts{Add: 2, Change: 1, Remove: 1, Operation: "plan"}, actual.Counts)
+			assert.True(t, actual.HasChanges())
+			assert.Equal(
+				t,
+				[]string{"version", "planned_change", "planned_change", "planned_change", "change_summary"},
+				messageTypes,
+			)
+		},
+	)
+
+	t.Run(
+		"when planning fails, it returns error with error diagnostics",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteWithStreamsContext",
+				context.Background(),
+				"terraform",
+				[]string{"plan", "-input=false", "-json"},
+				[]string(nil),
+				"/tmp/infra",
+				mock.Anything,
+				mock.Anything,
+			).Run(func(args mock.Arguments) {
+				_, _ = args.Get(5).(io.Writer).Write([]byte(
+					`{"@level":"error","type":"diagnostic","diagnostic":` +
+						`{"severity":"error","summary":"Invalid reference","detail":"fake detail"}}`,
+				))
+			}).Return(errors.New("exit status 1"))
+
+			terraformInstance := NewTerraform(executorArg, "/tmp/infra")
+			actual, actualErr := terraformInstance.Plan(context.Background(), nil)
+			require.NotNil(t, actualErr)
+			assert.Nil(t, actual)
+			assert.Contains(t, actualErr.Error(), "Invalid reference: fake detail")
+		},
+	)
+}
+
+func TestTerraform_Apply(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteWithStreamsContext",
+		context.Background(),
+		"terraform",
+		[]string{"apply", "-input=false", "-auto-approve", "-json", "plan.tfplan"},
+		[]string(nil),
+		"/tmp/infra",
+		mock.Anything,
+		mock.Anything,
+	).Run(func(args mock.Arguments) {
+		_, _ = args.Get(5).(io.Writer).Write([]byte(
+			`{"type":"change_summary","changes":{"add":1,"change":0,"remove":0,"operation":"apply"}}` + "\n",
+		))
+	}).Return(nil)
+
+	terraformInstance := NewTerraform(executorArg, "/tmp/infra")
+	actual, actualErr := terraformInstance.Apply(
+		context.Background(),
+		&TerraformApplyOptions{PlanFile: "plan.tfplan", Vars: map[string]string{"ignored": "true"}},
+	)
+	require.Nil(t, actualErr)
+	assert.Equal(t, TerraformChangeCounts{Add: 1, Operation: "apply"}, actual.Counts)
+}
+
+func TestTerraform_Output(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"terraform",
+		[]string{"output", "-json"},
+		[]string(nil),
+		"/tmp/infra",
+	).Return(
+		[]byte(`{"bucket":{"sensitive":false,"type":"string","value":"logs"},`+
+			`"password":{"sensitive":true,"type":"string","value":"secret"}}`),
+		[]byte{},
+		nil,
+	)
+
+	terraformInstance := NewTerraform(executorArg, "/tmp/infra")
+	actual, actualErr := terraformInstance.Output(context.Background())
+	require.Nil(t, actualErr)
+
+	require.Len(t, actual, 2)
+	assert.Equal(t, `"logs"`, string(actual["bucket"].Value))
+	assert.True(t, actual["password"].Sensitive)
+}
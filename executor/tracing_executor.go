@@ -0,0 +1,197 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"github.com/sumup-oss/go-pkgs/os"
+	"github.com/sumup-oss/go-pkgs/tracing"
+)
+
+var (
+	_ os.OsExecutor           = (*TracingExecutor)(nil)
+	_ os.StdinCommandExecutor = (*TracingExecutor)(nil)
+)
+
+// secretFlags are the flags whose values are redacted from the args attribute,
+// even when the secrets are not added to the redactor.
+var secretFlags = map[string]bool{
+	"--client-secret":   true,
+	"--creds":           true,
+	"--dest-creds":      true,
+	"--federated-token": true,
+	"--password":        true,
+	"--secret":          true,
+	"--src-creds":       true,
+	"--token":           true,
+}
+
+// TracingExecutor is os.OsExecutor decorator, that traces the commands of the Execute methods
+// as children of the spans of their context, with the binary, args, exit code and duration attributes.
+type TracingExecutor struct {
+	os.OsExecutor
+
+	tracer   tracing.Tracer
+	redactor *logger.Redactor
+}
+
+// NewTracingExecutor creates TracingExecutor instance, that redacts the secrets of redactor, if any,
+// and the values of the secret flags, e.g `--password`, from the args attribute.
+func NewTracingExecutor(osExecutor os.OsExecutor, tracer tracing.Tracer, redactor *logger.Redactor) *TracingExecutor {
+	return &TracingExecutor{
+		OsExecutor: osExecutor,
+		tracer:     tracer,
+		redactor:   redactor,
+	}
+}
+
+func (c *TracingExecutor) Execute(cmd string, arg []string, env []string, dir string) ([]byte, []byte, error) {
+	span, finish := c.start(context.Background(), cmd, arg)
+	defer span.End()
+
+	stdout, stderr, err := c.OsExecutor.Execute(cmd, arg, env, dir)
+	finish(err)
+
+	return stdout, stderr, err
+}
+
+func (c *TracingExecutor) ExecuteContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+) ([]byte, []byte, error) {
+	span, finish := c.start(ctx, cmd, arg)
+	defer span.End()
+
+	stdout, stderr, err := c.OsExecutor.ExecuteContext(ctx, cmd, arg, env, dir)
+	finish(err)
+
+	return stdout, stderr, err
+}
+
+func (c *TracingExecutor) ExecuteWithStdinContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+	stdin io.Reader,
+) ([]byte, []byte, error) {
+	stdinExecutor, ok := c.OsExecutor.(os.StdinCommandExecutor)
+	if !ok {
+		return nil, nil, stacktrace.NewError("command executor does not support executing with stdin")
+	}
+
+	span, finish := c.start(ctx, cmd, arg)
+	defer span.End()
+
+	stdout, stderr, err := stdinExecutor.ExecuteWithStdinContext(ctx, cmd, arg, env, dir, stdin)
+	finish(err)
+
+	return stdout, stderr, err
+}
+
+func (c *TracingExecutor) ExecuteWithStreams(
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+	stdout io.Writer,
+	stderr io.Writer,
+) error {
+	span, finish := c.start(context.Background(), cmd, arg)
+	defer span.End()
+
+	err := c.OsExecutor.ExecuteWithStreams(cmd, arg, env, dir, stdout, stderr)
+	finish(err)
+
+	return err
+}
+
+func (c *TracingExecutor) ExecuteWithStreamsContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+	stdout io.Writer,
+	stderr io.Writer,
+) error {
+	span, finish := c.start(ctx, cmd, arg)
+	defer span.End()
+
+	err := c.OsExecutor.ExecuteWithStreamsContext(ctx, cmd, arg, env, dir, stdout, stderr)
+	finish(err)
+
+	return err
+}
+
+// start starts the span of a command and returns it along with the func recording the outcome of the command.
+//
+// NOTE: The span context is not passed to the command executor, since the commands are the leaves of the traces.
+func (c *TracingExecutor) start(ctx context.Context, cmd string, arg []string) (tracing.Span, func(err error)) {
+	binary := filepath.Base(cmd)
+
+	_, span := c.tracer.Start(
+		ctx,
+		"exec "+binary,
+		tracing.String("process.executable.name", binary),
+		tracing.Strings("process.command_args", c.redact(arg)),
+	)
+
+	start := time.Now()
+
+	return span, func(err error) {
+		span.SetAttributes(
+			tracing.Int("process.exit_code", exitCode(err)),
+			tracing.Float64("process.duration_seconds", time.Since(start).Seconds()),
+		)
+		span.RecordError(err)
+	}
+}
+
+func (c *TracingExecutor) redact(arg []string) []string {
+	redacted := make([]string, len(arg))
+
+	for i, item := range arg {
+		switch {
+		case i > 0 && secretFlags[arg[i-1]]:
+			item = logger.RedactedPlaceholder
+		case strings.HasPrefix(item, "--") && strings.Contains(item, "="):
+			flag := strings.SplitN(item, "=", 2)[0]
+			if secretFlags[flag] {
+				item = flag + "=" + logger.RedactedPlaceholder
+			}
+		}
+
+		if c.redactor != nil {
+			item = c.redactor.Redact(item)
+		}
+
+		redacted[i] = item
+	}
+
+	return redacted
+}
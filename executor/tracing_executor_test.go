@@ -0,0 +1,125 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+	"github.com/sumup-oss/go-pkgs/tracing/tracingtest"
+)
+
+func TestTracingExecutor_ExecuteContext(t *testing.T) {
+	t.Run(
+		"it traces the command as a child of the context span with the args redacted",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"/usr/bin/vault",
+				[]string{"login", "token=s3cr3t"},
+				[]string(nil),
+				"",
+			).Return([]byte{}, []byte("permission denied"), errors.New("permission denied")).Once()
+
+			redactor := logger.NewRedactor()
+			redactor.Add("s3cr3t")
+
+			tracer := tracingtest.NewRecordingTracer()
+			tracingExecutor := NewTracingExecutor(executorArg, tracer, redactor)
+
+			_, _, err := tracingExecutor.ExecuteContext(
+				context.Background(),
+				"/usr/bin/vault",
+				[]string{"login", "token=s3cr3t"},
+				nil,
+				"",
+			)
+			require.Error(t, err)
+
+			spans := tracer.Spans()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "exec vault", spans[0].Name)
+			assert.Nil(t, spans[0].Parent)
+			assert.Equal(t, "vault", spans[0].Attributes["process.executable.name"])
+			assert.Equal(t, []string{"login", "token=[REDACTED]"}, spans[0].Attributes["process.command_args"])
+			assert.Equal(t, UnknownExitCode, spans[0].Attributes["process.exit_code"])
+			assert.Contains(t, spans[0].Attributes, "process.duration_seconds")
+			assert.Equal(t, err, spans[0].Err)
+			assert.True(t, spans[0].Ended)
+			executorArg.AssertExpectations(t)
+		},
+	)
+	t.Run(
+		"it redacts the values of the secret flags",
+		func(t *testing.T) {
+			t.Parallel()
+
+			arg := []string{
+				"login",
+				"--service-principal",
+				"--username",
+				"app-id",
+				"--password",
+				"client-secret",
+				"--federated-token=federated-token",
+				"--tenant=tenant-id",
+			}
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On("ExecuteContext", context.Background(), "az", arg, []string(nil), "").
+				Return([]byte{}, []byte{}, nil).
+				Once()
+
+			tracer := tracingtest.NewRecordingTracer()
+
+			_, _, err := NewTracingExecutor(executorArg, tracer, nil).ExecuteContext(
+				context.Background(),
+				"az",
+				arg,
+				nil,
+				"",
+			)
+			require.NoError(t, err)
+
+			spans := tracer.Spans()
+			require.Len(t, spans, 1)
+			assert.Equal(
+				t,
+				[]string{
+					"login",
+					"--service-principal",
+					"--username",
+					"app-id",
+					"--password",
+					"[REDACTED]",
+					"--federated-token=[REDACTED]",
+					"--tenant=tenant-id",
+				},
+				spans[0].Attributes["process.command_args"],
+			)
+			executorArg.AssertExpectations(t)
+		},
+	)
+}
@@ -0,0 +1,389 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"time"
+
+	pkgOs "github.com/sumup-oss/go-pkgs/os"
+	"github.com/sumup-oss/go-pkgs/tracing"
+)
+
+var _ KubectlInterface = (*TracingKubectl)(nil)
+
+const namespaceAttribute = "k8s.namespace.name"
+
+// TracingKubectl is a Kubectl decorator, that traces the high-level operations, e.g `kubectl.RolloutStatus`,
+// as children of the span of its context. The operations execute their commands with the span context,
+// so that with TracingExecutor the commands are traced as children of the operations.
+type TracingKubectl struct {
+	kubectl *Kubectl
+	tracer  tracing.Tracer
+	ctx     context.Context
+}
+
+func NewTracingKubectl(kubectl *Kubectl, tracer tracing.Tracer) *TracingKubectl {
+	return &TracingKubectl{
+		kubectl: kubectl,
+		tracer:  tracer,
+		ctx:     kubectl.ctx,
+	}
+}
+
+// WithContext returns a shallow copy of the tracing kubectl, that traces the operations as children of
+// the span of `ctx`, and executes the commands with it, e.g `kubectl.WithContext(ctx).Apply(manifest, namespace)`.
+func (k *TracingKubectl) WithContext(ctx context.Context) *TracingKubectl {
+	kubectl := *k
+	kubectl.ctx = ctx
+
+	return &kubectl
+}
+
+func (k *TracingKubectl) trace(operation string, fn func(kubectl *Kubectl) error, attributes ...tracing.Attribute) error {
+	ctx := k.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, span := k.tracer.Start(ctx, "kubectl."+operation, attributes...)
+	defer span.End()
+
+	err := fn(k.kubectl.WithContext(ctx))
+	span.RecordError(err)
+
+	return err
+}
+
+func (k *TracingKubectl) Apply(manifest string, namespace string) error {
+	return k.trace(
+		"Apply",
+		func(kubectl *Kubectl) error {
+			return kubectl.Apply(manifest, namespace)
+		},
+		tracing.String(namespaceAttribute, namespace),
+	)
+}
+
+func (k *TracingKubectl) ApplyData(manifest []byte, namespace string) error {
+	return k.trace(
+		"ApplyData",
+		func(kubectl *Kubectl) error {
+			return kubectl.ApplyData(manifest, namespace)
+		},
+		tracing.String(namespaceAttribute, namespace),
+	)
+}
+
+func (k *TracingKubectl) Delete(manifest string) error {
+	return k.trace("Delete", func(kubectl *Kubectl) error {
+		return kubectl.Delete(manifest)
+	})
+}
+
+func (k *TracingKubectl) Create(manifest string) error {
+	return k.trace("Create", func(kubectl *Kubectl) error {
+		return kubectl.Create(manifest)
+	})
+}
+
+func (k *TracingKubectl) ClusterInfo() error {
+	return k.trace("ClusterInfo", func(kubectl *Kubectl) error {
+		return kubectl.ClusterInfo()
+	})
+}
+
+func (k *TracingKubectl) GetToken() ([]byte, error) {
+	var token []byte
+
+	err := k.trace("GetToken", func(kubectl *Kubectl) error {
+		var err error
+		token, err = kubectl.GetToken()
+
+		return err
+	})
+
+	return token, err
+}
+
+func (k *TracingKubectl) GetServiceAccountSecret(namespace, name, dataKeyName string) (string, error) {
+	var secret string
+
+	err := k.trace(
+		"GetServiceAccountSecret",
+		func(kubectl *Kubectl) error {
+			var err error
+			secret, err = kubectl.GetServiceAccountSecret(namespace, name, dataKeyName)
+
+			return err
+		},
+		tracing.String(namespaceAttribute, namespace),
+		tracing.String("k8s.serviceaccount.name", name),
+	)
+
+	return secret, err
+}
+
+func (k *TracingKubectl) GetIngressHost(namespace, name string) (string, error) {
+	var host string
+
+	err := k.trace(
+		"GetIngressHost",
+		func(kubectl *Kubectl) error {
+			var err error
+			host, err = kubectl.GetIngressHost(namespace, name)
+
+			return err
+		},
+		tracing.String(namespaceAttribute, namespace),
+		tracing.String("k8s.ingress.name", name),
+	)
+
+	return host, err
+}
+
+func (k *TracingKubectl) GetServices(namespace string) ([]*KubernetesService, error) {
+	var services []*KubernetesService
+
+	err := k.trace(
+		"GetServices",
+		func(kubectl *Kubectl) error {
+			var err error
+			services, err = kubectl.GetServices(namespace)
+
+			return err
+		},
+		tracing.String(namespaceAttribute, namespace),
+	)
+
+	return services, err
+}
+
+func (k *TracingKubectl) GetService(name, namespace string) (*KubernetesService, error) {
+	var service *KubernetesService
+
+	err := k.trace(
+		"GetService",
+		func(kubectl *Kubectl) error {
+			var err error
+			service, err = kubectl.GetService(name, namespace)
+
+			return err
+		},
+		tracing.String(namespaceAttribute, namespace),
+		tracing.String("k8s.service.name", name),
+	)
+
+	return service, err
+}
+
+func (k *TracingKubectl) ApplyConfigmap(name, namespace string, data map[string]string) error {
+	return k.trace(
+		"ApplyConfigmap",
+		func(kubectl *Kubectl) error {
+			return kubectl.ApplyConfigmap(name, namespace, data)
+		},
+		tracing.String(namespaceAttribute, namespace),
+		tracing.String("k8s.configmap.name", name),
+	)
+}
+
+func (k *TracingKubectl) ApplyService(service *KubernetesService) error {
+	var attributes []tracing.Attribute
+	if service != nil && service.Metadata != nil {
+		attributes = append(
+			attributes,
+			tracing.String(namespaceAttribute, service.Metadata.Namespace),
+			tracing.String("k8s.service.name", service.Metadata.Name),
+		)
+	}
+
+	return k.trace(
+		"ApplyService",
+		func(kubectl *Kubectl) error {
+			return kubectl.ApplyService(service)
+		},
+		attributes...,
+	)
+}
+
+func (k *TracingKubectl) GetServiceFQDN(namespace, serviceName string) (string, error) {
+	var fqdn string
+
+	err := k.trace(
+		"GetServiceFQDN",
+		func(kubectl *Kubectl) error {
+			var err error
+			fqdn, err = kubectl.GetServiceFQDN(namespace, serviceName)
+
+			return err
+		},
+		tracing.String(namespaceAttribute, namespace),
+		tracing.String("k8s.service.name", serviceName),
+	)
+
+	return fqdn, err
+}
+
+func (k *TracingKubectl) GetServiceMeta(namespace, serviceName, key string) (string, error) {
+	var value string
+
+	err := k.trace(
+		"GetServiceMeta",
+		func(kubectl *Kubectl) error {
+			var err error
+			value, err = kubectl.GetServiceMeta(namespace, serviceName, key)
+
+			return err
+		},
+		tracing.String(namespaceAttribute, namespace),
+		tracing.String("k8s.service.name", serviceName),
+	)
+
+	return value, err
+}
+
+func (k *TracingKubectl) GetServicePort(namespace, serviceName, portName string) (string, error) {
+	var port string
+
+	err := k.trace(
+		"GetServicePort",
+		func(kubectl *Kubectl) error {
+			var err error
+			port, err = kubectl.GetServicePort(namespace, serviceName, portName)
+
+			return err
+		},
+		tracing.String(namespaceAttribute, namespace),
+		tracing.String("k8s.service.name", serviceName),
+	)
+
+	return port, err
+}
+
+func (k *TracingKubectl) GetIngresses(namespace string) ([]*KubernetesIngress, error) {
+	var ingresses []*KubernetesIngress
+
+	err := k.trace(
+		"GetIngresses",
+		func(kubectl *Kubectl) error {
+			var err error
+			ingresses, err = kubectl.GetIngresses(namespace)
+
+			return err
+		},
+		tracing.String(namespaceAttribute, namespace),
+	)
+
+	return ingresses, err
+}
+
+func (k *TracingKubectl) RolloutStatus(timeout time.Duration, resource, namespace string) error {
+	return k.trace(
+		"RolloutStatus",
+		func(kubectl *Kubectl) error {
+			return kubectl.RolloutStatus(timeout, resource, namespace)
+		},
+		tracing.String(namespaceAttribute, namespace),
+		tracing.String("k8s.resource", resource),
+	)
+}
+
+func (k *TracingKubectl) JobStatus(name, namespace string) (KubernetesJobStatus, error) {
+	var status KubernetesJobStatus
+
+	err := k.trace(
+		"JobStatus",
+		func(kubectl *Kubectl) error {
+			var err error
+			status, err = kubectl.JobStatus(name, namespace)
+
+			return err
+		},
+		tracing.String(namespaceAttribute, namespace),
+		tracing.String("k8s.job.name", name),
+	)
+
+	return status, err
+}
+
+func (k *TracingKubectl) WaitForJob(
+	timeout,
+	pollInterval time.Duration,
+	name,
+	namespace string,
+) (KubernetesJobStatus, error) {
+	var status KubernetesJobStatus
+
+	err := k.trace(
+		"WaitForJob",
+		func(kubectl *Kubectl) error {
+			var err error
+			status, err = kubectl.WaitForJob(timeout, pollInterval, name, namespace)
+
+			return err
+		},
+		tracing.String(namespaceAttribute, namespace),
+		tracing.String("k8s.job.name", name),
+	)
+
+	return status, err
+}
+
+func (k *TracingKubectl) DeleteResource(namespace, resourceType, resourceName string) error {
+	return k.trace(
+		"DeleteResource",
+		func(kubectl *Kubectl) error {
+			return kubectl.DeleteResource(namespace, resourceType, resourceName)
+		},
+		tracing.String(namespaceAttribute, namespace),
+		tracing.String("k8s.resource", resourceType+"/"+resourceName),
+	)
+}
+
+func (k *TracingKubectl) DeleteAllResources(namespace, resourceType string) error {
+	return k.trace(
+		"DeleteAllResources",
+		func(kubectl *Kubectl) error {
+			return kubectl.DeleteAllResources(namespace, resourceType)
+		},
+		tracing.String(namespaceAttribute, namespace),
+		tracing.String("k8s.resource", resourceType),
+	)
+}
+
+func (k *TracingKubectl) DeleteAllResourcesByLabel(namespace string, labels map[string]string) error {
+	return k.trace(
+		"DeleteAllResourcesByLabel",
+		func(kubectl *Kubectl) error {
+			return kubectl.DeleteAllResourcesByLabel(namespace, labels)
+		},
+		tracing.String(namespaceAttribute, namespace),
+	)
+}
+
+func (k *TracingKubectl) DeleteAllResourcesByLabelInNamespaces(namespaces []string, labels map[string]string) error {
+	return k.trace(
+		"DeleteAllResourcesByLabelInNamespaces",
+		func(kubectl *Kubectl) error {
+			return kubectl.DeleteAllResourcesByLabelInNamespaces(namespaces, labels)
+		},
+		tracing.Strings("k8s.namespace.names", namespaces),
+	)
+}
+
+func (k *TracingKubectl) ResetExecutor(commandExecutor pkgOs.CommandExecutor) pkgOs.CommandExecutor {
+	return k.kubectl.ResetExecutor(commandExecutor)
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+	"github.com/sumup-oss/go-pkgs/tracing/tracingtest"
+)
+
+func TestTracingKubectl_RolloutStatus(t *testing.T) {
+	t.Run(
+		"it traces the operation as a child of the context span and its commands as children of the operation",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteContext",
+				mock.Anything,
+				"kubectl",
+				[]string{"-n", "default", "rollout", "status", "deployment/foo", "--timeout", "5s"},
+				[]string(nil),
+				"",
+			).Return([]byte("rolled out"), []byte(nil), nil).Once()
+
+			tracer := tracingtest.NewRecordingTracer()
+			ctx, parent := tracer.Start(context.Background(), "deploy")
+
+			kubectl := NewKubectl(NewTracingExecutor(executorArg, tracer, nil), "", "svc.cluster.local")
+			tracingKubectl := NewTracingKubectl(kubectl, tracer).WithContext(ctx)
+
+			err := tracingKubectl.RolloutStatus(5*time.Second, "deployment/foo", "default")
+			require.Nil(t, err)
+
+			spans := tracer.Spans()
+			require.Len(t, spans, 3)
+			assert.Equal(t, "kubectl.RolloutStatus", spans[1].Name)
+			assert.Equal(t, parent, spans[1].Parent)
+			assert.Equal(t, "default", spans[1].Attributes["k8s.namespace.name"])
+			assert.Equal(t, "deployment/foo", spans[1].Attributes["k8s.resource"])
+			assert.True(t, spans[1].Ended)
+			assert.Equal(t, "exec kubectl", spans[2].Name)
+			assert.Equal(t, spans[1], spans[2].Parent)
+			assert.Equal(t, 0, spans[2].Attributes["process.exit_code"])
+			executorArg.AssertExpectations(t)
+		},
+	)
+}
+
+func TestTracingKubectl_GetServiceFQDN(t *testing.T) {
+	t.Run(
+		"it returns the result of the operation without a context",
+		func(t *testing.T) {
+			t.Parallel()
+
+			tracer := tracingtest.NewRecordingTracer()
+			kubectl := NewKubectl(ostest.NewFakeOsExecutor(t), "", "svc.cluster.local")
+
+			fqdn, err := NewTracingKubectl(kubectl, tracer).GetServiceFQDN("default", "api")
+			require.Nil(t, err)
+			assert.Equal(t, "api.default.svc.cluster.local", fqdn)
+
+			spans := tracer.Spans()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "kubectl.GetServiceFQDN", spans[0].Name)
+			assert.Equal(t, "api", spans[0].Attributes["k8s.service.name"])
+			assert.Nil(t, spans[0].Err)
+		},
+	)
+}
@@ -0,0 +1,352 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+// defaultVaultMinRedactedLength is the default minimum length of the redacted data values.
+const defaultVaultMinRedactedLength = 8
+
+type VaultOptions struct {
+	// Address is the address of the Vault server, when not `VAULT_ADDR`.
+	Address   string
+	Namespace string
+	// MinRedactedLength is the minimum length of the KV and lease data values added to the redactor,
+	// 8 when not set. Shorter values, e.g `true` or a port, would redact each of their occurrences in the logs.
+	MinRedactedLength int
+}
+
+// VaultLoginOptions are the options of Vault login.
+// The approle, kubernetes and jwt methods log in by writing Params to `auth/<Path>/login`,
+// the userpass, ldap, okta and radius methods by writing Params, except `username`,
+// to `auth/<Path>/login/<username>`, the rest with `vault login -method=<Method>`, e.g `oidc` or `token`.
+// Params are never passed as arguments of the CLI, since they're visible to the other processes.
+type VaultLoginOptions struct {
+	Method string
+	// Path is the mount path of the auth method, when not the default `Method`.
+	Path   string
+	Params map[string]string
+}
+
+// vaultSecretLoginParams are the login params, whose values are added to the redactor.
+var vaultSecretLoginParams = map[string]bool{
+	"jwt":       true,
+	"password":  true,
+	"secret_id": true,
+	"token":     true,
+}
+
+type VaultAuth struct {
+	ClientToken   string   `json:"client_token"`
+	Accessor      string   `json:"accessor"`
+	Policies      []string `json:"policies"`
+	LeaseDuration int      `json:"lease_duration"`
+	Renewable     bool     `json:"renewable"`
+}
+
+// VaultLease is a secret with a lease, e.g dynamic database credentials.
+type VaultLease struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+}
+
+type vaultSecret struct {
+	VaultLease
+	Auth *VaultAuth `json:"auth"`
+}
+
+// Vault wraps the Vault CLI. Every retrieved secret is added to the redactor, when present,
+// so that a logger.RedactingLogger never logs it. The KV and lease data values are added
+// only when at least VaultOptions.MinRedactedLength long.
+// The commands are executed with stdin, that has the secrets written to the CLI, so the command executor
+// must implement os.StdinCommandExecutor.
+type Vault struct {
+	binPath         string
+	options         VaultOptions
+	token           string
+	redactor        *logger.Redactor
+	commandExecutor os.CommandExecutor
+}
+
+func NewVault(executor os.CommandExecutor, options *VaultOptions, redactor *logger.Redactor) *Vault {
+	vault := &Vault{
+		binPath:         "vault",
+		redactor:        redactor,
+		commandExecutor: executor,
+	}
+
+	if options != nil {
+		vault.options = *options
+	}
+
+	return vault
+}
+
+// SetToken sets the token of the executed commands, e.g issued by another login.
+func (vault *Vault) SetToken(token string) {
+	vault.redact(token)
+	vault.token = token
+}
+
+// Login logs in and uses the issued token for the executed commands.
+// The token is not stored in the token helper of the Vault CLI.
+func (vault *Vault) Login(ctx context.Context, options *VaultLoginOptions) (*VaultAuth, error) {
+	mountPath := options.Path
+	if mountPath == "" {
+		mountPath = options.Method
+	}
+
+	for key, value := range options.Params {
+		if vaultSecretLoginParams[key] {
+			vault.redact(value)
+		}
+	}
+
+	var secret *vaultSecret
+
+	var err error
+
+	switch options.Method {
+	case "approle", "kubernetes", "jwt":
+		secret, err = vault.executeJSON(
+			ctx,
+			options.Params,
+			"write",
+			"-format=json",
+			path.Join("auth", mountPath, "login"),
+			"-",
+		)
+	case "userpass", "ldap", "okta", "radius":
+		params := make(map[string]string, len(options.Params))
+		for key, value := range options.Params {
+			if key != "username" {
+				params[key] = value
+			}
+		}
+
+		secret, err = vault.executeJSON(
+			ctx,
+			params,
+			"write",
+			"-format=json",
+			path.Join("auth", mountPath, "login", options.Params["username"]),
+			"-",
+		)
+	default:
+		secret, err = vault.loginWithCLI(ctx, options.Method, mountPath, options.Params)
+	}
+
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "vault login with %s method failed", options.Method)
+	}
+
+	if secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return nil, stacktrace.NewError("vault login with %s method issued no token", options.Method)
+	}
+
+	vault.SetToken(secret.Auth.ClientToken)
+
+	return secret.Auth, nil
+}
+
+// loginWithCLI logs in with `vault login -method=<method>`. The CLI reads the value of a `<key>=-` param from stdin,
+// so a secret param, e.g `token`, is written to stdin and the rest are passed as arguments.
+func (vault *Vault) loginWithCLI(
+	ctx context.Context,
+	method,
+	mountPath string,
+	params map[string]string,
+) (*vaultSecret, error) {
+	args := []string{"login", "-format=json", "-no-store", "-method=" + method, "-path=" + mountPath}
+
+	var stdin []byte
+
+	secretKey := ""
+
+	for _, key := range sortedKeys(params) {
+		if !vaultSecretLoginParams[key] {
+			args = append(args, key+"="+params[key])
+			continue
+		}
+
+		if secretKey != "" {
+			return nil, stacktrace.NewError("only one of %s and %s params can be passed to %s method", secretKey, key, method)
+		}
+
+		secretKey = key
+		stdin = []byte(params[key])
+		args = append(args, key+"=-")
+	}
+
+	return vault.execute(ctx, stdin, args...)
+}
+
+// KVGet returns the data of the latest version of the secret at `secretPath` of the KV mount.
+// Both KV v1 and v2 are supported.
+func (vault *Vault) KVGet(ctx context.Context, mount, secretPath string) (map[string]interface{}, error) {
+	secret, err := vault.executeJSON(ctx, nil, "kv", "get", "-format=json", "-mount="+mount, secretPath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "vault kv get %s failed", path.Join(mount, secretPath))
+	}
+
+	data := secret.Data
+
+	// NOTE: KV v2 nests the data under `data` along with `metadata`.
+	if nested, ok := secret.Data["data"].(map[string]interface{}); ok {
+		if _, ok := secret.Data["metadata"]; ok {
+			data = nested
+		}
+	}
+
+	vault.redactData(data)
+
+	return data, nil
+}
+
+// KVPut writes the data as the secret at `secretPath` of the KV mount.
+func (vault *Vault) KVPut(ctx context.Context, mount, secretPath string, data map[string]string) error {
+	for _, value := range data {
+		vault.redactValue(value)
+	}
+
+	_, err := vault.executeJSON(ctx, data, "kv", "put", "-format=json", "-mount="+mount, secretPath, "-")
+	return stacktrace.Propagate(err, "vault kv put %s failed", path.Join(mount, secretPath))
+}
+
+// ReadCredentials issues dynamic credentials, e.g of `database/creds/<role>`.
+func (vault *Vault) ReadCredentials(ctx context.Context, credentialsPath string) (*VaultLease, error) {
+	secret, err := vault.executeJSON(ctx, nil, "read", "-format=json", credentialsPath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "vault read %s failed", credentialsPath)
+	}
+
+	vault.redactData(secret.Data)
+
+	return &secret.VaultLease, nil
+}
+
+// executeJSON executes the command with the JSON encoded input written to stdin, when present,
+// and decodes its JSON output.
+func (vault *Vault) executeJSON(ctx context.Context, input interface{}, args ...string) (*vaultSecret, error) {
+	var stdin []byte
+
+	if input != nil {
+		encoded, err := json.Marshal(input)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "json encode command input failed")
+		}
+
+		stdin = encoded
+	}
+
+	return vault.execute(ctx, stdin, args...)
+}
+
+// execute executes the command with stdin and decodes its JSON output.
+// NOTE: Every command is executed with stdin, even when it's empty, since the decorators of the command executor,
+// e.g ExecuteLogger, never log the output of the commands executed with stdin, that has secrets.
+func (vault *Vault) execute(ctx context.Context, stdin []byte, args ...string) (*vaultSecret, error) {
+	stdinExecutor, ok := vault.commandExecutor.(os.StdinCommandExecutor)
+	if !ok {
+		return nil, stacktrace.NewError("command executor does not support executing with stdin")
+	}
+
+	stdout, stderr, err := stdinExecutor.ExecuteWithStdinContext(
+		ctx,
+		vault.binPath,
+		args,
+		vault.env(),
+		"",
+		bytes.NewReader(stdin),
+	)
+	// NOTE: Never include stdout in errors, since it may contain secrets.
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s", stderr)
+	}
+
+	var secret vaultSecret
+
+	if len(bytes.TrimSpace(stdout)) == 0 {
+		return &secret, nil
+	}
+
+	err = json.Unmarshal(stdout, &secret)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "json decode command `vault %s` output failed", args[0])
+	}
+
+	return &secret, nil
+}
+
+func (vault *Vault) redactData(data map[string]interface{}) {
+	for _, value := range data {
+		switch v := value.(type) {
+		case string:
+			vault.redactValue(v)
+		case map[string]interface{}:
+			vault.redactData(v)
+		}
+	}
+}
+
+// redactValue adds the data value to the redactor, unless it's shorter than the minimum length.
+func (vault *Vault) redactValue(value string) {
+	minLength := vault.options.MinRedactedLength
+	if minLength <= 0 {
+		minLength = defaultVaultMinRedactedLength
+	}
+
+	if len(value) < minLength {
+		return
+	}
+
+	vault.redact(value)
+}
+
+func (vault *Vault) redact(secret string) {
+	if vault.redactor != nil {
+		vault.redactor.Add(secret)
+	}
+}
+
+func (vault *Vault) env() []string {
+	var env []string
+
+	if vault.options.Address != "" {
+		env = append(env, "VAULT_ADDR="+vault.options.Address)
+	}
+
+	if vault.options.Namespace != "" {
+		env = append(env, "VAULT_NAMESPACE="+vault.options.Namespace)
+	}
+
+	if vault.token != "" {
+		env = append(env, "VAULT_TOKEN="+vault.token)
+	}
+
+	return os.CommandEnv(env...)
+}
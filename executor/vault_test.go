@@ -0,0 +1,331 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+const fakeVaultLoginOutput = `{
+	"auth": {
+		"client_token": "hvs.faketoken",
+		"accessor": "fakeaccessor",
+		"policies": ["default", "deploy"],
+		"lease_duration": 3600,
+		"renewable": true
+	}
+}`
+
+func TestVault_Login(t *testing.T) {
+	t.Run(
+		"with approle method, it writes the params to stdin and uses the issued token",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"vault",
+				[]string{"write", "-format=json", "auth/ci-approle/login", "-"},
+				mock.MatchedBy(func(env []string) bool {
+					return containsString(env, "VAULT_ADDR=https://vault.example.com")
+				}),
+				"",
+				bytes.NewReader([]byte(`{"role_id":"fakeroleid","secret_id":"fakesecretid"}`)),
+			).Return([]byte(fakeVaultLoginOutput), []byte{}, nil)
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"vault",
+				[]string{"read", "-format=json", "database/creds/readonly"},
+				mock.MatchedBy(func(env []string) bool {
+					return containsString(env, "VAULT_TOKEN=hvs.faketoken")
+				}),
+				"",
+				bytes.NewReader(nil),
+			).Return(
+				[]byte(`{
+					"lease_id": "database/creds/readonly/fake",
+					"lease_duration": 600,
+					"renewable": true,
+					"data": {"username": "v-fake-user", "password": "fakepassword"}
+				}`),
+				[]byte{},
+				nil,
+			)
+
+			redactor := logger.NewRedactor()
+			vaultInstance := NewVault(executorArg, &VaultOptions{Address: "https://vault.example.com"}, redactor)
+
+			actual, actualErr := vaultInstance.Login(
+				context.Background(),
+				&VaultLoginOptions{
+					Method: "approle",
+					Path:   "ci-approle",
+					Params: map[string]string{"role_id": "fakeroleid", "secret_id": "fakesecretid"},
+				},
+			)
+			require.Nil(t, actualErr)
+			assert.Equal(t, "hvs.faketoken", actual.ClientToken)
+			assert.Equal(t, []string{"default", "deploy"}, actual.Policies)
+			assert.Equal(t, 3600, actual.LeaseDuration)
+
+			lease, err := vaultInstance.ReadCredentials(context.Background(), "database/creds/readonly")
+			require.Nil(t, err)
+			assert.Equal(t, "database/creds/readonly/fake", lease.LeaseID)
+			assert.Equal(t, "fakepassword", lease.Data["password"])
+
+			assert.Equal(
+				t,
+				"token [REDACTED], password [REDACTED]",
+				redactor.Redact("token hvs.faketoken, password fakepassword"),
+			)
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"with userpass method, it writes the password to stdin",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"vault",
+				[]string{"write", "-format=json", "auth/userpass/login/ci", "-"},
+				[]string(nil),
+				"",
+				bytes.NewReader([]byte(`{"password":"fakepassword"}`)),
+			).Return([]byte(fakeVaultLoginOutput), []byte{}, nil)
+
+			redactor := logger.NewRedactor()
+			vaultInstance := NewVault(executorArg, nil, redactor)
+			actual, actualErr := vaultInstance.Login(
+				context.Background(),
+				&VaultLoginOptions{
+					Method: "userpass",
+					Params: map[string]string{"username": "ci", "password": "fakepassword"},
+				},
+			)
+			require.Nil(t, actualErr)
+			assert.Equal(t, "hvs.faketoken", actual.ClientToken)
+			assert.Equal(t, "user ci, password [REDACTED]", redactor.Redact("user ci, password fakepassword"))
+		},
+	)
+
+	t.Run(
+		"with token method, it logs in with the token read from stdin and without storing the token",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"vault",
+				[]string{"login", "-format=json", "-no-store", "-method=token", "-path=token", "token=-"},
+				[]string(nil),
+				"",
+				bytes.NewReader([]byte("hvs.faketoken")),
+			).Return([]byte(fakeVaultLoginOutput), []byte{}, nil)
+
+			vaultInstance := NewVault(executorArg, nil, nil)
+			actual, actualErr := vaultInstance.Login(
+				context.Background(),
+				&VaultLoginOptions{Method: "token", Params: map[string]string{"token": "hvs.faketoken"}},
+			)
+			require.Nil(t, actualErr)
+			assert.Equal(t, "hvs.faketoken", actual.ClientToken)
+		},
+	)
+
+	t.Run(
+		"when the secret params are registered, it redacts them before executing the command",
+		func(t *testing.T) {
+			t.Parallel()
+
+			redactor := logger.NewRedactor()
+
+			var redactedBeforeExecuting string
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"vault",
+				[]string{"write", "-format=json", "auth/approle/login", "-"},
+				[]string(nil),
+				"",
+				mock.Anything,
+			).Run(func(args mock.Arguments) {
+				redactedBeforeExecuting = redactor.Redact("secret_id fakesecretid")
+			}).Return([]byte(fakeVaultLoginOutput), []byte{}, nil)
+
+			vaultInstance := NewVault(executorArg, nil, redactor)
+			_, actualErr := vaultInstance.Login(
+				context.Background(),
+				&VaultLoginOptions{
+					Method: "approle",
+					Params: map[string]string{"role_id": "fakeroleid", "secret_id": "fakesecretid"},
+				},
+			)
+			require.Nil(t, actualErr)
+			assert.Equal(t, "secret_id [REDACTED]", redactedBeforeExecuting)
+		},
+	)
+
+	t.Run(
+		"when login fails, it returns error without stdout",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"vault",
+				[]string{"login", "-format=json", "-no-store", "-method=oidc", "-path=oidc"},
+				[]string(nil),
+				"",
+				bytes.NewReader(nil),
+			).Return([]byte("fake stdout"), []byte("fake stderr"), errors.New("fake error"))
+
+			vaultInstance := NewVault(executorArg, nil, nil)
+			actual, actualErr := vaultInstance.Login(context.Background(), &VaultLoginOptions{Method: "oidc"})
+			require.NotNil(t, actualErr)
+			assert.Nil(t, actual)
+			assert.Contains(t, actualErr.Error(), "fake stderr")
+			assert.NotContains(t, actualErr.Error(), "fake stdout")
+		},
+	)
+}
+
+func TestVault_KVGet(t *testing.T) {
+	t.Run(
+		"with KV v2, it returns the nested data",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"vault",
+				[]string{"kv", "get", "-format=json", "-mount=secret", "apps/api"},
+				[]string(nil),
+				"",
+				bytes.NewReader(nil),
+			).Return(
+				[]byte(`{"data": {"data": {"api_key": "fakeapikey", "tls": "true"}, "metadata": {"version": 3}}}`),
+				[]byte{},
+				nil,
+			)
+
+			redactor := logger.NewRedactor()
+			vaultInstance := NewVault(executorArg, nil, redactor)
+			actual, actualErr := vaultInstance.KVGet(context.Background(), "secret", "apps/api")
+			require.Nil(t, actualErr)
+			assert.Equal(t, map[string]interface{}{"api_key": "fakeapikey", "tls": "true"}, actual)
+			assert.Equal(t, "key [REDACTED], tls true", redactor.Redact("key fakeapikey, tls true"))
+		},
+	)
+
+	t.Run(
+		"with min redacted length, it redacts only the values of at least that length",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"vault",
+				[]string{"kv", "get", "-format=json", "-mount=kv", "apps/api"},
+				[]string(nil),
+				"",
+				bytes.NewReader(nil),
+			).Return([]byte(`{"data": {"pin": "4821", "tls": "on"}}`), []byte{}, nil)
+
+			redactor := logger.NewRedactor()
+			vaultInstance := NewVault(executorArg, &VaultOptions{MinRedactedLength: 4}, redactor)
+			_, actualErr := vaultInstance.KVGet(context.Background(), "kv", "apps/api")
+			require.Nil(t, actualErr)
+			assert.Equal(t, "pin [REDACTED], tls on", redactor.Redact("pin 4821, tls on"))
+		},
+	)
+
+	t.Run(
+		"with KV v1, it returns the data",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On(
+				"ExecuteWithStdinContext",
+				context.Background(),
+				"vault",
+				[]string{"kv", "get", "-format=json", "-mount=kv", "apps/api"},
+				[]string(nil),
+				"",
+				bytes.NewReader(nil),
+			).Return([]byte(`{"data": {"api_key": "fakekey"}}`), []byte{}, nil)
+
+			vaultInstance := NewVault(executorArg, nil, nil)
+			actual, actualErr := vaultInstance.KVGet(context.Background(), "kv", "apps/api")
+			require.Nil(t, actualErr)
+			assert.Equal(t, map[string]interface{}{"api_key": "fakekey"}, actual)
+		},
+	)
+}
+
+func TestVault_KVPut(t *testing.T) {
+	t.Parallel()
+
+	executorArg := ostest.NewFakeOsExecutor(t)
+	executorArg.On(
+		"ExecuteWithStdinContext",
+		context.Background(),
+		"vault",
+		[]string{"kv", "put", "-format=json", "-mount=secret", "apps/api", "-"},
+		[]string(nil),
+		"",
+		bytes.NewReader([]byte(`{"api_key":"fakeapikey","tls":"true"}`)),
+	).Return([]byte(`{"data": {"version": 4}}`), []byte{}, nil)
+
+	redactor := logger.NewRedactor()
+	vaultInstance := NewVault(executorArg, nil, redactor)
+	actualErr := vaultInstance.KVPut(
+		context.Background(),
+		"secret",
+		"apps/api",
+		map[string]string{"api_key": "fakeapikey", "tls": "true"},
+	)
+	require.Nil(t, actualErr)
+	assert.Equal(t, "key [REDACTED], tls true", redactor.Redact("key fakeapikey, tls true"))
+	executorArg.AssertExpectations(t)
+}
@@ -0,0 +1,546 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/clock"
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+type VeleroPhase string
+
+const (
+	VeleroPhaseNew                        VeleroPhase = "New"
+	VeleroPhaseFailedValidation           VeleroPhase = "FailedValidation"
+	VeleroPhaseInProgress                 VeleroPhase = "InProgress"
+	VeleroPhaseWaitingForPluginOperations VeleroPhase = "WaitingForPluginOperations"
+	VeleroPhaseFinalizing                 VeleroPhase = "Finalizing"
+	VeleroPhaseCompleted                  VeleroPhase = "Completed"
+	VeleroPhasePartiallyFailed            VeleroPhase = "PartiallyFailed"
+	VeleroPhaseFailed                     VeleroPhase = "Failed"
+	VeleroPhaseDeleting                   VeleroPhase = "Deleting"
+	// VeleroPhaseEnabled is the phase of a valid schedule.
+	VeleroPhaseEnabled VeleroPhase = "Enabled"
+)
+
+// IsTerminal returns whether the backup or restore is done, successfully or not.
+func (phase VeleroPhase) IsTerminal() bool {
+	switch phase {
+	case VeleroPhaseCompleted, VeleroPhasePartiallyFailed, VeleroPhaseFailed, VeleroPhaseFailedValidation:
+		return true
+	default:
+		return false
+	}
+}
+
+type VeleroOptions struct {
+	// Namespace is the namespace of the velero server, when not `velero`.
+	Namespace   string
+	Kubeconfig  string
+	KubeContext string
+}
+
+type VeleroBackupOptions struct {
+	IncludeNamespaces []string
+	ExcludeNamespaces []string
+	IncludeResources  []string
+	ExcludeResources  []string
+	// Selector backs up only the resources matching the label selector, e.g `app=api`.
+	Selector string
+	// TTL is the retention of the backup, when not the server default.
+	TTL time.Duration
+	// SnapshotVolumes takes snapshots of the persistent volumes, when not the server default.
+	SnapshotVolumes *bool
+	StorageLocation string
+}
+
+type VeleroRestoreOptions struct {
+	IncludeNamespaces []string
+	ExcludeNamespaces []string
+	// NamespaceMappings restores the resources of a namespace into another one, e.g `production` => `staging`.
+	NamespaceMappings map[string]string
+	Selector          string
+	// RestorePVs restores the persistent volumes from snapshots, when not the server default.
+	RestorePVs *bool
+	// ExistingResourcePolicy is the policy of the resources which already exist, `none` or `update`.
+	ExistingResourcePolicy string
+}
+
+type VeleroBackup struct {
+	Name                string
+	Phase               VeleroPhase
+	Errors              int
+	Warnings            int
+	FailureReason       string
+	StartTimestamp      *time.Time
+	CompletionTimestamp *time.Time
+	Expiration          *time.Time
+}
+
+type VeleroRestore struct {
+	Name                string
+	BackupName          string
+	Phase               VeleroPhase
+	Errors              int
+	Warnings            int
+	FailureReason       string
+	StartTimestamp      *time.Time
+	CompletionTimestamp *time.Time
+}
+
+type VeleroSchedule struct {
+	Name       string
+	Schedule   string
+	Paused     bool
+	Phase      VeleroPhase
+	LastBackup *time.Time
+}
+
+type veleroBackupObject struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Status struct {
+		Phase               VeleroPhase `json:"phase"`
+		Errors              int         `json:"errors"`
+		Warnings            int         `json:"warnings"`
+		FailureReason       string      `json:"failureReason"`
+		StartTimestamp      *time.Time  `json:"startTimestamp"`
+		CompletionTimestamp *time.Time  `json:"completionTimestamp"`
+		Expiration          *time.Time  `json:"expiration"`
+	} `json:"status"`
+}
+
+type veleroRestoreObject struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		BackupName string `json:"backupName"`
+	} `json:"spec"`
+	Status struct {
+		Phase               VeleroPhase `json:"phase"`
+		Errors              int         `json:"errors"`
+		Warnings            int         `json:"warnings"`
+		FailureReason       string      `json:"failureReason"`
+		StartTimestamp      *time.Time  `json:"startTimestamp"`
+		CompletionTimestamp *time.Time  `json:"completionTimestamp"`
+	} `json:"status"`
+}
+
+type veleroScheduleObject struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Schedule string `json:"schedule"`
+		Paused   bool   `json:"paused"`
+	} `json:"spec"`
+	Status struct {
+		Phase      VeleroPhase `json:"phase"`
+		LastBackup *time.Time  `json:"lastBackup"`
+	} `json:"status"`
+}
+
+// Velero orchestrates the backups and restores of kubernetes resources and persistent volumes.
+type Velero struct {
+	binPath         string
+	options         VeleroOptions
+	commandExecutor os.CommandExecutor
+	clock           clock.Clock
+}
+
+func NewVelero(executor os.CommandExecutor, options *VeleroOptions) *Velero {
+	velero := &Velero{
+		binPath:         "velero",
+		commandExecutor: executor,
+		clock:           clock.NewRealClock(),
+	}
+
+	if options != nil {
+		velero.options = *options
+	}
+
+	return velero
+}
+
+// WithClock returns a shallow copy of the velero executor, that polls the backups and restores with `clk`,
+// e.g clocktest.FakeClock in tests.
+func (velero *Velero) WithClock(clk clock.Clock) *Velero {
+	copied := *velero
+	copied.clock = clk
+
+	return &copied
+}
+
+// CreateBackup creates the backup, without waiting for it to complete.
+func (velero *Velero) CreateBackup(ctx context.Context, name string, options *VeleroBackupOptions) error {
+	args := append([]string{"backup", "create", name}, veleroBackupArgs(options)...)
+
+	_, err := velero.execute(ctx, args...)
+	return err
+}
+
+// BackupStatus returns the backup with its current phase.
+func (velero *Velero) BackupStatus(ctx context.Context, name string) (*VeleroBackup, error) {
+	objects, err := velero.get(ctx, "backup", name)
+	if err != nil {
+		return nil, err
+	}
+
+	var object veleroBackupObject
+
+	err = json.Unmarshal(objects[0], &object)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "json decode backup %s failed", name)
+	}
+
+	return &VeleroBackup{
+		Name:                object.Metadata.Name,
+		Phase:               object.Status.Phase,
+		Errors:              object.Status.Errors,
+		Warnings:            object.Status.Warnings,
+		FailureReason:       object.Status.FailureReason,
+		StartTimestamp:      object.Status.StartTimestamp,
+		CompletionTimestamp: object.Status.CompletionTimestamp,
+		Expiration:          object.Status.Expiration,
+	}, nil
+}
+
+// WaitForBackup polls the backup status every `pollInterval` until it's done or ctx is done.
+// Returns error along with the backup when the backup is not completed successfully.
+func (velero *Velero) WaitForBackup(ctx context.Context, name string, pollInterval time.Duration) (*VeleroBackup, error) {
+	var backup *VeleroBackup
+
+	err := velero.poll(ctx, pollInterval, func() (VeleroPhase, error) {
+		var err error
+
+		backup, err = velero.BackupStatus(ctx, name)
+		if err != nil {
+			return "", err
+		}
+
+		return backup.Phase, nil
+	})
+	if err != nil {
+		return backup, stacktrace.Propagate(err, "waiting for backup %s failed", name)
+	}
+
+	if backup.Phase != VeleroPhaseCompleted {
+		return backup, stacktrace.NewError(
+			"backup %s %s with %d errors: %s",
+			name,
+			backup.Phase,
+			backup.Errors,
+			backup.FailureReason,
+		)
+	}
+
+	return backup, nil
+}
+
+// CreateRestore creates the restore of the backup, without waiting for it to complete.
+func (velero *Velero) CreateRestore(
+	ctx context.Context,
+	name,
+	backup string,
+	options *VeleroRestoreOptions,
+) error {
+	args := []string{"restore", "create", name, "--from-backup", backup}
+
+	if options != nil {
+		if len(options.IncludeNamespaces) > 0 {
+			args = append(args, "--include-namespaces", strings.Join(options.IncludeNamespaces, ","))
+		}
+
+		if len(options.ExcludeNamespaces) > 0 {
+			args = append(args, "--exclude-namespaces", strings.Join(options.ExcludeNamespaces, ","))
+		}
+
+		if len(options.NamespaceMappings) > 0 {
+			mappings := make([]string, 0, len(options.NamespaceMappings))
+			for _, source := range sortedKeys(options.NamespaceMappings) {
+				mappings = append(mappings, fmt.Sprintf("%s:%s", source, options.NamespaceMappings[source]))
+			}
+
+			args = append(args, "--namespace-mappings", strings.Join(mappings, ","))
+		}
+
+		if options.Selector != "" {
+			args = append(args, "--selector", options.Selector)
+		}
+
+		if options.RestorePVs != nil {
+			args = append(args, "--restore-volumes="+strconv.FormatBool(*options.RestorePVs))
+		}
+
+		if options.ExistingResourcePolicy != "" {
+			args = append(args, "--existing-resource-policy", options.ExistingResourcePolicy)
+		}
+	}
+
+	_, err := velero.execute(ctx, args...)
+	return err
+}
+
+// RestoreStatus returns the restore with its current phase.
+func (velero *Velero) RestoreStatus(ctx context.Context, name string) (*VeleroRestore, error) {
+	objects, err := velero.get(ctx, "restore", name)
+	if err != nil {
+		return nil, err
+	}
+
+	var object veleroRestoreObject
+
+	err = json.Unmarshal(objects[0], &object)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "json decode restore %s failed", name)
+	}
+
+	return &VeleroRestore{
+		Name:                object.Metadata.Name,
+		BackupName:          object.Spec.BackupName,
+		Phase:               object.Status.Phase,
+		Errors:              object.Status.Errors,
+		Warnings:            object.Status.Warnings,
+		FailureReason:       object.Status.FailureReason,
+		StartTimestamp:      object.Status.StartTimestamp,
+		CompletionTimestamp: object.Status.CompletionTimestamp,
+	}, nil
+}
+
+// WaitForRestore polls the restore status every `pollInterval` until it's done or ctx is done.
+// Returns error along with the restore when the restore is not completed successfully.
+func (velero *Velero) WaitForRestore(
+	ctx context.Context,
+	name string,
+	pollInterval time.Duration,
+) (*VeleroRestore, error) {
+	var restore *VeleroRestore
+
+	err := velero.poll(ctx, pollInterval, func() (VeleroPhase, error) {
+		var err error
+
+		restore, err = velero.RestoreStatus(ctx, name)
+		if err != nil {
+			return "", err
+		}
+
+		return restore.Phase, nil
+	})
+	if err != nil {
+		return restore, stacktrace.Propagate(err, "waiting for restore %s failed", name)
+	}
+
+	if restore.Phase != VeleroPhaseCompleted {
+		return restore, stacktrace.NewError(
+			"restore %s %s with %d errors: %s",
+			name,
+			restore.Phase,
+			restore.Errors,
+			restore.FailureReason,
+		)
+	}
+
+	return restore, nil
+}
+
+// CreateSchedule creates a schedule of backups with the options, on the cron expression, e.g `0 3 * * *`.
+func (velero *Velero) CreateSchedule(
+	ctx context.Context,
+	name,
+	schedule string,
+	options *VeleroBackupOptions,
+) error {
+	args := append([]string{"schedule", "create", name, "--schedule", schedule}, veleroBackupArgs(options)...)
+
+	_, err := velero.execute(ctx, args...)
+	return err
+}
+
+func (velero *Velero) DeleteSchedule(ctx context.Context, name string) error {
+	_, err := velero.execute(ctx, "schedule", "delete", name, "--confirm")
+	return err
+}
+
+// PauseSchedule stops creating backups of the schedule, e.g during a maintenance window.
+func (velero *Velero) PauseSchedule(ctx context.Context, name string) error {
+	_, err := velero.execute(ctx, "schedule", "pause", name)
+	return err
+}
+
+func (velero *Velero) UnpauseSchedule(ctx context.Context, name string) error {
+	_, err := velero.execute(ctx, "schedule", "unpause", name)
+	return err
+}
+
+// GetSchedules returns all schedules.
+func (velero *Velero) GetSchedules(ctx context.Context) ([]*VeleroSchedule, error) {
+	objects, err := velero.get(ctx, "schedule", "")
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]*VeleroSchedule, 0, len(objects))
+
+	for _, raw := range objects {
+		var object veleroScheduleObject
+
+		err = json.Unmarshal(raw, &object)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "json decode schedule failed")
+		}
+
+		schedules = append(
+			schedules,
+			&VeleroSchedule{
+				Name:       object.Metadata.Name,
+				Schedule:   object.Spec.Schedule,
+				Paused:     object.Spec.Paused,
+				Phase:      object.Status.Phase,
+				LastBackup: object.Status.LastBackup,
+			},
+		)
+	}
+
+	return schedules, nil
+}
+
+func (velero *Velero) poll(ctx context.Context, pollInterval time.Duration, phase func() (VeleroPhase, error)) error {
+	for {
+		current, err := phase()
+		if err != nil {
+			return err
+		}
+
+		if current.IsTerminal() {
+			return nil
+		}
+
+		timer := velero.clock.NewTimer(pollInterval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return stacktrace.Propagate(ctx.Err(), "phase %s", current)
+		case <-timer.C():
+		}
+	}
+}
+
+// get returns the JSON objects of the kind, the one of `name` or all when blank.
+func (velero *Velero) get(ctx context.Context, kind, name string) ([]json.RawMessage, error) {
+	args := []string{kind, "get"}
+	if name != "" {
+		args = append(args, name)
+	}
+
+	stdout, err := velero.execute(ctx, append(args, "--output", "json")...)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Kind  string            `json:"kind"`
+		Items []json.RawMessage `json:"items"`
+	}
+
+	err = json.Unmarshal(stdout, &list)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "json decode command `velero %s get` output failed", kind)
+	}
+
+	// NOTE: A single object is printed as is, instead of a list of one item.
+	if !strings.HasSuffix(list.Kind, "List") {
+		return []json.RawMessage{stdout}, nil
+	}
+
+	if name != "" && len(list.Items) == 0 {
+		return nil, stacktrace.NewError("%s %s not found", kind, name)
+	}
+
+	return list.Items, nil
+}
+
+func (velero *Velero) execute(ctx context.Context, args ...string) ([]byte, error) {
+	if velero.options.Namespace != "" {
+		args = append(args, "--namespace", velero.options.Namespace)
+	}
+
+	if velero.options.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", velero.options.Kubeconfig)
+	}
+
+	if velero.options.KubeContext != "" {
+		args = append(args, "--kubecontext", velero.options.KubeContext)
+	}
+
+	stdout, stderr, err := velero.commandExecutor.ExecuteContext(ctx, velero.binPath, args, nil, "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+
+	return stdout, nil
+}
+
+func veleroBackupArgs(options *VeleroBackupOptions) []string {
+	var args []string
+	if options == nil {
+		return args
+	}
+
+	if len(options.IncludeNamespaces) > 0 {
+		args = append(args, "--include-namespaces", strings.Join(options.IncludeNamespaces, ","))
+	}
+
+	if len(options.ExcludeNamespaces) > 0 {
+		args = append(args, "--exclude-namespaces", strings.Join(options.ExcludeNamespaces, ","))
+	}
+
+	if len(options.IncludeResources) > 0 {
+		args = append(args, "--include-resources", strings.Join(options.IncludeResources, ","))
+	}
+
+	if len(options.ExcludeResources) > 0 {
+		args = append(args, "--exclude-resources", strings.Join(options.ExcludeResources, ","))
+	}
+
+	if options.Selector != "" {
+		args = append(args, "--selector", options.Selector)
+	}
+
+	if options.TTL > 0 {
+		args = append(args, "--ttl", options.TTL.String())
+	}
+
+	if options.SnapshotVolumes != nil {
+		args = append(args, "--snapshot-volumes="+strconv.FormatBool(*options.SnapshotVolumes))
+	}
+
+	if options.StorageLocation != "" {
+		args = append(args, "--storage-location", options.StorageLocation)
+	}
+
+	return args
+}
@@ -0,0 +1,363 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/palantir/stacktrace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/clock/clocktest"
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func fakeVeleroBackupJSON(phase string) []byte {
+	return []byte(`{
+  "kind": "Backup",
+  "apiVersion": "velero.io/v1",
+  "metadata": {"name": "pre-deploy-42", "namespace": "velero"},
+  "status": {
+    "phase": "` + phase + `",
+    "errors": 1,
+    "warnings": 2,
+    "startTimestamp": "2019-10-01T12:30:00Z",
+    "expiration": "2019-10-31T12:30:00Z"
+  }
+}`)
+}
+
+func TestVelero_CreateBackup(t *testing.T) {
+	t.Parallel()
+
+	snapshotVolumes := false
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"velero",
+		[]string{
+			"backup", "create", "pre-deploy-42",
+			"--include-namespaces", "api,worker",
+			"--exclude-resources", "events",
+			"--selector", "backup=true",
+			"--ttl", "720h0m0s",
+			"--snapshot-volumes=false",
+			"--storage-location", "s3",
+			"--namespace", "backups",
+			"--kubecontext", "production",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	veleroInstance := NewVelero(executorArg, &VeleroOptions{Namespace: "backups", KubeContext: "production"})
+	actual := veleroInstance.CreateBackup(
+		context.Background(),
+		"pre-deploy-42",
+		&VeleroBackupOptions{
+			IncludeNamespaces: []string{"api", "worker"},
+			ExcludeResources:  []string{"events"},
+			Selector:          "backup=true",
+			TTL:               720 * time.Hour,
+			SnapshotVolumes:   &snapshotVolumes,
+			StorageLocation:   "s3",
+		},
+	)
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestVelero_BackupStatus(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"velero",
+		[]string{"backup", "get", "pre-deploy-42", "--output", "json"},
+		[]string(nil),
+		"",
+	).Return(fakeVeleroBackupJSON("InProgress"), []byte{}, nil)
+
+	veleroInstance := NewVelero(executorArg, nil)
+	actual, actualErr := veleroInstance.BackupStatus(context.Background(), "pre-deploy-42")
+	require.Nil(t, actualErr)
+	assert.Equal(t, "pre-deploy-42", actual.Name)
+	assert.Equal(t, VeleroPhaseInProgress, actual.Phase)
+	assert.False(t, actual.Phase.IsTerminal())
+	assert.Equal(t, 1, actual.Errors)
+	assert.Equal(t, 2, actual.Warnings)
+	assert.Equal(t, time.Date(2019, 10, 31, 12, 30, 0, 0, time.UTC), *actual.Expiration)
+	assert.Nil(t, actual.CompletionTimestamp)
+}
+
+func TestVelero_WaitForBackup(t *testing.T) {
+	t.Run(
+		"it polls until the backup is completed",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"velero",
+				[]string{"backup", "get", "pre-deploy-42", "--output", "json"},
+				[]string(nil),
+				"",
+			).Return(fakeVeleroBackupJSON("New"), []byte{}, nil).Once()
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"velero",
+				[]string{"backup", "get", "pre-deploy-42", "--output", "json"},
+				[]string(nil),
+				"",
+			).Return(fakeVeleroBackupJSON("InProgress"), []byte{}, nil).Once()
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"velero",
+				[]string{"backup", "get", "pre-deploy-42", "--output", "json"},
+				[]string(nil),
+				"",
+			).Return(fakeVeleroBackupJSON("Completed"), []byte{}, nil).Once()
+
+			start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			fakeClock := clocktest.NewFakeClock(start)
+			fakeClock.SetAutoAdvance(true)
+
+			veleroInstance := NewVelero(executorArg, nil).WithClock(fakeClock)
+			actual, actualErr := veleroInstance.WaitForBackup(context.Background(), "pre-deploy-42", 30*time.Second)
+			require.Nil(t, actualErr)
+			assert.Equal(t, VeleroPhaseCompleted, actual.Phase)
+			assert.Equal(t, start.Add(time.Minute), fakeClock.Now())
+			executorArg.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"when backup partially fails, it returns error with the backup",
+		func(t *testing.T) {
+			t.Parallel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				context.Background(),
+				"velero",
+				[]string{"backup", "get", "pre-deploy-42", "--output", "json"},
+				[]string(nil),
+				"",
+			).Return(fakeVeleroBackupJSON("PartiallyFailed"), []byte{}, nil)
+
+			veleroInstance := NewVelero(executorArg, nil)
+			actual, actualErr := veleroInstance.WaitForBackup(context.Background(), "pre-deploy-42", time.Millisecond)
+			require.NotNil(t, actualErr)
+			assert.Contains(t, actualErr.Error(), "PartiallyFailed")
+			assert.Equal(t, VeleroPhasePartiallyFailed, actual.Phase)
+		},
+	)
+
+	t.Run(
+		"when context is done, it returns error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			executorArg := &ostest.FakeOsExecutor{}
+			executorArg.On(
+				"ExecuteContext",
+				ctx,
+				"velero",
+				[]string{"backup", "get", "pre-deploy-42", "--output", "json"},
+				[]string(nil),
+				"",
+			).Return(fakeVeleroBackupJSON("InProgress"), []byte{}, nil)
+
+			veleroInstance := NewVelero(executorArg, nil)
+			actual, actualErr := veleroInstance.WaitForBackup(ctx, "pre-deploy-42", time.Hour)
+			require.NotNil(t, actualErr)
+			assert.Equal(t, context.DeadlineExceeded, stacktrace.RootCause(actualErr))
+			assert.Equal(t, VeleroPhaseInProgress, actual.Phase)
+		},
+	)
+}
+
+func TestVelero_CreateRestore(t *testing.T) {
+	t.Parallel()
+
+	restorePVs := true
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"velero",
+		[]string{
+			"restore", "create", "rollback-42", "--from-backup", "pre-deploy-42",
+			"--include-namespaces", "api",
+			"--namespace-mappings", "api:api-restored,worker:worker-restored",
+			"--restore-volumes=true",
+			"--existing-resource-policy", "update",
+		},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	veleroInstance := NewVelero(executorArg, nil)
+	actual := veleroInstance.CreateRestore(
+		context.Background(),
+		"rollback-42",
+		"pre-deploy-42",
+		&VeleroRestoreOptions{
+			IncludeNamespaces: []string{"api"},
+			NamespaceMappings: map[string]string{
+				"worker": "worker-restored",
+				"api":    "api-restored",
+			},
+			RestorePVs:             &restorePVs,
+			ExistingResourcePolicy: "update",
+		},
+	)
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestVelero_WaitForRestore(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"velero",
+		[]string{"restore", "get", "rollback-42", "--output", "json"},
+		[]string(nil),
+		"",
+	).Return(
+		[]byte(`{
+  "kind": "RestoreList",
+  "items": [{
+    "kind": "Restore",
+    "metadata": {"name": "rollback-42"},
+    "spec": {"backupName": "pre-deploy-42"},
+    "status": {"phase": "Failed", "errors": 3, "failureReason": "backup not found"}
+  }]
+}`),
+		[]byte{},
+		nil,
+	)
+
+	veleroInstance := NewVelero(executorArg, nil)
+	actual, actualErr := veleroInstance.WaitForRestore(context.Background(), "rollback-42", time.Millisecond)
+	require.NotNil(t, actualErr)
+	assert.Contains(t, actualErr.Error(), "backup not found")
+	assert.Equal(t, "pre-deploy-42", actual.BackupName)
+	assert.Equal(t, 3, actual.Errors)
+}
+
+func TestVelero_CreateSchedule(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"velero",
+		[]string{"schedule", "create", "nightly", "--schedule", "0 3 * * *", "--include-namespaces", "api"},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	veleroInstance := NewVelero(executorArg, nil)
+	actual := veleroInstance.CreateSchedule(
+		context.Background(),
+		"nightly",
+		"0 3 * * *",
+		&VeleroBackupOptions{IncludeNamespaces: []string{"api"}},
+	)
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
+
+func TestVelero_GetSchedules(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"velero",
+		[]string{"schedule", "get", "--output", "json"},
+		[]string(nil),
+		"",
+	).Return(
+		[]byte(`{
+  "kind": "ScheduleList",
+  "items": [
+    {
+      "metadata": {"name": "nightly"},
+      "spec": {"schedule": "0 3 * * *"},
+      "status": {"phase": "Enabled", "lastBackup": "2019-10-01T03:00:00Z"}
+    },
+    {
+      "metadata": {"name": "hourly"},
+      "spec": {"schedule": "@every 1h", "paused": true},
+      "status": {"phase": "Enabled"}
+    }
+  ]
+}`),
+		[]byte{},
+		nil,
+	)
+
+	veleroInstance := NewVelero(executorArg, nil)
+	actual, actualErr := veleroInstance.GetSchedules(context.Background())
+	require.Nil(t, actualErr)
+	require.Len(t, actual, 2)
+	assert.Equal(t, "0 3 * * *", actual[0].Schedule)
+	assert.Equal(t, VeleroPhaseEnabled, actual[0].Phase)
+	assert.Equal(t, time.Date(2019, 10, 1, 3, 0, 0, 0, time.UTC), *actual[0].LastBackup)
+	assert.True(t, actual[1].Paused)
+	assert.Nil(t, actual[1].LastBackup)
+}
+
+func TestVelero_DeleteSchedule(t *testing.T) {
+	t.Parallel()
+
+	executorArg := &ostest.FakeOsExecutor{}
+	executorArg.On(
+		"ExecuteContext",
+		context.Background(),
+		"velero",
+		[]string{"schedule", "delete", "nightly", "--confirm"},
+		[]string(nil),
+		"",
+	).Return([]byte{}, []byte{}, nil)
+
+	veleroInstance := NewVelero(executorArg, nil)
+	actual := veleroInstance.DeleteSchedule(context.Background(), "nightly")
+	require.Nil(t, actual)
+	executorArg.AssertExpectations(t)
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/httpclient"
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+// CommandCheck creates a Check passing when the command succeeds,
+// e.g `CommandCheck(executor, "kubectl", "auth", "can-i", "list", "deployments")`.
+func CommandCheck(executor os.CommandExecutor, cmd string, arg ...string) Check {
+	return func(ctx context.Context) error {
+		stdout, stderr, err := executor.ExecuteContext(ctx, cmd, arg, nil, "")
+		return stacktrace.Propagate(err, "Stderr: %s, Stdout: %s", stderr, stdout)
+	}
+}
+
+// HTTPCheck creates a Check passing when GET of the URL responds with a 2xx or 3xx status,
+// e.g the health endpoint of a sidecar.
+func HTTPCheck(client httpclient.Client, url string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return stacktrace.Propagate(err, "invalid health check URL %s", url)
+		}
+
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return stacktrace.Propagate(err, "health check request failed")
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return stacktrace.NewError("health check responded %d", resp.StatusCode)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/httpclient/httpclienttest"
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestCommandCheck(t *testing.T) {
+	t.Run(
+		"it passes when the command succeeds and fails with its output otherwise",
+		func(t *testing.T) {
+			t.Parallel()
+
+			args := []string{"auth", "can-i", "list", "deployments"}
+
+			executorArg := ostest.NewFakeOsExecutor(t)
+			executorArg.On("ExecuteContext", context.Background(), "kubectl", args, []string(nil), "").
+				Return([]byte("yes"), []byte{}, nil).
+				Once()
+			executorArg.On("ExecuteContext", context.Background(), "kubectl", args, []string(nil), "").
+				Return([]byte("no"), []byte("forbidden"), errors.New("exit status 1")).
+				Once()
+
+			check := CommandCheck(executorArg, "kubectl", args...)
+
+			assert.Nil(t, check(context.Background()))
+
+			err := check(context.Background())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "Stderr: forbidden, Stdout: no")
+			executorArg.AssertExpectations(t)
+		},
+	)
+}
+
+func TestHTTPCheck(t *testing.T) {
+	t.Run(
+		"it passes on the 2xx and 3xx responses and fails on the others",
+		func(t *testing.T) {
+			t.Parallel()
+
+			client := httpclienttest.NewFakeClient(t)
+			client.On("Do", httpclienttest.MatchRequest(http.MethodGet, "http://localhost:15021/healthz/ready")).
+				Return(httpclienttest.NewResponse(http.StatusOK, "ok"), nil).
+				Once()
+			client.On("Do", httpclienttest.MatchRequest(http.MethodGet, "http://localhost:15021/healthz/ready")).
+				Return(httpclienttest.NewResponse(http.StatusServiceUnavailable, ""), nil).
+				Once()
+			client.On("Do", httpclienttest.MatchRequest(http.MethodGet, "http://localhost:15021/healthz/ready")).
+				Return(nil, errors.New("connection refused")).
+				Once()
+
+			check := HTTPCheck(client, "http://localhost:15021/healthz/ready")
+
+			assert.Nil(t, check(context.Background()))
+
+			err := check(context.Background())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "health check responded 503")
+
+			err = check(context.Background())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "connection refused")
+			client.AssertExpectations(t)
+		},
+	)
+}
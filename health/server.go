@@ -0,0 +1,299 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health serves the liveness and readiness probes of long-running agents, at `/livez` and `/readyz`,
+// with pluggable checks whose results are cached for a TTL, so that frequent probes don't overload
+// the checked dependencies, e.g the Kubernetes API server.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/clock"
+	"github.com/sumup-oss/go-pkgs/logger"
+	"github.com/sumup-oss/go-pkgs/shutdown"
+)
+
+const (
+	defaultAddr     = ":8081"
+	defaultCacheTTL = 5 * time.Second
+	defaultTimeout  = 5 * time.Second
+
+	statusOK     = "ok"
+	statusFailed = "failed"
+)
+
+var _ shutdown.Hook = (*Server)(nil).Shutdown
+
+// Check checks a dependency or the agent itself, e.g that `kubectl auth can-i` succeeds.
+// The context is done when the check timeout is exceeded.
+type Check func(ctx context.Context) error
+
+// ServerOptions configures a Server.
+type ServerOptions struct {
+	// Addr is the listen address. Defaults to `:8081`.
+	Addr string
+	// CacheTTL is how long the result of a check is reused. Defaults to 5 seconds.
+	CacheTTL time.Duration
+	// Timeout of a single check. Defaults to 5 seconds.
+	Timeout time.Duration
+	// DrainDelay is how long Shutdown serves the failing readiness probe before stopping the server,
+	// so that the load balancers notice it, e.g a few Kubernetes readiness periods. Defaults to no delay.
+	DrainDelay time.Duration
+	// Clock measures the TTL of the cached results and the drain delay. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+// Report is the JSON response of the probes.
+type Report struct {
+	Status string                  `json:"status"`
+	Error  string                  `json:"error,omitempty"`
+	Checks map[string]*CheckReport `json:"checks,omitempty"`
+}
+
+// CheckReport is the result of a single check.
+type CheckReport struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Server serves the liveness checks at `/livez` and the readiness checks at `/readyz`,
+// responding 200 when all the checks pass and 503 otherwise.
+type Server struct {
+	log        logger.Logger
+	options    ServerOptions
+	httpServer *http.Server
+
+	mu        sync.RWMutex
+	liveness  []*cachedCheck
+	readiness []*cachedCheck
+
+	shuttingDown int32
+}
+
+// NewServer creates Server instance, with the defaults of ServerOptions when options is nil.
+func NewServer(log logger.Logger, options *ServerOptions) *Server {
+	var opts ServerOptions
+	if options != nil {
+		opts = *options
+	}
+
+	if opts.Addr == "" {
+		opts.Addr = defaultAddr
+	}
+
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = defaultCacheTTL
+	}
+
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = clock.NewRealClock()
+	}
+
+	server := &Server{
+		log:     log,
+		options: opts,
+	}
+
+	server.httpServer = &http.Server{
+		Addr:              opts.Addr,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: opts.Timeout,
+	}
+
+	return server
+}
+
+// AddLivenessCheck adds a check of `/livez`, failing of which restarts the agent, e.g that child processes are alive.
+func (s *Server) AddLivenessCheck(name string, check Check) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.liveness = append(s.liveness, s.newCachedCheck(name, check))
+}
+
+// AddReadinessCheck adds a check of `/readyz`, failing of which stops the traffic to the agent,
+// e.g that the Kubernetes API server is reachable.
+func (s *Server) AddReadinessCheck(name string, check Check) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.readiness = append(s.readiness, s.newCachedCheck(name, check))
+}
+
+// Handler returns the handler of the probes, e.g to mount them on an existing server.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/livez", func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.RLock()
+		checks := s.liveness
+		s.mu.RUnlock()
+
+		s.respond(w, checks, false)
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.RLock()
+		checks := s.readiness
+		s.mu.RUnlock()
+
+		s.respond(w, checks, atomic.LoadInt32(&s.shuttingDown) == 1)
+	})
+
+	return mux
+}
+
+// ListenAndServe serves the probes until the server is shut down.
+func (s *Server) ListenAndServe() error {
+	err := s.httpServer.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+
+	return stacktrace.Propagate(err, "health server failed")
+}
+
+// Shutdown fails the readiness probe, so that the agent stops receiving traffic, and gracefully stops the server
+// after the drain delay, or once ctx is done.
+// It's a shutdown.Hook, e.g `manager.Register("health", server.Shutdown)`.
+func (s *Server) Shutdown(ctx context.Context) error {
+	atomic.StoreInt32(&s.shuttingDown, 1)
+
+	if s.options.DrainDelay > 0 {
+		select {
+		case <-s.options.Clock.After(s.options.DrainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	return stacktrace.Propagate(s.httpServer.Shutdown(ctx), "health server shutdown failed")
+}
+
+func (s *Server) respond(w http.ResponseWriter, checks []*cachedCheck, shuttingDown bool) {
+	report := s.run(checks)
+	if shuttingDown {
+		report.Status = statusFailed
+		report.Error = "shutting down"
+	}
+
+	statusCode := http.StatusOK
+	if report.Status != statusOK {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// run runs the checks concurrently.
+func (s *Server) run(checks []*cachedCheck) *Report {
+	report := &Report{
+		Status: statusOK,
+		Checks: make(map[string]*CheckReport, len(checks)),
+	}
+
+	errs := make([]error, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i, check := range checks {
+		wg.Add(1)
+
+		go func(i int, check *cachedCheck) {
+			defer wg.Done()
+
+			errs[i] = check.run()
+		}(i, check)
+	}
+
+	wg.Wait()
+
+	for i, check := range checks {
+		if errs[i] == nil {
+			report.Checks[check.name] = &CheckReport{Status: statusOK}
+			continue
+		}
+
+		report.Status = statusFailed
+		report.Checks[check.name] = &CheckReport{Status: statusFailed, Error: errs[i].Error()}
+	}
+
+	return report
+}
+
+func (s *Server) newCachedCheck(name string, check Check) *cachedCheck {
+	return &cachedCheck{
+		name:    name,
+		check:   check,
+		ttl:     s.options.CacheTTL,
+		timeout: s.options.Timeout,
+		clock:   s.options.Clock,
+		log:     s.log,
+	}
+}
+
+// cachedCheck reuses the result of a check for the TTL. Concurrent probes wait for a single run of the check.
+type cachedCheck struct {
+	name    string
+	check   Check
+	ttl     time.Duration
+	timeout time.Duration
+	clock   clock.Clock
+	log     logger.Logger
+
+	mu        sync.Mutex
+	checked   bool
+	checkedAt time.Time
+	err       error
+}
+
+// run runs the check, or returns its cached result.
+//
+// NOTE: The check is not run with the context of the probe, since its result is shared with the other probes.
+func (c *cachedCheck) run() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.checked && c.clock.Since(c.checkedAt) < c.ttl {
+		return c.err
+	}
+
+	checkCtx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	err := c.check(checkCtx)
+	if err != nil {
+		c.log.Warnf("Health check %s failed: %s", c.name, err)
+	}
+
+	c.checked = true
+	c.checkedAt = c.clock.Now()
+	c.err = err
+
+	return err
+}
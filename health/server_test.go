@@ -0,0 +1,222 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/clock/clocktest"
+	"github.com/sumup-oss/go-pkgs/logger"
+	"github.com/sumup-oss/go-pkgs/logger/testlogger"
+)
+
+func probe(t *testing.T, server *Server, path string) (int, *Report) {
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var report Report
+
+	err := json.Unmarshal(recorder.Body.Bytes(), &report)
+	require.Nil(t, err)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	return recorder.Code, &report
+}
+
+func TestServer_Handler(t *testing.T) {
+	t.Run(
+		"it responds 200 when all the checks pass",
+		func(t *testing.T) {
+			t.Parallel()
+
+			server := NewServer(testlogger.NewRecording(), nil)
+			server.AddLivenessCheck("children", func(ctx context.Context) error { return nil })
+			server.AddReadinessCheck("kubectl", func(ctx context.Context) error { return nil })
+
+			code, report := probe(t, server, "/livez")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, &Report{Status: "ok", Checks: map[string]*CheckReport{"children": {Status: "ok"}}}, report)
+
+			code, report = probe(t, server, "/readyz")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, &Report{Status: "ok", Checks: map[string]*CheckReport{"kubectl": {Status: "ok"}}}, report)
+		},
+	)
+
+	t.Run(
+		"it responds 503 with the failed checks and logs them",
+		func(t *testing.T) {
+			t.Parallel()
+
+			log := testlogger.NewRecording()
+
+			server := NewServer(log, nil)
+			server.AddReadinessCheck("kubectl", func(ctx context.Context) error { return nil })
+			server.AddReadinessCheck("registry", func(ctx context.Context) error { return errors.New("connection refused") })
+
+			code, report := probe(t, server, "/readyz")
+			assert.Equal(t, http.StatusServiceUnavailable, code)
+			assert.Equal(
+				t,
+				&Report{
+					Status: "failed",
+					Checks: map[string]*CheckReport{
+						"kubectl":  {Status: "ok"},
+						"registry": {Status: "failed", Error: "connection refused"},
+					},
+				},
+				report,
+			)
+			log.AssertLogged(t, logger.WarnLevel, "Health check registry failed: connection refused")
+		},
+	)
+
+	t.Run(
+		"it reuses the results of the checks for the cache TTL",
+		func(t *testing.T) {
+			t.Parallel()
+
+			fakeClock := clocktest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+			var runs int32
+
+			server := NewServer(testlogger.NewRecording(), &ServerOptions{CacheTTL: 10 * time.Second, Clock: fakeClock})
+			server.AddReadinessCheck("kubectl", func(ctx context.Context) error {
+				atomic.AddInt32(&runs, 1)
+				return nil
+			})
+
+			probe(t, server, "/readyz")
+			fakeClock.Advance(9 * time.Second)
+			probe(t, server, "/readyz")
+			assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+
+			fakeClock.Advance(time.Second)
+			probe(t, server, "/readyz")
+			assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+		},
+	)
+
+	t.Run(
+		"it fails the checks exceeding the timeout",
+		func(t *testing.T) {
+			t.Parallel()
+
+			server := NewServer(testlogger.NewRecording(), &ServerOptions{Timeout: time.Millisecond})
+			server.AddLivenessCheck("children", func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+
+			code, report := probe(t, server, "/livez")
+			assert.Equal(t, http.StatusServiceUnavailable, code)
+			assert.Equal(t, "context deadline exceeded", report.Checks["children"].Error)
+		},
+	)
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	t.Run(
+		"it fails the readiness probe and stops the server",
+		func(t *testing.T) {
+			t.Parallel()
+
+			server := NewServer(testlogger.NewRecording(), &ServerOptions{Addr: "127.0.0.1:0"})
+			server.AddReadinessCheck("shutdown", func(ctx context.Context) error { return nil })
+
+			served := make(chan error, 1)
+			go func() {
+				served <- server.ListenAndServe()
+			}()
+
+			err := server.Shutdown(context.Background())
+			require.Nil(t, err)
+
+			select {
+			case err := <-served:
+				assert.Nil(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("server not stopped")
+			}
+
+			code, report := probe(t, server, "/readyz")
+			assert.Equal(t, http.StatusServiceUnavailable, code)
+			assert.Equal(t, "failed", report.Status)
+			assert.Equal(t, "shutting down", report.Error)
+			assert.Equal(t, &CheckReport{Status: "ok"}, report.Checks["shutdown"])
+
+			code, _ = probe(t, server, "/livez")
+			assert.Equal(t, http.StatusOK, code)
+		},
+	)
+	t.Run(
+		"it serves the failing readiness probe for the drain delay before stopping the server",
+		func(t *testing.T) {
+			t.Parallel()
+
+			fakeClock := clocktest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+			server := NewServer(
+				testlogger.NewRecording(),
+				&ServerOptions{Addr: "127.0.0.1:0", DrainDelay: 10 * time.Second, Clock: fakeClock},
+			)
+
+			served := make(chan error, 1)
+			go func() {
+				served <- server.ListenAndServe()
+			}()
+
+			shutdown := make(chan error, 1)
+			go func() {
+				shutdown <- server.Shutdown(context.Background())
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			require.Nil(t, fakeClock.BlockUntil(ctx, 1))
+
+			code, report := probe(t, server, "/readyz")
+			assert.Equal(t, http.StatusServiceUnavailable, code)
+			assert.Equal(t, "shutting down", report.Error)
+
+			select {
+			case <-shutdown:
+				t.Fatal("server stopped before the drain delay")
+			default:
+			}
+
+			fakeClock.Advance(10 * time.Second)
+
+			select {
+			case err := <-shutdown:
+				assert.Nil(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("server not stopped after the drain delay")
+			}
+
+			assert.Nil(t, <-served)
+		},
+	)
+}
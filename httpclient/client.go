@@ -0,0 +1,233 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httpclient is a resilient HTTP client with sane timeouts, retries of the connection errors
+// and 5xx responses of idempotent requests and request logging with redaction, e.g for webhook calls and health probes.
+package httpclient
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"github.com/sumup-oss/go-pkgs/retry"
+)
+
+const (
+	defaultTimeout     = 30 * time.Second
+	defaultMaxAttempts = 3
+)
+
+var _ Client = (*RealClient)(nil)
+
+// Client sends HTTP requests. It's satisfied by `http.Client` too.
+type Client interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Options configures a RealClient.
+type Options struct {
+	// Timeout of a single attempt, including reading the response body. Defaults to 30 seconds.
+	Timeout time.Duration
+	// Retry is the retry policy of the connection errors and 5xx responses.
+	// Defaults to 3 attempts with exponential backoff. Set `MaxAttempts` to 1 to disable retries.
+	Retry *retry.Policy
+	// Logger logs the requests and responses at debug level. No logging when nil.
+	Logger logger.Logger
+	// Redactor redacts the secrets, e.g tokens in query parameters, from the logged URLs.
+	Redactor *logger.Redactor
+	// Transport defaults to a transport with dial, TLS handshake and response header timeouts.
+	Transport http.RoundTripper
+}
+
+// RealClient is a Client retrying the idempotent failures of the wrapped `http.Client`.
+type RealClient struct {
+	client   *http.Client
+	policy   retry.Policy
+	logger   logger.Logger
+	redactor *logger.Redactor
+}
+
+// NewRealClient creates RealClient instance, with the defaults of Options when options is nil.
+func NewRealClient(options *Options) *RealClient {
+	var opts Options
+	if options != nil {
+		opts = *options
+	}
+
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	if opts.Transport == nil {
+		opts.Transport = newTransport()
+	}
+
+	policy := retry.Policy{
+		Backoff: &retry.Exponential{
+			Initial: 200 * time.Millisecond,
+			Max:     2 * time.Second,
+			Jitter:  0.2,
+		},
+		MaxAttempts: defaultMaxAttempts,
+	}
+	if opts.Retry != nil {
+		policy = *opts.Retry
+	}
+
+	return &RealClient{
+		client: &http.Client{
+			Timeout:   opts.Timeout,
+			Transport: opts.Transport,
+		},
+		policy:   policy,
+		logger:   opts.Logger,
+		redactor: opts.Redactor,
+	}
+}
+
+func newTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 20 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// Do sends the request until it succeeds, fails with a non-retryable error or the retry policy limits are exceeded,
+// and returns the response or error of the last attempt. Like `http.Client`, a 5xx response is not an error.
+// Only the idempotent requests are retried, i.e GET, HEAD, PUT, DELETE and OPTIONS ones, or the ones with
+// an `Idempotency-Key` header. Requests with a body are retried only when it can be rewound through `GetBody`,
+// which `http.NewRequest` sets for the bytes and strings readers.
+func (c *RealClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	retryable := rewindable && isIdempotent(req)
+	redactedURL := c.redact(req.URL.String())
+
+	var resp *http.Response
+
+	var err error
+
+	attempt := 0
+
+	_ = retry.Do(ctx, c.policy, func(ctx context.Context) error {
+		attempt++
+
+		if resp != nil {
+			drainAndClose(resp.Body)
+			resp = nil
+		}
+
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			attemptReq = req.WithContext(ctx)
+
+			attemptReq.Body, err = req.GetBody()
+			if err != nil {
+				err = stacktrace.Propagate(err, "rewinding body of HTTP request %s %s failed", req.Method, redactedURL)
+				return retry.Permanent(err)
+			}
+		}
+
+		c.debugf("http# %s %s (attempt %d)", req.Method, redactedURL, attempt)
+
+		start := time.Now()
+		resp, err = c.client.Do(attemptReq)
+
+		if err != nil {
+			err = c.redactURLError(err)
+
+			c.debugf("http# %s %s failed in %s: %s", req.Method, redactedURL, time.Since(start), err)
+
+			err = stacktrace.Propagate(err, "HTTP request %s %s failed", req.Method, redactedURL)
+			if !retryable || ctx.Err() != nil {
+				return retry.Permanent(err)
+			}
+
+			return err
+		}
+
+		c.debugf("http# %s %s responded %d in %s", req.Method, redactedURL, resp.StatusCode, time.Since(start))
+
+		if resp.StatusCode >= http.StatusInternalServerError && retryable {
+			return stacktrace.NewError("HTTP request %s %s responded %d", req.Method, redactedURL, resp.StatusCode)
+		}
+
+		return nil
+	})
+
+	return resp, err
+}
+
+// isIdempotent checks whether sending the request more than once has the same effect as sending it once.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// redactURLError redacts the URL of the `*url.Error` returned by `http.Client`, keeping its type,
+// so that the secrets of the URL never leak through the returned errors.
+func (c *RealClient) redactURLError(err error) error {
+	urlErr, ok := err.(*url.Error)
+	if !ok || c.redactor == nil {
+		return err
+	}
+
+	return &url.Error{
+		Op:  urlErr.Op,
+		URL: c.redact(urlErr.URL),
+		Err: urlErr.Err,
+	}
+}
+
+func (c *RealClient) debugf(format string, args ...interface{}) {
+	if c.logger == nil {
+		return
+	}
+
+	c.logger.Debugf(format, args...)
+}
+
+func (c *RealClient) redact(s string) string {
+	if c.redactor == nil {
+		return s
+	}
+
+	return c.redactor.Redact(s)
+}
+
+// drainAndClose reads the rest of body, so that its connection can be reused, and closes it.
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.Copy(ioutil.Discard, body)
+	_ = body.Close()
+}
@@ -0,0 +1,315 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/palantir/stacktrace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"github.com/sumup-oss/go-pkgs/logger/testlogger"
+	"github.com/sumup-oss/go-pkgs/retry"
+)
+
+func newTestClient(options *Options) *RealClient {
+	if options == nil {
+		options = &Options{}
+	}
+
+	options.Retry = &retry.Policy{
+		Backoff:     retry.Constant(time.Millisecond),
+		MaxAttempts: 3,
+	}
+
+	return NewRealClient(options)
+}
+
+func TestRealClient_Do(t *testing.T) {
+	t.Run(
+		"it retries the 5xx responses until it succeeds and replays the body",
+		func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				assert.Equal(t, `{"text":"deployed"}`, string(body))
+
+				if atomic.AddInt32(&calls, 1) < 3 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+
+				_, _ = w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader(`{"text":"deployed"}`))
+			require.Nil(t, err)
+
+			resp, err := newTestClient(nil).Do(req)
+			require.Nil(t, err)
+
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			require.Nil(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "ok", string(body))
+			assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+		},
+	)
+
+	t.Run(
+		"it does not retry a non-idempotent request",
+		func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(http.StatusBadGateway)
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"text":"deployed"}`))
+			require.Nil(t, err)
+
+			resp, err := newTestClient(nil).Do(req)
+			require.Nil(t, err)
+
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		},
+	)
+
+	t.Run(
+		"it retries a non-idempotent request with an idempotency key",
+		func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "deploy-42", r.Header.Get("Idempotency-Key"))
+
+				if atomic.AddInt32(&calls, 1) < 2 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"text":"deployed"}`))
+			require.Nil(t, err)
+
+			req.Header.Set("Idempotency-Key", "deploy-42")
+
+			resp, err := newTestClient(nil).Do(req)
+			require.Nil(t, err)
+
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+		},
+	)
+
+	t.Run(
+		"it returns the last 5xx response without error when the attempts are exceeded",
+		func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.Nil(t, err)
+
+			resp, err := newTestClient(nil).Do(req)
+			require.Nil(t, err)
+
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+			assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+		},
+	)
+
+	t.Run(
+		"it does not retry the 4xx responses",
+		func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.Nil(t, err)
+
+			resp, err := newTestClient(nil).Do(req)
+			require.Nil(t, err)
+
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		},
+	)
+
+	t.Run(
+		"it does not retry a request with a body that cannot be rewound",
+		func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPut, server.URL, ioutil.NopCloser(strings.NewReader("payload")))
+			require.Nil(t, err)
+
+			resp, err := newTestClient(nil).Do(req)
+			require.Nil(t, err)
+
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		},
+	)
+
+	t.Run(
+		"it returns error when the connection fails on every attempt",
+		func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.NotFoundHandler())
+			server.Close()
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.Nil(t, err)
+
+			resp, err := newTestClient(nil).Do(req)
+			require.Error(t, err)
+			assert.Nil(t, resp)
+			assert.Contains(t, err.Error(), "HTTP request GET "+server.URL+" failed")
+		},
+	)
+
+	t.Run(
+		"when the connection fails, it returns error with the secrets redacted from the URL",
+		func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.NotFoundHandler())
+			server.Close()
+
+			redactor := logger.NewRedactor()
+			redactor.Add("s3cr3t")
+
+			req, err := http.NewRequest(http.MethodGet, server.URL+"/hooks?token=s3cr3t", nil)
+			require.Nil(t, err)
+
+			_, err = newTestClient(&Options{Redactor: redactor}).Do(req)
+			require.Error(t, err)
+			assert.NotContains(t, err.Error(), "s3cr3t")
+			assert.Contains(t, err.Error(), "/hooks?token=[REDACTED]")
+
+			_, ok := stacktrace.RootCause(err).(*url.Error)
+			assert.True(t, ok)
+		},
+	)
+
+	t.Run(
+		"it does not retry when the context is canceled",
+		func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.Nil(t, err)
+
+			_, err = newTestClient(nil).Do(req.WithContext(ctx))
+			require.Error(t, err)
+			assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+		},
+	)
+
+	t.Run(
+		"it logs the requests with the secrets redacted from the URL",
+		func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			defer server.Close()
+
+			redactor := logger.NewRedactor()
+			redactor.Add("s3cr3t")
+
+			log := testlogger.NewRecording()
+
+			req, err := http.NewRequest(http.MethodGet, server.URL+"/hooks?token=s3cr3t", nil)
+			require.Nil(t, err)
+
+			resp, err := newTestClient(&Options{Logger: log, Redactor: redactor}).Do(req)
+			require.Nil(t, err)
+
+			defer resp.Body.Close()
+
+			log.AssertLogged(t, logger.DebugLevel, "http# GET "+server.URL+"/hooks?token=[REDACTED] (attempt 1)")
+			log.AssertLogged(t, logger.DebugLevel, "responded 200")
+			log.AssertNotLogged(t, logger.DebugLevel, "s3cr3t")
+		},
+	)
+}
@@ -0,0 +1,65 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclienttest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/sumup-oss/go-pkgs/httpclient"
+)
+
+var _ httpclient.Client = (*FakeClient)(nil)
+
+type FakeClient struct {
+	mock.Mock
+}
+
+func NewFakeClient(t *testing.T) *FakeClient {
+	fake := &FakeClient{}
+	fake.Test(t)
+
+	return fake
+}
+
+func (f *FakeClient) Do(req *http.Request) (*http.Response, error) {
+	args := f.Called(req)
+
+	resp, _ := args.Get(0).(*http.Response)
+
+	return resp, args.Error(1)
+}
+
+// NewResponse creates a response with statusCode and body, to be returned by FakeClient.
+func NewResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		Status:     http.StatusText(statusCode),
+		StatusCode: statusCode,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// MatchRequest matches the requests by method and URL in `On` expectations,
+// e.g `fake.On("Do", httpclienttest.MatchRequest(http.MethodGet, "https://example.com/healthz"))`.
+func MatchRequest(method, url string) interface{} {
+	return mock.MatchedBy(func(req *http.Request) bool {
+		return req.Method == method && req.URL.String() == url
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclienttest
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeClient_Do(t *testing.T) {
+	t.Run(
+		"it returns the response of the matched request",
+		func(t *testing.T) {
+			t.Parallel()
+
+			fake := NewFakeClient(t)
+			fake.On("Do", MatchRequest(http.MethodGet, "https://example.com/healthz")).
+				Return(NewResponse(http.StatusOK, "ok"), nil).
+				Once()
+
+			req, err := http.NewRequest(http.MethodGet, "https://example.com/healthz", nil)
+			require.Nil(t, err)
+
+			resp, err := fake.Do(req)
+			require.Nil(t, err)
+
+			body, err := ioutil.ReadAll(resp.Body)
+			require.Nil(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "ok", string(body))
+			fake.AssertExpectations(t)
+		},
+	)
+
+	t.Run(
+		"it returns the error without response",
+		func(t *testing.T) {
+			t.Parallel()
+
+			fake := NewFakeClient(t)
+			fake.On("Do", MatchRequest(http.MethodPost, "https://example.com/hooks")).
+				Return(nil, errors.New("connection refused")).
+				Once()
+
+			req, err := http.NewRequest(http.MethodPost, "https://example.com/hooks", nil)
+			require.Nil(t, err)
+
+			resp, err := fake.Do(req)
+			assert.Nil(t, resp)
+			assert.EqualError(t, err, "connection refused")
+			fake.AssertExpectations(t)
+		},
+	)
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sumup-oss/go-pkgs/tracing"
+)
+
+const (
+	// CorrelationIDField is the field name of the correlation ID attached by WithCorrelationID.
+	CorrelationIDField = "correlation_id"
+	// TraceIDField is the field name of the trace ID of the active span.
+	TraceIDField = "trace_id"
+	// SpanIDField is the field name of the span ID of the active span.
+	SpanIDField = "span_id"
+)
+
+// TraceExtractor returns the trace and span IDs of the active span carried by `ctx`.
+// `ok` is false when there is no valid active span.
+// By default, tracing.IDsFromContext extracts the IDs of the spans of a tracing.Tracer.
+//
+// E.g for OpenTelemetry, `IDsFromContext` of the `github.com/sumup-oss/go-pkgs/tracing/otel` module
+// extracts the IDs of `trace.SpanContextFromContext`:
+//
+//	logger.SetTraceExtractor(otel.IDsFromContext)
+type TraceExtractor func(ctx context.Context) (traceID, spanID string, ok bool)
+
+var (
+	traceExtractor   TraceExtractor = tracing.IDsFromContext
+	traceExtractorMu sync.RWMutex
+)
+
+// SetTraceExtractor sets the extractor used to attach `trace_id` and `span_id` fields
+// to the loggers returned by FromContext and WithContextFields, instead of tracing.IDsFromContext.
+// Passing nil disables it.
+func SetTraceExtractor(extractor TraceExtractor) {
+	traceExtractorMu.Lock()
+	defer traceExtractorMu.Unlock()
+	traceExtractor = extractor
+}
+
+func getTraceExtractor() TraceExtractor {
+	traceExtractorMu.RLock()
+	defer traceExtractorMu.RUnlock()
+	return traceExtractor
+}
+
+type loggerContextKey struct{}
+
+type correlationIDContextKey struct{}
+
+// NewContext returns a copy of `ctx` carrying `logger`.
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// FromContext returns the logger carried by `ctx`.
+// When there is none, the standard logger is returned, with the correlation ID of `ctx` attached if any.
+// The trace and span IDs of the active span of `ctx` are attached, per the TraceExtractor.
+func FromContext(ctx context.Context) Logger {
+	logger, ok := ctx.Value(loggerContextKey{}).(Logger)
+	if ok {
+		return withTraceFields(logger, ctx)
+	}
+
+	return WithContextFields(GetLogger(), ctx)
+}
+
+// WithContextFields returns `logger` with the correlation ID and the trace and span IDs of `ctx` attached.
+// It's meant for the loggers that are not carried by `ctx`, e.g injected ones.
+func WithContextFields(logger Logger, ctx context.Context) Logger {
+	correlationID := CorrelationIDFromContext(ctx)
+	if correlationID != "" {
+		logger = logger.With(CorrelationIDField, correlationID)
+	}
+
+	return withTraceFields(logger, ctx)
+}
+
+func withTraceFields(logger Logger, ctx context.Context) Logger {
+	extractor := getTraceExtractor()
+	if extractor == nil {
+		return logger
+	}
+
+	traceID, spanID, ok := extractor(ctx)
+	if !ok {
+		return logger
+	}
+
+	return logger.WithFields(Fields{
+		TraceIDField: traceID,
+		SpanIDField:  spanID,
+	})
+}
+
+// WithCorrelationID returns a copy of `ctx` carrying the correlation ID, e.g request or deploy ID,
+// and a logger derived from the logger of `ctx` that attaches it to every log entry.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	logger, ok := ctx.Value(loggerContextKey{}).(Logger)
+	if !ok {
+		logger = GetLogger()
+	}
+
+	logger = logger.With(CorrelationIDField, correlationID)
+
+	ctx = context.WithValue(ctx, correlationIDContextKey{}, correlationID)
+
+	return NewContext(ctx, logger)
+}
+
+// CorrelationIDFromContext returns the correlation ID carried by `ctx` or blank string if there is none.
+func CorrelationIDFromContext(ctx context.Context) string {
+	correlationID, _ := ctx.Value(correlationIDContextKey{}).(string)
+	return correlationID
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sumup-oss/go-pkgs/tracing"
+	"github.com/sumup-oss/go-pkgs/tracing/tracingtest"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Run("when context has no logger, it returns the standard logger", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, GetLogger(), FromContext(context.Background()))
+	})
+
+	t.Run("when context has a logger, it returns it", func(t *testing.T) {
+		t.Parallel()
+
+		log := NewLogrusLogger()
+		ctx := NewContext(context.Background(), log)
+
+		assert.Equal(t, log, FromContext(ctx))
+	})
+}
+
+func TestWithCorrelationID(t *testing.T) {
+	t.Run("it attaches the correlation ID to the context logger", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+
+		ctx := NewContext(context.Background(), log)
+		ctx = WithCorrelationID(ctx, "deploy-42")
+
+		FromContext(ctx).Info("deploying")
+
+		assert.Equal(t, "deploy-42", CorrelationIDFromContext(ctx))
+		assert.Contains(t, out.String(), "correlation_id=deploy-42")
+	})
+
+	t.Run("when context has no correlation ID, it returns blank string", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "", CorrelationIDFromContext(context.Background()))
+	})
+}
+
+func TestSetTraceExtractor(t *testing.T) {
+	t.Run("when context has an active span, it attaches trace and span IDs", func(t *testing.T) {
+		SetTraceExtractor(func(ctx context.Context) (string, string, bool) {
+			return "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true
+		})
+		defer SetTraceExtractor(tracing.IDsFromContext)
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+
+		FromContext(NewContext(context.Background(), log)).Info("traced")
+
+		assert.Contains(t, out.String(), "span_id=00f067aa0ba902b7 trace_id=4bf92f3577b34da6a3ce929d0e0e4736")
+	})
+
+	t.Run("when context has no active span, it does not attach trace and span IDs", func(t *testing.T) {
+		SetTraceExtractor(func(ctx context.Context) (string, string, bool) {
+			return "", "", false
+		})
+		defer SetTraceExtractor(tracing.IDsFromContext)
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+
+		WithContextFields(log, context.Background()).Info("not traced")
+
+		assert.NotContains(t, out.String(), "trace_id")
+	})
+
+	t.Run("by default, it attaches trace and span IDs of the tracing span", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+
+		ctx, span := tracingtest.NewRecordingTracer().Start(context.Background(), "deploy")
+
+		WithContextFields(log, ctx).Info("traced")
+
+		assert.Contains(t, out.String(), "span_id="+span.SpanContext().SpanID)
+		assert.Contains(t, out.String(), "trace_id="+span.SpanContext().TraceID)
+	})
+}
@@ -0,0 +1,146 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ErrorFieldKey is the key of the field holding the reported error, same as `ErrorField`.
+const ErrorFieldKey = "error"
+
+const errorReportingMaxFrames = 64
+
+var errorReportingSkippedPackages = []string{
+	"runtime.",
+	"github.com/sirupsen/logrus.",
+	"go.uber.org/zap.",
+	"go.uber.org/zap/zapcore.",
+	"github.com/sumup-oss/go-pkgs/logger.",
+}
+
+var _ Hook = (*ErrorReportingHook)(nil)
+
+// ErrorReporter sends error reports to an error tracking service, e.g Sentry.
+type ErrorReporter interface {
+	Report(report *ErrorReport) error
+}
+
+// ErrorReport is a logged error entry along with the stack trace of the log call.
+type ErrorReport struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	// Error is the value of the `ErrorFieldKey` field when it's an error.
+	Error  error
+	Fields map[string]interface{}
+	// Stacktrace is ordered from the log call to the outermost call.
+	Stacktrace []StackFrame
+}
+
+// StackFrame is a single frame of an ErrorReport stack trace.
+type StackFrame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// ErrorReportingHook reports the error, fatal and panic entries to an ErrorReporter.
+// It's added to a logger as any other hook, e.g `logger.AddHook(hook)` or
+// through `Configuration.Hooks` of ZapLogger.
+type ErrorReportingHook struct {
+	reporter ErrorReporter
+	levels   []Level
+}
+
+// NewErrorReportingHook creates ErrorReportingHook instance.
+// Without `levels`, error, fatal and panic entries are reported.
+func NewErrorReportingHook(reporter ErrorReporter, levels ...Level) *ErrorReportingHook {
+	if len(levels) == 0 {
+		levels = []Level{PanicLevel, FatalLevel, ErrorLevel}
+	}
+
+	return &ErrorReportingHook{
+		reporter: reporter,
+		levels:   levels,
+	}
+}
+
+func (hook *ErrorReportingHook) Levels() []Level {
+	return hook.levels
+}
+
+func (hook *ErrorReportingHook) Fire(entry Entry) error {
+	report := &ErrorReport{
+		Time:       entry.GetTime(),
+		Level:      entry.GetLevel(),
+		Message:    entry.GetMessage(),
+		Fields:     make(map[string]interface{}, len(entry.GetFields())),
+		Stacktrace: callerStacktrace(),
+	}
+
+	for key, value := range entry.GetFields() {
+		if err, ok := value.(error); ok && key == ErrorFieldKey {
+			report.Error = err
+			continue
+		}
+
+		report.Fields[key] = value
+	}
+
+	return hook.reporter.Report(report)
+}
+
+func callerStacktrace() []StackFrame {
+	pcs := make([]uintptr, errorReportingMaxFrames)
+	count := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:count])
+
+	var stacktrace []StackFrame
+	for {
+		frame, more := frames.Next()
+
+		// NOTE: Skip the frames of the logger itself until the log call site.
+		if len(stacktrace) > 0 || !isLoggingFrame(frame) {
+			stacktrace = append(stacktrace, StackFrame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			})
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return stacktrace
+}
+
+func isLoggingFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+
+	for _, pkg := range errorReportingSkippedPackages {
+		if strings.HasPrefix(frame.Function, pkg) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type recordingErrorReporter struct {
+	mu      sync.Mutex
+	reports []*ErrorReport
+}
+
+func (r *recordingErrorReporter) Report(report *ErrorReport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reports = append(r.reports, report)
+
+	return nil
+}
+
+func TestErrorReportingHook(t *testing.T) {
+	t.Run("it reports error entries of logrus logger with fields and stack trace", func(t *testing.T) {
+		t.Parallel()
+
+		reporter := &recordingErrorReporter{}
+		hook := NewErrorReportingHook(reporter)
+
+		log := NewLogrusLogger()
+		log.SetOutput(ioutil.Discard)
+		log.AddHook(hook)
+
+		err := errors.New("connection refused")
+
+		log.Warn("retrying")
+		log.WithFields(Fields{ErrorFieldKey: err, "namespace": "default"}).Error("deploy failed")
+
+		require.Len(t, reporter.reports, 1)
+
+		report := reporter.reports[0]
+		assert.Equal(t, ErrorLevel, report.Level)
+		assert.Equal(t, "deploy failed", report.Message)
+		assert.Equal(t, err, report.Error)
+		assert.Equal(t, map[string]interface{}{"namespace": "default"}, report.Fields)
+		require.NotEmpty(t, report.Stacktrace)
+		assert.True(
+			t,
+			strings.HasSuffix(report.Stacktrace[0].File, "error_reporting_test.go"),
+			report.Stacktrace[0].File,
+		)
+	})
+
+	t.Run("it reports error entries of zap logger with fields and stack trace", func(t *testing.T) {
+		t.Parallel()
+
+		reporter := &recordingErrorReporter{}
+
+		log, err := NewZapLogger(Configuration{
+			Level:    LogLevelInfo,
+			Encoding: EncodingJSON,
+			Hooks:    []Hook{NewErrorReportingHook(reporter)},
+		})
+		require.NoError(t, err)
+
+		reportedErr := errors.New("connection refused")
+
+		log.Info("deploying")
+		log.With(zap.String("namespace", "default")).Error("deploy failed", ErrorField(reportedErr))
+
+		require.Len(t, reporter.reports, 1)
+
+		report := reporter.reports[0]
+		assert.Equal(t, ErrorLevel, report.Level)
+		assert.Equal(t, "deploy failed", report.Message)
+		assert.Equal(t, reportedErr, report.Error)
+		assert.Equal(t, map[string]interface{}{"namespace": "default"}, report.Fields)
+		require.NotEmpty(t, report.Stacktrace)
+		assert.True(
+			t,
+			strings.HasSuffix(report.Stacktrace[0].File, "error_reporting_test.go"),
+			report.Stacktrace[0].File,
+		)
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+
+	"github.com/palantir/stacktrace"
+)
+
+// AtomicLevel is a level that is safe to read and change concurrently.
+type AtomicLevel struct {
+	level uint32
+}
+
+// NewAtomicLevel creates AtomicLevel instance.
+func NewAtomicLevel(level Level) *AtomicLevel {
+	return &AtomicLevel{
+		level: uint32(level),
+	}
+}
+
+// Level returns the current level.
+func (l *AtomicLevel) Level() Level {
+	return Level(atomic.LoadUint32(&l.level))
+}
+
+// SetLevel changes the current level.
+func (l *AtomicLevel) SetLevel(level Level) {
+	atomic.StoreUint32(&l.level, uint32(level))
+}
+
+// Enabled checks if entries at `level` are logged.
+func (l *AtomicLevel) Enabled(level Level) bool {
+	return l.Level() >= level
+}
+
+// ParseLevel parses a level name, e.g `debug` or `WARN`.
+func ParseLevel(name string) (Level, error) {
+	lowerName := strings.ToLower(name)
+
+	// NOTE: Accept `warn` alongside the `warning` returned by `Level.String`.
+	if lowerName == "warn" {
+		return WarnLevel, nil
+	}
+
+	for _, level := range AllLevels {
+		if level.String() == lowerName {
+			return level, nil
+		}
+	}
+
+	return InfoLevel, stacktrace.NewError("invalid log level %s", name)
+}
+
+type levelPayload struct {
+	Level string `json:"level"`
+}
+
+// NewLevelHandler creates an admin endpoint handler, that serves the level of `logger` on GET
+// and changes it on PUT, both as JSON, e.g `{"level":"debug"}`.
+func NewLevelHandler(logger Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var payload levelPayload
+
+			err := json.NewDecoder(r.Body).Decode(&payload)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+
+				return
+			}
+
+			level, err := ParseLevel(payload.Level)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+
+				return
+			}
+
+			logger.SetLevel(level)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(levelPayload{Level: logger.GetLevel().String()})
+	})
+}
+
+// ToggleLevelOnSignal switches the level of `logger` to `level` on every `signals` receive
+// and back to the previous level on the next receive, e.g to debug on SIGUSR1.
+// The returned func stops the signals handling.
+func ToggleLevelOnSignal(logger Logger, level Level, signals ...os.Signal) func() {
+	signalCh := make(chan os.Signal, 1)
+	doneCh := make(chan struct{})
+
+	signal.Notify(signalCh, signals...)
+
+	go func() {
+		previousLevel := logger.GetLevel()
+		toggled := false
+
+		for {
+			select {
+			case <-doneCh:
+				return
+			case <-signalCh:
+				if toggled {
+					logger.SetLevel(previousLevel)
+				} else {
+					previousLevel = logger.GetLevel()
+					logger.SetLevel(level)
+				}
+
+				toggled = !toggled
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signalCh)
+		close(doneCh)
+	}
+}
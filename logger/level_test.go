@@ -0,0 +1,105 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseLevel(t *testing.T) {
+	t.Run("it parses level names case insensitively", func(t *testing.T) {
+		t.Parallel()
+
+		for name, expected := range map[string]Level{
+			"debug":   DebugLevel,
+			"INFO":    InfoLevel,
+			"warn":    WarnLevel,
+			"Warning": WarnLevel,
+			"error":   ErrorLevel,
+		} {
+			actual, err := ParseLevel(name)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual, name)
+		}
+	})
+
+	t.Run("when level name is invalid, it returns an error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseLevel("verbose")
+		assert.Error(t, err)
+	})
+}
+
+func TestNewLevelHandler(t *testing.T) {
+	t.Run("it serves and changes the logger level", func(t *testing.T) {
+		t.Parallel()
+
+		log := NewLogrusLogger()
+		log.SetLevel(InfoLevel)
+		handler := NewLevelHandler(log)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"level":"info"}`, recorder.Body.String())
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(
+			recorder,
+			httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"debug"}`)),
+		)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"level":"debug"}`, recorder.Body.String())
+		assert.Equal(t, DebugLevel, log.GetLevel())
+	})
+
+	t.Run("when level is invalid, it responds with bad request", func(t *testing.T) {
+		t.Parallel()
+
+		log := NewLogrusLogger()
+		handler := NewLevelHandler(log)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(
+			recorder,
+			httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"verbose"}`)),
+		)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestZapLogger_SetLevel(t *testing.T) {
+	t.Run("it changes the level at runtime", func(t *testing.T) {
+		t.Parallel()
+
+		log, err := NewZapLogger(Configuration{Level: LogLevelInfo, Encoding: EncodingJSON, StdoutEnabled: true})
+		require.NoError(t, err)
+
+		assert.False(t, log.Core().Enabled(zapcore.DebugLevel))
+
+		log.SetLevel(zapcore.DebugLevel)
+
+		assert.Equal(t, zapcore.DebugLevel, log.GetLevel())
+		assert.True(t, log.Core().Enabled(zapcore.DebugLevel))
+	})
+}
@@ -44,4 +44,10 @@ type Logger interface {
 	Logln(level Level, args ...interface{})
 	SetLevel(level Level)
 	GetLevel() Level
+	With(key string, value interface{}) Logger
+	WithFields(fields Fields) Logger
 }
+
+// Fields is a set of structured context key-value pairs attached to every entry of a logger,
+// e.g namespace, release or command.
+type Fields map[string]interface{}
@@ -16,7 +16,11 @@ package logger
 
 import (
 	"io"
+	"os"
 	"sync"
+	"time"
+
+	"github.com/palantir/stacktrace"
 
 	//nolint:depguard
 	"github.com/sirupsen/logrus"
@@ -85,8 +89,13 @@ func (hook *LogrusHook) Fire(entry *logrus.Entry) error {
 		Message: entry.Message,
 		Level:   Level(uint32(entry.Level)),
 		Time:    entry.Time,
-		Fields:  make(map[string]interface{}),
+		Fields:  make(map[string]interface{}, len(entry.Data)),
+	}
+
+	for key, value := range entry.Data {
+		basicEntry.Fields[key] = value
 	}
+
 	return hook.Hook.Fire(basicEntry)
 }
 
@@ -105,16 +114,61 @@ func (hook *LogrusHook) Levels() []logrus.Level {
 
 type LogrusLogger struct {
 	internalLogger *logrus.Logger
-	mu             *sync.Mutex
+	// NOTE: `logrus.Entry` is safe for concurrent use, since every log call works on a copy of it.
+	entry *logrus.Entry
+	mu    *sync.Mutex
 }
 
 func NewLogrusLogger() *LogrusLogger {
+	internalLogger := logrus.New()
+
 	return &LogrusLogger{
-		internalLogger: logrus.New(),
+		internalLogger: internalLogger,
+		entry:          logrus.NewEntry(internalLogger),
 		mu:             &sync.Mutex{},
 	}
 }
 
+// NewLogrusLoggerWithEncoding creates a logger with output encoding,
+// one of `EncodingJSON` or `EncodingPlain`.
+func NewLogrusLoggerWithEncoding(encoding string) (*LogrusLogger, error) {
+	logger := NewLogrusLogger()
+
+	err := logger.SetEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	return logger, nil
+}
+
+// NewLogrusLoggerFromEnv creates a logger with output encoding specified by the `LOG_ENCODING`
+// environment variable. When it's blank, `EncodingPlain` is used.
+func NewLogrusLoggerFromEnv() (*LogrusLogger, error) {
+	encoding := os.Getenv(EncodingEnvVar)
+	if encoding == "" {
+		encoding = EncodingPlain
+	}
+
+	return NewLogrusLoggerWithEncoding(encoding)
+}
+
+// With returns a child logger that attaches the key-value pair to every log entry.
+// The child logger shares level, output and hooks with its parent.
+func (std *LogrusLogger) With(key string, value interface{}) Logger {
+	return std.WithFields(Fields{key: value})
+}
+
+// WithFields returns a child logger that attaches the fields to every log entry.
+// The child logger shares level, output and hooks with its parent.
+func (std *LogrusLogger) WithFields(fields Fields) Logger {
+	return &LogrusLogger{
+		internalLogger: std.internalLogger,
+		entry:          std.entry.WithFields(logrus.Fields(fields)),
+		mu:             std.mu,
+	}
+}
+
 // SetOutput sets the standard logger output.
 func (std *LogrusLogger) SetOutput(out io.Writer) {
 	std.mu.Lock()
@@ -122,12 +176,37 @@ func (std *LogrusLogger) SetOutput(out io.Writer) {
 	std.internalLogger.Out = out
 }
 
-//// SetFormatter sets the standard logger formatter.
-//func (std *LogrusLogger) SetFormatter(formatter logrus.Formatter) {
-//	std.mu.Lock()
-//	defer std.mu.Unlock()
-//	std.internalLogger.Formatter = formatter
-//}
+// SetEncoding sets the standard logger output encoding, one of `EncodingJSON` or `EncodingPlain`.
+func (std *LogrusLogger) SetEncoding(encoding string) error {
+	formatter, err := newLogrusFormatter(encoding)
+	if err != nil {
+		return err
+	}
+
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.internalLogger.Formatter = formatter
+
+	return nil
+}
+
+func newLogrusFormatter(encoding string) (logrus.Formatter, error) {
+	switch encoding {
+	case EncodingJSON:
+		return &logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339Nano,
+			FieldMap: logrus.FieldMap{
+				logrus.FieldKeyTime:  defaultZapEncoderConfig.TimeKey,
+				logrus.FieldKeyLevel: defaultZapEncoderConfig.LevelKey,
+				logrus.FieldKeyMsg:   defaultZapEncoderConfig.MessageKey,
+			},
+		}, nil
+	case EncodingPlain:
+		return &logrus.TextFormatter{}, nil
+	default:
+		return nil, stacktrace.NewError("invalid encoder type: %s", encoding)
+	}
+}
 
 // SetLevel sets the standard logger level.
 func (std *LogrusLogger) SetLevel(level Level) {
@@ -159,135 +238,135 @@ func (std *LogrusLogger) AddHook(hook Hook) {
 
 // Debug logs a message at level Debug on the standard logger.
 func (std *LogrusLogger) Debug(args ...interface{}) {
-	std.internalLogger.Debug(args...)
+	std.entry.Debug(args...)
 }
 
 // Print logs a message at level Info on the standard logger.
 func (std *LogrusLogger) Print(args ...interface{}) {
-	std.internalLogger.Print(args...)
+	std.entry.Print(args...)
 }
 
 // Info logs a message at level Info on the standard logger.
 func (std *LogrusLogger) Info(args ...interface{}) {
-	std.internalLogger.Info(args...)
+	std.entry.Info(args...)
 }
 
 // Warn logs a message at level Warn on the standard logger.
 func (std *LogrusLogger) Warn(args ...interface{}) {
-	std.internalLogger.Warn(args...)
+	std.entry.Warn(args...)
 }
 
 // Warning logs a message at level Warn on the standard logger.
 func (std *LogrusLogger) Warning(args ...interface{}) {
-	std.internalLogger.Warning(args...)
+	std.entry.Warning(args...)
 }
 
 // Error logs a message at level Error on the standard logger.
 func (std *LogrusLogger) Error(args ...interface{}) {
-	std.internalLogger.Error(args...)
+	std.entry.Error(args...)
 }
 
 // Panic logs a message at level Panic on the standard logger.
 func (std *LogrusLogger) Panic(args ...interface{}) {
-	std.internalLogger.Panic(args...)
+	std.entry.Panic(args...)
 }
 
 // Fatal logs a message at level Fatal on the standard logger.
 func (std *LogrusLogger) Fatal(args ...interface{}) {
-	std.internalLogger.Fatal(args...)
+	std.entry.Fatal(args...)
 }
 
 // Debugf logs a message at level Debug on the standard logger.
 func (std *LogrusLogger) Debugf(format string, args ...interface{}) {
-	std.internalLogger.Debugf(format, args...)
+	std.entry.Debugf(format, args...)
 }
 
 // Logf logs a message at specified level on the standard logger.
 func (std *LogrusLogger) Logf(level Level, format string, args ...interface{}) {
-	std.internalLogger.Logf(logrus.Level(level), format, args...)
+	std.entry.Logf(logrus.Level(level), format, args...)
 }
 
 // Log logs a message at specified level on the standard logger.
 func (std *LogrusLogger) Log(level Level, args ...interface{}) {
-	std.internalLogger.Log(logrus.Level(level), args...)
+	std.entry.Log(logrus.Level(level), args...)
 }
 
 // Logln logs a message at specified level on the standard logger.
 func (std *LogrusLogger) Logln(level Level, args ...interface{}) {
-	std.internalLogger.Logln(logrus.Level(level), args...)
+	std.entry.Logln(logrus.Level(level), args...)
 }
 
 // Printf logs a message at level Info on the standard logger.
 func (std *LogrusLogger) Printf(format string, args ...interface{}) {
-	std.internalLogger.Printf(format, args...)
+	std.entry.Printf(format, args...)
 }
 
 // Infof logs a message at level Info on the standard logger.
 func (std *LogrusLogger) Infof(format string, args ...interface{}) {
-	std.internalLogger.Infof(format, args...)
+	std.entry.Infof(format, args...)
 }
 
 // Warnf logs a message at level Warn on the standard logger.
 func (std *LogrusLogger) Warnf(format string, args ...interface{}) {
-	std.internalLogger.Warnf(format, args...)
+	std.entry.Warnf(format, args...)
 }
 
 // Warningf logs a message at level Warn on the standard logger.
 func (std *LogrusLogger) Warningf(format string, args ...interface{}) {
-	std.internalLogger.Warningf(format, args...)
+	std.entry.Warningf(format, args...)
 }
 
 // Errorf logs a message at level Error on the standard logger.
 func (std *LogrusLogger) Errorf(format string, args ...interface{}) {
-	std.internalLogger.Errorf(format, args...)
+	std.entry.Errorf(format, args...)
 }
 
 // Panicf logs a message at level Panic on the standard logger.
 func (std *LogrusLogger) Panicf(format string, args ...interface{}) {
-	std.internalLogger.Panicf(format, args...)
+	std.entry.Panicf(format, args...)
 }
 
 // Fatalf logs a message at level Fatal on the standard logger.
 func (std *LogrusLogger) Fatalf(format string, args ...interface{}) {
-	std.internalLogger.Fatalf(format, args...)
+	std.entry.Fatalf(format, args...)
 }
 
 // Debugln logs a message at level Debug on the standard logger.
 func (std *LogrusLogger) Debugln(args ...interface{}) {
-	std.internalLogger.Debugln(args...)
+	std.entry.Debugln(args...)
 }
 
 // Println logs a message at level Info on the standard logger.
 func (std *LogrusLogger) Println(args ...interface{}) {
-	std.internalLogger.Println(args...)
+	std.entry.Println(args...)
 }
 
 // Infoln logs a message at level Info on the standard logger.
 func (std *LogrusLogger) Infoln(args ...interface{}) {
-	std.internalLogger.Infoln(args...)
+	std.entry.Infoln(args...)
 }
 
 // Warnln logs a message at level Warn on the standard logger.
 func (std *LogrusLogger) Warnln(args ...interface{}) {
-	std.internalLogger.Warnln(args...)
+	std.entry.Warnln(args...)
 }
 
 // Warningln logs a message at level Warn on the standard logger.
 func (std *LogrusLogger) Warningln(args ...interface{}) {
-	std.internalLogger.Warningln(args...)
+	std.entry.Warningln(args...)
 }
 
 // Errorln logs a message at level Error on the standard logger.
 func (std *LogrusLogger) Errorln(args ...interface{}) {
-	std.internalLogger.Errorln(args...)
+	std.entry.Errorln(args...)
 }
 
 // Panicln logs a message at level Panic on the standard logger.
 func (std *LogrusLogger) Panicln(args ...interface{}) {
-	std.internalLogger.Panicln(args...)
+	std.entry.Panicln(args...)
 }
 
 // Fatalln logs a message at level Fatal on the standard logger.
 func (std *LogrusLogger) Fatalln(args ...interface{}) {
-	std.internalLogger.Fatalln(args...)
+	std.entry.Fatalln(args...)
 }
@@ -0,0 +1,90 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogrusLogger_WithFields(t *testing.T) {
+	t.Run("it attaches the fields of the child logger only", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+
+		child := log.WithFields(Fields{"namespace": "default"}).With("release", "foo")
+		child.Info("child message")
+		log.Info("parent message")
+
+		lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+		assert.Len(t, lines, 2)
+		assert.Contains(t, string(lines[0]), `msg="child message" namespace=default release=foo`)
+		assert.NotContains(t, string(lines[1]), "namespace=")
+	})
+
+	t.Run("it shares the level with the parent logger", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+
+		child := log.With("command", "kubectl")
+		log.SetLevel(ErrorLevel)
+		child.Info("suppressed")
+
+		assert.Equal(t, ErrorLevel, child.GetLevel())
+		assert.Empty(t, out.String())
+	})
+}
+
+func TestNewLogrusLoggerWithEncoding(t *testing.T) {
+	t.Run("when encoding is json, it logs time, level, message and fields as json", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		log, err := NewLogrusLoggerWithEncoding(EncodingJSON)
+		require.NoError(t, err)
+		log.SetOutput(&out)
+
+		log.With("namespace", "default").Warnf("rollout of %s is slow", "foo")
+
+		var actual map[string]interface{}
+		err = json.Unmarshal(out.Bytes(), &actual)
+		require.NoError(t, err)
+
+		assert.Equal(t, "rollout of foo is slow", actual["msg"])
+		assert.Equal(t, "warning", actual["level"])
+		assert.Equal(t, "default", actual["namespace"])
+		assert.NotEmpty(t, actual["time"])
+	})
+
+	t.Run("when encoding is invalid, it returns an error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewLogrusLoggerWithEncoding("xml")
+		assert.Error(t, err)
+	})
+}
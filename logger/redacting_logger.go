@@ -0,0 +1,276 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var _ Logger = (*RedactingLogger)(nil)
+
+// RedactedPlaceholder replaces the redacted secrets.
+const RedactedPlaceholder = "[REDACTED]"
+
+// Redactor is a registry of secrets, e.g retrieved credentials, that must never leak into logs.
+// It's safe for concurrent use.
+type Redactor struct {
+	mu       sync.RWMutex
+	secrets  map[string]struct{}
+	replacer *strings.Replacer
+}
+
+func NewRedactor() *Redactor {
+	return &Redactor{
+		secrets: make(map[string]struct{}),
+	}
+}
+
+// Add registers the secrets. Blank secrets are ignored.
+func (r *Redactor) Add(secrets ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+
+		r.secrets[secret] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(r.secrets))
+	for secret := range r.secrets {
+		sorted = append(sorted, secret)
+	}
+
+	// NOTE: The replacer prefers the earlier of the secrets matching at the same position,
+	// so the longest go first, otherwise a secret would be partially redacted by its prefix.
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+
+		return sorted[i] < sorted[j]
+	})
+
+	oldnew := make([]string, 0, len(sorted)*2)
+	for _, secret := range sorted {
+		oldnew = append(oldnew, secret, RedactedPlaceholder)
+	}
+
+	r.replacer = strings.NewReplacer(oldnew...)
+}
+
+// Redact replaces the registered secrets in s with RedactedPlaceholder.
+func (r *Redactor) Redact(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.replacer == nil {
+		return s
+	}
+
+	return r.replacer.Replace(s)
+}
+
+func (r *Redactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.Redact(v)
+	case error:
+		redacted := r.Redact(v.Error())
+		if redacted == v.Error() {
+			return v
+		}
+
+		return &redactedError{cause: v, message: redacted}
+	default:
+		return value
+	}
+}
+
+// redactedError keeps the redacted error in the chain, so that it can still be matched with `errors.Is`.
+type redactedError struct {
+	cause   error
+	message string
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.cause
+}
+
+// RedactingLogger is a Logger decorator that redacts the secrets of a Redactor from the messages
+// and the string and error fields.
+type RedactingLogger struct {
+	Logger
+
+	redactor *Redactor
+}
+
+// NewRedactingLogger creates a RedactingLogger instance.
+// The child loggers created by With and WithFields share the redactor of their parent.
+func NewRedactingLogger(logger Logger, redactor *Redactor) *RedactingLogger {
+	return &RedactingLogger{
+		Logger:   logger,
+		redactor: redactor,
+	}
+}
+
+func (r *RedactingLogger) With(key string, value interface{}) Logger {
+	return &RedactingLogger{
+		Logger:   r.Logger.With(key, r.redactor.redactValue(value)),
+		redactor: r.redactor,
+	}
+}
+
+func (r *RedactingLogger) WithFields(fields Fields) Logger {
+	redacted := make(Fields, len(fields))
+	for key, value := range fields {
+		redacted[key] = r.redactor.redactValue(value)
+	}
+
+	return &RedactingLogger{
+		Logger:   r.Logger.WithFields(redacted),
+		redactor: r.redactor,
+	}
+}
+
+func (r *RedactingLogger) Debug(args ...interface{}) {
+	r.Logger.Debug(r.sprint(args))
+}
+
+func (r *RedactingLogger) Print(args ...interface{}) {
+	r.Logger.Print(r.sprint(args))
+}
+
+func (r *RedactingLogger) Info(args ...interface{}) {
+	r.Logger.Info(r.sprint(args))
+}
+
+func (r *RedactingLogger) Warn(args ...interface{}) {
+	r.Logger.Warn(r.sprint(args))
+}
+
+func (r *RedactingLogger) Warning(args ...interface{}) {
+	r.Logger.Warning(r.sprint(args))
+}
+
+func (r *RedactingLogger) Error(args ...interface{}) {
+	r.Logger.Error(r.sprint(args))
+}
+
+func (r *RedactingLogger) Panic(args ...interface{}) {
+	r.Logger.Panic(r.sprint(args))
+}
+
+func (r *RedactingLogger) Fatal(args ...interface{}) {
+	r.Logger.Fatal(r.sprint(args))
+}
+
+func (r *RedactingLogger) Debugf(format string, args ...interface{}) {
+	r.Logger.Debug(r.sprintf(format, args))
+}
+
+func (r *RedactingLogger) Printf(format string, args ...interface{}) {
+	r.Logger.Print(r.sprintf(format, args))
+}
+
+func (r *RedactingLogger) Infof(format string, args ...interface{}) {
+	r.Logger.Info(r.sprintf(format, args))
+}
+
+func (r *RedactingLogger) Warnf(format string, args ...interface{}) {
+	r.Logger.Warn(r.sprintf(format, args))
+}
+
+func (r *RedactingLogger) Warningf(format string, args ...interface{}) {
+	r.Logger.Warning(r.sprintf(format, args))
+}
+
+func (r *RedactingLogger) Errorf(format string, args ...interface{}) {
+	r.Logger.Error(r.sprintf(format, args))
+}
+
+func (r *RedactingLogger) Panicf(format string, args ...interface{}) {
+	r.Logger.Panic(r.sprintf(format, args))
+}
+
+func (r *RedactingLogger) Fatalf(format string, args ...interface{}) {
+	r.Logger.Fatal(r.sprintf(format, args))
+}
+
+func (r *RedactingLogger) Debugln(args ...interface{}) {
+	r.Logger.Debugln(r.sprintln(args))
+}
+
+func (r *RedactingLogger) Println(args ...interface{}) {
+	r.Logger.Println(r.sprintln(args))
+}
+
+func (r *RedactingLogger) Infoln(args ...interface{}) {
+	r.Logger.Infoln(r.sprintln(args))
+}
+
+func (r *RedactingLogger) Warnln(args ...interface{}) {
+	r.Logger.Warnln(r.sprintln(args))
+}
+
+func (r *RedactingLogger) Warningln(args ...interface{}) {
+	r.Logger.Warningln(r.sprintln(args))
+}
+
+func (r *RedactingLogger) Errorln(args ...interface{}) {
+	r.Logger.Errorln(r.sprintln(args))
+}
+
+func (r *RedactingLogger) Panicln(args ...interface{}) {
+	r.Logger.Panicln(r.sprintln(args))
+}
+
+func (r *RedactingLogger) Fatalln(args ...interface{}) {
+	r.Logger.Fatalln(r.sprintln(args))
+}
+
+func (r *RedactingLogger) Logf(level Level, format string, args ...interface{}) {
+	r.Logger.Log(level, r.sprintf(format, args))
+}
+
+func (r *RedactingLogger) Log(level Level, args ...interface{}) {
+	r.Logger.Log(level, r.sprint(args))
+}
+
+func (r *RedactingLogger) Logln(level Level, args ...interface{}) {
+	r.Logger.Logln(level, r.sprintln(args))
+}
+
+func (r *RedactingLogger) sprint(args []interface{}) string {
+	return r.redactor.Redact(fmt.Sprint(args...))
+}
+
+func (r *RedactingLogger) sprintf(format string, args []interface{}) string {
+	return r.redactor.Redact(fmt.Sprintf(format, args...))
+}
+
+// NOTE: The ln variants of the decorated logger add the trailing newline back.
+func (r *RedactingLogger) sprintln(args []interface{}) string {
+	return r.redactor.Redact(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
@@ -0,0 +1,117 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	stdErrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+)
+
+func TestRedactingLogger(t *testing.T) {
+	t.Run("it redacts the secrets from the messages", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+
+		redactor := NewRedactor()
+		redactor.Add("s3cr3t", "")
+
+		redacting := NewRedactingLogger(log, redactor)
+		redacting.Infof("logged in with token %s", "s3cr3t")
+		redacting.Warn("password=", "s3cr3t")
+		redacting.Errorln("failed with", "s3cr3t")
+
+		assert.NotContains(t, out.String(), "s3cr3t")
+		assert.Contains(t, out.String(), "logged in with token [REDACTED]")
+		assert.Contains(t, out.String(), "password=[REDACTED]")
+		assert.Contains(t, out.String(), "failed with [REDACTED]")
+	})
+
+	t.Run("it redacts the secrets added after the logger is created", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+
+		redactor := NewRedactor()
+		redacting := NewRedactingLogger(log, redactor).With("component", "vault")
+
+		redactor.Add("hvs.fake")
+		redacting.Info("token hvs.fake issued")
+
+		assert.Contains(t, out.String(), "token [REDACTED] issued")
+	})
+
+	t.Run("it redacts the string and error fields", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+
+		redactor := NewRedactor()
+		redactor.Add("s3cr3t")
+
+		fakeErr := stdErrors.New("invalid password s3cr3t")
+
+		redacting := NewRedactingLogger(log, redactor).
+			WithFields(Fields{"password": "s3cr3t", "error": fakeErr, "attempt": 1})
+		redacting.Error("login failed")
+
+		assert.NotContains(t, out.String(), "s3cr3t")
+		assert.Contains(t, out.String(), "attempt=1")
+	})
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	t.Run("when a secret is the prefix of another, it redacts the whole longer secret", func(t *testing.T) {
+		t.Parallel()
+
+		// NOTE: The secrets are stored in a map, so check it with many redactors to cover the iteration order.
+		for i := 0; i < 20; i++ {
+			redactor := NewRedactor()
+			redactor.Add("s3cr3t", "s3cr3t-suffix")
+
+			assert.Equal(t, "token=[REDACTED], other=[REDACTED]", redactor.Redact("token=s3cr3t-suffix, other=s3cr3t"))
+		}
+	})
+}
+
+func TestRedactor_redactValue(t *testing.T) {
+	t.Parallel()
+
+	redactor := NewRedactor()
+	redactor.Add("s3cr3t")
+
+	fakeErr := stdErrors.New("invalid password s3cr3t")
+
+	actual := redactor.redactValue(fakeErr)
+	assert.EqualError(t, actual.(error), "invalid password [REDACTED]")
+	assert.True(t, errors.Is(actual.(error), fakeErr))
+
+	otherErr := stdErrors.New("timeout")
+	assert.Equal(t, otherErr, redactor.redactValue(otherErr))
+}
@@ -0,0 +1,332 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	rotatingFileBackupTimeFormat = "2006-01-02T15-04-05.000"
+	rotatingFileCompressedExt    = ".gz"
+)
+
+var _ io.WriteCloser = (*RotatingFile)(nil)
+
+// RotatingFileConfiguration configures a RotatingFile.
+type RotatingFileConfiguration struct {
+	Path string
+	// MaxSize is the size in bytes after which the file is rotated. Zero disables the size rotation.
+	MaxSize int64
+	// MaxAge is the duration since the file is opened after which it is rotated.
+	// Zero disables the age rotation.
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files to retain. Zero retains all of them.
+	MaxBackups int
+	// Compress gzips the rotated files in the background.
+	Compress bool
+}
+
+// RotatingFile is a log file writer with size- and age-based rotation,
+// meant to be used as a sink of long-running processes.
+//
+// The rotated files are named after the file with the rotation time appended,
+// e.g `agent.log` is rotated to `agent-2019-11-23T10-00-00.000.log`.
+type RotatingFile struct {
+	config RotatingFileConfiguration
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+
+	// NOTE: The rotated files are compressed and the old backups removed in the background,
+	// one rotation at a time, without blocking the writes.
+	backgroundMu  sync.Mutex
+	backgroundWg  sync.WaitGroup
+	backgroundErr error
+}
+
+// NewRotatingFile creates RotatingFile instance and opens the file, creating its dir if necessary.
+func NewRotatingFile(config RotatingFileConfiguration) (*RotatingFile, error) {
+	if config.Path == "" {
+		return nil, stacktrace.NewError("rotating file path is blank")
+	}
+
+	rotatingFile := &RotatingFile{
+		config: config,
+		now:    time.Now,
+	}
+
+	err := rotatingFile.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return rotatingFile, nil
+}
+
+// Write writes to the file, rotating it first when the size or age limit is reached.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		err := r.open()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if r.shouldRotate(int64(len(p))) {
+		err := r.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	written, err := r.file.Write(p)
+	r.size += int64(written)
+
+	return written, stacktrace.Propagate(err, "writing to rotating file failed")
+}
+
+// Rotate rotates the file regardless of the limits, e.g on SIGHUP.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rotate()
+}
+
+// Close closes the file and waits for the background compression of the rotated files.
+// It returns the error of the last failed compression, if any. Subsequent writes reopen the file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err error
+
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+
+	r.backgroundWg.Wait()
+
+	if err != nil {
+		return stacktrace.Propagate(err, "closing rotating file failed")
+	}
+
+	r.backgroundMu.Lock()
+	defer r.backgroundMu.Unlock()
+
+	err = r.backgroundErr
+	r.backgroundErr = nil
+
+	return err
+}
+
+func (r *RotatingFile) shouldRotate(writeSize int64) bool {
+	if r.config.MaxSize > 0 && r.size > 0 && r.size+writeSize > r.config.MaxSize {
+		return true
+	}
+
+	return r.config.MaxAge > 0 && r.size > 0 && r.now().Sub(r.openedAt) >= r.config.MaxAge
+}
+
+func (r *RotatingFile) open() error {
+	err := os.MkdirAll(filepath.Dir(r.config.Path), 0755)
+	if err != nil {
+		return stacktrace.Propagate(err, "creating rotating file dir failed")
+	}
+
+	//nolint:gosec
+	file, err := os.OpenFile(r.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return stacktrace.Propagate(err, "opening rotating file failed")
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return stacktrace.Propagate(err, "stat of rotating file failed")
+	}
+
+	r.file = file
+	r.size = fileInfo.Size()
+	r.openedAt = r.now()
+
+	return nil
+}
+
+func (r *RotatingFile) rotate() error {
+	if r.file != nil {
+		err := r.file.Close()
+		r.file = nil
+
+		if err != nil {
+			return stacktrace.Propagate(err, "closing rotating file failed")
+		}
+	}
+
+	backupPath := r.backupPath(r.now())
+
+	err := os.Rename(r.config.Path, backupPath)
+	if err != nil && !os.IsNotExist(err) {
+		return stacktrace.Propagate(err, "renaming rotated file failed")
+	}
+
+	err = r.open()
+	if err != nil {
+		return err
+	}
+
+	if !r.config.Compress {
+		return r.removeOldBackups()
+	}
+
+	r.backgroundWg.Add(1)
+
+	go func() {
+		defer r.backgroundWg.Done()
+
+		r.backgroundMu.Lock()
+		defer r.backgroundMu.Unlock()
+
+		err := compressFile(backupPath)
+		if err == nil {
+			err = r.removeOldBackups()
+		}
+
+		if err != nil {
+			r.backgroundErr = err
+		}
+	}()
+
+	return nil
+}
+
+func (r *RotatingFile) backupPath(rotatedAt time.Time) string {
+	ext := filepath.Ext(r.config.Path)
+	prefix := strings.TrimSuffix(r.config.Path, ext)
+
+	return prefix + "-" + rotatedAt.Format(rotatingFileBackupTimeFormat) + ext
+}
+
+func (r *RotatingFile) removeOldBackups() error {
+	if r.config.MaxBackups < 1 {
+		return nil
+	}
+
+	dir := filepath.Dir(r.config.Path)
+
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return stacktrace.Propagate(err, "listing rotated files failed")
+	}
+
+	var backups []string
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.IsDir() && r.isBackup(fileInfo.Name()) {
+			backups = append(backups, fileInfo.Name())
+		}
+	}
+
+	if len(backups) <= r.config.MaxBackups {
+		return nil
+	}
+
+	// NOTE: The rotation time format sorts lexicographically.
+	sort.Strings(backups)
+
+	for _, name := range backups[:len(backups)-r.config.MaxBackups] {
+		err = os.Remove(filepath.Join(dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return stacktrace.Propagate(err, "removing rotated file %s failed", name)
+		}
+	}
+
+	return nil
+}
+
+// isBackup reports whether `name` is exactly a rotated file name, optionally compressed,
+// so that other files of the dir, e.g `agent-old.log`, are never removed.
+func (r *RotatingFile) isBackup(name string) bool {
+	ext := filepath.Ext(r.config.Path)
+	prefix := strings.TrimSuffix(filepath.Base(r.config.Path), ext) + "-"
+
+	name = strings.TrimSuffix(name, rotatingFileCompressedExt)
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+		return false
+	}
+
+	rotatedAt := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+
+	_, err := time.Parse(rotatingFileBackupTimeFormat, rotatedAt)
+
+	return err == nil
+}
+
+func compressFile(path string) error {
+	//nolint:gosec
+	src, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return stacktrace.Propagate(err, "opening rotated file failed")
+	}
+	defer src.Close()
+
+	//nolint:gosec
+	dst, err := os.OpenFile(path+rotatingFileCompressedExt, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return stacktrace.Propagate(err, "creating compressed rotated file failed")
+	}
+
+	gzipWriter := gzip.NewWriter(dst)
+
+	_, err = io.Copy(gzipWriter, src)
+	if err != nil {
+		_ = dst.Close()
+		return stacktrace.Propagate(err, "compressing rotated file failed")
+	}
+
+	err = gzipWriter.Close()
+	if err != nil {
+		_ = dst.Close()
+		return stacktrace.Propagate(err, "compressing rotated file failed")
+	}
+
+	err = dst.Close()
+	if err != nil {
+		return stacktrace.Propagate(err, "closing compressed rotated file failed")
+	}
+
+	return stacktrace.Propagate(os.Remove(path), "removing uncompressed rotated file failed")
+}
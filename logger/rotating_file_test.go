@@ -0,0 +1,174 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRotatingFile(
+	t *testing.T,
+	config RotatingFileConfiguration,
+) (*RotatingFile, *time.Time, string, func()) {
+	dir, err := ioutil.TempDir("", "rotating-file")
+	require.NoError(t, err)
+
+	config.Path = filepath.Join(dir, "logs", "agent.log")
+
+	rotatingFile, err := NewRotatingFile(config)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+	}
+
+	require.NoError(t, err)
+
+	now := time.Date(2019, 11, 23, 10, 0, 0, 0, time.UTC)
+	rotatingFile.now = func() time.Time { return now }
+	rotatingFile.openedAt = now
+
+	cleanup := func() {
+		_ = rotatingFile.Close()
+		_ = os.RemoveAll(dir)
+	}
+
+	return rotatingFile, &now, filepath.Dir(config.Path), cleanup
+}
+
+func TestRotatingFile_Write(t *testing.T) {
+	t.Run("when max size is exceeded, it rotates the file", func(t *testing.T) {
+		t.Parallel()
+
+		rotatingFile, now, dir, cleanup := newTestRotatingFile(t, RotatingFileConfiguration{MaxSize: 10})
+		defer cleanup()
+
+		_, err := rotatingFile.Write([]byte("12345678\n"))
+		require.NoError(t, err)
+
+		*now = now.Add(time.Second)
+		_, err = rotatingFile.Write([]byte("abc\n"))
+		require.NoError(t, err)
+
+		current, err := ioutil.ReadFile(filepath.Join(dir, "agent.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "abc\n", string(current))
+
+		rotated, err := ioutil.ReadFile(filepath.Join(dir, "agent-2019-11-23T10-00-01.000.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "12345678\n", string(rotated))
+	})
+
+	t.Run("when max age is exceeded, it rotates the file", func(t *testing.T) {
+		t.Parallel()
+
+		rotatingFile, now, dir, cleanup := newTestRotatingFile(t, RotatingFileConfiguration{MaxAge: time.Hour})
+		defer cleanup()
+
+		_, err := rotatingFile.Write([]byte("first\n"))
+		require.NoError(t, err)
+
+		*now = now.Add(time.Hour)
+		_, err = rotatingFile.Write([]byte("second\n"))
+		require.NoError(t, err)
+
+		rotated, err := ioutil.ReadFile(filepath.Join(dir, "agent-2019-11-23T11-00-00.000.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "first\n", string(rotated))
+	})
+
+	t.Run("it compresses the rotated files and retains max backups", func(t *testing.T) {
+		t.Parallel()
+
+		rotatingFile, now, dir, cleanup := newTestRotatingFile(
+			t,
+			RotatingFileConfiguration{MaxBackups: 2, Compress: true},
+		)
+		defer cleanup()
+
+		for i := 0; i < 4; i++ {
+			_, err := rotatingFile.Write([]byte("entry\n"))
+			require.NoError(t, err)
+
+			*now = now.Add(time.Minute)
+			require.NoError(t, rotatingFile.Rotate())
+		}
+
+		// NOTE: Close waits for the background compression.
+		require.NoError(t, rotatingFile.Close())
+
+		fileInfos, err := ioutil.ReadDir(dir)
+		require.NoError(t, err)
+
+		var names []string
+		for _, fileInfo := range fileInfos {
+			names = append(names, fileInfo.Name())
+		}
+
+		assert.Equal(
+			t,
+			[]string{
+				"agent-2019-11-23T10-03-00.000.log.gz",
+				"agent-2019-11-23T10-04-00.000.log.gz",
+				"agent.log",
+			},
+			names,
+		)
+
+		compressed, err := os.Open(filepath.Join(dir, "agent-2019-11-23T10-04-00.000.log.gz"))
+		require.NoError(t, err)
+		defer compressed.Close()
+
+		gzipReader, err := gzip.NewReader(compressed)
+		require.NoError(t, err)
+
+		content, err := ioutil.ReadAll(gzipReader)
+		require.NoError(t, err)
+		assert.Equal(t, "entry\n", string(content))
+	})
+
+	t.Run("when retaining max backups, it does not remove other files of the dir", func(t *testing.T) {
+		t.Parallel()
+
+		rotatingFile, now, dir, cleanup := newTestRotatingFile(t, RotatingFileConfiguration{MaxBackups: 1})
+		defer cleanup()
+
+		others := []string{"agent.log.bak", "agent-old.log", "agent-2019-11-23.log", "agentx-2019-11-23T09-00-00.000.log"}
+		for _, name := range others {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte("other\n"), 0644))
+		}
+
+		for i := 0; i < 3; i++ {
+			_, err := rotatingFile.Write([]byte("entry\n"))
+			require.NoError(t, err)
+
+			*now = now.Add(time.Minute)
+			require.NoError(t, rotatingFile.Rotate())
+		}
+
+		for _, name := range append(others, "agent-2019-11-23T10-03-00.000.log", "agent.log") {
+			assert.FileExists(t, filepath.Join(dir, name))
+		}
+
+		_, err := os.Stat(filepath.Join(dir, "agent-2019-11-23T10-02-00.000.log"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
@@ -0,0 +1,340 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+var _ Logger = (*SampledLogger)(nil)
+
+// SamplingConfiguration limits the amount of logged entries, e.g in tight retry loops.
+//
+// Sampling logs the `First` entries with the same level and message (the format for the formatted
+// log calls) per `Tick` and every `Thereafter`-th entry after that. Zero `Thereafter` drops the entries
+// after the `First` ones. `Levels` overrides `First` and `Thereafter` per level.
+// Zero `Tick` disables the sampling.
+// Rate limiting logs at most `RateLimit` entries per second with bursts of `Burst` entries.
+// Zero `RateLimit` disables the rate limiting.
+// Panic and fatal entries are never dropped.
+type SamplingConfiguration struct {
+	Tick       time.Duration
+	First      int
+	Thereafter int
+	Levels     map[Level]LevelSamplingConfiguration
+	RateLimit  float64
+	Burst      int
+}
+
+// LevelSamplingConfiguration is the sampling of the entries of a level, e.g to sample debug entries
+// more aggressively than the error ones.
+type LevelSamplingConfiguration struct {
+	First      int
+	Thereafter int
+}
+
+func (config *SamplingConfiguration) levelSampling(level Level) LevelSamplingConfiguration {
+	levelConfig, ok := config.Levels[level]
+	if ok {
+		return levelConfig
+	}
+
+	return LevelSamplingConfiguration{
+		First:      config.First,
+		Thereafter: config.Thereafter,
+	}
+}
+
+// SampledLogger is a Logger decorator that drops entries per SamplingConfiguration.
+type SampledLogger struct {
+	Logger
+
+	sampler *sampler
+}
+
+// NewSampledLogger creates a SampledLogger instance.
+// The child loggers created by With and WithFields share the sampling of their parent.
+func NewSampledLogger(logger Logger, config SamplingConfiguration) *SampledLogger {
+	return &SampledLogger{
+		Logger:  logger,
+		sampler: newSampler(config),
+	}
+}
+
+func (s *SampledLogger) With(key string, value interface{}) Logger {
+	return &SampledLogger{
+		Logger:  s.Logger.With(key, value),
+		sampler: s.sampler,
+	}
+}
+
+func (s *SampledLogger) WithFields(fields Fields) Logger {
+	return &SampledLogger{
+		Logger:  s.Logger.WithFields(fields),
+		sampler: s.sampler,
+	}
+}
+
+func (s *SampledLogger) Debug(args ...interface{}) {
+	if s.sampler.allow(s.Logger, DebugLevel, fmt.Sprint(args...)) {
+		s.Logger.Debug(args...)
+	}
+}
+
+func (s *SampledLogger) Print(args ...interface{}) {
+	if s.sampler.allow(s.Logger, InfoLevel, fmt.Sprint(args...)) {
+		s.Logger.Print(args...)
+	}
+}
+
+func (s *SampledLogger) Info(args ...interface{}) {
+	if s.sampler.allow(s.Logger, InfoLevel, fmt.Sprint(args...)) {
+		s.Logger.Info(args...)
+	}
+}
+
+func (s *SampledLogger) Warn(args ...interface{}) {
+	if s.sampler.allow(s.Logger, WarnLevel, fmt.Sprint(args...)) {
+		s.Logger.Warn(args...)
+	}
+}
+
+func (s *SampledLogger) Warning(args ...interface{}) {
+	if s.sampler.allow(s.Logger, WarnLevel, fmt.Sprint(args...)) {
+		s.Logger.Warning(args...)
+	}
+}
+
+func (s *SampledLogger) Error(args ...interface{}) {
+	if s.sampler.allow(s.Logger, ErrorLevel, fmt.Sprint(args...)) {
+		s.Logger.Error(args...)
+	}
+}
+
+func (s *SampledLogger) Panic(args ...interface{}) {
+	if s.sampler.allow(s.Logger, PanicLevel, fmt.Sprint(args...)) {
+		s.Logger.Panic(args...)
+	}
+}
+
+func (s *SampledLogger) Fatal(args ...interface{}) {
+	if s.sampler.allow(s.Logger, FatalLevel, fmt.Sprint(args...)) {
+		s.Logger.Fatal(args...)
+	}
+}
+
+func (s *SampledLogger) Debugf(format string, args ...interface{}) {
+	if s.sampler.allow(s.Logger, DebugLevel, format) {
+		s.Logger.Debugf(format, args...)
+	}
+}
+
+func (s *SampledLogger) Printf(format string, args ...interface{}) {
+	if s.sampler.allow(s.Logger, InfoLevel, format) {
+		s.Logger.Printf(format, args...)
+	}
+}
+
+func (s *SampledLogger) Infof(format string, args ...interface{}) {
+	if s.sampler.allow(s.Logger, InfoLevel, format) {
+		s.Logger.Infof(format, args...)
+	}
+}
+
+func (s *SampledLogger) Warnf(format string, args ...interface{}) {
+	if s.sampler.allow(s.Logger, WarnLevel, format) {
+		s.Logger.Warnf(format, args...)
+	}
+}
+
+func (s *SampledLogger) Warningf(format string, args ...interface{}) {
+	if s.sampler.allow(s.Logger, WarnLevel, format) {
+		s.Logger.Warningf(format, args...)
+	}
+}
+
+func (s *SampledLogger) Errorf(format string, args ...interface{}) {
+	if s.sampler.allow(s.Logger, ErrorLevel, format) {
+		s.Logger.Errorf(format, args...)
+	}
+}
+
+func (s *SampledLogger) Panicf(format string, args ...interface{}) {
+	if s.sampler.allow(s.Logger, PanicLevel, format) {
+		s.Logger.Panicf(format, args...)
+	}
+}
+
+func (s *SampledLogger) Fatalf(format string, args ...interface{}) {
+	if s.sampler.allow(s.Logger, FatalLevel, format) {
+		s.Logger.Fatalf(format, args...)
+	}
+}
+
+func (s *SampledLogger) Debugln(args ...interface{}) {
+	if s.sampler.allow(s.Logger, DebugLevel, fmt.Sprint(args...)) {
+		s.Logger.Debugln(args...)
+	}
+}
+
+func (s *SampledLogger) Println(args ...interface{}) {
+	if s.sampler.allow(s.Logger, InfoLevel, fmt.Sprint(args...)) {
+		s.Logger.Println(args...)
+	}
+}
+
+func (s *SampledLogger) Infoln(args ...interface{}) {
+	if s.sampler.allow(s.Logger, InfoLevel, fmt.Sprint(args...)) {
+		s.Logger.Infoln(args...)
+	}
+}
+
+func (s *SampledLogger) Warnln(args ...interface{}) {
+	if s.sampler.allow(s.Logger, WarnLevel, fmt.Sprint(args...)) {
+		s.Logger.Warnln(args...)
+	}
+}
+
+func (s *SampledLogger) Warningln(args ...interface{}) {
+	if s.sampler.allow(s.Logger, WarnLevel, fmt.Sprint(args...)) {
+		s.Logger.Warningln(args...)
+	}
+}
+
+func (s *SampledLogger) Errorln(args ...interface{}) {
+	if s.sampler.allow(s.Logger, ErrorLevel, fmt.Sprint(args...)) {
+		s.Logger.Errorln(args...)
+	}
+}
+
+func (s *SampledLogger) Panicln(args ...interface{}) {
+	if s.sampler.allow(s.Logger, PanicLevel, fmt.Sprint(args...)) {
+		s.Logger.Panicln(args...)
+	}
+}
+
+func (s *SampledLogger) Fatalln(args ...interface{}) {
+	if s.sampler.allow(s.Logger, FatalLevel, fmt.Sprint(args...)) {
+		s.Logger.Fatalln(args...)
+	}
+}
+
+func (s *SampledLogger) Logf(level Level, format string, args ...interface{}) {
+	if s.sampler.allow(s.Logger, level, format) {
+		s.Logger.Logf(level, format, args...)
+	}
+}
+
+func (s *SampledLogger) Log(level Level, args ...interface{}) {
+	if s.sampler.allow(s.Logger, level, fmt.Sprint(args...)) {
+		s.Logger.Log(level, args...)
+	}
+}
+
+func (s *SampledLogger) Logln(level Level, args ...interface{}) {
+	if s.sampler.allow(s.Logger, level, fmt.Sprint(args...)) {
+		s.Logger.Logln(level, args...)
+	}
+}
+
+type samplingKey struct {
+	level   Level
+	message string
+}
+
+type sampler struct {
+	config  SamplingConfiguration
+	limiter *rate.Limiter
+
+	mu        sync.Mutex
+	tickStart time.Time
+	counts    map[samplingKey]int
+	now       func() time.Time
+}
+
+func newSampler(config SamplingConfiguration) *sampler {
+	var limiter *rate.Limiter
+	if config.RateLimit > 0 {
+		burst := config.Burst
+		if burst < 1 {
+			burst = 1
+		}
+
+		limiter = rate.NewLimiter(rate.Limit(config.RateLimit), burst)
+	}
+
+	return &sampler{
+		config:  config,
+		limiter: limiter,
+		counts:  make(map[samplingKey]int),
+		now:     time.Now,
+	}
+}
+
+func (s *sampler) allow(logger Logger, level Level, message string) bool {
+	if level <= FatalLevel {
+		return true
+	}
+
+	// NOTE: Don't count the entries that are not logged anyway.
+	if logger.GetLevel() < level {
+		return false
+	}
+
+	if !s.sample(level, message) {
+		return false
+	}
+
+	if s.limiter != nil {
+		return s.limiter.Allow()
+	}
+
+	return true
+}
+
+func (s *sampler) sample(level Level, message string) bool {
+	if s.config.Tick <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.tickStart) >= s.config.Tick {
+		s.tickStart = now
+		s.counts = make(map[samplingKey]int)
+	}
+
+	key := samplingKey{level: level, message: message}
+	s.counts[key]++
+	count := s.counts[key]
+
+	levelConfig := s.config.levelSampling(level)
+
+	if count <= levelConfig.First {
+		return true
+	}
+
+	if levelConfig.Thereafter < 1 {
+		return false
+	}
+
+	return (count-levelConfig.First)%levelConfig.Thereafter == 0
+}
@@ -0,0 +1,190 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampledLogger(t *testing.T) {
+	t.Run("it logs the first entries and every thereafter-th entry per message", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+
+		sampled := NewSampledLogger(log, SamplingConfiguration{Tick: time.Hour, First: 2, Thereafter: 3})
+
+		for i := 0; i < 8; i++ {
+			sampled.Warnf("retrying kubectl, attempt %d", i)
+		}
+
+		sampled.Warn("another message")
+
+		assert.Equal(t, 4, strings.Count(out.String(), "retrying kubectl"))
+		assert.Contains(t, out.String(), "attempt 0")
+		assert.Contains(t, out.String(), "attempt 1")
+		assert.Contains(t, out.String(), "attempt 4")
+		assert.Contains(t, out.String(), "attempt 7")
+		assert.Contains(t, out.String(), "another message")
+	})
+
+	t.Run("when the tick elapses, it resets the counts", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+
+		now := time.Now()
+		sampled := NewSampledLogger(log, SamplingConfiguration{Tick: time.Second, First: 1})
+		sampled.sampler.now = func() time.Time { return now }
+
+		sampled.Info("tick")
+		sampled.Info("tick")
+
+		now = now.Add(time.Second)
+		sampled.With("namespace", "default").Info("tick")
+
+		assert.Equal(t, 2, strings.Count(out.String(), "msg=tick"))
+	})
+
+	t.Run("when rate limit is exceeded, it drops the entries", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+
+		sampled := NewSampledLogger(log, SamplingConfiguration{RateLimit: 0.001, Burst: 2})
+
+		for i := 0; i < 5; i++ {
+			sampled.Errorf("failed %d", i)
+		}
+
+		assert.Equal(t, 2, strings.Count(out.String(), "msg=\"failed"))
+	})
+
+	t.Run("it does not count entries below the logger level", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+		log.SetLevel(InfoLevel)
+
+		sampled := NewSampledLogger(log, SamplingConfiguration{Tick: time.Hour, First: 1})
+
+		sampled.Debug("verbose")
+		log.SetLevel(DebugLevel)
+		sampled.Debug("verbose")
+
+		assert.Equal(t, 1, strings.Count(out.String(), "msg=verbose"))
+	})
+
+	t.Run("it samples the levels with their own configuration", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		log := NewLogrusLogger()
+		log.SetOutput(&out)
+		log.SetLevel(DebugLevel)
+
+		sampled := NewSampledLogger(log, SamplingConfiguration{
+			Tick:   time.Hour,
+			First:  3,
+			Levels: map[Level]LevelSamplingConfiguration{DebugLevel: {First: 1}},
+		})
+
+		for i := 0; i < 4; i++ {
+			sampled.Debug("polling")
+			sampled.Error("failed")
+		}
+
+		assert.Equal(t, 1, strings.Count(out.String(), "msg=polling"))
+		assert.Equal(t, 3, strings.Count(out.String(), "msg=failed"))
+	})
+}
+
+func TestNewZapLogger_Sampling(t *testing.T) {
+	newLogger := func(t *testing.T, config *SamplingConfiguration) (*ZapLogger, *bytes.Buffer) {
+		var out bytes.Buffer
+
+		log, err := NewZapLogger(Configuration{
+			Level:    LogLevelDebug,
+			Encoding: EncodingJSON,
+			Sinks:    []SinkConfiguration{{Writer: &out, Encoding: EncodingJSON}},
+			Sampling: config,
+		})
+		require.NoError(t, err)
+
+		return log, &out
+	}
+
+	t.Run("when configuration is zero value, it does not sample", func(t *testing.T) {
+		t.Parallel()
+
+		log, out := newLogger(t, &SamplingConfiguration{})
+
+		for i := 0; i < 3; i++ {
+			log.Info("polling")
+		}
+
+		assert.Equal(t, 3, strings.Count(out.String(), `"msg":"polling"`))
+	})
+
+	t.Run("when thereafter is zero, it logs only the first entries", func(t *testing.T) {
+		t.Parallel()
+
+		log, out := newLogger(t, &SamplingConfiguration{Tick: time.Hour, First: 2})
+
+		for i := 0; i < 5; i++ {
+			log.Info("polling")
+		}
+
+		assert.Equal(t, 2, strings.Count(out.String(), `"msg":"polling"`))
+	})
+
+	t.Run("it samples the levels with their own configuration", func(t *testing.T) {
+		t.Parallel()
+
+		log, out := newLogger(t, &SamplingConfiguration{
+			Tick:       time.Hour,
+			First:      3,
+			Thereafter: 1,
+			Levels:     map[Level]LevelSamplingConfiguration{DebugLevel: {First: 1, Thereafter: 2}},
+		})
+
+		for i := 0; i < 5; i++ {
+			log.Debug("polling")
+			log.Error("failed")
+		}
+
+		assert.Equal(t, 3, strings.Count(out.String(), `"msg":"polling"`))
+		assert.Equal(t, 5, strings.Count(out.String(), `"msg":"failed"`))
+	})
+}
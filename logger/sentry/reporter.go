@@ -0,0 +1,432 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sentry is a reference logger.ErrorReporter implementation that sends
+// the logged errors to Sentry through its HTTP envelope API, without depending on the Sentry SDK.
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"github.com/sumup-oss/go-pkgs/shutdown"
+)
+
+const (
+	clientName       = "go-pkgs/sentry"
+	protocolVer      = "7"
+	defaultTimeout   = 5 * time.Second
+	defaultQueueSize = 100
+)
+
+var (
+	_ logger.ErrorReporter = (*Reporter)(nil)
+	_ shutdown.Hook        = (*Reporter)(nil).Shutdown
+)
+
+var levels = map[logger.Level]string{
+	logger.PanicLevel: "fatal",
+	logger.FatalLevel: "fatal",
+	logger.ErrorLevel: "error",
+	logger.WarnLevel:  "warning",
+	logger.InfoLevel:  "info",
+	logger.DebugLevel: "debug",
+}
+
+// Configuration configures a Reporter.
+type Configuration struct {
+	// DSN is the Sentry project DSN, e.g `https://public@sentry.example.com/1`.
+	DSN         string
+	Environment string
+	Release     string
+	ServerName  string
+	// Logger is the name of the reporting logger, shown in Sentry.
+	Logger string
+	// Timeout of a single report. Defaults to 5 seconds.
+	Timeout time.Duration
+	// HTTPClient defaults to `http.Client` with `Timeout`.
+	HTTPClient *http.Client
+	// QueueSize is the number of reports queued for sending, above which the reports are dropped.
+	// Defaults to 100.
+	QueueSize int
+	// OnError is called with the errors of the reports that failed to be sent, e.g to count them.
+	// It must not log errors, since they would be reported again.
+	OnError func(err error)
+}
+
+// Reporter sends error reports to Sentry.
+//
+// The reports are queued and sent by a background worker, so that the logging is never blocked
+// by Sentry, and the queue must be flushed on shutdown with Shutdown.
+type Reporter struct {
+	config      Configuration
+	envelopeURL string
+	authHeader  string
+	httpClient  *http.Client
+
+	queue        chan *envelope
+	done         chan struct{}
+	shutdownOnce sync.Once
+	// NOTE: Guards sending to queue against closing it on shutdown.
+	mu     sync.RWMutex
+	closed bool
+}
+
+type envelope struct {
+	eventID string
+	body    []byte
+}
+
+// NewReporter creates Reporter instance and starts its background worker.
+func NewReporter(config Configuration) (*Reporter, error) {
+	envelopeURL, authHeader, err := parseDSN(config.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	if config.QueueSize < 1 {
+		config.QueueSize = defaultQueueSize
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: config.Timeout}
+	}
+
+	reporter := &Reporter{
+		config:      config,
+		envelopeURL: envelopeURL,
+		authHeader:  authHeader,
+		httpClient:  httpClient,
+		queue:       make(chan *envelope, config.QueueSize),
+		done:        make(chan struct{}),
+	}
+
+	go reporter.work()
+
+	return reporter, nil
+}
+
+// NewHook creates a logger.ErrorReportingHook reporting to Sentry.
+func NewHook(config Configuration, levels ...logger.Level) (*logger.ErrorReportingHook, error) {
+	reporter, err := NewReporter(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return logger.NewErrorReportingHook(reporter, levels...), nil
+}
+
+// Report queues the report to be sent as a Sentry event.
+// It returns an error, without blocking, when the queue is full or the reporter is shut down.
+func (r *Reporter) Report(report *logger.ErrorReport) error {
+	evt := r.newEvent(report)
+
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return stacktrace.Propagate(err, "failed to encode sentry event")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		return stacktrace.NewError("sentry reporter is shut down, dropping event %s", evt.EventID)
+	}
+
+	select {
+	case r.queue <- &envelope{eventID: evt.EventID, body: body}:
+		return nil
+	default:
+		return stacktrace.NewError("sentry queue is full, dropping event %s", evt.EventID)
+	}
+}
+
+// Shutdown stops accepting reports and waits until the queued ones are sent or ctx is done.
+func (r *Reporter) Shutdown(ctx context.Context) error {
+	r.shutdownOnce.Do(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.closed = true
+		close(r.queue)
+	})
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return stacktrace.Propagate(ctx.Err(), "flushing sentry queue failed, %d events dropped", len(r.queue))
+	}
+}
+
+func (r *Reporter) work() {
+	defer close(r.done)
+
+	for env := range r.queue {
+		err := r.send(env)
+		if err != nil && r.config.OnError != nil {
+			r.config.OnError(err)
+		}
+	}
+}
+
+// send posts the event as an envelope with a single item.
+func (r *Reporter) send(env *envelope) error {
+	header, err := json.Marshal(map[string]string{
+		"event_id": env.eventID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return stacktrace.Propagate(err, "failed to encode sentry envelope header")
+	}
+
+	itemHeader, err := json.Marshal(map[string]interface{}{
+		"type":   "event",
+		"length": len(env.body),
+	})
+	if err != nil {
+		return stacktrace.Propagate(err, "failed to encode sentry envelope item header")
+	}
+
+	var body bytes.Buffer
+
+	body.Write(header)
+	body.WriteByte('\n')
+	body.Write(itemHeader)
+	body.WriteByte('\n')
+	body.Write(env.body)
+	body.WriteByte('\n')
+
+	req, err := http.NewRequest(http.MethodPost, r.envelopeURL, &body)
+	if err != nil {
+		return stacktrace.Propagate(err, "failed to create sentry request")
+	}
+
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("User-Agent", clientName)
+	req.Header.Set("X-Sentry-Auth", r.authHeader)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return stacktrace.Propagate(err, "failed to send sentry event %s", env.eventID)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return stacktrace.NewError("sentry responded to event %s with status code %d", env.eventID, resp.StatusCode)
+	}
+
+	return nil
+}
+
+type event struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Logger      string                 `json:"logger,omitempty"`
+	Platform    string                 `json:"platform"`
+	Message     string                 `json:"message,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	Exception   *valuesOf              `json:"exception,omitempty"`
+	Threads     *valuesOf              `json:"threads,omitempty"`
+}
+
+type valuesOf struct {
+	Values []interface{} `json:"values"`
+}
+
+type exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *stackTrace `json:"stacktrace,omitempty"`
+}
+
+type thread struct {
+	Current    bool        `json:"current"`
+	Stacktrace *stackTrace `json:"stacktrace,omitempty"`
+}
+
+type stackTrace struct {
+	Frames []frame `json:"frames"`
+}
+
+type frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+}
+
+func (r *Reporter) newEvent(report *logger.ErrorReport) *event {
+	evt := &event{
+		EventID:     newEventID(),
+		Timestamp:   report.Time.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		Level:       levels[report.Level],
+		Logger:      r.config.Logger,
+		Platform:    "go",
+		Message:     report.Message,
+		Environment: r.config.Environment,
+		Release:     r.config.Release,
+		ServerName:  r.config.ServerName,
+		Extra:       make(map[string]interface{}, len(report.Fields)),
+	}
+
+	for key, value := range report.Fields {
+		evt.Extra[key] = extraValue(value)
+	}
+
+	trace := newStackTrace(report.Stacktrace)
+
+	if report.Error != nil {
+		evt.Exception = &valuesOf{
+			Values: []interface{}{
+				&exception{
+					Type:       fmt.Sprintf("%T", report.Error),
+					Value:      report.Error.Error(),
+					Stacktrace: trace,
+				},
+			},
+		}
+
+		return evt
+	}
+
+	evt.Threads = &valuesOf{
+		Values: []interface{}{
+			&thread{
+				Current:    true,
+				Stacktrace: trace,
+			},
+		},
+	}
+
+	return evt
+}
+
+func newStackTrace(frames []logger.StackFrame) *stackTrace {
+	if len(frames) == 0 {
+		return nil
+	}
+
+	trace := &stackTrace{Frames: make([]frame, len(frames))}
+
+	// NOTE: Sentry expects the frames from the outermost call to the innermost one.
+	for idx, stackFrame := range frames {
+		module, function := splitFunction(stackFrame.Function)
+
+		trace.Frames[len(frames)-1-idx] = frame{
+			Function: function,
+			Module:   module,
+			AbsPath:  stackFrame.File,
+			Lineno:   stackFrame.Line,
+		}
+	}
+
+	return trace
+}
+
+// splitFunction splits `github.com/org/repo/pkg.(*Type).Method` to its package and function.
+func splitFunction(name string) (string, string) {
+	pkgStart := strings.LastIndex(name, "/") + 1
+
+	dotIdx := strings.Index(name[pkgStart:], ".")
+	if dotIdx < 0 {
+		return "", name
+	}
+
+	return name[:pkgStart+dotIdx], name[pkgStart+dotIdx+1:]
+}
+
+func extraValue(value interface{}) interface{} {
+	if err, ok := value.(error); ok {
+		return err.Error()
+	}
+
+	_, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%+v", value)
+	}
+
+	return value
+}
+
+func parseDSN(dsn string) (string, string, error) {
+	parsedURL, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", stacktrace.Propagate(err, "invalid sentry DSN")
+	}
+
+	if parsedURL.User == nil || parsedURL.User.Username() == "" {
+		return "", "", stacktrace.NewError("sentry DSN has no public key")
+	}
+
+	pathIdx := strings.LastIndex(parsedURL.Path, "/")
+	projectID := parsedURL.Path[pathIdx+1:]
+
+	if pathIdx < 0 || projectID == "" {
+		return "", "", stacktrace.NewError("sentry DSN has no project ID")
+	}
+
+	envelopeURL := fmt.Sprintf(
+		"%s://%s%s/api/%s/envelope/",
+		parsedURL.Scheme,
+		parsedURL.Host,
+		parsedURL.Path[:pathIdx],
+		projectID,
+	)
+
+	authHeader := fmt.Sprintf(
+		"Sentry sentry_version=%s, sentry_client=%s, sentry_key=%s",
+		protocolVer,
+		clientName,
+		parsedURL.User.Username(),
+	)
+
+	if secret, ok := parsedURL.User.Password(); ok {
+		authHeader += ", sentry_secret=" + secret
+	}
+
+	return envelopeURL, authHeader, nil
+}
+
+func newEventID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,203 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sentry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+)
+
+func TestNewReporter(t *testing.T) {
+	t.Run("it returns error for DSN without public key", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewReporter(Configuration{DSN: "https://sentry.example.com/1"})
+		assert.Error(t, err)
+	})
+
+	t.Run("it returns error for DSN without project ID", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewReporter(Configuration{DSN: "https://public@sentry.example.com"})
+		assert.Error(t, err)
+	})
+}
+
+func TestReporter_Report(t *testing.T) {
+	t.Run("it sends the report as sentry event", func(t *testing.T) {
+		t.Parallel()
+
+		var path, auth, contentType string
+		var header, itemHeader, evt map[string]interface{}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			auth = r.Header.Get("X-Sentry-Auth")
+			contentType = r.Header.Get("Content-Type")
+
+			decoder := json.NewDecoder(r.Body)
+			_ = decoder.Decode(&header)
+			_ = decoder.Decode(&itemHeader)
+			_ = decoder.Decode(&evt)
+		}))
+		defer server.Close()
+
+		dsn := strings.Replace(server.URL, "http://", "http://public:secret@", 1) + "/sentry/42"
+
+		reporter, err := NewReporter(Configuration{DSN: dsn, Environment: "staging", Release: "v1.0.0"})
+		require.NoError(t, err)
+
+		err = reporter.Report(&logger.ErrorReport{
+			Time:    time.Date(2019, 11, 23, 10, 0, 0, 0, time.UTC),
+			Level:   logger.ErrorLevel,
+			Message: "deploy failed",
+			Error:   errors.New("connection refused"),
+			Fields:  map[string]interface{}{"namespace": "default"},
+			Stacktrace: []logger.StackFrame{
+				{Function: "github.com/sumup-oss/go-pkgs/executor.(*Helm).Upgrade", File: "/src/helm.go", Line: 10},
+				{Function: "main.main", File: "/src/main.go", Line: 5},
+			},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, reporter.Shutdown(context.Background()))
+
+		assert.Equal(t, "/sentry/api/42/envelope/", path)
+		assert.Equal(t, "application/x-sentry-envelope", contentType)
+		assert.Equal(t, evt["event_id"], header["event_id"])
+		assert.Equal(t, "event", itemHeader["type"])
+		assert.Contains(t, auth, "sentry_key=public")
+		assert.Contains(t, auth, "sentry_secret=secret")
+		assert.Equal(t, "error", evt["level"])
+		assert.Equal(t, "deploy failed", evt["message"])
+		assert.Equal(t, "staging", evt["environment"])
+		assert.Equal(t, "v1.0.0", evt["release"])
+		assert.Equal(t, "2019-11-23T10:00:00.000000Z", evt["timestamp"])
+		assert.Equal(t, map[string]interface{}{"namespace": "default"}, evt["extra"])
+
+		exception := evt["exception"].(map[string]interface{})["values"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "*errors.errorString", exception["type"])
+		assert.Equal(t, "connection refused", exception["value"])
+
+		frames := exception["stacktrace"].(map[string]interface{})["frames"].([]interface{})
+		require.Len(t, frames, 2)
+		assert.Equal(t, "main", frames[0].(map[string]interface{})["function"])
+		assert.Equal(t, "(*Helm).Upgrade", frames[1].(map[string]interface{})["function"])
+		assert.Equal(t, "github.com/sumup-oss/go-pkgs/executor", frames[1].(map[string]interface{})["module"])
+	})
+
+	t.Run("when sentry rejects the event, it calls OnError", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		dsn := strings.Replace(server.URL, "http://", "http://public@", 1) + "/1"
+
+		var sendErr error
+
+		reporter, err := NewReporter(Configuration{
+			DSN:     dsn,
+			OnError: func(err error) { sendErr = err },
+		})
+		require.NoError(t, err)
+
+		err = reporter.Report(&logger.ErrorReport{Level: logger.ErrorLevel, Message: "deploy failed"})
+		require.NoError(t, err)
+
+		require.NoError(t, reporter.Shutdown(context.Background()))
+		assert.Contains(t, sendErr.Error(), "status code 429")
+	})
+
+	t.Run("when queue is full, it drops the report without blocking", func(t *testing.T) {
+		t.Parallel()
+
+		unblock := make(chan struct{})
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-unblock
+		}))
+		defer server.Close()
+
+		dsn := strings.Replace(server.URL, "http://", "http://public@", 1) + "/1"
+
+		reporter, err := NewReporter(Configuration{DSN: dsn, QueueSize: 1})
+		require.NoError(t, err)
+
+		var errs []error
+		for i := 0; i < 3; i++ {
+			errs = append(errs, reporter.Report(&logger.ErrorReport{Level: logger.ErrorLevel, Message: "deploy failed"}))
+		}
+
+		close(unblock)
+
+		// NOTE: The worker may have taken the first report off the queue already.
+		assert.NoError(t, errs[0])
+		assert.Error(t, errs[2])
+		assert.Contains(t, errs[2].Error(), "sentry queue is full")
+
+		require.NoError(t, reporter.Shutdown(context.Background()))
+	})
+
+	t.Run("when shut down, it rejects the reports", func(t *testing.T) {
+		t.Parallel()
+
+		reporter, err := NewReporter(Configuration{DSN: "http://public@127.0.0.1:1/1"})
+		require.NoError(t, err)
+
+		require.NoError(t, reporter.Shutdown(context.Background()))
+		require.NoError(t, reporter.Shutdown(context.Background()))
+
+		err = reporter.Report(&logger.ErrorReport{Level: logger.ErrorLevel, Message: "deploy failed"})
+		assert.Error(t, err)
+	})
+
+	t.Run("when flushing exceeds the context, it returns error", func(t *testing.T) {
+		t.Parallel()
+
+		unblock := make(chan struct{})
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-unblock
+		}))
+		defer server.Close()
+		defer close(unblock)
+
+		dsn := strings.Replace(server.URL, "http://", "http://public@", 1) + "/1"
+
+		reporter, err := NewReporter(Configuration{DSN: dsn})
+		require.NoError(t, err)
+
+		require.NoError(t, reporter.Report(&logger.ErrorReport{Level: logger.ErrorLevel, Message: "deploy failed"}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		assert.Error(t, reporter.Shutdown(ctx))
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/palantir/stacktrace"
+	//nolint:depguard
+	"github.com/sirupsen/logrus"
+)
+
+// Sink is an additional output of a logger with its own level and encoding,
+// e.g human-readable console output along with machine-readable debug file.
+type Sink struct {
+	Writer io.Writer
+	// Level is the most verbose level written to the sink.
+	Level Level
+	// Encoding is one of `EncodingJSON` or `EncodingPlain`.
+	Encoding string
+}
+
+// NewLogrusLoggerWithSinks creates a logger that writes only to `sinks`.
+// The logger level is set to the most verbose level of the sinks.
+func NewLogrusLoggerWithSinks(sinks ...Sink) (*LogrusLogger, error) {
+	logger := NewLogrusLogger()
+	logger.SetOutput(ioutil.Discard)
+
+	level := PanicLevel
+	for _, sink := range sinks {
+		err := logger.AddSink(sink)
+		if err != nil {
+			return nil, err
+		}
+
+		if sink.Level > level {
+			level = sink.Level
+		}
+	}
+
+	logger.SetLevel(level)
+
+	return logger, nil
+}
+
+// AddSink adds an output to the logger.
+//
+// NOTE: The entries are filtered by the logger level first, so it must be at least
+// as verbose as the sink level.
+func (std *LogrusLogger) AddSink(sink Sink) error {
+	if sink.Writer == nil {
+		return stacktrace.NewError("sink writer is nil")
+	}
+
+	formatter, err := newLogrusFormatter(sink.Encoding)
+	if err != nil {
+		return stacktrace.Propagate(err, "creating sink formatter failed")
+	}
+
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.internalLogger.Hooks.Add(&logrusSinkHook{
+		writer:    sink.Writer,
+		level:     logrus.Level(sink.Level),
+		formatter: formatter,
+	})
+
+	return nil
+}
+
+type logrusSinkHook struct {
+	mu        sync.Mutex
+	writer    io.Writer
+	level     logrus.Level
+	formatter logrus.Formatter
+}
+
+func (hook *logrusSinkHook) Levels() []logrus.Level {
+	levels := make([]logrus.Level, 0, len(logrus.AllLevels))
+	for _, level := range logrus.AllLevels {
+		if level <= hook.level {
+			levels = append(levels, level)
+		}
+	}
+
+	return levels
+}
+
+func (hook *logrusSinkHook) Fire(entry *logrus.Entry) error {
+	data, err := hook.formatter.Format(entry)
+	if err != nil {
+		return stacktrace.Propagate(err, "formatting sink entry failed")
+	}
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	_, err = hook.writer.Write(data)
+	return stacktrace.Propagate(err, "writing sink entry failed")
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogrusLoggerWithSinks(t *testing.T) {
+	t.Run("it writes to every sink with its own level and encoding", func(t *testing.T) {
+		t.Parallel()
+
+		var console, debugFile bytes.Buffer
+
+		log, err := NewLogrusLoggerWithSinks(
+			Sink{Writer: &console, Level: InfoLevel, Encoding: EncodingPlain},
+			Sink{Writer: &debugFile, Level: DebugLevel, Encoding: EncodingJSON},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, DebugLevel, log.GetLevel())
+
+		log.Debug("debug details")
+		log.With("namespace", "default").Info("deployed")
+
+		assert.NotContains(t, console.String(), "debug details")
+		assert.Contains(t, console.String(), `msg=deployed namespace=default`)
+
+		lines := strings.Split(strings.TrimSpace(debugFile.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[0], `"msg":"debug details"`)
+		assert.Contains(t, lines[1], `"namespace":"default"`)
+	})
+
+	t.Run("when sink encoding is invalid, it returns an error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewLogrusLoggerWithSinks(Sink{Writer: &bytes.Buffer{}, Encoding: "xml"})
+		assert.Error(t, err)
+	})
+}
+
+func TestNewZapLogger_Sinks(t *testing.T) {
+	t.Run("it writes to every sink with its own level and encoding", func(t *testing.T) {
+		t.Parallel()
+
+		var console, debugFile bytes.Buffer
+
+		log, err := NewZapLogger(Configuration{
+			Level:    LogLevelInfo,
+			Encoding: EncodingJSON,
+			Sinks: []SinkConfiguration{
+				{Writer: &console, Encoding: EncodingPlain},
+				{Writer: &debugFile, Level: LogLevelDebug, Encoding: EncodingJSON},
+			},
+		})
+		require.NoError(t, err)
+
+		log.Debug("debug details")
+		log.Info("deployed")
+
+		assert.NotContains(t, console.String(), "debug details")
+		assert.Contains(t, console.String(), "deployed")
+		assert.Contains(t, debugFile.String(), `"msg":"debug details"`)
+		assert.Contains(t, debugFile.String(), `"msg":"deployed"`)
+	})
+}
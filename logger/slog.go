@@ -0,0 +1,303 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+)
+
+const (
+	// SlogLevelFatal is the slog level of the fatal entries logged through SlogLogger.
+	SlogLevelFatal = slog.LevelError + 4
+	// SlogLevelPanic is the slog level of the panic entries logged through SlogLogger.
+	SlogLevelPanic = slog.LevelError + 8
+)
+
+var (
+	_ slog.Handler = (*SlogHandler)(nil)
+	_ Logger       = (*SlogLogger)(nil)
+)
+
+// SlogHandler is a slog.Handler that writes the records to a Logger,
+// e.g `slog.New(logger.NewSlogHandler(logger.NewLogrusLogger()))`.
+//
+// The attributes are passed as fields, with the keys of groups prefixed by the group name, e.g `request.id`.
+// Records above the error level are logged as errors, so that slog calls never exit or panic.
+type SlogHandler struct {
+	logger Logger
+	prefix string
+}
+
+// NewSlogHandler creates SlogHandler instance.
+func NewSlogHandler(logger Logger) *SlogHandler {
+	return &SlogHandler{
+		logger: logger,
+	}
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.GetLevel() >= levelFromSlog(level)
+}
+
+// NOTE: We pass `record` by value to satisfy the interface requirements
+// nolint:gocritic
+func (h *SlogHandler) Handle(_ context.Context, record slog.Record) error {
+	fields := make(Fields, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		addSlogAttr(fields, h.prefix, attr)
+		return true
+	})
+
+	log := h.logger
+	if len(fields) > 0 {
+		log = log.WithFields(fields)
+	}
+
+	log.Log(levelFromSlog(record.Level), record.Message)
+
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	fields := make(Fields, len(attrs))
+	for _, attr := range attrs {
+		addSlogAttr(fields, h.prefix, attr)
+	}
+
+	return &SlogHandler{
+		logger: h.logger.WithFields(fields),
+		prefix: h.prefix,
+	}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &SlogHandler{
+		logger: h.logger,
+		prefix: h.prefix + name + ".",
+	}
+}
+
+func addSlogAttr(fields Fields, prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+
+	if value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		// NOTE: Attributes of an inline group, with blank key, are added as they are.
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+
+		for _, groupAttr := range value.Group() {
+			addSlogAttr(fields, groupPrefix, groupAttr)
+		}
+
+		return
+	}
+
+	if attr.Key == "" {
+		return
+	}
+
+	fields[prefix+attr.Key] = value.Any()
+}
+
+func levelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+func levelToSlog(level Level) slog.Level {
+	switch level {
+	case PanicLevel:
+		return SlogLevelPanic
+	case FatalLevel:
+		return SlogLevelFatal
+	case ErrorLevel:
+		return slog.LevelError
+	case WarnLevel:
+		return slog.LevelWarn
+	case InfoLevel:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// SlogLogger is a Logger that writes to a *slog.Logger, e.g to pass a slog logger to the executors.
+// Fatal entries exit the process and panic entries panic after being logged, same as LogrusLogger.
+type SlogLogger struct {
+	logger *slog.Logger
+	level  *AtomicLevel
+}
+
+// NewSlogLogger creates SlogLogger instance with info level.
+// The level is applied before the level of the slog handler.
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	return &SlogLogger{
+		logger: logger,
+		level:  NewAtomicLevel(InfoLevel),
+	}
+}
+
+func (s *SlogLogger) With(key string, value interface{}) Logger {
+	return &SlogLogger{
+		logger: s.logger.With(key, value),
+		level:  s.level,
+	}
+}
+
+func (s *SlogLogger) WithFields(fields Fields) Logger {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	args := make([]interface{}, 0, 2*len(fields))
+	for _, key := range keys {
+		args = append(args, key, fields[key])
+	}
+
+	return &SlogLogger{
+		logger: s.logger.With(args...),
+		level:  s.level,
+	}
+}
+
+func (s *SlogLogger) SetLevel(level Level) {
+	s.level.SetLevel(level)
+}
+
+func (s *SlogLogger) GetLevel() Level {
+	return s.level.Level()
+}
+
+// log must be called directly by the exported methods, for the caller of the record to be correct.
+func (s *SlogLogger) log(level Level, msg string) {
+	if s.level.Enabled(level) {
+		ctx := context.Background()
+		slogLevel := levelToSlog(level)
+
+		if s.logger.Enabled(ctx, slogLevel) {
+			var pcs [1]uintptr
+			// NOTE: Skip `runtime.Callers`, `log` and the exported method.
+			runtime.Callers(3, pcs[:])
+
+			record := slog.NewRecord(time.Now(), slogLevel, msg, pcs[0])
+			_ = s.logger.Handler().Handle(ctx, record)
+		}
+	}
+
+	switch level {
+	case PanicLevel:
+		panic(msg)
+	case FatalLevel:
+		os.Exit(1)
+	}
+}
+
+// Taken from logrus implementation
+func slogSprintlnn(args ...interface{}) string {
+	msg := fmt.Sprintln(args...)
+	return msg[:len(msg)-1]
+}
+
+func (s *SlogLogger) Log(level Level, args ...interface{}) {
+	s.log(level, fmt.Sprint(args...))
+}
+
+func (s *SlogLogger) Logf(level Level, format string, args ...interface{}) {
+	s.log(level, fmt.Sprintf(format, args...))
+}
+
+func (s *SlogLogger) Logln(level Level, args ...interface{}) {
+	s.log(level, slogSprintlnn(args...))
+}
+
+func (s *SlogLogger) Debug(args ...interface{})   { s.log(DebugLevel, fmt.Sprint(args...)) }
+func (s *SlogLogger) Print(args ...interface{})   { s.log(InfoLevel, fmt.Sprint(args...)) }
+func (s *SlogLogger) Info(args ...interface{})    { s.log(InfoLevel, fmt.Sprint(args...)) }
+func (s *SlogLogger) Warn(args ...interface{})    { s.log(WarnLevel, fmt.Sprint(args...)) }
+func (s *SlogLogger) Warning(args ...interface{}) { s.log(WarnLevel, fmt.Sprint(args...)) }
+func (s *SlogLogger) Error(args ...interface{})   { s.log(ErrorLevel, fmt.Sprint(args...)) }
+func (s *SlogLogger) Panic(args ...interface{})   { s.log(PanicLevel, fmt.Sprint(args...)) }
+func (s *SlogLogger) Fatal(args ...interface{})   { s.log(FatalLevel, fmt.Sprint(args...)) }
+
+func (s *SlogLogger) Debugf(format string, args ...interface{}) {
+	s.log(DebugLevel, fmt.Sprintf(format, args...))
+}
+
+func (s *SlogLogger) Printf(format string, args ...interface{}) {
+	s.log(InfoLevel, fmt.Sprintf(format, args...))
+}
+
+func (s *SlogLogger) Infof(format string, args ...interface{}) {
+	s.log(InfoLevel, fmt.Sprintf(format, args...))
+}
+
+func (s *SlogLogger) Warnf(format string, args ...interface{}) {
+	s.log(WarnLevel, fmt.Sprintf(format, args...))
+}
+
+func (s *SlogLogger) Warningf(format string, args ...interface{}) {
+	s.log(WarnLevel, fmt.Sprintf(format, args...))
+}
+
+func (s *SlogLogger) Errorf(format string, args ...interface{}) {
+	s.log(ErrorLevel, fmt.Sprintf(format, args...))
+}
+
+func (s *SlogLogger) Panicf(format string, args ...interface{}) {
+	s.log(PanicLevel, fmt.Sprintf(format, args...))
+}
+
+func (s *SlogLogger) Fatalf(format string, args ...interface{}) {
+	s.log(FatalLevel, fmt.Sprintf(format, args...))
+}
+
+func (s *SlogLogger) Debugln(args ...interface{})   { s.log(DebugLevel, slogSprintlnn(args...)) }
+func (s *SlogLogger) Println(args ...interface{})   { s.log(InfoLevel, slogSprintlnn(args...)) }
+func (s *SlogLogger) Infoln(args ...interface{})    { s.log(InfoLevel, slogSprintlnn(args...)) }
+func (s *SlogLogger) Warnln(args ...interface{})    { s.log(WarnLevel, slogSprintlnn(args...)) }
+func (s *SlogLogger) Warningln(args ...interface{}) { s.log(WarnLevel, slogSprintlnn(args...)) }
+func (s *SlogLogger) Errorln(args ...interface{})   { s.log(ErrorLevel, slogSprintlnn(args...)) }
+func (s *SlogLogger) Panicln(args ...interface{})   { s.log(PanicLevel, slogSprintlnn(args...)) }
+func (s *SlogLogger) Fatalln(args ...interface{})   { s.log(FatalLevel, slogSprintlnn(args...)) }
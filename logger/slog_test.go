@@ -0,0 +1,126 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeJSONLines(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestSlogHandler(t *testing.T) {
+	t.Run("it writes slog records with attributes and groups as fields", func(t *testing.T) {
+		t.Parallel()
+
+		var buffer bytes.Buffer
+
+		log, err := NewLogrusLoggerWithEncoding(EncodingJSON)
+		require.NoError(t, err)
+		log.SetOutput(&buffer)
+
+		slogger := slog.New(NewSlogHandler(log)).With("command", "kubectl")
+
+		slogger.Debug("skipped")
+		slogger.WithGroup("request").Warn("retrying", "attempt", 2, slog.Group("pod", "name", "api"))
+
+		entries := decodeJSONLines(t, &buffer)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "warning", entries[0]["level"])
+		assert.Equal(t, "retrying", entries[0]["msg"])
+		assert.Equal(t, "kubectl", entries[0]["command"])
+		assert.Equal(t, float64(2), entries[0]["request.attempt"])
+		assert.Equal(t, "api", entries[0]["request.pod.name"])
+	})
+
+	t.Run("it logs records above error level as errors", func(t *testing.T) {
+		t.Parallel()
+
+		var buffer bytes.Buffer
+
+		log, err := NewLogrusLoggerWithEncoding(EncodingJSON)
+		require.NoError(t, err)
+		log.SetOutput(&buffer)
+
+		slog.New(NewSlogHandler(log)).Log(context.Background(), SlogLevelFatal, "failed")
+
+		entries := decodeJSONLines(t, &buffer)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "error", entries[0]["level"])
+	})
+}
+
+func TestSlogLogger(t *testing.T) {
+	t.Run("it writes entries with fields to slog logger", func(t *testing.T) {
+		t.Parallel()
+
+		var buffer bytes.Buffer
+
+		handler := slog.NewJSONHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug, AddSource: true})
+		log := NewSlogLogger(slog.New(handler))
+
+		log.Debug("skipped")
+		log.WithFields(Fields{"namespace": "default"}).With("attempt", 2).Warnf("retrying %s", "apply")
+
+		log.SetLevel(DebugLevel)
+		log.Debugln("details")
+
+		entries := decodeJSONLines(t, &buffer)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "WARN", entries[0]["level"])
+		assert.Equal(t, "retrying apply", entries[0]["msg"])
+		assert.Equal(t, "default", entries[0]["namespace"])
+		assert.Equal(t, float64(2), entries[0]["attempt"])
+		assert.Equal(t, "DEBUG", entries[1]["level"])
+		assert.Equal(t, "details", entries[1]["msg"])
+
+		source := entries[0]["source"].(map[string]interface{})
+		assert.True(t, strings.HasSuffix(source["file"].(string), "slog_test.go"), source["file"])
+	})
+
+	t.Run("it logs and panics on panic entries", func(t *testing.T) {
+		t.Parallel()
+
+		var buffer bytes.Buffer
+
+		log := NewSlogLogger(slog.New(slog.NewJSONHandler(&buffer, nil)))
+
+		assert.PanicsWithValue(t, "boom", func() {
+			log.Panic("boom")
+		})
+		assert.Contains(t, buffer.String(), `"level":"ERROR+8"`)
+	})
+}
@@ -50,3 +50,5 @@ func (l *NilLogger) Log(level logger.Level, args ...interface{})
 func (l *NilLogger) Logln(level logger.Level, args ...interface{})               {}
 func (l *NilLogger) SetLevel(level logger.Level)                                 {}
 func (l *NilLogger) GetLevel() logger.Level                                      { return logger.InfoLevel }
+func (l *NilLogger) With(key string, value interface{}) logger.Logger            { return l }
+func (l *NilLogger) WithFields(fields logger.Fields) logger.Logger               { return l }
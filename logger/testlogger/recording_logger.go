@@ -0,0 +1,347 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testlogger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+)
+
+var _ logger.Logger = (*RecordingLogger)(nil)
+
+// RecordedEntry is a single entry captured by RecordingLogger.
+type RecordedEntry struct {
+	Level   logger.Level
+	Message string
+	Fields  logger.Fields
+}
+
+// FieldMatcher matches the fields of a RecordedEntry.
+type FieldMatcher struct {
+	Description string
+	Match       func(fields logger.Fields) bool
+}
+
+// HasField matches entries that have the field `key`.
+func HasField(key string) FieldMatcher {
+	return FieldMatcher{
+		Description: fmt.Sprintf("has field %s", key),
+		Match: func(fields logger.Fields) bool {
+			_, ok := fields[key]
+			return ok
+		},
+	}
+}
+
+// FieldEquals matches entries that have the field `key` equal to `value`.
+func FieldEquals(key string, value interface{}) FieldMatcher {
+	return FieldMatcher{
+		Description: fmt.Sprintf("has field %s=%v", key, value),
+		Match: func(fields logger.Fields) bool {
+			actual, ok := fields[key]
+			return ok && reflect.DeepEqual(actual, value)
+		},
+	}
+}
+
+type recording struct {
+	mu      sync.Mutex
+	level   logger.Level
+	entries []RecordedEntry
+}
+
+// RecordingLogger captures the logged entries along with their fields for assertions.
+// Children created by `With` and `WithFields` capture into the same entries.
+//
+// Unlike the other loggers, fatal entries are only captured and do not exit the process.
+type RecordingLogger struct {
+	recording *recording
+	fields    logger.Fields
+}
+
+// NewRecording creates RecordingLogger instance that captures entries of all levels.
+func NewRecording() *RecordingLogger {
+	return &RecordingLogger{
+		recording: &recording{level: logger.DebugLevel},
+	}
+}
+
+// Entries returns the captured entries in the order they were logged.
+func (rl *RecordingLogger) Entries() []RecordedEntry {
+	rl.recording.mu.Lock()
+	defer rl.recording.mu.Unlock()
+
+	entries := make([]RecordedEntry, len(rl.recording.entries))
+	copy(entries, rl.recording.entries)
+
+	return entries
+}
+
+// EntriesAt returns the captured entries of `level`.
+func (rl *RecordingLogger) EntriesAt(level logger.Level) []RecordedEntry {
+	var entries []RecordedEntry
+	for _, entry := range rl.Entries() {
+		if entry.Level == level {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// Reset discards the captured entries.
+func (rl *RecordingLogger) Reset() {
+	rl.recording.mu.Lock()
+	defer rl.recording.mu.Unlock()
+
+	rl.recording.entries = nil
+}
+
+// Find returns the first entry of `level` containing `substring` and matching all `matchers`.
+func (rl *RecordingLogger) Find(level logger.Level, substring string, matchers ...FieldMatcher) (RecordedEntry, bool) {
+	for _, entry := range rl.EntriesAt(level) {
+		if entry.matches(substring, matchers) {
+			return entry, true
+		}
+	}
+
+	return RecordedEntry{}, false
+}
+
+// AssertLogged asserts that an entry of `level` containing `substring` and matching all `matchers` was logged.
+func (rl *RecordingLogger) AssertLogged(
+	t assert.TestingT,
+	level logger.Level,
+	substring string,
+	matchers ...FieldMatcher,
+) bool {
+	_, ok := rl.Find(level, substring, matchers...)
+	if ok {
+		return true
+	}
+
+	return assert.Fail(
+		t,
+		fmt.Sprintf("No %s entry containing %q%s was logged", levelName(level), substring, describe(matchers)),
+		rl.dump(),
+	)
+}
+
+// AssertNotLogged asserts that no entry of `level` containing `substring` and matching all `matchers` was logged.
+func (rl *RecordingLogger) AssertNotLogged(
+	t assert.TestingT,
+	level logger.Level,
+	substring string,
+	matchers ...FieldMatcher,
+) bool {
+	entry, ok := rl.Find(level, substring, matchers...)
+	if !ok {
+		return true
+	}
+
+	return assert.Fail(
+		t,
+		fmt.Sprintf("Unexpected %s entry %q was logged", levelName(level), entry.Message),
+		rl.dump(),
+	)
+}
+
+func (entry *RecordedEntry) matches(substring string, matchers []FieldMatcher) bool {
+	if !strings.Contains(entry.Message, substring) {
+		return false
+	}
+
+	for _, matcher := range matchers {
+		if !matcher.Match(entry.Fields) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (rl *RecordingLogger) dump() string {
+	var builder strings.Builder
+	builder.WriteString("Logged entries:")
+
+	for _, entry := range rl.Entries() {
+		builder.WriteString(fmt.Sprintf("\n\t%s %q %v", levelName(entry.Level), entry.Message, entry.Fields))
+	}
+
+	return builder.String()
+}
+
+func describe(matchers []FieldMatcher) string {
+	if len(matchers) == 0 {
+		return ""
+	}
+
+	descriptions := make([]string, len(matchers))
+	for idx, matcher := range matchers {
+		descriptions[idx] = matcher.Description
+	}
+
+	return " that " + strings.Join(descriptions, " and ")
+}
+
+func levelName(level logger.Level) string {
+	switch level {
+	case logger.PanicLevel:
+		return "panic"
+	case logger.FatalLevel:
+		return "fatal"
+	case logger.ErrorLevel:
+		return "error"
+	case logger.WarnLevel:
+		return "warning"
+	case logger.InfoLevel:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+func (rl *RecordingLogger) record(level logger.Level, msg string) {
+	rl.recording.mu.Lock()
+	defer rl.recording.mu.Unlock()
+
+	if rl.recording.level < level {
+		return
+	}
+
+	fields := make(logger.Fields, len(rl.fields))
+	for key, value := range rl.fields {
+		fields[key] = value
+	}
+
+	rl.recording.entries = append(rl.recording.entries, RecordedEntry{
+		Level:   level,
+		Message: msg,
+		Fields:  fields,
+	})
+}
+
+// Taken from logrus implementation
+func sprintlnn(args ...interface{}) string {
+	msg := fmt.Sprintln(args...)
+	return msg[:len(msg)-1]
+}
+
+func (rl *RecordingLogger) With(key string, value interface{}) logger.Logger {
+	return rl.WithFields(logger.Fields{key: value})
+}
+
+func (rl *RecordingLogger) WithFields(fields logger.Fields) logger.Logger {
+	merged := make(logger.Fields, len(rl.fields)+len(fields))
+	for key, value := range rl.fields {
+		merged[key] = value
+	}
+
+	for key, value := range fields {
+		merged[key] = value
+	}
+
+	return &RecordingLogger{
+		recording: rl.recording,
+		fields:    merged,
+	}
+}
+
+func (rl *RecordingLogger) SetLevel(level logger.Level) {
+	rl.recording.mu.Lock()
+	defer rl.recording.mu.Unlock()
+
+	rl.recording.level = level
+}
+
+func (rl *RecordingLogger) GetLevel() logger.Level {
+	rl.recording.mu.Lock()
+	defer rl.recording.mu.Unlock()
+
+	return rl.recording.level
+}
+
+func (rl *RecordingLogger) Log(level logger.Level, args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	rl.record(level, msg)
+
+	if level == logger.PanicLevel {
+		panic(msg)
+	}
+}
+
+func (rl *RecordingLogger) Logf(level logger.Level, format string, args ...interface{}) {
+	rl.Log(level, fmt.Sprintf(format, args...))
+}
+
+func (rl *RecordingLogger) Logln(level logger.Level, args ...interface{}) {
+	rl.Log(level, sprintlnn(args...))
+}
+
+func (rl *RecordingLogger) Debug(args ...interface{})   { rl.Log(logger.DebugLevel, args...) }
+func (rl *RecordingLogger) Print(args ...interface{})   { rl.Log(logger.InfoLevel, args...) }
+func (rl *RecordingLogger) Info(args ...interface{})    { rl.Log(logger.InfoLevel, args...) }
+func (rl *RecordingLogger) Warn(args ...interface{})    { rl.Log(logger.WarnLevel, args...) }
+func (rl *RecordingLogger) Warning(args ...interface{}) { rl.Log(logger.WarnLevel, args...) }
+func (rl *RecordingLogger) Error(args ...interface{})   { rl.Log(logger.ErrorLevel, args...) }
+func (rl *RecordingLogger) Panic(args ...interface{})   { rl.Log(logger.PanicLevel, args...) }
+func (rl *RecordingLogger) Fatal(args ...interface{})   { rl.Log(logger.FatalLevel, args...) }
+
+func (rl *RecordingLogger) Debugf(format string, args ...interface{}) {
+	rl.Logf(logger.DebugLevel, format, args...)
+}
+
+func (rl *RecordingLogger) Printf(format string, args ...interface{}) {
+	rl.Logf(logger.InfoLevel, format, args...)
+}
+
+func (rl *RecordingLogger) Infof(format string, args ...interface{}) {
+	rl.Logf(logger.InfoLevel, format, args...)
+}
+
+func (rl *RecordingLogger) Warnf(format string, args ...interface{}) {
+	rl.Logf(logger.WarnLevel, format, args...)
+}
+
+func (rl *RecordingLogger) Warningf(format string, args ...interface{}) {
+	rl.Logf(logger.WarnLevel, format, args...)
+}
+
+func (rl *RecordingLogger) Errorf(format string, args ...interface{}) {
+	rl.Logf(logger.ErrorLevel, format, args...)
+}
+
+func (rl *RecordingLogger) Panicf(format string, args ...interface{}) {
+	rl.Logf(logger.PanicLevel, format, args...)
+}
+
+func (rl *RecordingLogger) Fatalf(format string, args ...interface{}) {
+	rl.Logf(logger.FatalLevel, format, args...)
+}
+
+func (rl *RecordingLogger) Debugln(args ...interface{})   { rl.Logln(logger.DebugLevel, args...) }
+func (rl *RecordingLogger) Println(args ...interface{})   { rl.Logln(logger.InfoLevel, args...) }
+func (rl *RecordingLogger) Infoln(args ...interface{})    { rl.Logln(logger.InfoLevel, args...) }
+func (rl *RecordingLogger) Warnln(args ...interface{})    { rl.Logln(logger.WarnLevel, args...) }
+func (rl *RecordingLogger) Warningln(args ...interface{}) { rl.Logln(logger.WarnLevel, args...) }
+func (rl *RecordingLogger) Errorln(args ...interface{})   { rl.Logln(logger.ErrorLevel, args...) }
+func (rl *RecordingLogger) Panicln(args ...interface{})   { rl.Logln(logger.PanicLevel, args...) }
+func (rl *RecordingLogger) Fatalln(args ...interface{})   { rl.Logln(logger.FatalLevel, args...) }
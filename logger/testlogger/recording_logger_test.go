@@ -0,0 +1,86 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testlogger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+)
+
+func TestRecordingLogger(t *testing.T) {
+	t.Run("it captures entries with fields of children", func(t *testing.T) {
+		t.Parallel()
+
+		log := NewRecording()
+
+		log.Info("deploying")
+		log.With("attempt", 2).Warnf("retrying %s", "kubectl apply")
+
+		entries := log.Entries()
+		require.Len(t, entries, 2)
+		assert.Equal(t, RecordedEntry{Level: logger.InfoLevel, Message: "deploying", Fields: logger.Fields{}}, entries[0])
+		assert.Equal(
+			t,
+			RecordedEntry{Level: logger.WarnLevel, Message: "retrying kubectl apply", Fields: logger.Fields{"attempt": 2}},
+			entries[1],
+		)
+
+		log.AssertLogged(t, logger.WarnLevel, "retrying", HasField("attempt"), FieldEquals("attempt", 2))
+		log.AssertNotLogged(t, logger.ErrorLevel, "retrying")
+	})
+
+	t.Run("it fails the assertion when no entry matches", func(t *testing.T) {
+		t.Parallel()
+
+		log := NewRecording()
+		log.With("attempt", 1).Warn("retrying")
+
+		fakeT := &testing.T{}
+		assert.False(t, log.AssertLogged(fakeT, logger.WarnLevel, "retrying", FieldEquals("attempt", 2)))
+		assert.False(t, log.AssertLogged(fakeT, logger.InfoLevel, "retrying"))
+		assert.False(t, log.AssertNotLogged(fakeT, logger.WarnLevel, "retrying"))
+	})
+
+	t.Run("it captures only entries enabled by the level", func(t *testing.T) {
+		t.Parallel()
+
+		log := NewRecording()
+		log.SetLevel(logger.InfoLevel)
+
+		log.Debug("details")
+		log.Error("failed")
+
+		assert.Empty(t, log.EntriesAt(logger.DebugLevel))
+		assert.Len(t, log.EntriesAt(logger.ErrorLevel), 1)
+
+		log.Reset()
+		assert.Empty(t, log.Entries())
+	})
+
+	t.Run("it captures panic entries and panics", func(t *testing.T) {
+		t.Parallel()
+
+		log := NewRecording()
+
+		assert.PanicsWithValue(t, "boom", func() {
+			log.Panic("boom")
+		})
+		log.AssertLogged(t, logger.PanicLevel, "boom")
+	})
+}
@@ -17,6 +17,8 @@ package testlogger
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/sumup-oss/go-pkgs/logger"
@@ -31,6 +33,9 @@ type TestLogger struct {
 	DebugLogs []string
 	ErrorLogs []string
 	WarnLogs  []string
+
+	parent *TestLogger
+	fields logger.Fields
 }
 
 func NewTestLogger(level logger.Level) *TestLogger {
@@ -43,6 +48,60 @@ func NewTestLogger(level logger.Level) *TestLogger {
 	}
 }
 
+// With returns a child logger that captures into the same logs,
+// with the key-value pair appended to every message as ` key=value`.
+func (tl *TestLogger) With(key string, value interface{}) logger.Logger {
+	return tl.WithFields(logger.Fields{key: value})
+}
+
+// WithFields returns a child logger that captures into the same logs,
+// with the fields appended to every message as ` key=value`, sorted by key.
+func (tl *TestLogger) WithFields(fields logger.Fields) logger.Logger {
+	merged := make(logger.Fields, len(tl.fields)+len(fields))
+	for key, value := range tl.fields {
+		merged[key] = value
+	}
+
+	for key, value := range fields {
+		merged[key] = value
+	}
+
+	return &TestLogger{
+		parent: tl.root(),
+		fields: merged,
+	}
+}
+
+func (tl *TestLogger) root() *TestLogger {
+	if tl.parent != nil {
+		return tl.parent
+	}
+
+	return tl
+}
+
+func (tl *TestLogger) withFields(msg string) string {
+	if len(tl.fields) == 0 {
+		return msg
+	}
+
+	keys := make([]string, 0, len(tl.fields))
+	for key := range tl.fields {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	builder.WriteString(msg)
+
+	for _, key := range keys {
+		builder.WriteString(fmt.Sprintf(" %s=%v", key, tl.fields[key]))
+	}
+
+	return builder.String()
+}
+
 // Taken from logrus implementation
 func (tl *TestLogger) sprintlnn(args ...interface{}) string {
 	msg := fmt.Sprintln(args...)
@@ -50,15 +109,17 @@ func (tl *TestLogger) sprintlnn(args ...interface{}) string {
 }
 
 func (tl *TestLogger) SetLevel(level logger.Level) {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
-	tl.Level = level
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	root.Level = level
 }
 
 func (tl *TestLogger) GetLevel() logger.Level {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
-	return tl.Level
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	return root.Level
 }
 
 func (tl *TestLogger) Print(args ...interface{}) {
@@ -74,93 +135,102 @@ func (tl *TestLogger) Printf(format string, args ...interface{}) {
 }
 
 func (tl *TestLogger) Info(args ...interface{}) {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-	if tl.Level < logger.InfoLevel {
+	if root.Level < logger.InfoLevel {
 		return
 	}
-	tl.InfoLogs = append(tl.InfoLogs, fmt.Sprint(args...))
+	root.InfoLogs = append(root.InfoLogs, tl.withFields(fmt.Sprint(args...)))
 }
 
 func (tl *TestLogger) Infoln(args ...interface{}) {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-	if tl.Level < logger.InfoLevel {
+	if root.Level < logger.InfoLevel {
 		return
 	}
-	tl.InfoLogs = append(tl.InfoLogs, tl.sprintlnn(args...))
+	root.InfoLogs = append(root.InfoLogs, tl.withFields(tl.sprintlnn(args...)))
 }
 
 func (tl *TestLogger) Infof(format string, args ...interface{}) {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-	if tl.Level < logger.InfoLevel {
+	if root.Level < logger.InfoLevel {
 		return
 	}
-	tl.InfoLogs = append(tl.InfoLogs, fmt.Sprintf(format, args...))
+	root.InfoLogs = append(root.InfoLogs, tl.withFields(fmt.Sprintf(format, args...)))
 }
 
 func (tl *TestLogger) Debug(args ...interface{}) {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-	if tl.Level < logger.DebugLevel {
+	if root.Level < logger.DebugLevel {
 		return
 	}
-	tl.DebugLogs = append(tl.DebugLogs, fmt.Sprint(args...))
+	root.DebugLogs = append(root.DebugLogs, tl.withFields(fmt.Sprint(args...)))
 }
 
 func (tl *TestLogger) Debugln(args ...interface{}) {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-	if tl.Level < logger.DebugLevel {
+	if root.Level < logger.DebugLevel {
 		return
 	}
-	tl.DebugLogs = append(tl.DebugLogs, tl.sprintlnn(args...))
+	root.DebugLogs = append(root.DebugLogs, tl.withFields(tl.sprintlnn(args...)))
 }
 
 func (tl *TestLogger) Debugf(format string, args ...interface{}) {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-	if tl.Level < logger.DebugLevel {
+	if root.Level < logger.DebugLevel {
 		return
 	}
-	tl.DebugLogs = append(tl.DebugLogs, fmt.Sprintf(format, args...))
+	root.DebugLogs = append(root.DebugLogs, tl.withFields(fmt.Sprintf(format, args...)))
 }
 
 func (tl *TestLogger) Warn(args ...interface{}) {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-	if tl.Level < logger.DebugLevel {
+	if root.Level < logger.DebugLevel {
 		return
 	}
-	tl.WarnLogs = append(tl.WarnLogs, fmt.Sprint(args...))
+	root.WarnLogs = append(root.WarnLogs, tl.withFields(fmt.Sprint(args...)))
 }
 
 func (tl *TestLogger) Warnln(args ...interface{}) {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-	if tl.Level < logger.DebugLevel {
+	if root.Level < logger.DebugLevel {
 		return
 	}
-	tl.WarnLogs = append(tl.WarnLogs, tl.sprintlnn(args...))
+	root.WarnLogs = append(root.WarnLogs, tl.withFields(tl.sprintlnn(args...)))
 }
 
 func (tl *TestLogger) Warnf(format string, args ...interface{}) {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-	if tl.Level < logger.DebugLevel {
+	if root.Level < logger.DebugLevel {
 		return
 	}
-	tl.WarnLogs = append(tl.WarnLogs, fmt.Sprintf(format, args...))
+	root.WarnLogs = append(root.WarnLogs, tl.withFields(fmt.Sprintf(format, args...)))
 }
 
 func (tl *TestLogger) Warning(args ...interface{}) {
@@ -176,33 +246,36 @@ func (tl *TestLogger) Warningf(format string, args ...interface{}) {
 }
 
 func (tl *TestLogger) Error(args ...interface{}) {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-	if tl.Level < logger.ErrorLevel {
+	if root.Level < logger.ErrorLevel {
 		return
 	}
-	tl.ErrorLogs = append(tl.ErrorLogs, fmt.Sprint(args...))
+	root.ErrorLogs = append(root.ErrorLogs, tl.withFields(fmt.Sprint(args...)))
 }
 
 func (tl *TestLogger) Errorln(args ...interface{}) {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-	if tl.Level < logger.ErrorLevel {
+	if root.Level < logger.ErrorLevel {
 		return
 	}
-	tl.ErrorLogs = append(tl.ErrorLogs, tl.sprintlnn(args...))
+	root.ErrorLogs = append(root.ErrorLogs, tl.withFields(tl.sprintlnn(args...)))
 }
 
 func (tl *TestLogger) Errorf(format string, args ...interface{}) {
-	tl.mu.Lock()
-	defer tl.mu.Unlock()
+	root := tl.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-	if tl.Level < logger.ErrorLevel {
+	if root.Level < logger.ErrorLevel {
 		return
 	}
-	tl.ErrorLogs = append(tl.ErrorLogs, fmt.Sprintf(format, args...))
+	root.ErrorLogs = append(root.ErrorLogs, tl.withFields(fmt.Sprintf(format, args...)))
 }
 
 func (tl *TestLogger) Fatal(args ...interface{}) {
@@ -41,3 +41,7 @@ func NewZapNopLogger() *ZapNopLogger {
 func (z *ZapNopLogger) GetLevel() zapcore.Level {
 	return z.level
 }
+
+func (z *ZapNopLogger) SetLevel(level zapcore.Level) {
+	z.level = level
+}
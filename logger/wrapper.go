@@ -27,7 +27,14 @@ var (
 //nolint:gochecknoinits
 func init() {
 	mu = sync.RWMutex{}
-	std = NewLogrusLogger()
+
+	// NOTE: Fallback to the default encoding, since there is no way to report invalid `LOG_ENCODING` here.
+	logrusLogger, err := NewLogrusLoggerFromEnv()
+	if err != nil {
+		logrusLogger = NewLogrusLogger()
+	}
+
+	std = logrusLogger
 }
 
 func SetLogger(logger Logger) {
@@ -48,6 +55,16 @@ func SetLevel(level Level) {
 	std.SetLevel(level)
 }
 
+// With returns a child of the standard logger that attaches the key-value pair to every log entry.
+func With(key string, value interface{}) Logger {
+	return GetLogger().With(key, value)
+}
+
+// WithFields returns a child of the standard logger that attaches the fields to every log entry.
+func WithFields(fields Fields) Logger {
+	return GetLogger().WithFields(fields)
+}
+
 // Debug logs a message at level Debug on the standard logger.
 func Debug(args ...interface{}) {
 	std.Debug(args...)
@@ -0,0 +1,103 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var zapLevelsByLevel = map[Level]zapcore.Level{
+	PanicLevel: zapcore.PanicLevel,
+	FatalLevel: zapcore.FatalLevel,
+	ErrorLevel: zapcore.ErrorLevel,
+	WarnLevel:  zapcore.WarnLevel,
+	InfoLevel:  zapcore.InfoLevel,
+	DebugLevel: zapcore.DebugLevel,
+}
+
+// ZapHookCore is a zapcore.Core that fires a Hook for the entries at the hook levels.
+type ZapHookCore struct {
+	zapcore.LevelEnabler
+	hook   Hook
+	levels map[zapcore.Level]Level
+	fields []zapcore.Field
+}
+
+// NewZapHookCore creates ZapHookCore instance.
+func NewZapHookCore(hook Hook) *ZapHookCore {
+	levels := make(map[zapcore.Level]Level)
+	for _, level := range hook.Levels() {
+		levels[zapLevelsByLevel[level]] = level
+	}
+
+	// NOTE: DPanic is reported along with the panic entries.
+	if level, ok := levels[zapcore.PanicLevel]; ok {
+		levels[zapcore.DPanicLevel] = level
+	}
+
+	return &ZapHookCore{
+		LevelEnabler: zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+			_, ok := levels[level]
+			return ok
+		}),
+		hook:   hook,
+		levels: levels,
+	}
+}
+
+func (core *ZapHookCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *core
+	clone.fields = append(append([]zapcore.Field(nil), core.fields...), fields...)
+
+	return &clone
+}
+
+// NOTE: We pass `entry` by value to satisfy the interface requirements
+// nolint:gocritic
+func (core *ZapHookCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(entry.Level) {
+		return checked.AddCore(entry, core)
+	}
+
+	return checked
+}
+
+// NOTE: We pass `entry` by value to satisfy the interface requirements
+// nolint:gocritic
+func (core *ZapHookCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	encoder := zapcore.NewMapObjectEncoder()
+
+	for _, field := range append(append([]zapcore.Field(nil), core.fields...), fields...) {
+		// NOTE: Keep the errors as they are instead of their messages, e.g for error reporting.
+		if err, ok := field.Interface.(error); ok && field.Type == zapcore.ErrorType {
+			encoder.Fields[field.Key] = err
+			continue
+		}
+
+		field.AddTo(encoder)
+	}
+
+	return core.hook.Fire(&BasicEntry{
+		Time:    entry.Time,
+		Level:   core.levels[entry.Level],
+		Message: entry.Message,
+		Fields:  encoder.Fields,
+	})
+}
+
+func (core *ZapHookCore) Sync() error {
+	return nil
+}
@@ -15,6 +15,7 @@
 package logger
 
 import (
+	"io"
 	"os"
 
 	gsyslog "github.com/hashicorp/go-syslog"
@@ -28,6 +29,8 @@ const (
 	// Logger encoding types
 	EncodingJSON  = "json"
 	EncodingPlain = "plain"
+	// EncodingEnvVar is the environment variable selecting the encoding of loggers created from env.
+	EncodingEnvVar = "LOG_ENCODING"
 
 	// LogLevelPanic level, highest level of severity. Logs and then calls panic with the
 	// message passed to Debug, Info, ...
@@ -82,6 +85,21 @@ type Configuration struct {
 	SyslogFacility string
 	// SyslogTag is tag for all messages produced
 	SyslogTag string
+	// Sampling limits the amount of logged entries, nil disables it.
+	Sampling *SamplingConfiguration
+	// Sinks are additional outputs with their own level and encoding.
+	Sinks []SinkConfiguration
+	// Hooks are fired for the entries at their levels, e.g error reporting.
+	Hooks []Hook
+}
+
+// SinkConfiguration is an additional output of ZapLogger.
+type SinkConfiguration struct {
+	Writer io.Writer
+	// Level is one of the `LogLevel*` levels. When blank, the logger level is used.
+	Level string
+	// Encoding is one of `EncodingJSON` or `EncodingPlain`.
+	Encoding string
 }
 
 type StructuredLogger interface {
@@ -92,6 +110,7 @@ type StructuredLogger interface {
 	Warn(msg string, fields ...zap.Field)
 	Debug(msg string, fields ...zap.Field)
 	GetLevel() zapcore.Level
+	SetLevel(level zapcore.Level)
 	Sync() error
 }
 
@@ -101,11 +120,13 @@ func NewZapLogger(config Configuration) (*ZapLogger, error) {
 		return nil, stacktrace.Propagate(err, "creating logger encoder failed")
 	}
 
-	level, err := getZapLevel(config.Level)
+	zapLevel, err := getZapLevel(config.Level)
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "creating logger failed")
 	}
 
+	level := zap.NewAtomicLevelAt(zapLevel)
+
 	var cores []zapcore.Core
 
 	if config.StdoutEnabled {
@@ -126,8 +147,35 @@ func NewZapLogger(config Configuration) (*ZapLogger, error) {
 		cores = append(cores, NewZapSyslogCore(level, encoder, writer))
 	}
 
+	for idx, sink := range config.Sinks {
+		sinkEncoder, err := newEncoder(sink.Encoding, &defaultZapEncoderConfig)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "creating sink #%d encoder failed", idx)
+		}
+
+		var sinkLevel zapcore.LevelEnabler = level
+		if sink.Level != "" {
+			sinkLevel, err = getZapLevel(sink.Level)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "creating sink #%d failed", idx)
+			}
+		}
+
+		cores = append(cores, zapcore.NewCore(sinkEncoder, zapcore.Lock(zapcore.AddSync(sink.Writer)), sinkLevel))
+	}
+
+	for _, hook := range config.Hooks {
+		cores = append(cores, NewZapHookCore(hook))
+	}
+
+	core := zapcore.NewTee(cores...)
+
+	if config.Sampling != nil {
+		core = newZapSamplingCore(core, config.Sampling)
+	}
+
 	logger := zap.New(
-		zapcore.NewTee(cores...),
+		core,
 		zap.AddCaller(),
 	)
 
@@ -162,9 +210,20 @@ var _ StructuredLogger = (*ZapLogger)(nil)
 
 type ZapLogger struct {
 	*zap.Logger
-	level zapcore.Level
+	level zap.AtomicLevel
 }
 
 func (z *ZapLogger) GetLevel() zapcore.Level {
+	return z.level.Level()
+}
+
+// SetLevel changes the level of the logger at runtime, e.g on signal or admin endpoint request.
+func (z *ZapLogger) SetLevel(level zapcore.Level) {
+	z.level.SetLevel(level)
+}
+
+// AtomicLevel returns the level of the logger.
+// It's also an `http.Handler` that serves and changes the level as JSON, e.g `{"level":"debug"}`.
+func (z *ZapLogger) AtomicLevel() zap.AtomicLevel {
 	return z.level
 }
@@ -0,0 +1,146 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logger
+
+import (
+	"math"
+
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/time/rate"
+)
+
+// ZapRateLimitedCore is a zapcore.Core decorator that drops entries above the rate limit.
+// Panic and fatal entries are never dropped.
+type ZapRateLimitedCore struct {
+	zapcore.Core
+	limiter *rate.Limiter
+}
+
+func newZapSamplingCore(core zapcore.Core, config *SamplingConfiguration) zapcore.Core {
+	if config.Tick > 0 {
+		core = newZapSampler(core, config)
+	}
+
+	if config.RateLimit > 0 {
+		burst := config.Burst
+		if burst < 1 {
+			burst = 1
+		}
+
+		core = &ZapRateLimitedCore{
+			Core:    core,
+			limiter: rate.NewLimiter(rate.Limit(config.RateLimit), burst),
+		}
+	}
+
+	return core
+}
+
+// newZapSampler samples the levels of `config.Levels` with their own sampler
+// and the rest of the levels with the default one.
+func newZapSampler(core zapcore.Core, config *SamplingConfiguration) zapcore.Core {
+	if len(config.Levels) == 0 {
+		return zapcore.NewSampler(core, config.Tick, config.First, zapThereafter(config.Thereafter))
+	}
+
+	overridden := make(map[zapcore.Level]bool)
+	cores := make([]zapcore.Core, 0, len(config.Levels)+1)
+
+	for level, levelConfig := range config.Levels {
+		zapLevel := zapLevelsByLevel[level]
+		overridden[zapLevel] = true
+
+		levelCore := &zapLevelFilterCore{
+			Core: core,
+			enabled: func(level zapcore.Level) bool {
+				return level == zapLevel
+			},
+		}
+
+		cores = append(
+			cores,
+			zapcore.NewSampler(levelCore, config.Tick, levelConfig.First, zapThereafter(levelConfig.Thereafter)),
+		)
+	}
+
+	defaultCore := &zapLevelFilterCore{
+		Core: core,
+		enabled: func(level zapcore.Level) bool {
+			return !overridden[level]
+		},
+	}
+
+	cores = append(cores, zapcore.NewSampler(defaultCore, config.Tick, config.First, zapThereafter(config.Thereafter)))
+
+	return zapcore.NewTee(cores...)
+}
+
+// zapThereafter returns the `thereafter` of zapcore.NewSampler, that panics when it's zero.
+// Zero `thereafter` drops the entries after the first ones, as SampledLogger does.
+func zapThereafter(thereafter int) int {
+	if thereafter < 1 {
+		return math.MaxInt32
+	}
+
+	return thereafter
+}
+
+// zapLevelFilterCore is a zapcore.Core decorator that passes through only the enabled levels.
+type zapLevelFilterCore struct {
+	zapcore.Core
+	enabled func(level zapcore.Level) bool
+}
+
+func (core *zapLevelFilterCore) Enabled(level zapcore.Level) bool {
+	return core.enabled(level) && core.Core.Enabled(level)
+}
+
+func (core *zapLevelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &zapLevelFilterCore{
+		Core:    core.Core.With(fields),
+		enabled: core.enabled,
+	}
+}
+
+// NOTE: We pass `entry` by value to satisfy the interface requirements
+// nolint:gocritic
+func (core *zapLevelFilterCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !core.Enabled(entry.Level) {
+		return checked
+	}
+
+	return core.Core.Check(entry, checked)
+}
+
+func (core *ZapRateLimitedCore) With(fields []zapcore.Field) zapcore.Core {
+	return &ZapRateLimitedCore{
+		Core:    core.Core.With(fields),
+		limiter: core.limiter,
+	}
+}
+
+// NOTE: We pass `entry` by value to satisfy the interface requirements
+// nolint:gocritic
+func (core *ZapRateLimitedCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !core.Enabled(entry.Level) {
+		return checked
+	}
+
+	if entry.Level < zapcore.DPanicLevel && !core.limiter.Allow() {
+		return checked
+	}
+
+	return core.Core.Check(entry, checked)
+}
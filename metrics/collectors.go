@@ -0,0 +1,94 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/executor"
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+var (
+	_ executor.CommandMetricsSink = (*CommandMetrics)(nil)
+	_ task.MetricsSink            = (*TaskMetrics)(nil)
+)
+
+// CommandMetrics is executor.CommandMetricsSink collecting the commands by binary,
+// e.g `executor_commands_total{binary="kubectl",exit_code="1"}`.
+type CommandMetrics struct {
+	commands *CounterVec
+	duration *HistogramVec
+}
+
+func NewCommandMetrics(registry *Registry) *CommandMetrics {
+	return &CommandMetrics{
+		commands: registry.NewCounterVec(
+			"executor_commands_total",
+			"Count of the executed commands by binary and exit code.",
+			"binary",
+			"exit_code",
+		),
+		duration: registry.NewHistogramVec(
+			"executor_command_duration_seconds",
+			"Duration of the executed commands by binary.",
+			nil,
+			"binary",
+		),
+	}
+}
+
+func (m *CommandMetrics) ObserveCommand(binary string, exitCode int, duration time.Duration) {
+	m.commands.Inc(binary, strconv.Itoa(exitCode))
+	m.duration.Observe(duration.Seconds(), binary)
+}
+
+// TaskMetrics is task.MetricsSink collecting the task runs by name,
+// e.g `task_duration_seconds_count{task="deploy",status="failed"}`.
+type TaskMetrics struct {
+	attempts *CounterVec
+	duration *HistogramVec
+}
+
+func NewTaskMetrics(registry *Registry) *TaskMetrics {
+	return &TaskMetrics{
+		attempts: registry.NewCounterVec(
+			"task_attempts_total",
+			"Count of the started task runs by task.",
+			"task",
+		),
+		duration: registry.NewHistogramVec(
+			"task_duration_seconds",
+			"Duration of the finished task runs by task and status.",
+			nil,
+			"task",
+			"status",
+		),
+	}
+}
+
+func (m *TaskMetrics) IncTaskAttempts(name string) {
+	m.attempts.Inc(name)
+}
+
+func (m *TaskMetrics) ObserveTaskDuration(name string, succeeded bool, duration time.Duration) {
+	status := "failed"
+	if succeeded {
+		status = "succeeded"
+	}
+
+	m.duration.Observe(duration.Seconds(), name, status)
+}
@@ -0,0 +1,76 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+func TestCommandMetrics_ObserveCommand(t *testing.T) {
+	t.Run(
+		"it counts the commands by binary and exit code and observes their duration by binary",
+		func(t *testing.T) {
+			t.Parallel()
+
+			metrics := NewCommandMetrics(NewRegistry())
+			metrics.ObserveCommand("kubectl", 0, time.Second)
+			metrics.ObserveCommand("kubectl", 1, 2*time.Second)
+			metrics.ObserveCommand("helm", 0, time.Second)
+
+			assert.Equal(t, float64(1), metrics.commands.Value("kubectl", "0"))
+			assert.Equal(t, float64(1), metrics.commands.Value("kubectl", "1"))
+			assert.Equal(t, float64(1), metrics.commands.Value("helm", "0"))
+			assert.Equal(t, uint64(2), metrics.duration.Count("kubectl"))
+		},
+	)
+}
+
+func TestTaskMetrics(t *testing.T) {
+	t.Run(
+		"it collects the attempts and durations by status of a task decorated with metrics",
+		func(t *testing.T) {
+			t.Parallel()
+
+			metrics := NewTaskMetrics(NewRegistry())
+
+			calls := 0
+			fn := task.NewTaskFunc(
+				func(ctx context.Context) error {
+					calls++
+					if calls == 1 {
+						return errors.New("api server unavailable")
+					}
+
+					return nil
+				},
+				task.WithMetrics("deploy", metrics),
+			)
+
+			_ = fn(context.Background())
+			_ = fn(context.Background())
+
+			assert.Equal(t, float64(2), metrics.attempts.Value("deploy"))
+			assert.Equal(t, uint64(1), metrics.duration.Count("deploy", "failed"))
+			assert.Equal(t, uint64(1), metrics.duration.Count("deploy", "succeeded"))
+		},
+	)
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prommetrics provides the collectors of the executor commands and the task runs
+// as Prometheus client collectors, so that they are registered with the `prometheus.Registerer`
+// of an application, e.g along with its HTTP metrics, instead of the metrics.Registry.
+// It's a separate module, so that go-pkgs does not depend on the Prometheus client.
+package prommetrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sumup-oss/go-pkgs/executor"
+	"github.com/sumup-oss/go-pkgs/metrics"
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+var (
+	_ executor.CommandMetricsSink = (*CommandMetrics)(nil)
+	_ prometheus.Collector        = (*CommandMetrics)(nil)
+	_ task.MetricsSink            = (*TaskMetrics)(nil)
+	_ prometheus.Collector        = (*TaskMetrics)(nil)
+)
+
+// CommandMetrics is executor.CommandMetricsSink collecting the commands by binary,
+// e.g `executor_commands_total{binary="kubectl",exit_code="1"}`, same as metrics.CommandMetrics.
+type CommandMetrics struct {
+	commands *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewCommandMetrics creates CommandMetrics instance, to be registered,
+// e.g `prometheus.MustRegister(prommetrics.NewCommandMetrics())`.
+func NewCommandMetrics() *CommandMetrics {
+	return &CommandMetrics{
+		commands: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "executor_commands_total",
+				Help: "Count of the executed commands by binary and exit code.",
+			},
+			[]string{"binary", "exit_code"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "executor_command_duration_seconds",
+				Help:    "Duration of the executed commands by binary.",
+				Buckets: metrics.DefaultBuckets,
+			},
+			[]string{"binary"},
+		),
+	}
+}
+
+func (m *CommandMetrics) ObserveCommand(binary string, exitCode int, duration time.Duration) {
+	m.commands.WithLabelValues(binary, strconv.Itoa(exitCode)).Inc()
+	m.duration.WithLabelValues(binary).Observe(duration.Seconds())
+}
+
+func (m *CommandMetrics) Describe(descs chan<- *prometheus.Desc) {
+	m.commands.Describe(descs)
+	m.duration.Describe(descs)
+}
+
+func (m *CommandMetrics) Collect(collected chan<- prometheus.Metric) {
+	m.commands.Collect(collected)
+	m.duration.Collect(collected)
+}
+
+// TaskMetrics is task.MetricsSink collecting the task runs by name,
+// e.g `task_duration_seconds_count{task="deploy",status="failed"}`, same as metrics.TaskMetrics.
+type TaskMetrics struct {
+	attempts *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewTaskMetrics creates TaskMetrics instance, to be registered,
+// e.g `prometheus.MustRegister(prommetrics.NewTaskMetrics())`.
+func NewTaskMetrics() *TaskMetrics {
+	return &TaskMetrics{
+		attempts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "task_attempts_total",
+				Help: "Count of the started task runs by task.",
+			},
+			[]string{"task"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "task_duration_seconds",
+				Help:    "Duration of the finished task runs by task and status.",
+				Buckets: metrics.DefaultBuckets,
+			},
+			[]string{"task", "status"},
+		),
+	}
+}
+
+func (m *TaskMetrics) IncTaskAttempts(name string) {
+	m.attempts.WithLabelValues(name).Inc()
+}
+
+func (m *TaskMetrics) ObserveTaskDuration(name string, succeeded bool, duration time.Duration) {
+	status := "failed"
+	if succeeded {
+		status = "succeeded"
+	}
+
+	m.duration.WithLabelValues(name, status).Observe(duration.Seconds())
+}
+
+func (m *TaskMetrics) Describe(descs chan<- *prometheus.Desc) {
+	m.attempts.Describe(descs)
+	m.duration.Describe(descs)
+}
+
+func (m *TaskMetrics) Collect(collected chan<- prometheus.Metric) {
+	m.attempts.Collect(collected)
+	m.duration.Collect(collected)
+}
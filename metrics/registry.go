@@ -0,0 +1,342 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics is a minimal metrics registry exposed in the Prometheus text format,
+// with ready-made collectors of the executor commands and the task runs,
+// for tools that cannot afford the dependencies of the Prometheus client.
+// The `github.com/sumup-oss/go-pkgs/metrics/prommetrics` module provides the same collectors
+// as Prometheus client collectors, to be registered with an existing `prometheus.Registerer`.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the histogram buckets, in seconds, suited for command and task durations.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+var (
+	_ http.Handler = (*Registry)(nil)
+	_ io.WriterTo  = (*Registry)(nil)
+)
+
+type collector interface {
+	write(buffer *bytes.Buffer)
+}
+
+// Registry holds metrics and exposes them in the Prometheus text format. It's safe for concurrent use.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// NewCounterVec registers a counter with the label names, e.g `executor_commands_total` by `binary`.
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	counter := &CounterVec{
+		desc:   newDesc(name, help, "counter", labelNames),
+		values: make(map[string]*counterValue),
+	}
+
+	r.register(counter)
+
+	return counter
+}
+
+// NewHistogramVec registers a histogram with the upper bounds of the buckets, in increasing order,
+// and the label names. DefaultBuckets are used when buckets is empty.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	histogram := &HistogramVec{
+		desc:    newDesc(name, help, "histogram", labelNames),
+		buckets: buckets,
+		values:  make(map[string]*histogramValue),
+	}
+
+	r.register(histogram)
+
+	return histogram
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.collectors = append(r.collectors, c)
+}
+
+// WriteTo writes the metrics in the Prometheus text format.
+func (r *Registry) WriteTo(writer io.Writer) (int64, error) {
+	r.mu.Lock()
+	collectors := make([]collector, len(r.collectors))
+	copy(collectors, r.collectors)
+	r.mu.Unlock()
+
+	var buffer bytes.Buffer
+	for _, c := range collectors {
+		c.write(&buffer)
+	}
+
+	return buffer.WriteTo(writer)
+}
+
+// ServeHTTP serves the metrics to the Prometheus scrapes, e.g at `/metrics`.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	_, _ = r.WriteTo(w)
+}
+
+type desc struct {
+	name       string
+	help       string
+	metricType string
+	labelNames []string
+}
+
+func newDesc(name, help, metricType string, labelNames []string) desc {
+	return desc{
+		name:       name,
+		help:       help,
+		metricType: metricType,
+		labelNames: labelNames,
+	}
+}
+
+// key returns the key of a series by its label values.
+// It panics when the count of label values doesn't match the label names, like the Prometheus client.
+func (d *desc) key(labelValues []string) string {
+	if len(labelValues) != len(d.labelNames) {
+		panic(
+			fmt.Sprintf(
+				"metric %s expects %d label values, got %d",
+				d.name,
+				len(d.labelNames),
+				len(labelValues),
+			),
+		)
+	}
+
+	return strings.Join(labelValues, "\xff")
+}
+
+func (d *desc) writeHeader(buffer *bytes.Buffer) {
+	fmt.Fprintf(buffer, "# HELP %s %s\n", d.name, strings.Replace(d.help, "\n", `\n`, -1))
+	fmt.Fprintf(buffer, "# TYPE %s %s\n", d.name, d.metricType)
+}
+
+// labels formats the labels of a series, with the extra label pairs appended, e.g `{binary="kubectl",le="1"}`.
+func (d *desc) labels(labelValues []string, extra ...string) string {
+	pairs := make([]string, 0, len(labelValues)+len(extra)/2)
+
+	for i, value := range labelValues {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, d.labelNames[i], escapeLabelValue(value)))
+	}
+
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], escapeLabelValue(extra[i+1])))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func sortedKeys(keys []string) []string {
+	sort.Strings(keys)
+	return keys
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct {
+	desc desc
+
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labelValues []string
+	value       float64
+}
+
+// Inc increments the counter of the label values by 1.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds value, which must not be negative, to the counter of the label values.
+func (c *CounterVec) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		panic(fmt.Sprintf("counter %s cannot decrease", c.desc.name))
+	}
+
+	key := c.desc.key(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	series, ok := c.values[key]
+	if !ok {
+		series = &counterValue{labelValues: append([]string(nil), labelValues...)}
+		c.values[key] = series
+	}
+
+	series.value += value
+}
+
+// Value returns the counter of the label values, e.g for assertions in tests.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	key := c.desc.key(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	series, ok := c.values[key]
+	if !ok {
+		return 0
+	}
+
+	return series.value
+}
+
+func (c *CounterVec) write(buffer *bytes.Buffer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.desc.writeHeader(buffer)
+
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+
+	for _, key := range sortedKeys(keys) {
+		series := c.values[key]
+		fmt.Fprintf(buffer, "%s%s %s\n", c.desc.name, c.desc.labels(series.labelValues), formatFloat(series.value))
+	}
+}
+
+// HistogramVec is a histogram partitioned by labels.
+type HistogramVec struct {
+	desc    desc
+	buckets []float64
+
+	mu     sync.Mutex
+	values map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	// counts are the non-cumulative counts of the observations per bucket.
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Observe adds an observation, e.g a duration in seconds, to the histogram of the label values.
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := h.desc.key(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	series, ok := h.values[key]
+	if !ok {
+		series = &histogramValue{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.values[key] = series
+	}
+
+	index := sort.SearchFloat64s(h.buckets, value)
+	if index < len(h.buckets) {
+		series.counts[index]++
+	}
+
+	series.count++
+	series.sum += value
+}
+
+// Count returns the count of the observations of the label values, e.g for assertions in tests.
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	key := h.desc.key(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	series, ok := h.values[key]
+	if !ok {
+		return 0
+	}
+
+	return series.count
+}
+
+func (h *HistogramVec) write(buffer *bytes.Buffer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.desc.writeHeader(buffer)
+
+	keys := make([]string, 0, len(h.values))
+	for key := range h.values {
+		keys = append(keys, key)
+	}
+
+	for _, key := range sortedKeys(keys) {
+		series := h.values[key]
+
+		var cumulative uint64
+		for i, upperBound := range h.buckets {
+			cumulative += series.counts[i]
+			fmt.Fprintf(
+				buffer,
+				"%s_bucket%s %d\n",
+				h.desc.name,
+				h.desc.labels(series.labelValues, "le", formatFloat(upperBound)),
+				cumulative,
+			)
+		}
+
+		fmt.Fprintf(buffer, "%s_bucket%s %d\n", h.desc.name, h.desc.labels(series.labelValues, "le", "+Inf"), series.count)
+		fmt.Fprintf(buffer, "%s_sum%s %s\n", h.desc.name, h.desc.labels(series.labelValues), formatFloat(series.sum))
+		fmt.Fprintf(buffer, "%s_count%s %d\n", h.desc.name, h.desc.labels(series.labelValues), series.count)
+	}
+}
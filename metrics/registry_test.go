@@ -0,0 +1,130 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	t.Run(
+		"it writes the counters and histograms in the Prometheus text format sorted by labels",
+		func(t *testing.T) {
+			t.Parallel()
+
+			registry := NewRegistry()
+
+			counter := registry.NewCounterVec("pushes_total", "Count of pushes.", "registry")
+			counter.Inc("quay.io")
+			counter.Add(2, "docker.io")
+
+			histogram := registry.NewHistogramVec("push_duration_seconds", "Duration of pushes.", []float64{1, 5}, "registry")
+			histogram.Observe(0.5, "docker.io")
+			histogram.Observe(3, "docker.io")
+			histogram.Observe(7, "docker.io")
+
+			var buffer bytes.Buffer
+
+			_, err := registry.WriteTo(&buffer)
+			require.Nil(t, err)
+			assert.Equal(
+				t,
+				"# HELP pushes_total Count of pushes.\n"+
+					"# TYPE pushes_total counter\n"+
+					"pushes_total{registry=\"docker.io\"} 2\n"+
+					"pushes_total{registry=\"quay.io\"} 1\n"+
+					"# HELP push_duration_seconds Duration of pushes.\n"+
+					"# TYPE push_duration_seconds histogram\n"+
+					"push_duration_seconds_bucket{registry=\"docker.io\",le=\"1\"} 1\n"+
+					"push_duration_seconds_bucket{registry=\"docker.io\",le=\"5\"} 2\n"+
+					"push_duration_seconds_bucket{registry=\"docker.io\",le=\"+Inf\"} 3\n"+
+					"push_duration_seconds_sum{registry=\"docker.io\"} 10.5\n"+
+					"push_duration_seconds_count{registry=\"docker.io\"} 3\n",
+				buffer.String(),
+			)
+			assert.Equal(t, float64(2), counter.Value("docker.io"))
+			assert.Equal(t, uint64(3), histogram.Count("docker.io"))
+		},
+	)
+
+	t.Run(
+		"it escapes the label values",
+		func(t *testing.T) {
+			t.Parallel()
+
+			registry := NewRegistry()
+			registry.NewCounterVec("errors_total", "Count of errors.", "message").Inc("say \"hi\"\\\n")
+
+			var buffer bytes.Buffer
+
+			_, err := registry.WriteTo(&buffer)
+			require.Nil(t, err)
+			assert.Contains(t, buffer.String(), `errors_total{message="say \"hi\"\\\n"} 1`)
+		},
+	)
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	t.Run(
+		"it serves the metrics with the Prometheus text format content type",
+		func(t *testing.T) {
+			t.Parallel()
+
+			registry := NewRegistry()
+			registry.NewCounterVec("deploys_total", "Count of deploys.").Inc()
+
+			recorder := httptest.NewRecorder()
+			registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+			assert.Contains(t, recorder.Body.String(), "deploys_total 1\n")
+		},
+	)
+}
+
+func TestCounterVec_Add(t *testing.T) {
+	t.Run(
+		"it panics when the label values don't match the label names",
+		func(t *testing.T) {
+			t.Parallel()
+
+			counter := NewRegistry().NewCounterVec("pushes_total", "Count of pushes.", "registry")
+
+			assert.PanicsWithValue(t, "metric pushes_total expects 1 label values, got 2", func() {
+				counter.Inc("docker.io", "extra")
+			})
+		},
+	)
+
+	t.Run(
+		"it panics when the value is negative",
+		func(t *testing.T) {
+			t.Parallel()
+
+			counter := NewRegistry().NewCounterVec("pushes_total", "Count of pushes.")
+
+			assert.PanicsWithValue(t, "counter pushes_total cannot decrease", func() {
+				counter.Add(-1)
+			})
+		},
+	)
+}
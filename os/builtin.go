@@ -37,11 +37,13 @@ var osArgs = os.Args
 var osChdir = os.Chdir
 var osChmod = os.Chmod
 var osCreate = os.Create
+var osEnviron = os.Environ
 var osExit = os.Exit
 var osGetenv = os.Getenv
 var osGetwd = os.Getwd
 var osIsExist = os.IsExist
 var osIsNotExist = os.IsNotExist
+var osLookupEnv = os.LookupEnv
 var osLstat = os.Lstat
 var osMkdir = os.Mkdir
 var osMkdirAll = os.MkdirAll
@@ -0,0 +1,27 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package os
+
+// CommandEnv returns the environment of the current process with `env` added to it,
+// or nil when `env` is empty, so that the executed command inherits the current process environment.
+// NOTE: A non-empty env replaces the environment of the executed command,
+// which would otherwise run without variables such as `PATH` and `HOME`.
+func CommandEnv(env ...string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+
+	return append(osEnviron(), env...)
+}
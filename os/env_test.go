@@ -0,0 +1,43 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package os
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandEnv(t *testing.T) {
+	t.Run("when env is empty, it returns nil", func(t *testing.T) {
+		assert.Nil(t, CommandEnv())
+	})
+
+	t.Run("it adds env to the builtin `osEnviron`", func(t *testing.T) {
+		environ := []string{"PATH=/usr/bin", "HOME=/root"}
+
+		osEnviron = func() []string {
+			return environ
+		}
+		defer func() {
+			osEnviron = os.Environ
+		}()
+
+		actual := CommandEnv("FOO=bar")
+
+		assert.Equal(t, []string{"PATH=/usr/bin", "HOME=/root", "FOO=bar"}, actual)
+	})
+}
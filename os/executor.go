@@ -31,10 +31,11 @@ import (
 
 var (
 	// Compile-time proof of interfaces implementation.
-	_ OsExecutor        = (*RealOsExecutor)(nil)
-	_ CommandExecutor   = (*RealOsExecutor)(nil)
-	_ EnvProvider       = (*RealOsExecutor)(nil)
-	_ IOStreamsProvider = (*RealOsExecutor)(nil)
+	_ OsExecutor           = (*RealOsExecutor)(nil)
+	_ CommandExecutor      = (*RealOsExecutor)(nil)
+	_ StdinCommandExecutor = (*RealOsExecutor)(nil)
+	_ EnvProvider          = (*RealOsExecutor)(nil)
+	_ IOStreamsProvider    = (*RealOsExecutor)(nil)
 )
 
 type RealOsExecutor struct {
@@ -153,6 +154,31 @@ func (ex *RealOsExecutor) ExecuteContext(
 	return stdout.Bytes(), stderr.Bytes(), err
 }
 
+func (ex *RealOsExecutor) ExecuteWithStdinContext(
+	ctx context.Context,
+	cmd string,
+	arg,
+	env []string,
+	dir string,
+	stdin io.Reader,
+) ([]byte, []byte, error) {
+	command := execCommandContext(ctx, cmd, arg...)
+
+	if len(env) > 0 {
+		command.Env = env
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	command.Stdin = stdin
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	command.Dir = dir
+
+	err := command.Run()
+	return stdout.Bytes(), stderr.Bytes(), stacktrace.Propagate(err, "executing command failed")
+}
+
 func (ex *RealOsExecutor) ExecuteWithStreams(
 	cmd string,
 	arg,
@@ -317,6 +343,10 @@ func (ex *RealOsExecutor) Symlink(oldname, newname string) error {
 	return osSymlink(oldname, newname)
 }
 
+func (ex *RealOsExecutor) LookupEnv(key string) (string, bool) {
+	return osLookupEnv(key)
+}
+
 func (ex *RealOsExecutor) Lstat(name string) (os.FileInfo, error) {
 	return osLstat(name)
 }
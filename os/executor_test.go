@@ -16,6 +16,7 @@ package os
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -394,6 +395,37 @@ func TestRealOsExecutor_Getenv(t *testing.T) {
 	)
 }
 
+func TestRealOsExecutor_LookupEnv(t *testing.T) {
+	t.Run(
+		"it uses builtin 'osLookupEnv'",
+		func(t *testing.T) {
+			keyArg := "HOME"
+
+			called := false
+			var calledKey string
+			calledReturn := "predefinedbytest"
+
+			osLookupEnv = func(key string) (string, bool) {
+				called = true
+				calledKey = key
+
+				return calledReturn, true
+			}
+			defer func() {
+				osLookupEnv = os.LookupEnv
+			}()
+
+			osExecutor := &RealOsExecutor{}
+			actualEnv, actualOk := osExecutor.LookupEnv(keyArg)
+
+			assert.True(t, called)
+			assert.Equal(t, calledKey, keyArg)
+			assert.Equal(t, actualEnv, calledReturn)
+			assert.True(t, actualOk)
+		},
+	)
+}
+
 func TestRealOsExecutor_GetOS(t *testing.T) {
 	t.Run(
 		"it uses `runtime.GOOS`",
@@ -718,6 +750,71 @@ func TestRealOsExecutor_Execute(t *testing.T) {
 	)
 }
 
+func TestRealOsExecutor_ExecuteWithStdinContext(t *testing.T) {
+	t.Run(
+		"it runs command with specified ctx, cmd, args, env, dir and stdin",
+		func(t *testing.T) {
+			fakeCmd := &exec.Cmd{}
+
+			var calledCtx context.Context
+			var calledName string
+			var calledArgs []string
+
+			execCommandContext = func(ctx context.Context, name string, arg ...string) *exec.Cmd {
+				calledCtx = ctx
+				calledName = name
+				calledArgs = arg
+				return fakeCmd
+			}
+			defer func() {
+				execCommandContext = exec.CommandContext
+			}()
+
+			osExecutor := &RealOsExecutor{}
+
+			ctxArg := context.Background()
+			cmdArg := "kubectl"
+			argsArg := []string{"apply", "-f", "-"}
+			envArg := []string{"GOPKGS_EXAMPLE=1"}
+			dirArg := "/tmp"
+			stdinArg := bytes.NewBufferString("kind: ConfigMap")
+
+			_, _, actualErr := osExecutor.ExecuteWithStdinContext(ctxArg, cmdArg, argsArg, envArg, dirArg, stdinArg)
+
+			assert.Equal(t, ctxArg, calledCtx)
+			assert.Equal(t, cmdArg, calledName)
+			assert.Equal(t, argsArg, calledArgs)
+			assert.Contains(t, actualErr.Error(), "executing command failed")
+
+			assert.Equal(t, envArg, fakeCmd.Env)
+			assert.Equal(t, dirArg, fakeCmd.Dir)
+			assert.Equal(t, stdinArg, fakeCmd.Stdin)
+		},
+	)
+
+	t.Run(
+		"it writes stdin to the command",
+		func(t *testing.T) {
+			if runtime.GOOS == "windows" {
+				t.Skip("`cat` is not available")
+			}
+
+			osExecutor := &RealOsExecutor{}
+
+			actualStdout, _, actualErr := osExecutor.ExecuteWithStdinContext(
+				context.Background(),
+				"cat",
+				nil,
+				nil,
+				"",
+				bytes.NewBufferString("example"),
+			)
+			require.Nil(t, actualErr)
+			assert.Equal(t, "example", string(actualStdout))
+		},
+	)
+}
+
 func TestRealOsExecutor_RemoveAll(t *testing.T) {
 	t.Run("it uses builtin `osRemoveAll`", func(t *testing.T) {
 		called := false
@@ -72,6 +72,18 @@ type (
 		Execute(cmd string, arg, env []string, dir string) ([]byte, []byte, error)
 		ExecuteContext(ctx context.Context, cmd string, arg, env []string, dir string) ([]byte, []byte, error)
 	}
+	// StdinCommandExecutor is a command executor that supports passing data to the command stdin,
+	// e.g a manifest to `kubectl apply -f -`.
+	StdinCommandExecutor interface {
+		ExecuteWithStdinContext(
+			ctx context.Context,
+			cmd string,
+			arg,
+			env []string,
+			dir string,
+			stdin io.Reader,
+		) ([]byte, []byte, error)
+	}
 	EnvProvider interface {
 		Getenv(key string) string
 		GetOS() string
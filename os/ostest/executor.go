@@ -19,6 +19,7 @@ import (
 	"io"
 	stdOs "os"
 	"os/user"
+	"sync"
 	"testing"
 
 	"github.com/sumup-oss/go-pkgs/os"
@@ -26,10 +27,20 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
-var _ os.OsExecutor = (*FakeOsExecutor)(nil)
+var (
+	_ os.OsExecutor           = (*FakeOsExecutor)(nil)
+	_ os.StdinCommandExecutor = (*FakeOsExecutor)(nil)
+)
 
 type FakeOsExecutor struct {
 	mock.Mock
+
+	passthroughMu        sync.Mutex
+	passthrough          os.CommandExecutor
+	passthroughAllowlist []string
+
+	historyMu sync.Mutex
+	history   []*RecordedCall
 }
 
 func NewFakeOsExecutor(t *testing.T) *FakeOsExecutor {
@@ -60,7 +71,13 @@ func (f *FakeOsExecutor) Execute(
 	env []string,
 	dir string,
 ) ([]byte, []byte, error) {
-	args := f.Called(cmd, arg, env, dir)
+	args, passThrough := f.called(cmd, "Execute", cmd, arg, env, dir)
+	f.recordCall("Execute", cmd, arg, env, dir, passThrough)
+
+	if passThrough {
+		return f.passthrough.Execute(cmd, arg, env, dir)
+	}
+
 	rawStdout := args.Get(0)
 	rawStderr := args.Get(1)
 	returnErr := args.Error(2)
@@ -83,7 +100,13 @@ func (f *FakeOsExecutor) ExecuteContext(
 	env []string,
 	dir string,
 ) ([]byte, []byte, error) {
-	args := f.Called(ctx, cmd, arg, env, dir)
+	args, passThrough := f.called(cmd, "ExecuteContext", ctx, cmd, arg, env, dir)
+	f.recordCall("ExecuteContext", cmd, arg, env, dir, passThrough)
+
+	if passThrough {
+		return f.passthrough.ExecuteContext(ctx, cmd, arg, env, dir)
+	}
+
 	rawStdout := args.Get(0)
 	rawStderr := args.Get(1)
 	returnErr := args.Error(2)
@@ -198,6 +221,36 @@ func (f *FakeOsExecutor) GetOS() string {
 	return args.String(0)
 }
 
+func (f *FakeOsExecutor) ExecuteWithStdinContext(
+	ctx context.Context,
+	cmd string,
+	arg []string,
+	env []string,
+	dir string,
+	stdin io.Reader,
+) ([]byte, []byte, error) {
+	args, passThrough := f.called(cmd, "ExecuteWithStdinContext", ctx, cmd, arg, env, dir, stdin)
+	f.recordCall("ExecuteWithStdinContext", cmd, arg, env, dir, passThrough)
+
+	if passThrough {
+		return f.passthroughWithStdin().ExecuteWithStdinContext(ctx, cmd, arg, env, dir, stdin)
+	}
+
+	rawStdout := args.Get(0)
+	rawStderr := args.Get(1)
+	returnErr := args.Error(2)
+
+	var returnStdout, returnStderr []byte
+	if rawStdout != nil {
+		returnStdout = rawStdout.([]byte)
+	}
+	if rawStderr != nil {
+		returnStderr = rawStderr.([]byte)
+	}
+
+	return returnStdout, returnStderr, returnErr
+}
+
 func (f *FakeOsExecutor) ExecuteWithStreams(
 	cmd string,
 	arg []string,
@@ -206,7 +259,13 @@ func (f *FakeOsExecutor) ExecuteWithStreams(
 	stdout io.Writer,
 	stderr io.Writer,
 ) error {
-	args := f.Called(cmd, arg, env, dir, stdout, stderr)
+	args, passThrough := f.called(cmd, "ExecuteWithStreams", cmd, arg, env, dir, stdout, stderr)
+	f.recordCall("ExecuteWithStreams", cmd, arg, env, dir, passThrough)
+
+	if passThrough {
+		return f.passthroughWithStreams().ExecuteWithStreams(cmd, arg, env, dir, stdout, stderr)
+	}
+
 	return args.Error(0)
 }
 
@@ -219,7 +278,13 @@ func (f *FakeOsExecutor) ExecuteWithStreamsContext(
 	stdout io.Writer,
 	stderr io.Writer,
 ) error {
-	args := f.Called(ctx, cmd, arg, env, dir, stdout, stderr)
+	args, passThrough := f.called(cmd, "ExecuteWithStreamsContext", ctx, cmd, arg, env, dir, stdout, stderr)
+	f.recordCall("ExecuteWithStreamsContext", cmd, arg, env, dir, passThrough)
+
+	if passThrough {
+		return f.passthroughWithStreams().ExecuteWithStreamsContext(ctx, cmd, arg, env, dir, stdout, stderr)
+	}
+
 	return args.Error(0)
 }
 
@@ -349,6 +414,11 @@ func (f *FakeOsExecutor) Symlink(oldname, newname string) error {
 	return args.Error(0)
 }
 
+func (f *FakeOsExecutor) LookupEnv(key string) (string, bool) {
+	args := f.Called(key)
+	return args.String(0), args.Bool(1)
+}
+
 func (f *FakeOsExecutor) Lstat(name string) (stdOs.FileInfo, error) {
 	args := f.Called(name)
 	returnValue := args.Get(0)
@@ -0,0 +1,279 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ostest
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/palantir/stacktrace"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/yaml.v2"
+)
+
+// Fixture describes scripted command responses of a FakeOsExecutor.
+//
+// Example:
+//
+//	commands:
+//	  - command: kubectl
+//	    args: ["get", "pods", "-o", "json"]
+//	    stdout: '{"items": []}'
+//	  - command: helm
+//	    argsPattern: "^upgrade --install"
+//	    stderr: "release failed"
+//	    exitCode: 1
+//	    delay: 100ms
+//	  - command: git
+//	    method: ExecuteWithStreams
+//	    stdout: "v1.0.0"
+//	    times: 2
+type Fixture struct {
+	Commands []*FixtureCommand `yaml:"commands"`
+}
+
+// FixtureCommand is a single command expectation and its response.
+// When neither `Args` nor `ArgsPattern` is set, any arguments are matched.
+// `ArgsPattern` is a regular expression matched against the space-joined arguments.
+// When `Method` is blank, the command is scripted for all execute methods and
+// its expectations are optional, otherwise only for `Method` and are required.
+type FixtureCommand struct {
+	Method      string        `yaml:"method"`
+	Command     string        `yaml:"command"`
+	Args        []string      `yaml:"args"`
+	ArgsPattern string        `yaml:"argsPattern"`
+	Stdout      string        `yaml:"stdout"`
+	Stderr      string        `yaml:"stderr"`
+	ExitCode    int           `yaml:"exitCode"`
+	Delay       time.Duration `yaml:"delay"`
+	Times       int           `yaml:"times"`
+}
+
+// ExitError is returned by the scripted commands that have a non-zero exit code.
+type ExitError struct {
+	Code   int
+	Stderr []byte
+}
+
+func (err *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", err.Code)
+}
+
+// ExitCode returns the scripted exit code, same as `exec.ExitError`.
+func (err *ExitError) ExitCode() int {
+	return err.Code
+}
+
+// ParseFixture parses YAML fixture data.
+func ParseFixture(data []byte) (*Fixture, error) {
+	var fixture Fixture
+
+	err := yaml.UnmarshalStrict(data, &fixture)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "failed to parse fixture")
+	}
+
+	for idx, command := range fixture.Commands {
+		if command.Command == "" {
+			return nil, stacktrace.NewError("fixture command #%d has blank `command`", idx)
+		}
+
+		if command.Args != nil && command.ArgsPattern != "" {
+			return nil, stacktrace.NewError(
+				"fixture command #%d has both `args` and `argsPattern` specified",
+				idx,
+			)
+		}
+
+		if command.Method != "" && !isExecuteMethod(command.Method) {
+			return nil, stacktrace.NewError("fixture command #%d has unknown `method` %s", idx, command.Method)
+		}
+
+		if command.ArgsPattern != "" {
+			_, err := regexp.Compile(command.ArgsPattern)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "fixture command #%d has invalid `argsPattern`", idx)
+			}
+		}
+	}
+
+	return &fixture, nil
+}
+
+// LoadFixture reads and parses a YAML fixture file.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "failed to read fixture file %s", path)
+	}
+
+	return ParseFixture(data)
+}
+
+// NewFakeOsExecutorFromFixture creates a FakeOsExecutor scripted by the fixture file at `path`.
+func NewFakeOsExecutorFromFixture(t *testing.T, path string) *FakeOsExecutor {
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		t.Fatalf("loading fixture failed: %s", err)
+	}
+
+	fake := NewFakeOsExecutor(t)
+	fake.ApplyFixture(fixture)
+
+	return fake
+}
+
+// ApplyFixture registers `Execute`, `ExecuteContext`, `ExecuteWithStreams` and
+// `ExecuteWithStreamsContext` expectations for the fixture commands.
+func (f *FakeOsExecutor) ApplyFixture(fixture *Fixture) {
+	for _, command := range fixture.Commands {
+		methods := executeMethods
+		if command.Method != "" {
+			methods = []string{command.Method}
+		}
+
+		for _, method := range methods {
+			call := f.onFixtureCommand(method, command)
+
+			if command.Delay > 0 {
+				call.After(command.Delay)
+			}
+
+			if command.Times > 0 {
+				call.Times(command.Times)
+			}
+
+			// NOTE: Without explicit method, the command is expected to be invoked through
+			// any of the execute methods, not through all of them.
+			if command.Method == "" {
+				call.Maybe()
+			}
+		}
+	}
+}
+
+var executeMethods = []string{
+	"Execute",
+	"ExecuteContext",
+	"ExecuteWithStreams",
+	"ExecuteWithStreamsContext",
+}
+
+func isExecuteMethod(method string) bool {
+	for _, executeMethod := range executeMethods {
+		if method == executeMethod {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *FakeOsExecutor) onFixtureCommand(method string, command *FixtureCommand) *mock.Call {
+	argsMatcher := command.argsMatcher()
+
+	var stdout, stderr []byte
+	if command.Stdout != "" {
+		stdout = []byte(command.Stdout)
+	}
+
+	if command.Stderr != "" {
+		stderr = []byte(command.Stderr)
+	}
+
+	var returnErr error
+	if command.ExitCode != 0 {
+		returnErr = &ExitError{
+			Code:   command.ExitCode,
+			Stderr: stderr,
+		}
+	}
+
+	switch method {
+	case "Execute":
+		return f.On(method, command.Command, argsMatcher, mock.Anything, mock.Anything).
+			Return(stdout, stderr, returnErr)
+	case "ExecuteContext":
+		return f.On(method, mock.Anything, command.Command, argsMatcher, mock.Anything, mock.Anything).
+			Return(stdout, stderr, returnErr)
+	case "ExecuteWithStreams":
+		return f.On(
+			method,
+			command.Command,
+			argsMatcher,
+			mock.Anything,
+			mock.Anything,
+			mock.Anything,
+			mock.Anything,
+		).
+			Run(writeStreams(4, stdout, stderr)).
+			Return(returnErr)
+	default:
+		return f.On(
+			method,
+			mock.Anything,
+			command.Command,
+			argsMatcher,
+			mock.Anything,
+			mock.Anything,
+			mock.Anything,
+			mock.Anything,
+		).
+			Run(writeStreams(5, stdout, stderr)).
+			Return(returnErr)
+	}
+}
+
+func (command *FixtureCommand) argsMatcher() interface{} {
+	if command.Args != nil {
+		expected := command.Args
+
+		return mock.MatchedBy(func(actual []string) bool {
+			if len(expected) == 0 && len(actual) == 0 {
+				return true
+			}
+
+			return reflect.DeepEqual(expected, actual)
+		})
+	}
+
+	if command.ArgsPattern != "" {
+		pattern := regexp.MustCompile(command.ArgsPattern)
+
+		return mock.MatchedBy(func(actual []string) bool {
+			return pattern.MatchString(strings.Join(actual, " "))
+		})
+	}
+
+	return mock.Anything
+}
+
+func writeStreams(stdoutIdx int, stdout, stderr []byte) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		if writer, ok := args.Get(stdoutIdx).(io.Writer); ok && len(stdout) > 0 {
+			_, _ = writer.Write(stdout)
+		}
+
+		if writer, ok := args.Get(stdoutIdx + 1).(io.Writer); ok && len(stderr) > 0 {
+			_, _ = writer.Write(stderr)
+		}
+	}
+}
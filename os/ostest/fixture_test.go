@@ -0,0 +1,105 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ostest
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFixture(t *testing.T) {
+	t.Run("when a command is blank, it returns an error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseFixture([]byte("commands:\n  - stdout: foo\n"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "blank `command`")
+	})
+
+	t.Run("when both args and argsPattern are specified, it returns an error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseFixture([]byte("commands:\n  - command: foo\n    args: [bar]\n    argsPattern: bar\n"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "both `args` and `argsPattern`")
+	})
+
+	t.Run("when method is unknown, it returns an error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseFixture([]byte("commands:\n  - command: foo\n    method: Run\n"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown `method`")
+	})
+
+	t.Run("when argsPattern is invalid, it returns an error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseFixture([]byte("commands:\n  - command: foo\n    argsPattern: \"(\"\n"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid `argsPattern`")
+	})
+}
+
+func TestNewFakeOsExecutorFromFixture(t *testing.T) {
+	t.Run("it scripts Execute with exact args", func(t *testing.T) {
+		t.Parallel()
+
+		fake := NewFakeOsExecutorFromFixture(t, filepath.Join("testdata", "fixture.yaml"))
+
+		stdout, stderr, err := fake.Execute("kubectl", []string{"get", "pods", "-o", "json"}, nil, "")
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`{"items": []}`), stdout)
+		assert.Nil(t, stderr)
+	})
+
+	t.Run("it scripts ExecuteContext with args pattern and exit code", func(t *testing.T) {
+		t.Parallel()
+
+		fake := NewFakeOsExecutorFromFixture(t, filepath.Join("testdata", "fixture.yaml"))
+
+		_, stderr, err := fake.ExecuteContext(
+			context.Background(),
+			"helm",
+			[]string{"upgrade", "--install", "foo", "./chart"},
+			nil,
+			"",
+		)
+		require.Error(t, err)
+		assert.Equal(t, 1, err.(*ExitError).ExitCode())
+		assert.Equal(t, []byte("release failed"), stderr)
+	})
+
+	t.Run("it scripts ExecuteWithStreams with any args, delay and times", func(t *testing.T) {
+		t.Parallel()
+
+		fake := NewFakeOsExecutorFromFixture(t, filepath.Join("testdata", "fixture.yaml"))
+
+		var stdout, stderr bytes.Buffer
+		start := time.Now()
+		err := fake.ExecuteWithStreams("git", []string{"status"}, nil, "/tmp", &stdout, &stderr)
+		require.NoError(t, err)
+		assert.True(t, time.Since(start) >= 10*time.Millisecond)
+		assert.Equal(t, "ok", stdout.String())
+		assert.Equal(t, "", stderr.String())
+		fake.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ostest
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// RecordedCall is a command executed through the FakeOsExecutor,
+// regardless whether it was faked or passed through.
+type RecordedCall struct {
+	Method      string
+	Command     string
+	Args        []string
+	Env         []string
+	Dir         string
+	Time        time.Time
+	PassThrough bool
+}
+
+// Calls returns all recorded command calls in the order of execution.
+func (f *FakeOsExecutor) Calls() []*RecordedCall {
+	f.historyMu.Lock()
+	defer f.historyMu.Unlock()
+
+	calls := make([]*RecordedCall, len(f.history))
+	copy(calls, f.history)
+
+	return calls
+}
+
+// CallsTo returns the recorded calls of `cmd`.
+// `cmd` matches both the exact command and its base name, e.g `kubectl` matches `/usr/bin/kubectl`.
+func (f *FakeOsExecutor) CallsTo(cmd string) []*RecordedCall {
+	var calls []*RecordedCall
+
+	for _, call := range f.Calls() {
+		if call.Command == cmd || filepath.Base(call.Command) == cmd {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// NthCall returns the i-th (zero based) recorded call or nil when there is no such call.
+func (f *FakeOsExecutor) NthCall(i int) *RecordedCall {
+	calls := f.Calls()
+	if i < 0 || i >= len(calls) {
+		return nil
+	}
+
+	return calls[i]
+}
+
+// ResetCalls clears the recorded calls.
+func (f *FakeOsExecutor) ResetCalls() {
+	f.historyMu.Lock()
+	defer f.historyMu.Unlock()
+
+	f.history = nil
+}
+
+func (f *FakeOsExecutor) recordCall(method, cmd string, arg, env []string, dir string, passThrough bool) {
+	f.historyMu.Lock()
+	defer f.historyMu.Unlock()
+
+	f.history = append(f.history, &RecordedCall{
+		Method:      method,
+		Command:     cmd,
+		Args:        append([]string(nil), arg...),
+		Env:         append([]string(nil), env...),
+		Dir:         dir,
+		Time:        time.Now(),
+		PassThrough: passThrough,
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ostest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeOsExecutor_Calls(t *testing.T) {
+	t.Run("it records the executed commands in order", func(t *testing.T) {
+		t.Parallel()
+
+		fake := NewFakeOsExecutor(t)
+		fake.On("Execute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return([]byte(nil), []byte(nil), nil)
+		fake.On("ExecuteContext", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return([]byte(nil), []byte(nil), nil)
+
+		_, _, _ = fake.Execute("kubectl", []string{"apply"}, []string{"FOO=bar"}, "/tmp")
+		_, _, _ = fake.ExecuteContext(context.Background(), "/usr/bin/helm", []string{"list"}, nil, "")
+		_, _, _ = fake.Execute("kubectl", []string{"get"}, nil, "")
+
+		calls := fake.Calls()
+		require.Len(t, calls, 3)
+
+		assert.Equal(t, "Execute", calls[0].Method)
+		assert.Equal(t, "kubectl", calls[0].Command)
+		assert.Equal(t, []string{"apply"}, calls[0].Args)
+		assert.Equal(t, []string{"FOO=bar"}, calls[0].Env)
+		assert.Equal(t, "/tmp", calls[0].Dir)
+		assert.False(t, calls[0].Time.IsZero())
+		assert.False(t, calls[0].PassThrough)
+
+		assert.Len(t, fake.CallsTo("kubectl"), 2)
+		assert.Len(t, fake.CallsTo("helm"), 1)
+		assert.Equal(t, "ExecuteContext", fake.NthCall(1).Method)
+		assert.Nil(t, fake.NthCall(3))
+
+		fake.ResetCalls()
+		assert.Empty(t, fake.Calls())
+	})
+}
@@ -0,0 +1,148 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ostest
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/sumup-oss/go-pkgs/os"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// StreamsCommandExecutor is a command executor that supports executing with streams.
+type StreamsCommandExecutor interface {
+	os.CommandExecutor
+	ExecuteWithStreams(cmd string, arg, env []string, dir string, stdout, stderr io.Writer) error
+	ExecuteWithStreamsContext(
+		ctx context.Context,
+		cmd string,
+		arg,
+		env []string,
+		dir string,
+		stdout,
+		stderr io.Writer,
+	) error
+}
+
+// NewPartialFakeOsExecutor creates a FakeOsExecutor that executes the commands without matching
+// expectation through `passthrough`.
+// When `allowlist` is not empty, only the listed commands are passed through and
+// the rest fail as unexpected calls.
+//
+// E.g. real `git`, but faked `kubectl`:
+//
+//	executor := ostest.NewPartialFakeOsExecutor(t, &os.RealOsExecutor{}, "git")
+//	executor.On("Execute", "kubectl", ...).Return(...)
+func NewPartialFakeOsExecutor(t *testing.T, passthrough os.CommandExecutor, allowlist ...string) *FakeOsExecutor {
+	fake := NewFakeOsExecutor(t)
+	fake.SetPassthrough(passthrough, allowlist...)
+
+	return fake
+}
+
+// SetPassthrough configures the executor used for the commands without matching expectation.
+// Passing nil executor disables the passthrough.
+//
+// NOTE: Expectations must be registered before the executor is used concurrently.
+func (f *FakeOsExecutor) SetPassthrough(passthrough os.CommandExecutor, allowlist ...string) {
+	f.passthroughMu.Lock()
+	defer f.passthroughMu.Unlock()
+
+	f.passthrough = passthrough
+	f.passthroughAllowlist = allowlist
+}
+
+// called returns the arguments of the matching expectation of the method,
+// or passThrough when the command has no matching expectation and must be passed through.
+//
+// NOTE: mock.Mock does not expose its mutex, so the expectations are looked up under passthroughMu
+// and consumed by `MethodCalled` after it, so that the calls are not serialized, e.g by the `After` delays,
+// and `Run` functions may call the executor. Hence, with passthrough enabled, the expectations
+// of the concurrent calls must not be limited by `Times` or `Once`, which `MethodCalled` updates.
+func (f *FakeOsExecutor) called(cmd, method string, arguments ...interface{}) (mock.Arguments, bool) {
+	if f.shouldPassthrough(cmd, method, arguments...) {
+		return nil, true
+	}
+
+	return f.MethodCalled(method, arguments...), false
+}
+
+// shouldPassthrough reports whether the command must be passed through.
+// It reads passthrough without locking, since it's set before the executor is used concurrently.
+func (f *FakeOsExecutor) shouldPassthrough(cmd, method string, arguments ...interface{}) bool {
+	if f.passthrough == nil {
+		return false
+	}
+
+	f.passthroughMu.Lock()
+	defer f.passthroughMu.Unlock()
+
+	if !f.isPassthroughAllowed(cmd) {
+		return false
+	}
+
+	return !f.hasExpectedCall(method, arguments...)
+}
+
+func (f *FakeOsExecutor) isPassthroughAllowed(cmd string) bool {
+	if len(f.passthroughAllowlist) == 0 {
+		return true
+	}
+
+	for _, allowed := range f.passthroughAllowlist {
+		if cmd == allowed || filepath.Base(cmd) == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *FakeOsExecutor) hasExpectedCall(method string, arguments ...interface{}) bool {
+	for _, call := range f.ExpectedCalls {
+		if call.Method != method || call.Repeatability < 0 {
+			continue
+		}
+
+		_, diffCount := call.Arguments.Diff(arguments)
+		if diffCount == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *FakeOsExecutor) passthroughWithStreams() StreamsCommandExecutor {
+	streamsExecutor, ok := f.passthrough.(StreamsCommandExecutor)
+	if !ok {
+		panic("ostest: passthrough executor does not support executing with streams")
+	}
+
+	return streamsExecutor
+}
+
+func (f *FakeOsExecutor) passthroughWithStdin() os.StdinCommandExecutor {
+	stdinExecutor, ok := f.passthrough.(os.StdinCommandExecutor)
+	if !ok {
+		panic("ostest: passthrough executor does not support executing with stdin")
+	}
+
+	return stdinExecutor
+}
@@ -0,0 +1,166 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ostest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPartialFakeOsExecutor(t *testing.T) {
+	t.Run("when a command has matching expectation, it is not passed through", func(t *testing.T) {
+		t.Parallel()
+
+		passthrough := NewFakeOsExecutor(t)
+		fake := NewPartialFakeOsExecutor(t, passthrough)
+
+		fake.On("Execute", "kubectl", []string{"version"}, []string(nil), "").
+			Return([]byte("faked"), []byte(nil), nil)
+
+		stdout, _, err := fake.Execute("kubectl", []string{"version"}, nil, "")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("faked"), stdout)
+
+		fake.AssertExpectations(t)
+		passthrough.AssertExpectations(t)
+	})
+
+	t.Run("when a command has no matching expectation, it is passed through", func(t *testing.T) {
+		t.Parallel()
+
+		passthrough := NewFakeOsExecutor(t)
+		fake := NewPartialFakeOsExecutor(t, passthrough)
+
+		passthrough.On("Execute", "git", []string{"status"}, []string(nil), "/tmp").
+			Return([]byte("real"), []byte(nil), nil)
+
+		stdout, _, err := fake.Execute("git", []string{"status"}, nil, "/tmp")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("real"), stdout)
+
+		passthrough.AssertExpectations(t)
+	})
+
+	t.Run("when a matching expectation is exhausted, it is passed through", func(t *testing.T) {
+		t.Parallel()
+
+		passthrough := NewFakeOsExecutor(t)
+		fake := NewPartialFakeOsExecutor(t, passthrough)
+
+		fake.On("Execute", "git", []string{"fetch"}, []string(nil), "").
+			Return([]byte("faked"), []byte(nil), nil).
+			Once()
+		passthrough.On("Execute", "git", []string{"fetch"}, []string(nil), "").
+			Return([]byte("real"), []byte(nil), nil)
+
+		first, _, _ := fake.Execute("git", []string{"fetch"}, nil, "")
+		second, _, _ := fake.Execute("git", []string{"fetch"}, nil, "")
+
+		assert.Equal(t, []byte("faked"), first)
+		assert.Equal(t, []byte("real"), second)
+	})
+
+	t.Run("when a command is not in the allowlist, it is not passed through", func(t *testing.T) {
+		t.Parallel()
+
+		passthrough := NewFakeOsExecutor(t)
+		// NOTE: Not bound to `t`, so that the unexpected call panics instead of failing the test.
+		fake := &FakeOsExecutor{}
+		fake.SetPassthrough(passthrough, "git")
+
+		assert.Panics(t, func() {
+			_, _, _ = fake.Execute("/usr/local/bin/kubectl", []string{"version"}, nil, "")
+		})
+
+		passthrough.On("Execute", "/usr/bin/git", []string{"status"}, []string(nil), "").
+			Return([]byte("real"), []byte(nil), nil)
+
+		stdout, _, err := fake.Execute("/usr/bin/git", []string{"status"}, nil, "")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("real"), stdout)
+	})
+
+	t.Run("when used concurrently, it fakes or passes through each call", func(t *testing.T) {
+		t.Parallel()
+
+		passthrough := NewFakeOsExecutor(t)
+		fake := NewPartialFakeOsExecutor(t, passthrough)
+
+		fake.On("Execute", "git", []string{"fetch"}, []string(nil), "").
+			Return([]byte("faked"), []byte(nil), nil)
+		passthrough.On("Execute", "git", []string{"status"}, []string(nil), "").
+			Return([]byte("real"), []byte(nil), nil)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				if i%2 == 0 {
+					_, _, _ = fake.Execute("git", []string{"fetch"}, nil, "")
+					return
+				}
+
+				_, _, _ = fake.Execute("git", []string{"status"}, nil, "")
+			}(i)
+		}
+
+		wg.Wait()
+
+		fake.AssertNumberOfCalls(t, "Execute", 5)
+		passthrough.AssertNumberOfCalls(t, "Execute", 5)
+	})
+
+	t.Run("when a Run function calls the executor, it does not deadlock", func(t *testing.T) {
+		t.Parallel()
+
+		passthrough := NewFakeOsExecutor(t)
+		fake := NewPartialFakeOsExecutor(t, passthrough)
+
+		fake.On("Execute", "git", []string{"fetch"}, []string(nil), "").
+			Run(func(mock.Arguments) {
+				_, _, _ = fake.Execute("git", []string{"status"}, nil, "")
+			}).
+			Return([]byte("faked"), []byte(nil), nil).
+			Once()
+		passthrough.On("Execute", "git", []string{"status"}, []string(nil), "").
+			Return([]byte("real"), []byte(nil), nil).
+			Once()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+
+			_, _, _ = fake.Execute("git", []string{"fetch"}, nil, "")
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("executor deadlocked by the Run function")
+		}
+
+		fake.AssertExpectations(t)
+		passthrough.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,138 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UnknownPercent is the percent of the updates that do not know the completion, e.g waiting for a rollout.
+const UnknownPercent = -1
+
+var (
+	_ Reporter = (*Console)(nil)
+	_ Reporter = (*JSONLines)(nil)
+	_ Reporter = (*Nop)(nil)
+)
+
+// Update is a progress update of a long-running task.
+type Update struct {
+	Task    string    `json:"task"`
+	Step    string    `json:"step,omitempty"`
+	Percent float64   `json:"percent"`
+	Message string    `json:"message,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Reporter receives the progress updates of long-running tasks, e.g to show them to CI users.
+type Reporter interface {
+	Report(update Update)
+}
+
+// Console reports human-readable progress lines, e.g `[deploy] rollout 50%: 2 of 4 replicas updated`.
+type Console struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+// NewConsole creates Console instance.
+func NewConsole(writer io.Writer) *Console {
+	return &Console{
+		writer: writer,
+	}
+}
+
+func (c *Console) Report(update Update) {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("[%s]", update.Task))
+
+	if update.Step != "" {
+		builder.WriteString(" " + update.Step)
+	}
+
+	if update.Percent >= 0 {
+		builder.WriteString(fmt.Sprintf(" %.0f%%", update.Percent))
+	}
+
+	if update.Message != "" {
+		builder.WriteString(": " + update.Message)
+	}
+
+	builder.WriteString("\n")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, _ = io.WriteString(c.writer, builder.String())
+}
+
+// JSONLines reports every update as a JSON object on a separate line, e.g for CI tooling.
+type JSONLines struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONLines creates JSONLines instance.
+func NewJSONLines(writer io.Writer) *JSONLines {
+	return &JSONLines{
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+func (j *JSONLines) Report(update Update) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	_ = j.encoder.Encode(update)
+}
+
+// Nop discards the updates.
+type Nop struct{}
+
+func (n *Nop) Report(update Update) {}
+
+type contextKey struct{}
+
+// NewContext returns a context with the reporter, that is used by the tasks run with it.
+func NewContext(ctx context.Context, reporter Reporter) context.Context {
+	return context.WithValue(ctx, contextKey{}, reporter)
+}
+
+// FromContext returns the reporter of ctx, or Nop reporter when there is none.
+func FromContext(ctx context.Context) Reporter {
+	if ctx != nil {
+		if reporter, ok := ctx.Value(contextKey{}).(Reporter); ok {
+			return reporter
+		}
+	}
+
+	return &Nop{}
+}
+
+// Report reports an update to the reporter of ctx, setting the update time when it's zero.
+func Report(ctx context.Context, update Update) {
+	if update.Time.IsZero() {
+		update.Time = time.Now()
+	}
+
+	FromContext(ctx).Report(update)
+}
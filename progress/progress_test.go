@@ -0,0 +1,74 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package progress
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsole_Report(t *testing.T) {
+	t.Run("it writes human-readable lines", func(t *testing.T) {
+		t.Parallel()
+
+		var buffer bytes.Buffer
+		console := NewConsole(&buffer)
+
+		console.Report(Update{Task: "deploy", Step: "rollout", Percent: 50, Message: "2 of 4 replicas updated"})
+		console.Report(Update{Task: "deploy", Step: "wait", Percent: UnknownPercent})
+
+		assert.Equal(t, "[deploy] rollout 50%: 2 of 4 replicas updated\n[deploy] wait\n", buffer.String())
+	})
+}
+
+func TestJSONLines_Report(t *testing.T) {
+	t.Run("it writes an update per line", func(t *testing.T) {
+		t.Parallel()
+
+		var buffer bytes.Buffer
+		jsonLines := NewJSONLines(&buffer)
+
+		jsonLines.Report(Update{
+			Task:    "deploy",
+			Step:    "rollout",
+			Percent: 100,
+			Time:    time.Date(2019, 11, 23, 10, 0, 0, 0, time.UTC),
+		})
+
+		assert.Equal(
+			t,
+			`{"task":"deploy","step":"rollout","percent":100,"time":"2019-11-23T10:00:00Z"}`+"\n",
+			buffer.String(),
+		)
+	})
+}
+
+func TestReport(t *testing.T) {
+	t.Run("it reports to the reporter of the context", func(t *testing.T) {
+		t.Parallel()
+
+		var buffer bytes.Buffer
+		ctx := NewContext(context.Background(), NewConsole(&buffer))
+
+		Report(ctx, Update{Task: "deploy", Percent: 10})
+		Report(context.Background(), Update{Task: "discarded", Percent: 10})
+
+		assert.Equal(t, "[deploy] 10%\n", buffer.String())
+	})
+}
@@ -0,0 +1,170 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit throttles operations against shared resources, e.g commands against a busy API server,
+// with token bucket or sliding window limiters, optionally per key, e.g per tenant.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/palantir/stacktrace"
+	"golang.org/x/time/rate"
+)
+
+var (
+	_ Limiter = (*TokenBucket)(nil)
+	_ Limiter = (*SlidingWindow)(nil)
+)
+
+// Limiter limits the rate of operations.
+type Limiter interface {
+	// Allow reports whether an operation may happen now, and records it when it may.
+	Allow() bool
+	// Wait blocks until an operation may happen, or returns error when ctx is done before that.
+	Wait(ctx context.Context) error
+}
+
+// TokenBucket is a Limiter allowing bursts of up to `burst` operations, refilled at `limit` operations per second.
+type TokenBucket struct {
+	limiter *rate.Limiter
+}
+
+func NewTokenBucket(limit float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		limiter: rate.NewLimiter(rate.Limit(limit), burst),
+	}
+}
+
+func (b *TokenBucket) Allow() bool {
+	return b.limiter.Allow()
+}
+
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	return stacktrace.Propagate(b.limiter.Wait(ctx), "rate limit wait failed")
+}
+
+// SlidingWindow is a Limiter allowing up to `limit` operations in any `window` period,
+// e.g to respect a quota of 100 registry pushes per minute without bursts at the window boundaries.
+type SlidingWindow struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	// events are the times of the operations within the window, oldest first.
+	events []time.Time
+	now    func() time.Time
+}
+
+func NewSlidingWindow(limit int, window time.Duration) *SlidingWindow {
+	return &SlidingWindow{
+		limit:  limit,
+		window: window,
+		events: make([]time.Time, 0, limit),
+		now:    time.Now,
+	}
+}
+
+func (w *SlidingWindow) Allow() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.reserve() == 0
+}
+
+func (w *SlidingWindow) Wait(ctx context.Context) error {
+	for {
+		w.mu.Lock()
+		delay := w.reserve()
+		w.mu.Unlock()
+
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return stacktrace.Propagate(ctx.Err(), "rate limit wait failed")
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve records an operation and returns zero when it's allowed,
+// otherwise returns the delay until the oldest operation leaves the window.
+func (w *SlidingWindow) reserve() time.Duration {
+	now := w.now()
+	start := now.Add(-w.window)
+
+	expired := 0
+	for expired < len(w.events) && !w.events[expired].After(start) {
+		expired++
+	}
+
+	w.events = w.events[expired:]
+
+	if len(w.events) < w.limit {
+		w.events = append(w.events, now)
+		return 0
+	}
+
+	// NOTE: A limit of zero never allows, retry after a whole window.
+	if len(w.events) == 0 {
+		return w.window
+	}
+
+	return w.events[0].Sub(start)
+}
+
+// Keyed limits the rate of operations per key, e.g per tenant or per command,
+// with a Limiter per key created on first use.
+type Keyed struct {
+	mu       sync.Mutex
+	limiters map[string]Limiter
+	factory  func(key string) Limiter
+}
+
+// NewKeyed creates Keyed instance creating the limiter of a key with factory,
+// e.g `func(string) Limiter { return NewTokenBucket(5, 10) }`.
+func NewKeyed(factory func(key string) Limiter) *Keyed {
+	return &Keyed{
+		limiters: make(map[string]Limiter),
+		factory:  factory,
+	}
+}
+
+func (k *Keyed) Allow(key string) bool {
+	return k.limiter(key).Allow()
+}
+
+func (k *Keyed) Wait(ctx context.Context, key string) error {
+	return k.limiter(key).Wait(ctx)
+}
+
+func (k *Keyed) limiter(key string) Limiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	limiter, ok := k.limiters[key]
+	if !ok {
+		limiter = k.factory(key)
+		k.limiters[key] = limiter
+	}
+
+	return limiter
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t.Run(
+		"it allows bursts up to the burst size",
+		func(t *testing.T) {
+			t.Parallel()
+
+			bucket := NewTokenBucket(0.001, 2)
+			assert.True(t, bucket.Allow())
+			assert.True(t, bucket.Allow())
+			assert.False(t, bucket.Allow())
+		},
+	)
+
+	t.Run(
+		"it returns error when the context is done before a token is available",
+		func(t *testing.T) {
+			t.Parallel()
+
+			bucket := NewTokenBucket(0.001, 1)
+			require.True(t, bucket.Allow())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			err := bucket.Wait(ctx)
+			assert.Error(t, err)
+		},
+	)
+}
+
+func TestSlidingWindow_Allow(t *testing.T) {
+	t.Run(
+		"it allows up to the limit of operations within the window",
+		func(t *testing.T) {
+			t.Parallel()
+
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			window := NewSlidingWindow(2, time.Minute)
+			window.now = func() time.Time { return now }
+
+			assert.True(t, window.Allow())
+
+			now = now.Add(30 * time.Second)
+			assert.True(t, window.Allow())
+			assert.False(t, window.Allow())
+
+			// NOTE: The first operation leaves the window, the second one is still in it.
+			now = now.Add(30 * time.Second)
+			assert.True(t, window.Allow())
+			assert.False(t, window.Allow())
+		},
+	)
+}
+
+func TestSlidingWindow_Wait(t *testing.T) {
+	t.Run(
+		"it waits until an operation leaves the window",
+		func(t *testing.T) {
+			t.Parallel()
+
+			window := NewSlidingWindow(1, 20*time.Millisecond)
+			require.Nil(t, window.Wait(context.Background()))
+
+			start := time.Now()
+			err := window.Wait(context.Background())
+			require.Nil(t, err)
+			assert.True(t, time.Since(start) >= 10*time.Millisecond)
+		},
+	)
+
+	t.Run(
+		"it returns error when the context is done before the window allows",
+		func(t *testing.T) {
+			t.Parallel()
+
+			window := NewSlidingWindow(1, time.Hour)
+			require.Nil(t, window.Wait(context.Background()))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := window.Wait(ctx)
+			assert.Error(t, err)
+		},
+	)
+}
+
+func TestKeyed(t *testing.T) {
+	t.Run(
+		"it limits each key independently with a limiter created once per key",
+		func(t *testing.T) {
+			t.Parallel()
+
+			var created []string
+
+			keyed := NewKeyed(func(key string) Limiter {
+				created = append(created, key)
+				return NewSlidingWindow(1, time.Hour)
+			})
+
+			assert.True(t, keyed.Allow("tenant-a"))
+			assert.False(t, keyed.Allow("tenant-a"))
+			assert.True(t, keyed.Allow("tenant-b"))
+			assert.Nil(t, keyed.Wait(context.Background(), "tenant-c"))
+			assert.Equal(t, []string{"tenant-a", "tenant-b", "tenant-c"}, created)
+		},
+	)
+}
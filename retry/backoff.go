@@ -0,0 +1,130 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+const defaultMultiplier = 2
+
+var (
+	_ Backoff = Constant(0)
+	_ Backoff = (*Exponential)(nil)
+	_ Backoff = (*DecorrelatedJitter)(nil)
+	_ Backoff = BackoffFunc(nil)
+)
+
+// Backoff is a strategy of the intervals between the attempts.
+type Backoff interface {
+	// Interval returns the interval to wait after the failed `attempt`, starting from 1,
+	// given the previous interval, which is zero after the first attempt.
+	Interval(attempt int, previous time.Duration) time.Duration
+}
+
+// BackoffFunc is an adapter of a function to Backoff.
+type BackoffFunc func(attempt int, previous time.Duration) time.Duration
+
+func (fn BackoffFunc) Interval(attempt int, previous time.Duration) time.Duration {
+	return fn(attempt, previous)
+}
+
+// Constant is a Backoff with the same interval between the attempts.
+type Constant time.Duration
+
+func (c Constant) Interval(attempt int, previous time.Duration) time.Duration {
+	return time.Duration(c)
+}
+
+// Exponential is a Backoff multiplying the interval after every attempt.
+type Exponential struct {
+	// Initial is the interval after the first attempt.
+	Initial time.Duration
+	// Max caps the interval. Zero means no cap.
+	Max time.Duration
+	// Multiplier grows the interval after every attempt. Zero defaults to 2.
+	Multiplier float64
+	// Jitter randomizes every interval by up to the given fraction of it, e.g 0.2 is +/-20%.
+	Jitter float64
+}
+
+func (e *Exponential) Interval(attempt int, previous time.Duration) time.Duration {
+	if e.Initial <= 0 {
+		return 0
+	}
+
+	multiplier := e.Multiplier
+	if multiplier == 0 {
+		multiplier = defaultMultiplier
+	}
+
+	// NOTE: The interval overflows time.Duration after enough attempts without Max,
+	// so it's capped to the max duration.
+	maxInterval := float64(math.MaxInt64)
+	if e.Max > 0 {
+		maxInterval = float64(e.Max)
+	}
+
+	interval := float64(e.Initial) * math.Pow(multiplier, float64(attempt-1))
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+
+	if e.Jitter > 0 {
+		//nolint:gosec
+		interval += interval * e.Jitter * (2*rand.Float64() - 1)
+	}
+
+	if interval >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Duration(interval)
+}
+
+// DecorrelatedJitter is a Backoff picking a random interval between Base and three times the previous interval,
+// which spreads the retries of concurrent clients better than Exponential with jitter.
+// See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/.
+type DecorrelatedJitter struct {
+	Base time.Duration
+	// Max caps the interval. Zero means no cap.
+	Max time.Duration
+}
+
+func (d *DecorrelatedJitter) Interval(attempt int, previous time.Duration) time.Duration {
+	// NOTE: Three times the previous interval overflows time.Duration, when it's over a third of the max duration.
+	upper := time.Duration(math.MaxInt64)
+	if previous < upper/3 {
+		upper = 3 * previous
+	}
+
+	if upper < d.Base {
+		upper = d.Base
+	}
+
+	interval := d.Base
+	if upper > d.Base {
+		//nolint:gosec
+		interval += time.Duration(rand.Int63n(int64(upper - d.Base)))
+	}
+
+	if d.Max > 0 && interval > d.Max {
+		interval = d.Max
+	}
+
+	return interval
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstant_Interval(t *testing.T) {
+	t.Parallel()
+
+	backoff := Constant(time.Second)
+	assert.Equal(t, time.Second, backoff.Interval(1, 0))
+	assert.Equal(t, time.Second, backoff.Interval(5, time.Second))
+}
+
+func TestExponential_Interval(t *testing.T) {
+	t.Run(
+		"it multiplies the interval up to max",
+		func(t *testing.T) {
+			t.Parallel()
+
+			backoff := &Exponential{Initial: 100 * time.Millisecond, Max: time.Second}
+			assert.Equal(t, 100*time.Millisecond, backoff.Interval(1, 0))
+			assert.Equal(t, 200*time.Millisecond, backoff.Interval(2, 0))
+			assert.Equal(t, 800*time.Millisecond, backoff.Interval(4, 0))
+			assert.Equal(t, time.Second, backoff.Interval(5, 0))
+		},
+	)
+
+	t.Run(
+		"without max, it caps the overflowing interval to the max duration",
+		func(t *testing.T) {
+			t.Parallel()
+
+			backoff := &Exponential{Initial: time.Second}
+			assert.Equal(t, time.Duration(math.MaxInt64), backoff.Interval(100, 0))
+			assert.Equal(t, time.Duration(math.MaxInt64), backoff.Interval(10000, 0))
+
+			withJitter := &Exponential{Initial: time.Second, Jitter: 0.2}
+			assert.True(t, withJitter.Interval(10000, 0) > 0)
+		},
+	)
+
+	t.Run(
+		"with jitter, it randomizes the interval within the fraction",
+		func(t *testing.T) {
+			t.Parallel()
+
+			backoff := &Exponential{Initial: time.Second, Multiplier: 3, Jitter: 0.5}
+			for i := 0; i < 100; i++ {
+				interval := backoff.Interval(2, 0)
+				assert.True(t, interval >= 1500*time.Millisecond && interval <= 4500*time.Millisecond, interval)
+			}
+		},
+	)
+}
+
+func TestDecorrelatedJitter_Interval(t *testing.T) {
+	t.Parallel()
+
+	backoff := &DecorrelatedJitter{Base: 100 * time.Millisecond, Max: 2 * time.Second}
+	assert.Equal(t, 100*time.Millisecond, backoff.Interval(1, 0))
+
+	previous := time.Duration(0)
+	for attempt := 1; attempt < 100; attempt++ {
+		interval := backoff.Interval(attempt, previous)
+
+		upper := 3 * previous
+		if upper < backoff.Base {
+			upper = backoff.Base
+		}
+
+		if upper > backoff.Max {
+			upper = backoff.Max
+		}
+
+		assert.True(t, interval >= backoff.Base && interval <= upper, interval)
+		previous = interval
+	}
+}
+
+func TestDecorrelatedJitter_Interval_Overflow(t *testing.T) {
+	t.Parallel()
+
+	backoff := &DecorrelatedJitter{Base: time.Second}
+
+	interval := backoff.Interval(100, time.Duration(math.MaxInt64/2))
+	assert.True(t, interval >= time.Second, interval)
+}
+
+func TestBackoffFunc_Interval(t *testing.T) {
+	t.Parallel()
+
+	backoff := BackoffFunc(func(attempt int, previous time.Duration) time.Duration {
+		return time.Duration(attempt) * time.Second
+	})
+	assert.Equal(t, 3*time.Second, backoff.Interval(3, 0))
+}
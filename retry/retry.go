@@ -0,0 +1,157 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package retry retries operations with pluggable backoff strategies, e.g Exponential or DecorrelatedJitter,
+// bounded by attempts, elapsed time and context cancellation.
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/clock"
+	"github.com/sumup-oss/go-pkgs/errors"
+)
+
+// Policy is a retry policy of Do.
+type Policy struct {
+	// Backoff is the strategy of the intervals between the attempts. Defaults to no interval.
+	Backoff Backoff
+	// MaxAttempts is the max number of attempts. Zero means unlimited attempts.
+	MaxAttempts int
+	// MaxElapsedTime stops retrying when the next attempt would start after it, since the first attempt.
+	// Zero means no limit.
+	MaxElapsedTime time.Duration
+	// IsRetryable classifies the errors that can be retried. Defaults to all errors but Permanent ones.
+	IsRetryable func(err error) bool
+	// OnRetry is called after every failed attempt that is retried, e.g to log it,
+	// with the interval to wait before the next attempt.
+	OnRetry func(attempt int, err error, interval time.Duration)
+	// Clock measures the elapsed time and waits for the intervals. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+// Do calls fn until it returns no error, the error is not retryable, the policy limits are exceeded
+// or ctx is done. Returns the last error of fn, annotated with the attempts count when the limits are exceeded,
+// and along with the context error when ctx is done.
+func Do(ctx context.Context, policy Policy, fn func(ctx context.Context) error) error {
+	clk := policy.clock()
+	start := clk.Now()
+
+	var interval time.Duration
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if !policy.isRetryable(err) {
+			return unwrapPermanent(err)
+		}
+
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			return errors.Wrapf(err, "giving up after %d attempts", attempt)
+		}
+
+		if policy.Backoff != nil {
+			interval = policy.Backoff.Interval(attempt, interval)
+		}
+
+		if policy.MaxElapsedTime > 0 && clk.Since(start)+interval > policy.MaxElapsedTime {
+			return errors.Wrapf(err, "giving up after %d attempts in %s", attempt, clk.Since(start))
+		}
+
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt, err, interval)
+		}
+
+		sleepErr := sleep(ctx, clk, interval)
+		if sleepErr != nil {
+			return errors.Append(err, sleepErr).ErrorOrNil()
+		}
+	}
+}
+
+func (p Policy) isRetryable(err error) bool {
+	if IsPermanent(err) {
+		return false
+	}
+
+	if p.IsRetryable != nil {
+		return p.IsRetryable(err)
+	}
+
+	return true
+}
+
+func (p Policy) clock() clock.Clock {
+	if p.Clock != nil {
+		return p.Clock
+	}
+
+	return clock.NewRealClock()
+}
+
+func sleep(ctx context.Context, clk clock.Clock, interval time.Duration) error {
+	if interval <= 0 {
+		return ctx.Err()
+	}
+
+	timer := clk.NewTimer(interval)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
+
+// permanentError is an error that is never retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as not retryable, e.g a validation error. Do returns err without the mark.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// IsPermanent checks whether err, or any error in its chain, is marked as Permanent.
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+func unwrapPermanent(err error) error {
+	if permanent, ok := err.(*permanentError); ok {
+		return permanent.err
+	}
+
+	return err
+}
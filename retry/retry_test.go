@@ -0,0 +1,176 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	stdErrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/clock/clocktest"
+	"github.com/sumup-oss/go-pkgs/errors"
+)
+
+var errFake = stdErrors.New("fake error")
+
+func failingTimes(times int, err error) (func(ctx context.Context) error, *int) {
+	attempts := 0
+
+	return func(ctx context.Context) error {
+		attempts++
+		if attempts <= times {
+			return err
+		}
+
+		return nil
+	}, &attempts
+}
+
+func TestDo(t *testing.T) {
+	t.Run(
+		"it retries until fn succeeds and calls OnRetry for every retried attempt",
+		func(t *testing.T) {
+			t.Parallel()
+
+			fn, attempts := failingTimes(2, errFake)
+
+			var retried []int
+
+			actual := Do(
+				context.Background(),
+				Policy{
+					Backoff: Constant(time.Millisecond),
+					OnRetry: func(attempt int, err error, interval time.Duration) {
+						assert.Equal(t, errFake, err)
+						assert.Equal(t, time.Millisecond, interval)
+						retried = append(retried, attempt)
+					},
+				},
+				fn,
+			)
+			require.Nil(t, actual)
+			assert.Equal(t, 3, *attempts)
+			assert.Equal(t, []int{1, 2}, retried)
+		},
+	)
+
+	t.Run(
+		"when max attempts are exceeded, it returns the last error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			fn, attempts := failingTimes(10, errFake)
+
+			actual := Do(context.Background(), Policy{MaxAttempts: 3}, fn)
+			require.NotNil(t, actual)
+			assert.True(t, errors.Is(actual, errFake))
+			assert.Equal(t, "giving up after 3 attempts: fake error", actual.Error())
+			assert.Equal(t, 3, *attempts)
+		},
+	)
+
+	t.Run(
+		"when max elapsed time would be exceeded, it stops retrying",
+		func(t *testing.T) {
+			t.Parallel()
+
+			fn, attempts := failingTimes(10, errFake)
+
+			actual := Do(
+				context.Background(),
+				Policy{Backoff: Constant(time.Hour), MaxElapsedTime: time.Minute},
+				fn,
+			)
+			require.NotNil(t, actual)
+			assert.True(t, errors.Is(actual, errFake))
+			assert.Equal(t, 1, *attempts)
+		},
+	)
+
+	t.Run(
+		"it measures the elapsed time and waits for the intervals with the policy clock",
+		func(t *testing.T) {
+			t.Parallel()
+
+			fakeClock := clocktest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+			fakeClock.SetAutoAdvance(true)
+
+			fn, attempts := failingTimes(10, errFake)
+
+			actual := Do(
+				context.Background(),
+				Policy{Backoff: Constant(time.Minute), MaxElapsedTime: 3 * time.Minute, Clock: fakeClock},
+				fn,
+			)
+			require.NotNil(t, actual)
+			assert.Equal(t, "giving up after 4 attempts in 3m0s: fake error", actual.Error())
+			assert.Equal(t, 4, *attempts)
+		},
+	)
+
+	t.Run(
+		"when error is not retryable, it returns it right away",
+		func(t *testing.T) {
+			t.Parallel()
+
+			validationErr := stdErrors.New("invalid manifest")
+
+			fn, attempts := failingTimes(10, Permanent(validationErr))
+			actual := Do(context.Background(), Policy{}, fn)
+			assert.Equal(t, validationErr, actual)
+			assert.Equal(t, 1, *attempts)
+
+			fn, attempts = failingTimes(10, validationErr)
+			actual = Do(
+				context.Background(),
+				Policy{IsRetryable: func(err error) bool { return err != validationErr }},
+				fn,
+			)
+			assert.Equal(t, validationErr, actual)
+			assert.Equal(t, 1, *attempts)
+		},
+	)
+
+	t.Run(
+		"when context is done, it returns the last error along with the context error",
+		func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			fn, _ := failingTimes(10, errFake)
+
+			actual := Do(ctx, Policy{Backoff: Constant(time.Hour)}, fn)
+			require.NotNil(t, actual)
+			assert.True(t, errors.Is(actual, errFake))
+			assert.True(t, errors.Is(actual, context.DeadlineExceeded))
+		},
+	)
+}
+
+func TestPermanent(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, Permanent(nil))
+	assert.False(t, IsPermanent(errFake))
+	assert.True(t, IsPermanent(Permanent(errFake)))
+	assert.Equal(t, "fake error", Permanent(errFake).Error())
+	assert.True(t, errors.Is(Permanent(errFake), errFake))
+}
@@ -0,0 +1,235 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"context"
+	stdOs "os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/palantir/stacktrace"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"github.com/sumup-oss/go-pkgs/os"
+)
+
+const (
+	// ExitCodeFailure is the exit code when the main function or a cleanup hook fails.
+	ExitCodeFailure = 1
+	// exitCodeSignalBase is added to the signal number, same as in shells, e.g 130 for SIGINT.
+	exitCodeSignalBase = 128
+	// defaultDrainTimeout is the drain timeout when none is set, same as the Kubernetes termination grace period.
+	defaultDrainTimeout = 30 * time.Second
+)
+
+// Hook is a cleanup hook run on shutdown, e.g to kill port-forwards or delete temp namespaces.
+// The context is done when the drain timeout is exceeded.
+type Hook func(ctx context.Context) error
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+// Manager cancels a root context on SIGINT or SIGTERM, waits for the work in progress to stop
+// and runs the registered cleanup hooks in LIFO order, each within the drain timeout,
+// and exits with the corresponding exit code.
+//
+// A second signal during the cleanup exits immediately.
+type Manager struct {
+	osExecutor   os.OsExecutor
+	log          logger.Logger
+	drainTimeout time.Duration
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	signals chan stdOs.Signal
+
+	mu       sync.Mutex
+	hooks    []*namedHook
+	received stdOs.Signal
+}
+
+// NewManager creates Manager instance. The drain timeout defaults to 30 seconds when not positive.
+func NewManager(osExecutor os.OsExecutor, log logger.Logger, drainTimeout time.Duration) *Manager {
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Manager{
+		osExecutor:   osExecutor,
+		log:          log,
+		drainTimeout: drainTimeout,
+		ctx:          ctx,
+		cancel:       cancel,
+		signals:      make(chan stdOs.Signal, 2),
+	}
+}
+
+// Context returns the root context that is canceled on shutdown.
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// Register registers a cleanup hook. The hooks are run in reverse order of registration.
+func (m *Manager) Register(name string, hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, &namedHook{name: name, hook: hook})
+}
+
+// Run runs fn with the root context until it returns or a shutdown signal is received,
+// then runs the cleanup hooks and exits. On a signal, the root context is canceled and fn is given
+// the drain timeout to return, so that the work in progress is not killed mid-operation by the cleanup.
+//
+// The exit code is 128 plus the signal number when a signal is received, ExitCodeFailure when fn
+// or any of the hooks fail and 0 otherwise.
+func (m *Manager) Run(fn func(ctx context.Context) error) {
+	signal.Notify(m.signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(m.signals)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(m.ctx)
+	}()
+
+	exitCode := 0
+
+	select {
+	case err := <-done:
+		if err != nil {
+			m.log.Errorf("Run failed: %s", err)
+			exitCode = ExitCodeFailure
+		}
+	case sig := <-m.signals:
+		m.setReceived(sig)
+		m.log.Infof("Received %s, shutting down", sig)
+		exitCode = signalExitCode(sig)
+
+		m.cancel()
+
+		drainTimer := time.NewTimer(m.drainTimeout)
+		defer drainTimer.Stop()
+
+		select {
+		case <-done:
+		case <-drainTimer.C:
+			m.log.Warnf("Drain timeout %s exceeded, running the cleanup hooks", m.drainTimeout)
+		case sig := <-m.signals:
+			m.log.Warnf("Received %s during drain, exiting immediately", sig)
+			m.osExecutor.Exit(signalExitCode(sig))
+
+			return
+		}
+	}
+
+	hooksDone := make(chan error, 1)
+	go func() {
+		hooksDone <- m.Shutdown()
+	}()
+
+	select {
+	case err := <-hooksDone:
+		if err != nil && exitCode == 0 {
+			exitCode = ExitCodeFailure
+		}
+	case sig := <-m.signals:
+		m.log.Warnf("Received %s during cleanup, exiting immediately", sig)
+		exitCode = signalExitCode(sig)
+	}
+
+	m.osExecutor.Exit(exitCode)
+}
+
+// Shutdown cancels the root context and runs the cleanup hooks in LIFO order.
+// The hooks are run only once, subsequent calls return nil.
+// Every hook is run even when the previous one failed, the first error is returned.
+func (m *Manager) Shutdown() error {
+	m.cancel()
+
+	m.mu.Lock()
+	hooks := m.hooks
+	m.hooks = nil
+	m.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.drainTimeout)
+	defer cancel()
+
+	var firstErr error
+	for idx := len(hooks) - 1; idx >= 0; idx-- {
+		hook := hooks[idx]
+
+		err := runHook(ctx, hook)
+		if err == nil {
+			continue
+		}
+
+		m.log.Errorf("Cleanup failed: %s", err)
+
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Signal returns the received shutdown signal, nil if none was received.
+func (m *Manager) Signal() stdOs.Signal {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.received
+}
+
+func (m *Manager) setReceived(sig stdOs.Signal) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.received = sig
+}
+
+func runHook(ctx context.Context, hook *namedHook) error {
+	if ctx.Err() != nil {
+		return stacktrace.Propagate(ctx.Err(), "drain timeout exceeded before cleanup hook %s", hook.name)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.hook(ctx)
+	}()
+
+	// NOTE: Do not wait for hooks that ignore the drain timeout.
+	select {
+	case err := <-done:
+		return stacktrace.Propagate(err, "cleanup hook %s failed", hook.name)
+	case <-ctx.Done():
+		return stacktrace.Propagate(ctx.Err(), "drain timeout exceeded by cleanup hook %s", hook.name)
+	}
+}
+
+func signalExitCode(sig stdOs.Signal) int {
+	if sysSignal, ok := sig.(syscall.Signal); ok {
+		return exitCodeSignalBase + int(sysSignal)
+	}
+
+	return ExitCodeFailure
+}
@@ -0,0 +1,227 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"context"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sumup-oss/go-pkgs/logger"
+	"github.com/sumup-oss/go-pkgs/logger/testlogger"
+	"github.com/sumup-oss/go-pkgs/os/ostest"
+)
+
+func TestManager_Run(t *testing.T) {
+	t.Run("when a signal is received, it cancels the context, runs the hooks in LIFO order and exits", func(t *testing.T) {
+		t.Parallel()
+
+		osExecutor := ostest.NewFakeOsExecutor(t)
+		osExecutor.On("Exit", 130).Once()
+
+		log := testlogger.NewRecording()
+		manager := NewManager(osExecutor, log, time.Second)
+
+		var mu sync.Mutex
+		var order []string
+		hook := func(name string) Hook {
+			return func(ctx context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				order = append(order, name)
+
+				return nil
+			}
+		}
+
+		manager.Register("kill port-forwards", hook("kill port-forwards"))
+		manager.Register("delete namespace", hook("delete namespace"))
+
+		manager.Run(func(ctx context.Context) error {
+			manager.signals <- syscall.SIGINT
+			<-ctx.Done()
+
+			// NOTE: The hooks are run once the work in progress is stopped.
+			time.Sleep(10 * time.Millisecond)
+			hook("run")(ctx)
+
+			return nil
+		})
+
+		osExecutor.AssertExpectations(t)
+		assert.Equal(t, []string{"run", "delete namespace", "kill port-forwards"}, order)
+		assert.Equal(t, syscall.SIGINT, manager.Signal())
+		assert.Error(t, manager.Context().Err())
+		log.AssertLogged(t, logger.InfoLevel, "Received interrupt")
+	})
+
+	t.Run("when the run ignores the canceled context, it runs the hooks after the drain timeout", func(t *testing.T) {
+		t.Parallel()
+
+		osExecutor := ostest.NewFakeOsExecutor(t)
+		osExecutor.On("Exit", 143).Once()
+
+		log := testlogger.NewRecording()
+		manager := NewManager(osExecutor, log, 10*time.Millisecond)
+
+		hung := make(chan struct{})
+		defer close(hung)
+
+		cleaned := false
+		manager.Register("cleanup", func(ctx context.Context) error {
+			cleaned = true
+			return nil
+		})
+
+		manager.Run(func(ctx context.Context) error {
+			manager.signals <- syscall.SIGTERM
+			<-hung
+
+			return nil
+		})
+
+		osExecutor.AssertExpectations(t)
+		assert.True(t, cleaned)
+		log.AssertLogged(t, logger.WarnLevel, "Drain timeout 10ms exceeded")
+	})
+
+	t.Run("when a second signal is received during the drain, it exits immediately", func(t *testing.T) {
+		t.Parallel()
+
+		osExecutor := ostest.NewFakeOsExecutor(t)
+		osExecutor.On("Exit", 130).Once()
+
+		manager := NewManager(osExecutor, testlogger.NewRecording(), time.Hour)
+
+		hung := make(chan struct{})
+		defer close(hung)
+
+		cleaned := false
+		manager.Register("cleanup", func(ctx context.Context) error {
+			cleaned = true
+			return nil
+		})
+
+		manager.Run(func(ctx context.Context) error {
+			manager.signals <- syscall.SIGTERM
+			<-ctx.Done()
+			manager.signals <- syscall.SIGINT
+			<-hung
+
+			return nil
+		})
+
+		osExecutor.AssertExpectations(t)
+		assert.False(t, cleaned)
+	})
+
+	t.Run("when the run fails, it runs the hooks and exits with failure", func(t *testing.T) {
+		t.Parallel()
+
+		osExecutor := ostest.NewFakeOsExecutor(t)
+		osExecutor.On("Exit", ExitCodeFailure).Once()
+
+		manager := NewManager(osExecutor, testlogger.NewRecording(), time.Second)
+
+		cleaned := false
+		manager.Register("cleanup", func(ctx context.Context) error {
+			cleaned = true
+			return nil
+		})
+
+		manager.Run(func(ctx context.Context) error {
+			return assert.AnError
+		})
+
+		osExecutor.AssertExpectations(t)
+		assert.True(t, cleaned)
+	})
+
+	t.Run("when the run succeeds, it exits with zero code", func(t *testing.T) {
+		t.Parallel()
+
+		osExecutor := ostest.NewFakeOsExecutor(t)
+		osExecutor.On("Exit", 0).Once()
+
+		manager := NewManager(osExecutor, testlogger.NewRecording(), time.Second)
+
+		manager.Run(func(ctx context.Context) error {
+			return nil
+		})
+
+		osExecutor.AssertExpectations(t)
+	})
+}
+
+func TestManager_Shutdown(t *testing.T) {
+	t.Run("it runs all the hooks and returns the first error", func(t *testing.T) {
+		t.Parallel()
+
+		manager := NewManager(ostest.NewFakeOsExecutor(t), testlogger.NewRecording(), time.Second)
+
+		called := false
+		manager.Register("first", func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+		manager.Register("failing", func(ctx context.Context) error {
+			return assert.AnError
+		})
+
+		err := manager.Shutdown()
+		assert.Error(t, err)
+		assert.True(t, called)
+
+		// NOTE: The hooks are run only once.
+		assert.NoError(t, manager.Shutdown())
+	})
+
+	t.Run("when the drain timeout is exceeded, it does not wait for the hooks", func(t *testing.T) {
+		t.Parallel()
+
+		manager := NewManager(ostest.NewFakeOsExecutor(t), testlogger.NewRecording(), time.Millisecond)
+
+		hung := make(chan struct{})
+		defer close(hung)
+
+		called := false
+		manager.Register("next", func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+		manager.Register("hung", func(ctx context.Context) error {
+			<-hung
+			return nil
+		})
+
+		err := manager.Shutdown()
+		assert.Error(t, err)
+		assert.False(t, called)
+	})
+}
+
+func TestNewManager(t *testing.T) {
+	t.Run("with not positive drain timeout, it uses the default one", func(t *testing.T) {
+		t.Parallel()
+
+		manager := NewManager(ostest.NewFakeOsExecutor(t), testlogger.NewRecording(), 0)
+		assert.Equal(t, 30*time.Second, manager.drainTimeout)
+	})
+}
@@ -0,0 +1,154 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/clock"
+	"github.com/sumup-oss/go-pkgs/retry"
+)
+
+const defaultBackoffMultiplier = 2
+
+// Backoff is a retry policy of NewRetry.
+type Backoff struct {
+	// InitialInterval is the interval before the first retry.
+	InitialInterval time.Duration
+	// MaxInterval caps the interval between retries. Zero means no cap.
+	MaxInterval time.Duration
+	// Multiplier grows the interval after every retry. Zero defaults to 2, one means constant interval.
+	Multiplier float64
+	// Jitter randomizes every interval by up to the given fraction of it, e.g 0.2 is +/-20%.
+	Jitter float64
+	// MaxAttempts is the max number of task runs. Zero means unlimited attempts.
+	MaxAttempts int
+	// IsRetryable classifies the errors that can be retried. Defaults to IsRetryableError.
+	IsRetryable func(err error) bool
+	// Clock waits for the intervals. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+// ExponentialBackoff creates a Backoff doubling the interval from initialInterval up to maxInterval,
+// with 20% jitter and up to maxAttempts task runs.
+func ExponentialBackoff(initialInterval, maxInterval time.Duration, maxAttempts int) Backoff {
+	return Backoff{
+		InitialInterval: initialInterval,
+		MaxInterval:     maxInterval,
+		Multiplier:      defaultBackoffMultiplier,
+		Jitter:          0.2,
+		MaxAttempts:     maxAttempts,
+	}
+}
+
+// ConstantBackoff creates a Backoff with the same interval between up to maxAttempts task runs.
+func ConstantBackoff(interval time.Duration, maxAttempts int) Backoff {
+	return Backoff{
+		InitialInterval: interval,
+		Multiplier:      1,
+		MaxAttempts:     maxAttempts,
+	}
+}
+
+// Interval returns the interval to wait after the failed `attempt`, starting from 1.
+func (b Backoff) Interval(attempt int) time.Duration {
+	return b.exponential().Interval(attempt, 0)
+}
+
+// exponential returns the retry.Exponential of the intervals of the backoff.
+func (b Backoff) exponential() *retry.Exponential {
+	return &retry.Exponential{
+		Initial:    b.InitialInterval,
+		Max:        b.MaxInterval,
+		Multiplier: b.Multiplier,
+		Jitter:     b.Jitter,
+	}
+}
+
+func (b Backoff) isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if b.IsRetryable != nil {
+		return b.IsRetryable(err)
+	}
+
+	return IsRetryableError(err)
+}
+
+type attemptContextKey struct{}
+
+// AttemptFromContext returns the attempt, starting from 1, of the task run by NewRetry with ctx.
+// It's 1 when the task is not retried.
+func AttemptFromContext(ctx context.Context) int {
+	attempt, ok := ctx.Value(attemptContextKey{}).(int)
+	if !ok {
+		return 1
+	}
+
+	return attempt
+}
+
+// NewRetry retries a task with the intervals of the backoff policy until it returns no error,
+// or the returned error is not retryable per the policy.
+// If the task do not complete for the policy MaxAttempts, the task returns MaxRetryExceedError.
+//
+// When the context is done before the task succeeds, the context error is returned.
+// The task is run with the attempt in its context, see AttemptFromContext.
+func NewRetry(fn TaskFunc, policy Backoff) TaskFunc {
+	return func(ctx context.Context) error {
+		attempt := 0
+
+		var taskErr error
+
+		err := retry.Do(ctx, policy.retryPolicy(), func(ctx context.Context) error {
+			attempt++
+
+			taskErr = fn(context.WithValue(ctx, attemptContextKey{}, attempt))
+			if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts && policy.isRetryable(taskErr) {
+				taskErr = NewMaxRetryError(policy.MaxAttempts, taskErr)
+				return retry.Permanent(taskErr)
+			}
+
+			return taskErr
+		})
+
+		// NOTE: Do returns the task error along with the context error, when the context is done while waiting.
+		if ctxErr := ctx.Err(); ctxErr != nil && err != nil && err != taskErr {
+			return ctxErr
+		}
+
+		return err
+	}
+}
+
+// retryPolicy returns the retry.Policy of the backoff. The max attempts are handled by NewRetry,
+// which returns MaxRetryExceedError when they're exceeded.
+func (b Backoff) retryPolicy() retry.Policy {
+	return retry.Policy{
+		Backoff:     b.exponential(),
+		IsRetryable: b.isRetryable,
+		Clock:       b.Clock,
+	}
+}
+
+// RetryDecorator creates a TaskFuncDecorator retrying the decorated task with NewRetry.
+func RetryDecorator(policy Backoff) TaskFuncDecorator {
+	return func(fn TaskFunc) TaskFunc {
+		return NewRetry(fn, policy)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/clock/clocktest"
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+func TestBackoff_Interval(t *testing.T) {
+	t.Run("it grows the interval exponentially up to max interval", func(t *testing.T) {
+		t.Parallel()
+
+		backoff := task.Backoff{InitialInterval: time.Second, MaxInterval: 5 * time.Second}
+
+		assert.Equal(t, time.Second, backoff.Interval(1))
+		assert.Equal(t, 2*time.Second, backoff.Interval(2))
+		assert.Equal(t, 4*time.Second, backoff.Interval(3))
+		assert.Equal(t, 5*time.Second, backoff.Interval(4))
+	})
+
+	t.Run("when max interval is zero, it does not overflow", func(t *testing.T) {
+		t.Parallel()
+
+		backoff := task.ExponentialBackoff(time.Second, 0, 0)
+
+		assert.True(t, backoff.Interval(100) > 0)
+		assert.True(t, backoff.Interval(10000) > 0)
+
+		backoff.Jitter = 0
+		assert.Equal(t, time.Duration(math.MaxInt64), backoff.Interval(10000))
+	})
+
+	t.Run("it randomizes the interval by the jitter", func(t *testing.T) {
+		t.Parallel()
+
+		backoff := task.ExponentialBackoff(time.Second, time.Minute, 0)
+
+		for i := 0; i < 100; i++ {
+			interval := backoff.Interval(2)
+			assert.True(t, interval >= 1600*time.Millisecond && interval <= 2400*time.Millisecond, interval)
+		}
+	})
+}
+
+func TestNewRetry(t *testing.T) {
+	t.Run("it retries the task until it succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		attempts := 0
+		fn := task.NewRetry(func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return task.NewRetryableError(errors.New("fooErr"))
+			}
+
+			return nil
+		}, task.ConstantBackoff(time.Millisecond, 5))
+
+		err := fn(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("it waits for the intervals with the policy clock", func(t *testing.T) {
+		t.Parallel()
+
+		start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		fakeClock := clocktest.NewFakeClock(start)
+		fakeClock.SetAutoAdvance(true)
+
+		policy := task.ConstantBackoff(time.Hour, 3)
+		policy.Clock = fakeClock
+
+		attempts := 0
+		fn := task.NewRetry(func(ctx context.Context) error {
+			attempts++
+			return task.NewRetryableError(errors.New("fooErr"))
+		}, policy)
+
+		err := fn(context.Background())
+		require.IsType(t, &task.MaxRetryExceedError{}, err)
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, start.Add(2*time.Hour), fakeClock.Now())
+	})
+
+	t.Run("when max attempts are exceeded, it returns MaxRetryExceedError", func(t *testing.T) {
+		t.Parallel()
+
+		attempts := 0
+		lastErr := task.NewRetryableError(errors.New("fooErr"))
+		fn := task.NewRetry(func(ctx context.Context) error {
+			attempts++
+			return lastErr
+		}, task.ExponentialBackoff(time.Millisecond, 2*time.Millisecond, 3))
+
+		err := fn(context.Background())
+		require.IsType(t, &task.MaxRetryExceedError{}, err)
+		assert.Equal(t, lastErr, err.(*task.MaxRetryExceedError).Cause())
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("it retries only the errors classified as retryable", func(t *testing.T) {
+		t.Parallel()
+
+		errTransient := errors.New("transient")
+		errFatal := errors.New("fatal")
+
+		attempts := 0
+		policy := task.ConstantBackoff(time.Millisecond, 0)
+		policy.IsRetryable = func(err error) bool {
+			return err == errTransient
+		}
+
+		fn := task.NewTaskFunc(func(ctx context.Context) error {
+			attempts++
+			if attempts < 2 {
+				return errTransient
+			}
+
+			return errFatal
+		}, task.RetryDecorator(policy))
+
+		err := fn(context.Background())
+		assert.Equal(t, errFatal, err)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("when the context is done, it stops retrying and returns the context error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		fn := task.NewRetry(func(ctx context.Context) error {
+			cancel()
+			return task.NewRetryableError(errors.New("fooErr"))
+		}, task.ConstantBackoff(time.Hour, 0))
+
+		err := fn(ctx)
+		assert.Equal(t, context.Canceled, err)
+	})
+}
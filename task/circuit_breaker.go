@@ -0,0 +1,227 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by CircuitBreaker instead of running the task while the circuit is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+var _ TaskInterface = (*CircuitBreaker)(nil)
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState string
+
+const (
+	// CircuitClosed runs the task.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen short-circuits the task runs with ErrCircuitOpen.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen runs a single probe of the task after the cool-down.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitBreakerOptions configures a CircuitBreaker.
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive failures that trips the circuit. Defaults to 5.
+	FailureThreshold int
+	// CoolDown is the duration the circuit stays open before a probe is let through. Defaults to 1 minute.
+	CoolDown time.Duration
+	// SuccessThreshold is the number of consecutive successful probes that close the circuit. Defaults to 1.
+	SuccessThreshold int
+	// IsFailure classifies the errors that count as failures. Defaults to any non-nil error.
+	IsFailure func(err error) bool
+	// OnStateChange is called on every state change, e.g for logging.
+	// It's called without holding the lock of the CircuitBreaker, so it may call its methods.
+	OnStateChange func(from, to CircuitState)
+}
+
+// CircuitBreaker runs a task until it fails FailureThreshold consecutive times,
+// then short-circuits the runs with ErrCircuitOpen for the cool-down period.
+// After the cool-down, the task runs are probed one at a time until SuccessThreshold of them succeed,
+// and a failed probe opens the circuit again.
+type CircuitBreaker struct {
+	fn      TaskFunc
+	options CircuitBreakerOptions
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	successes int
+	openedAt  time.Time
+	probing   bool
+}
+
+// NewCircuitBreaker creates CircuitBreaker instance.
+func NewCircuitBreaker(fn TaskFunc, options CircuitBreakerOptions) *CircuitBreaker {
+	if options.FailureThreshold < 1 {
+		options.FailureThreshold = 5
+	}
+
+	if options.CoolDown <= 0 {
+		options.CoolDown = time.Minute
+	}
+
+	if options.SuccessThreshold < 1 {
+		options.SuccessThreshold = 1
+	}
+
+	if options.IsFailure == nil {
+		options.IsFailure = func(err error) bool {
+			return err != nil
+		}
+	}
+
+	return &CircuitBreaker{
+		fn:      fn,
+		options: options,
+		state:   CircuitClosed,
+	}
+}
+
+// CircuitBreakerDecorator creates a TaskFuncDecorator wrapping the decorated task with NewCircuitBreaker.
+func CircuitBreakerDecorator(options CircuitBreakerOptions) TaskFuncDecorator {
+	return func(fn TaskFunc) TaskFunc {
+		return Task(NewCircuitBreaker(fn, options))
+	}
+}
+
+// Run runs the task unless the circuit is open.
+func (cb *CircuitBreaker) Run(ctx context.Context) error {
+	probe, err := cb.before()
+	if err != nil {
+		return err
+	}
+
+	completed := false
+
+	defer func() {
+		// NOTE: A panicking task counts as failed, so that the probe is not left running forever.
+		if !completed {
+			cb.after(probe, true)
+		}
+	}()
+
+	err = cb.fn(ctx)
+	completed = true
+
+	cb.after(probe, cb.options.IsFailure(err))
+
+	return err
+}
+
+// State returns the current state.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitOpen && time.Since(cb.openedAt) >= cb.options.CoolDown {
+		return CircuitHalfOpen
+	}
+
+	return cb.state
+}
+
+func (cb *CircuitBreaker) before() (bool, error) {
+	var changes circuitStateChanges
+	defer changes.notify(cb.options.OnStateChange)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitOpen {
+		if time.Since(cb.openedAt) < cb.options.CoolDown {
+			return false, ErrCircuitOpen
+		}
+
+		cb.setState(&changes, CircuitHalfOpen)
+	}
+
+	if cb.state == CircuitHalfOpen {
+		// NOTE: Only a single probe runs at a time.
+		if cb.probing {
+			return false, ErrCircuitOpen
+		}
+
+		cb.probing = true
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (cb *CircuitBreaker) after(probe, failed bool) {
+	var changes circuitStateChanges
+	defer changes.notify(cb.options.OnStateChange)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if probe {
+		cb.probing = false
+	}
+
+	if failed {
+		cb.successes = 0
+		cb.failures++
+
+		if cb.state == CircuitHalfOpen || cb.failures >= cb.options.FailureThreshold {
+			cb.openedAt = time.Now()
+			cb.setState(&changes, CircuitOpen)
+		}
+
+		return
+	}
+
+	cb.failures = 0
+
+	if cb.state == CircuitHalfOpen {
+		cb.successes++
+
+		if cb.successes >= cb.options.SuccessThreshold {
+			cb.successes = 0
+			cb.setState(&changes, CircuitClosed)
+		}
+	}
+}
+
+// setState changes the state and records the change to notify after unlocking.
+func (cb *CircuitBreaker) setState(changes *circuitStateChanges, state CircuitState) {
+	if cb.state == state {
+		return
+	}
+
+	*changes = append(*changes, [2]CircuitState{cb.state, state})
+	cb.state = state
+}
+
+// circuitStateChanges are the state changes, as from and to pairs, of a single locked section.
+type circuitStateChanges [][2]CircuitState
+
+func (changes *circuitStateChanges) notify(onStateChange func(from, to CircuitState)) {
+	if onStateChange == nil {
+		return
+	}
+
+	for _, change := range *changes {
+		onStateChange(change[0], change[1])
+	}
+}
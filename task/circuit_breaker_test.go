@@ -0,0 +1,178 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+func TestCircuitBreaker_Run(t *testing.T) {
+	t.Run("it trips after consecutive failures and probes after the cool-down", func(t *testing.T) {
+		t.Parallel()
+
+		var taskErr error
+		runs := 0
+
+		var transitions []task.CircuitState
+		breaker := task.NewCircuitBreaker(func(ctx context.Context) error {
+			runs++
+			return taskErr
+		}, task.CircuitBreakerOptions{
+			FailureThreshold: 2,
+			CoolDown:         20 * time.Millisecond,
+			OnStateChange: func(from, to task.CircuitState) {
+				transitions = append(transitions, to)
+			},
+		})
+
+		taskErr = assert.AnError
+		assert.Equal(t, assert.AnError, breaker.Run(context.Background()))
+		assert.Equal(t, task.CircuitClosed, breaker.State())
+		assert.Equal(t, assert.AnError, breaker.Run(context.Background()))
+		assert.Equal(t, task.CircuitOpen, breaker.State())
+
+		assert.Equal(t, task.ErrCircuitOpen, breaker.Run(context.Background()))
+		assert.Equal(t, 2, runs)
+
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, task.CircuitHalfOpen, breaker.State())
+
+		// NOTE: A failed probe opens the circuit again.
+		assert.Equal(t, assert.AnError, breaker.Run(context.Background()))
+		assert.Equal(t, task.CircuitOpen, breaker.State())
+
+		time.Sleep(20 * time.Millisecond)
+
+		taskErr = nil
+		assert.NoError(t, breaker.Run(context.Background()))
+		assert.Equal(t, task.CircuitClosed, breaker.State())
+		assert.Equal(t, 4, runs)
+
+		assert.Equal(
+			t,
+			[]task.CircuitState{
+				task.CircuitOpen,
+				task.CircuitHalfOpen,
+				task.CircuitOpen,
+				task.CircuitHalfOpen,
+				task.CircuitClosed,
+			},
+			transitions,
+		)
+	})
+
+	t.Run("it runs a single probe at a time", func(t *testing.T) {
+		t.Parallel()
+
+		probe := NewTestTask(nil)
+		failing := true
+
+		breaker := task.NewCircuitBreaker(func(ctx context.Context) error {
+			if failing {
+				failing = false
+				return assert.AnError
+			}
+
+			return probe.Run(ctx)
+		}, task.CircuitBreakerOptions{FailureThreshold: 1, CoolDown: time.Millisecond})
+
+		assert.Equal(t, assert.AnError, breaker.Run(context.Background()))
+		time.Sleep(time.Millisecond)
+
+		done := make(chan error)
+		go func() {
+			done <- breaker.Run(context.Background())
+		}()
+
+		<-probe.RunReady
+		assert.Equal(t, task.ErrCircuitOpen, breaker.Run(context.Background()))
+
+		probe.RunUntil <- nil
+		assert.NoError(t, <-done)
+		assert.Equal(t, task.CircuitClosed, breaker.State())
+	})
+
+	t.Run("it counts only the errors classified as failures", func(t *testing.T) {
+		t.Parallel()
+
+		fn := task.NewTaskFunc(func(ctx context.Context) error {
+			return assert.AnError
+		}, task.CircuitBreakerDecorator(task.CircuitBreakerOptions{
+			FailureThreshold: 1,
+			IsFailure: func(err error) bool {
+				return false
+			},
+		}))
+
+		assert.Equal(t, assert.AnError, fn(context.Background()))
+		assert.Equal(t, assert.AnError, fn(context.Background()))
+	})
+
+	t.Run("it calls OnStateChange without holding its lock", func(t *testing.T) {
+		t.Parallel()
+
+		var breaker *task.CircuitBreaker
+
+		var states []task.CircuitState
+		breaker = task.NewCircuitBreaker(func(ctx context.Context) error {
+			return assert.AnError
+		}, task.CircuitBreakerOptions{
+			FailureThreshold: 1,
+			OnStateChange: func(from, to task.CircuitState) {
+				states = append(states, breaker.State())
+			},
+		})
+
+		assert.Equal(t, assert.AnError, breaker.Run(context.Background()))
+		assert.Equal(t, []task.CircuitState{task.CircuitOpen}, states)
+	})
+
+	t.Run("when the probe panics, it opens the circuit and lets the next probe through", func(t *testing.T) {
+		t.Parallel()
+
+		shouldPanic := true
+		breaker := task.NewCircuitBreaker(func(ctx context.Context) error {
+			if shouldPanic {
+				panic("probe panicked")
+			}
+
+			return nil
+		}, task.CircuitBreakerOptions{
+			FailureThreshold: 1,
+			CoolDown:         20 * time.Millisecond,
+		})
+
+		assert.Panics(t, func() {
+			_ = breaker.Run(context.Background())
+		})
+
+		time.Sleep(20 * time.Millisecond)
+		assert.Panics(t, func() {
+			_ = breaker.Run(context.Background())
+		})
+		assert.Equal(t, task.CircuitOpen, breaker.State())
+
+		time.Sleep(20 * time.Millisecond)
+		shouldPanic = false
+		assert.NoError(t, breaker.Run(context.Background()))
+		assert.Equal(t, task.CircuitClosed, breaker.State())
+	})
+}
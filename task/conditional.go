@@ -0,0 +1,65 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type skipRecorderKey struct{}
+
+type skipRecorder struct {
+	skipped int32
+}
+
+// withSkipRecorder returns a context that records whether the task run with it was skipped.
+func withSkipRecorder(ctx context.Context) (context.Context, *skipRecorder) {
+	recorder := &skipRecorder{}
+
+	return context.WithValue(ctx, skipRecorderKey{}, recorder), recorder
+}
+
+func (r *skipRecorder) isSkipped() bool {
+	return atomic.LoadInt32(&r.skipped) == 1
+}
+
+// MarkSkipped marks the task run with ctx as skipped, so that it is reported with TaskSkipped status
+// in the Graph summary instead of TaskSucceeded. The dependents of a skipped task are still run.
+func MarkSkipped(ctx context.Context) {
+	if recorder, ok := ctx.Value(skipRecorderKey{}).(*skipRecorder); ok {
+		atomic.StoreInt32(&recorder.skipped, 1)
+	}
+}
+
+// NewConditional runs a task only when the predicate returns true,
+// e.g run the migration job only if the schema version changed.
+// Otherwise the task is marked as skipped with MarkSkipped and returns no error.
+// When the predicate fails, its error is returned.
+func NewConditional(predicate func(ctx context.Context) (bool, error), fn TaskFunc) TaskFunc {
+	return func(ctx context.Context) error {
+		ok, err := predicate(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			MarkSkipped(ctx)
+			return nil
+		}
+
+		return fn(ctx)
+	}
+}
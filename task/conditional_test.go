@@ -0,0 +1,73 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+func TestNewConditional(t *testing.T) {
+	condition := func(ok bool, err error) func(ctx context.Context) (bool, error) {
+		return func(ctx context.Context) (bool, error) {
+			return ok, err
+		}
+	}
+
+	t.Run("it runs the task when the predicate is true", func(t *testing.T) {
+		t.Parallel()
+
+		fn := task.NewConditional(condition(true, nil), func(ctx context.Context) error {
+			return assert.AnError
+		})
+
+		assert.Equal(t, assert.AnError, fn(context.Background()))
+	})
+
+	t.Run("when the predicate fails, it returns its error", func(t *testing.T) {
+		t.Parallel()
+
+		ran := false
+		fn := task.NewConditional(condition(true, assert.AnError), func(ctx context.Context) error {
+			ran = true
+			return nil
+		})
+
+		assert.Equal(t, assert.AnError, fn(context.Background()))
+		assert.False(t, ran)
+	})
+
+	t.Run("when the predicate is false, it is reported as skipped and its dependents run", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := &runRecorder{}
+		graph := task.NewGraph(task.FailFast)
+
+		require.NoError(t, graph.Add("migrate", task.NewConditional(condition(false, nil), recorder.task("migrate", nil))))
+		require.NoError(t, graph.Add("apply", recorder.task("apply", nil), "migrate"))
+
+		summary, err := graph.Run(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"apply"}, recorder.runs)
+		assert.Equal(t, task.TaskSkipped, summary.Result("migrate").Status)
+		assert.Equal(t, task.TaskSucceeded, summary.Result("apply").Status)
+	})
+}
@@ -0,0 +1,255 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchLimit bounds the search of the next activation, e.g for `0 0 30 2 *`.
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+var (
+	_ Schedule = (*CronSchedule)(nil)
+	_ Schedule = (*IntervalSchedule)(nil)
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronDayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: cronMonthNames},
+	// NOTE: Both 0 and 7 are Sunday.
+	{name: "day of week", min: 0, max: 7, names: cronDayNames},
+}
+
+// Schedule specify the activation times of a scheduled task.
+type Schedule interface {
+	// Next returns the next activation time after t.
+	// Zero time means there is no next activation.
+	Next(t time.Time) time.Time
+}
+
+// IntervalSchedule activates at fixed intervals.
+type IntervalSchedule struct {
+	interval time.Duration
+}
+
+// Every creates a Schedule activating every interval.
+func Every(interval time.Duration) *IntervalSchedule {
+	return &IntervalSchedule{
+		interval: interval,
+	}
+}
+
+func (s *IntervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// CronSchedule activates per a cron expression.
+type CronSchedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	// anyDay is true when either the day of month or the day of week is `*`.
+	// Otherwise a day matches when it matches any of them, same as in cron.
+	anyDay bool
+}
+
+// ParseCron parses a standard 5-field cron expression, `minute hour day-of-month month day-of-week`,
+// supporting `*`, lists, ranges, steps and month and day names, e.g `*/15 2-4 * JAN-MAR MON,FRI`.
+//
+// The `@yearly`, `@monthly`, `@weekly`, `@daily`, `@hourly` descriptors are supported,
+// as well as `@every <duration>`, e.g `@every 1h30m`, that returns an IntervalSchedule.
+func ParseCron(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if strings.HasPrefix(expr, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid cron interval %s", expr)
+		}
+
+		return Every(interval), nil
+	}
+
+	if descriptor, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = descriptor
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("invalid cron expression %q, expected %d fields", expr, len(cronFields))
+	}
+
+	bits := make([]uint64, len(cronFields))
+	for idx, field := range cronFields {
+		fieldBits, err := field.parse(parts[idx])
+		if err != nil {
+			return nil, err
+		}
+
+		bits[idx] = fieldBits
+	}
+
+	// NOTE: Map Sunday as 7 to 0, as used by `time.Weekday`.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &CronSchedule{
+		minute:     bits[0],
+		hour:       bits[1],
+		dayOfMonth: bits[2],
+		month:      bits[3],
+		dayOfWeek:  bits[4],
+		anyDay:     strings.HasPrefix(parts[2], "*") || strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func (field cronField) parse(expr string) (uint64, error) {
+	var bits uint64
+
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, step := item, 1
+
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			var err error
+
+			rangeExpr = item[:idx]
+			step, err = strconv.Atoi(item[idx+1:])
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid cron %s step %s", field.name, item)
+			}
+		}
+
+		start, end := field.min, field.max
+
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			idx := strings.Index(rangeExpr, "-")
+
+			var err error
+			start, err = field.value(rangeExpr[:idx])
+			if err != nil {
+				return 0, err
+			}
+
+			end, err = field.value(rangeExpr[idx+1:])
+			if err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			start, err = field.value(rangeExpr)
+			if err != nil {
+				return 0, err
+			}
+
+			// NOTE: A single value with step, e.g `5/10`, ranges to the max value.
+			if step == 1 {
+				end = start
+			}
+		}
+
+		if start > end {
+			return 0, fmt.Errorf("invalid cron %s range %s", field.name, item)
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+func (field cronField) value(expr string) (int, error) {
+	if value, ok := field.names[strings.ToLower(expr)]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(expr)
+	if err != nil || value < field.min || value > field.max {
+		return 0, fmt.Errorf("invalid cron %s value %s", field.name, expr)
+	}
+
+	return value, nil
+}
+
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := next.Add(cronSearchLimit)
+
+	for next.Before(limit) {
+		switch {
+		case s.month&(1<<uint(next.Month())) == 0:
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !s.matchesDay(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case s.hour&(1<<uint(next.Hour())) == 0:
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case s.minute&(1<<uint(next.Minute())) == 0:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+
+	return time.Time{}
+}
+
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if s.anyDay {
+		return dayOfMonth && dayOfWeek
+	}
+
+	return dayOfMonth || dayOfWeek
+}
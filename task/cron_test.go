@@ -0,0 +1,80 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+func TestParseCron(t *testing.T) {
+	// NOTE: Saturday.
+	now := time.Date(2019, 11, 23, 10, 17, 30, 0, time.UTC)
+
+	testCases := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2019, 11, 23, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2019, 11, 23, 10, 30, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2019, 11, 23, 10, 25, 0, 0, time.UTC)},
+		{"0 2-4 * * *", time.Date(2019, 11, 24, 2, 0, 0, 0, time.UTC)},
+		{"30 9,18 * * *", time.Date(2019, 11, 23, 18, 30, 0, 0, time.UTC)},
+		{"0 0 * * MON-FRI", time.Date(2019, 11, 25, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2019, 11, 24, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 JAN *", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * MON", time.Date(2019, 11, 25, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2019, 11, 24, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2019, 11, 23, 11, 0, 0, 0, time.UTC)},
+		{"@every 90m", time.Date(2019, 11, 23, 11, 47, 30, 0, time.UTC)},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.expr, func(t *testing.T) {
+			t.Parallel()
+
+			schedule, err := task.ParseCron(testCase.expr)
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.expected, schedule.Next(now))
+		})
+	}
+
+	t.Run("it returns zero time when there is no next activation", func(t *testing.T) {
+		t.Parallel()
+
+		schedule, err := task.ParseCron("0 0 30 2 *")
+		require.NoError(t, err)
+
+		assert.True(t, schedule.Next(now).IsZero())
+	})
+
+	t.Run("it returns error for invalid expressions", func(t *testing.T) {
+		t.Parallel()
+
+		for _, expr := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "* * * FOO *", "@every x"} {
+			_, err := task.ParseCron(expr)
+			assert.Error(t, err, expr)
+		}
+	})
+}
@@ -0,0 +1,341 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FailurePolicy specify how a Graph or a Group handles a failed task.
+type FailurePolicy int
+
+const (
+	// FailFast cancels all the running tasks and does not start new tasks on the first task failure.
+	FailFast FailurePolicy = iota
+	// ContinueOnError keeps the other tasks running. A Graph skips only the tasks that depend on the failed task.
+	ContinueOnError
+)
+
+// TaskStatus is the status of a Graph task after the run.
+type TaskStatus string
+
+const (
+	TaskSucceeded TaskStatus = "succeeded"
+	TaskFailed    TaskStatus = "failed"
+	// TaskSkipped is the status of the tasks that depend on a failed task,
+	// and of the tasks that were skipped on their own, e.g by NewConditional.
+	TaskSkipped TaskStatus = "skipped"
+	// TaskCanceled is the status of the tasks not started due to cancellation or FailFast.
+	TaskCanceled TaskStatus = "canceled"
+)
+
+// TaskResult is the result of a single Graph task.
+type TaskResult struct {
+	Name      string
+	Status    TaskStatus
+	Err       error
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+// GraphSummary is the per-task result of a Graph run, in topological order.
+type GraphSummary struct {
+	Results []*TaskResult
+}
+
+// Result returns the result of the task `name` or nil if there is no such task.
+func (s *GraphSummary) Result(name string) *TaskResult {
+	for _, result := range s.Results {
+		if result.Name == name {
+			return result
+		}
+	}
+
+	return nil
+}
+
+// String returns human-readable summary with a line per task.
+func (s *GraphSummary) String() string {
+	lines := make([]string, len(s.Results))
+	for idx, result := range s.Results {
+		line := fmt.Sprintf("%s: %s", result.Name, result.Status)
+
+		if result.Status == TaskSucceeded || result.Status == TaskFailed {
+			line += fmt.Sprintf(" (%v)", result.Duration)
+		}
+
+		if result.Err != nil {
+			line += fmt.Sprintf(": %v", result.Err)
+		}
+
+		lines[idx] = line
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// GraphError is returned when some of the Graph tasks failed.
+type GraphError struct {
+	failed   []string
+	firstErr error
+}
+
+// NewGraphError creates GraphError instance.
+func NewGraphError(failed []string, firstErr error) error {
+	return &GraphError{
+		failed:   failed,
+		firstErr: firstErr,
+	}
+}
+
+// Error returns the error message.
+func (err *GraphError) Error() string {
+	return fmt.Sprintf("tasks %s failed, first err: %v", strings.Join(err.failed, ", "), err.firstErr)
+}
+
+// Failed returns the names of the failed tasks.
+func (err *GraphError) Failed() []string {
+	return err.failed
+}
+
+// Cause returns the error of the first failed task.
+func (err *GraphError) Cause() error {
+	return err.firstErr
+}
+
+type graphNode struct {
+	name      string
+	fn        TaskFunc
+	dependsOn []string
+}
+
+// Graph runs tasks that depend on each other, e.g build -> push -> apply -> wait -> smoke-test.
+//
+// The tasks are run in topological order with maximal parallelism,
+// i.e. every task is started in new goroutine as soon as all of its dependencies succeeded.
+// The panics of the tasks are recovered and handled as PanicError task failures.
+type Graph struct {
+	policy FailurePolicy
+	nodes  map[string]*graphNode
+	// names retains the order of adding for deterministic runs.
+	names []string
+}
+
+// NewGraph creates new task graph instance.
+func NewGraph(policy FailurePolicy) *Graph {
+	return &Graph{
+		policy: policy,
+		nodes:  make(map[string]*graphNode),
+	}
+}
+
+// Add adds the task `name` that is run after the `dependsOn` tasks succeed.
+// The dependencies can be added after the task, they are resolved on Run.
+func (g *Graph) Add(name string, fn TaskFunc, dependsOn ...string) error {
+	if name == "" {
+		return fmt.Errorf("task name is blank")
+	}
+
+	if _, ok := g.nodes[name]; ok {
+		return fmt.Errorf("task %s is already added", name)
+	}
+
+	g.nodes[name] = &graphNode{
+		name:      name,
+		fn:        fn,
+		dependsOn: dependsOn,
+	}
+	g.names = append(g.names, name)
+
+	return nil
+}
+
+// Run runs the graph tasks and waits for them to finish.
+//
+// Returns GraphError when any of the tasks failed, or the context error when the context
+// is done before all tasks are run. The summary is returned also on error,
+// except when the graph is invalid, i.e. it has unknown dependencies or cycles.
+func (g *Graph) Run(ctx context.Context) (*GraphSummary, error) {
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(map[string]*TaskResult, len(order))
+	pendingDeps := make(map[string]int, len(order))
+	dependents := make(map[string][]string, len(order))
+
+	for _, name := range order {
+		results[name] = &TaskResult{Name: name}
+		pendingDeps[name] = len(g.nodes[name].dependsOn)
+
+		for _, dep := range g.nodes[name].dependsOn {
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	done := make(chan *TaskResult)
+	running := 0
+	stopped := false
+
+	start := func(name string) {
+		running++
+
+		go func(node *graphNode, result *TaskResult) {
+			taskCtx, skipRecorder := withSkipRecorder(runCtx)
+
+			result.StartedAt = time.Now()
+			result.Err = Recover(node.fn)(taskCtx)
+			result.Duration = time.Since(result.StartedAt)
+
+			if result.Err == nil && skipRecorder.isSkipped() {
+				result.Status = TaskSkipped
+			}
+
+			done <- result
+		}(g.nodes[name], results[name])
+	}
+
+	for _, name := range order {
+		if pendingDeps[name] == 0 {
+			start(name)
+		}
+	}
+
+	var failed []string
+	var firstErr error
+
+	for running > 0 {
+		result := <-done
+		running--
+
+		if result.Err != nil {
+			result.Status = TaskFailed
+			failed = append(failed, result.Name)
+
+			if firstErr == nil {
+				firstErr = result.Err
+			}
+
+			if g.policy == FailFast {
+				stopped = true
+				cancel()
+			}
+
+			continue
+		}
+
+		if result.Status == "" {
+			result.Status = TaskSucceeded
+		}
+
+		for _, dependent := range dependents[result.Name] {
+			pendingDeps[dependent]--
+
+			if pendingDeps[dependent] == 0 && !stopped && runCtx.Err() == nil {
+				start(dependent)
+			}
+		}
+	}
+
+	summary := &GraphSummary{Results: make([]*TaskResult, len(order))}
+	// blocked are the tasks that failed or were not started due to a failed dependency.
+	blocked := make(map[string]bool)
+
+	for idx, name := range order {
+		result := results[name]
+		summary.Results[idx] = result
+
+		if result.Status == TaskFailed {
+			blocked[name] = true
+		}
+
+		if result.Status != "" {
+			continue
+		}
+
+		// NOTE: The dependencies precede in topological order, so their status is final.
+		result.Status = TaskCanceled
+		for _, dep := range g.nodes[name].dependsOn {
+			if blocked[dep] {
+				result.Status = TaskSkipped
+				blocked[name] = true
+
+				break
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return summary, NewGraphError(failed, firstErr)
+	}
+
+	return summary, ctx.Err()
+}
+
+// topologicalOrder returns the task names ordered so that every task follows its dependencies.
+func (g *Graph) topologicalOrder() ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(g.nodes))
+	order := make([]string, 0, len(g.nodes))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("task dependency cycle %s", strings.Join(append(path, name), " -> "))
+		}
+
+		state[name] = visiting
+
+		for _, dep := range g.nodes[name].dependsOn {
+			if _, ok := g.nodes[dep]; !ok {
+				return fmt.Errorf("task %s depends on unknown task %s", name, dep)
+			}
+
+			err := visit(dep, append(path, name))
+			if err != nil {
+				return err
+			}
+		}
+
+		state[name] = visited
+		order = append(order, name)
+
+		return nil
+	}
+
+	for _, name := range g.names {
+		err := visit(name, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
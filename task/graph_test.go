@@ -0,0 +1,209 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+type runRecorder struct {
+	mu   sync.Mutex
+	runs []string
+}
+
+func (r *runRecorder) task(name string, err error) task.TaskFunc {
+	return func(ctx context.Context) error {
+		r.mu.Lock()
+		r.runs = append(r.runs, name)
+		r.mu.Unlock()
+
+		return err
+	}
+}
+
+func (r *runRecorder) index(name string) int {
+	for idx, run := range r.runs {
+		if run == name {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+func TestGraph_Run(t *testing.T) {
+	t.Run("it runs the tasks after their dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := &runRecorder{}
+		graph := task.NewGraph(task.FailFast)
+
+		// NOTE: Added out of order on purpose.
+		require.NoError(t, graph.Add("smoke-test", recorder.task("smoke-test", nil), "wait"))
+		require.NoError(t, graph.Add("build", recorder.task("build", nil)))
+		require.NoError(t, graph.Add("push", recorder.task("push", nil), "build"))
+		require.NoError(t, graph.Add("migrate", recorder.task("migrate", nil)))
+		require.NoError(t, graph.Add("apply", recorder.task("apply", nil), "push", "migrate"))
+		require.NoError(t, graph.Add("wait", recorder.task("wait", nil), "apply"))
+
+		summary, err := graph.Run(context.Background())
+		require.NoError(t, err)
+
+		assert.Len(t, recorder.runs, 6)
+		assert.True(t, recorder.index("build") < recorder.index("push"))
+		assert.True(t, recorder.index("push") < recorder.index("apply"))
+		assert.True(t, recorder.index("migrate") < recorder.index("apply"))
+		assert.True(t, recorder.index("apply") < recorder.index("wait"))
+		assert.True(t, recorder.index("wait") < recorder.index("smoke-test"))
+
+		require.Len(t, summary.Results, 6)
+		for _, result := range summary.Results {
+			assert.Equal(t, task.TaskSucceeded, result.Status, result.Name)
+		}
+	})
+
+	t.Run("it runs independent tasks in parallel", func(t *testing.T) {
+		t.Parallel()
+
+		graph := task.NewGraph(task.FailFast)
+		foo := NewTestTask(nil)
+		bar := NewTestTask(nil)
+
+		require.NoError(t, graph.Add("foo", foo.Run))
+		require.NoError(t, graph.Add("bar", bar.Run))
+
+		go func() {
+			// NOTE: Both tasks are started before any of them completes.
+			<-foo.RunReady
+			<-bar.RunReady
+			foo.RunUntil <- nil
+			bar.RunUntil <- nil
+		}()
+
+		_, err := graph.Run(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("when a task fails with fail fast policy, it cancels the running tasks", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := &runRecorder{}
+		graph := task.NewGraph(task.FailFast)
+		slow := NewTestTask(nil)
+
+		require.NoError(t, graph.Add("slow", slow.Run))
+		require.NoError(t, graph.Add("build", func(ctx context.Context) error {
+			<-slow.RunReady
+			return assert.AnError
+		}))
+		require.NoError(t, graph.Add("after-slow", recorder.task("after-slow", nil), "slow"))
+		require.NoError(t, graph.Add("push", recorder.task("push", nil), "build"))
+
+		summary, err := graph.Run(context.Background())
+		require.Error(t, err)
+
+		graphErr, ok := err.(*task.GraphError)
+		require.True(t, ok)
+		assert.Equal(t, []string{"build"}, graphErr.Failed())
+		assert.Equal(t, assert.AnError, graphErr.Cause())
+
+		assert.Empty(t, recorder.runs)
+		assert.Equal(t, 1, slow.StopCount)
+		assert.Equal(t, task.TaskFailed, summary.Result("build").Status)
+		assert.Equal(t, task.TaskSucceeded, summary.Result("slow").Status)
+		assert.Equal(t, task.TaskCanceled, summary.Result("after-slow").Status)
+		assert.Equal(t, task.TaskSkipped, summary.Result("push").Status)
+	})
+
+	t.Run("when a task fails with continue policy, it skips only its dependents", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := &runRecorder{}
+		graph := task.NewGraph(task.ContinueOnError)
+
+		require.NoError(t, graph.Add("build", recorder.task("build", assert.AnError)))
+		require.NoError(t, graph.Add("push", recorder.task("push", nil), "build"))
+		require.NoError(t, graph.Add("apply", recorder.task("apply", nil), "push"))
+		require.NoError(t, graph.Add("lint", recorder.task("lint", nil)))
+		require.NoError(t, graph.Add("docs", recorder.task("docs", nil), "lint"))
+
+		summary, err := graph.Run(context.Background())
+		assert.Error(t, err)
+
+		assert.ElementsMatch(t, []string{"build", "lint", "docs"}, recorder.runs)
+		assert.Equal(t, task.TaskFailed, summary.Result("build").Status)
+		assert.Equal(t, assert.AnError, summary.Result("build").Err)
+		assert.Equal(t, task.TaskSkipped, summary.Result("push").Status)
+		assert.Equal(t, task.TaskSkipped, summary.Result("apply").Status)
+		assert.Equal(t, task.TaskSucceeded, summary.Result("docs").Status)
+		assert.Contains(t, summary.String(), "push: skipped")
+	})
+
+	t.Run("when the context is canceled, it does not start new tasks", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := &runRecorder{}
+		graph := task.NewGraph(task.FailFast)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		require.NoError(t, graph.Add("build", func(context.Context) error {
+			cancel()
+			return nil
+		}))
+		require.NoError(t, graph.Add("push", recorder.task("push", nil), "build"))
+
+		summary, err := graph.Run(ctx)
+		assert.Equal(t, context.Canceled, err)
+		assert.Empty(t, recorder.runs)
+		assert.Equal(t, task.TaskCanceled, summary.Result("push").Status)
+	})
+
+	t.Run("it returns error for invalid graph", func(t *testing.T) {
+		t.Parallel()
+
+		graph := task.NewGraph(task.FailFast)
+		require.NoError(t, graph.Add("build", nil, "apply"))
+		require.NoError(t, graph.Add("push", nil, "build"))
+		require.NoError(t, graph.Add("apply", nil, "push"))
+
+		_, err := graph.Run(context.Background())
+		assert.EqualError(t, err, "task dependency cycle build -> apply -> push -> build")
+
+		graph = task.NewGraph(task.FailFast)
+		require.NoError(t, graph.Add("push", nil, "build"))
+
+		_, err = graph.Run(context.Background())
+		assert.EqualError(t, err, "task push depends on unknown task build")
+	})
+}
+
+func TestGraph_Add(t *testing.T) {
+	t.Run("it returns error for duplicate task", func(t *testing.T) {
+		t.Parallel()
+
+		graph := task.NewGraph(task.FailFast)
+		require.NoError(t, graph.Add("build", nil))
+
+		assert.Error(t, graph.Add("build", nil))
+		assert.Error(t, graph.Add("", nil))
+	})
+}
@@ -17,35 +17,73 @@ package task
 import (
 	"context"
 	"sync"
+
+	"github.com/sumup-oss/go-pkgs/concurrency"
+	"github.com/sumup-oss/go-pkgs/errors"
 )
 
 // Group is used to wait for a group of tasks to finish.
 //
-// It will stop all the tasks on the first task failure, and the Wait() method will return only the
-// first encountered error.
+// It will stop all the tasks on the first task failure, unless the ContinueOnError policy is set,
+// and the Wait() method will return only the first encountered error.
+// All the encountered errors are returned by the Errors() method.
+//
+// The panics of the tasks are recovered and handled as PanicError task failures.
 type Group struct {
 	wg         sync.WaitGroup
 	ctx        context.Context
 	cancelFunc context.CancelFunc
+	// limit is a semaphore of the running tasks, nil when the concurrency is not limited.
+	limit  *concurrency.Semaphore
+	policy FailurePolicy
 
-	// mu protects the firstRunErr
+	// mu protects the firstRunErr and runErrs
 	mu          sync.Mutex
 	firstRunErr error
+	runErrs     []error
 }
 
 // NewGroup creates new task group instance.
 func NewGroup() *Group {
-	ctx, cancel := context.WithCancel(context.Background())
+	return NewGroupWithContext(context.Background())
+}
+
+// NewGroupWithContext creates new task group instance with tasks context derived from ctx.
+// When ctx is done all the tasks are canceled.
+func NewGroupWithContext(ctx context.Context) *Group {
+	groupCtx, cancel := context.WithCancel(ctx)
 
 	return &Group{
-		ctx:        ctx,
+		ctx:        groupCtx,
 		cancelFunc: cancel,
 	}
 }
 
+// SetLimit limits the number of concurrently running tasks, e.g for throttling.
+// The tasks above the limit wait for a running task to finish before they are started.
+// Zero or negative limit removes the limit.
+//
+// It must be called before any task is scheduled with the Group.Go() method.
+func (g *Group) SetLimit(limit int) {
+	if limit < 1 {
+		g.limit = nil
+		return
+	}
+
+	g.limit = concurrency.NewSemaphore(int64(limit))
+}
+
+// SetPolicy sets the policy on task failure, FailFast by default.
+// With ContinueOnError, a failed task does not cancel the other tasks, e.g for a multi-tenant sweep.
+//
+// It must be called before any task is scheduled with the Group.Go() method.
+func (g *Group) SetPolicy(policy FailurePolicy) {
+	g.policy = policy
+}
+
 // Go runs tasks in the group.
 //
-// Every task is run in new goroutine.
+// Every task is run in new goroutine, once there is a free slot when the concurrency is limited.
 // When a task returns an error, all the tasks in the group are canceled.
 //
 // Typically one should schedule tasks with the Group.Go() method and then wait for all of them to
@@ -60,10 +98,30 @@ func (g *Group) Go(tasks ...TaskFunc) {
 		go func(fn TaskFunc) {
 			defer g.wg.Done()
 
-			err := fn(g.ctx)
-			if err != nil {
-				g.cancelWithError(err)
+			if g.limit != nil {
+				if g.limit.Acquire(g.ctx, 1) != nil {
+					return
+				}
+
+				defer g.limit.Release(1)
+
+				// NOTE: The group might be canceled while waiting for the limit.
+				if g.ctx.Err() != nil {
+					return
+				}
 			}
+
+			err := Recover(fn)(g.ctx)
+			if err == nil {
+				return
+			}
+
+			if g.policy == ContinueOnError {
+				g.recordError(err)
+				return
+			}
+
+			g.cancelWithError(err)
 		}(fn)
 	}
 }
@@ -84,20 +142,46 @@ func (g *Group) Wait(ctx context.Context) error {
 	}
 
 	g.wg.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	return g.firstRunErr
 }
 
+// Errors returns all the errors encountered so far, in the order they were encountered.
+// Typically called after the Group.Wait() method, to report all the failed tasks.
+func (g *Group) Errors() []error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	errs := make([]error, len(g.runErrs))
+	copy(errs, g.runErrs)
+
+	return errs
+}
+
+// Err returns all the errors encountered so far as errors.MultiError, or nil when there are none.
+// Typically called after the Group.Wait() method with the ContinueOnError policy, to fail with all the failed tasks.
+func (g *Group) Err() error {
+	return errors.Append(nil, g.Errors()...).ErrorOrNil()
+}
+
 func (g *Group) cancelWithError(err error) {
+	g.recordError(err)
+	g.cancelFunc()
+}
+
+func (g *Group) recordError(err error) {
 	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	// NOTE: only the first error is retained.
+	// NOTE: only the first error is returned by Wait.
 	if g.firstRunErr == nil {
 		g.firstRunErr = err
 	}
 
-	g.mu.Unlock()
-
-	g.cancelFunc()
+	g.runErrs = append(g.runErrs, err)
 }
 
 // Cancel cancels all the tasks.
@@ -16,10 +16,14 @@ package task_test
 
 import (
 	"context"
+	stdErrors "errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/sumup-oss/go-pkgs/errors"
 	"github.com/sumup-oss/go-pkgs/task"
 )
 
@@ -143,6 +147,149 @@ func TestGroup_Go(t *testing.T) {
 	})
 }
 
+func TestGroup_SetLimit(t *testing.T) {
+	t.Run("it runs at most limit tasks concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		group := task.NewGroup()
+		group.SetLimit(2)
+
+		var running, maxRunning int32
+		tasks := make([]task.TaskFunc, 10)
+		for idx := range tasks {
+			tasks[idx] = func(ctx context.Context) error {
+				current := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+						break
+					}
+				}
+
+				return nil
+			}
+		}
+
+		group.Go(tasks...)
+
+		err := group.Wait(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, atomic.LoadInt32(&maxRunning) <= 2)
+	})
+
+	t.Run("when the group is canceled, it does not start the waiting tasks", func(t *testing.T) {
+		t.Parallel()
+
+		group := task.NewGroup()
+		group.SetLimit(1)
+
+		foo := NewTestTask(nil)
+		bar := NewTestTask(nil)
+
+		group.Go(foo.Run, bar.Run)
+
+		var first *TestTask
+		select {
+		case <-foo.RunReady:
+			first = foo
+		case <-bar.RunReady:
+			first = bar
+		}
+
+		first.RunUntil <- assert.AnError
+
+		err := group.Wait(context.Background())
+		assert.Equal(t, assert.AnError, err)
+		assert.Equal(t, 1, foo.RunCount+bar.RunCount)
+	})
+}
+
+func TestNewGroupWithContext(t *testing.T) {
+	t.Run("when the parent context is canceled, it cancels all the tasks", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		group := task.NewGroupWithContext(ctx)
+		foo := NewTestTask(nil)
+
+		group.Go(foo.Run)
+		<-foo.RunReady
+
+		cancel()
+
+		err := group.Wait(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, foo.StopCount)
+	})
+}
+
+func TestGroup_Errors(t *testing.T) {
+	t.Run("it returns all the task errors", func(t *testing.T) {
+		t.Parallel()
+
+		group := task.NewGroup()
+		fooErr := stdErrors.New("foo failed")
+		barErr := stdErrors.New("bar failed")
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+
+		failing := func(err error) task.TaskFunc {
+			return func(ctx context.Context) error {
+				// NOTE: Both tasks fail regardless of the cancellation by the first failure.
+				wg.Done()
+				wg.Wait()
+
+				return err
+			}
+		}
+
+		group.Go(failing(fooErr), failing(barErr))
+
+		err := group.Wait(context.Background())
+		assert.Error(t, err)
+		assert.ElementsMatch(t, []error{fooErr, barErr}, group.Errors())
+	})
+}
+
+func TestGroup_Err(t *testing.T) {
+	t.Run("it returns all the task errors as one error", func(t *testing.T) {
+		t.Parallel()
+
+		group := task.NewGroup()
+		group.SetPolicy(task.ContinueOnError)
+
+		fooErr := stdErrors.New("foo failed")
+		barErr := stdErrors.New("bar failed")
+
+		group.Go(
+			func(ctx context.Context) error { return fooErr },
+			func(ctx context.Context) error { return nil },
+			func(ctx context.Context) error { return barErr },
+		)
+
+		_ = group.Wait(context.Background())
+
+		err := group.Err()
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, fooErr))
+		assert.True(t, errors.Is(err, barErr))
+		assert.Contains(t, err.Error(), "2 errors occurred")
+	})
+
+	t.Run("when no task fails, it returns nil", func(t *testing.T) {
+		t.Parallel()
+
+		group := task.NewGroup()
+		group.Go(func(ctx context.Context) error { return nil })
+
+		assert.Nil(t, group.Wait(context.Background()))
+		assert.Nil(t, group.Err())
+	})
+}
+
 func TestGroup_Cancel(t *testing.T) {
 	t.Run("it cancels all the tasks", func(t *testing.T) {
 		t.Parallel()
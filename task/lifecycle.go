@@ -0,0 +1,111 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"time"
+)
+
+var _ MetricsSink = (*NopMetricsSink)(nil)
+
+// TaskEvent describes a single task run to the lifecycle hooks.
+type TaskEvent struct {
+	Name string
+	// Attempt is the number of the run, starting from 1, e.g when the task is retried.
+	Attempt   int
+	StartedAt time.Time
+	// Duration and Err are set only after the run.
+	Duration time.Duration
+	Err      error
+}
+
+// LifecycleHooks are called around every run of a task. Nil hooks are not called.
+type LifecycleHooks struct {
+	OnStart   func(event *TaskEvent)
+	OnSuccess func(event *TaskEvent)
+	OnFailure func(event *TaskEvent)
+}
+
+// MetricsSink receives the task metrics, e.g to export them as Prometheus metrics.
+type MetricsSink interface {
+	// IncTaskAttempts is called when a task run starts.
+	IncTaskAttempts(name string)
+	// ObserveTaskDuration is called when a task run finishes.
+	ObserveTaskDuration(name string, succeeded bool, duration time.Duration)
+}
+
+// NopMetricsSink is a MetricsSink that discards the metrics.
+type NopMetricsSink struct{}
+
+func (s *NopMetricsSink) IncTaskAttempts(name string) {}
+
+func (s *NopMetricsSink) ObserveTaskDuration(name string, succeeded bool, duration time.Duration) {}
+
+// WithLifecycle creates a TaskFuncDecorator calling the hooks around every run of the decorated task.
+//
+// When the task is decorated with a retry decorator,
+// e.g `NewTaskFunc(fn, RetryDecorator(policy), WithLifecycle("deploy", hooks))`,
+// the hooks are called for every retry, with the attempt of the retry, see AttemptFromContext.
+func WithLifecycle(name string, hooks LifecycleHooks) TaskFuncDecorator {
+	return func(fn TaskFunc) TaskFunc {
+		return func(ctx context.Context) error {
+			event := &TaskEvent{
+				Name:      name,
+				Attempt:   AttemptFromContext(ctx),
+				StartedAt: time.Now(),
+			}
+
+			if hooks.OnStart != nil {
+				hooks.OnStart(event)
+			}
+
+			err := fn(ctx)
+
+			event.Duration = time.Since(event.StartedAt)
+			event.Err = err
+
+			if err != nil {
+				if hooks.OnFailure != nil {
+					hooks.OnFailure(event)
+				}
+
+				return err
+			}
+
+			if hooks.OnSuccess != nil {
+				hooks.OnSuccess(event)
+			}
+
+			return nil
+		}
+	}
+}
+
+// WithMetrics creates a TaskFuncDecorator emitting the attempts and duration metrics
+// of every run of the decorated task to the sink.
+func WithMetrics(name string, sink MetricsSink) TaskFuncDecorator {
+	return WithLifecycle(name, LifecycleHooks{
+		OnStart: func(event *TaskEvent) {
+			sink.IncTaskAttempts(event.Name)
+		},
+		OnSuccess: func(event *TaskEvent) {
+			sink.ObserveTaskDuration(event.Name, true, event.Duration)
+		},
+		OnFailure: func(event *TaskEvent) {
+			sink.ObserveTaskDuration(event.Name, false, event.Duration)
+		},
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+type recordingMetricsSink struct {
+	mu        sync.Mutex
+	attempts  map[string]int
+	succeeded []bool
+}
+
+func (s *recordingMetricsSink) IncTaskAttempts(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts[name]++
+}
+
+func (s *recordingMetricsSink) ObserveTaskDuration(name string, succeeded bool, duration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.succeeded = append(s.succeeded, succeeded)
+}
+
+func TestWithLifecycle(t *testing.T) {
+	t.Run("it calls the hooks around every run", func(t *testing.T) {
+		t.Parallel()
+
+		var events []string
+		var lastEvent *task.TaskEvent
+		hooks := task.LifecycleHooks{
+			OnStart: func(event *task.TaskEvent) {
+				events = append(events, "start")
+			},
+			OnSuccess: func(event *task.TaskEvent) {
+				lastEvent = event
+				events = append(events, "success")
+			},
+			OnFailure: func(event *task.TaskEvent) {
+				require.Error(t, event.Err)
+				events = append(events, "failure")
+			},
+		}
+
+		attempts := 0
+		fn := task.NewTaskFunc(func(ctx context.Context) error {
+			attempts++
+			if attempts < 2 {
+				return task.NewRetryableError(errors.New("fooErr"))
+			}
+
+			return nil
+		}, task.RetryDecorator(task.ConstantBackoff(time.Millisecond, 3)), task.WithLifecycle("deploy", hooks))
+
+		err := fn(context.Background())
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"start", "failure", "start", "success"}, events)
+		require.NotNil(t, lastEvent)
+		assert.Equal(t, "deploy", lastEvent.Name)
+		assert.Equal(t, 2, lastEvent.Attempt)
+		assert.NoError(t, lastEvent.Err)
+	})
+
+	t.Run("it counts the attempts per run", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts []int
+		hooks := task.LifecycleHooks{
+			OnStart: func(event *task.TaskEvent) {
+				attempts = append(attempts, event.Attempt)
+			},
+		}
+
+		failures := 0
+		fn := task.NewTaskFunc(func(ctx context.Context) error {
+			failures++
+			if failures%2 == 1 {
+				return task.NewRetryableError(errors.New("fooErr"))
+			}
+
+			return nil
+		}, task.RetryDecorator(task.ConstantBackoff(time.Millisecond, 3)), task.WithLifecycle("deploy", hooks))
+
+		assert.NoError(t, fn(context.Background()))
+		assert.NoError(t, fn(context.Background()))
+
+		assert.Equal(t, []int{1, 2, 1, 2}, attempts)
+	})
+}
+
+func TestWithMetrics(t *testing.T) {
+	t.Run("it emits attempts and duration of every run", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &recordingMetricsSink{attempts: make(map[string]int)}
+
+		fn := task.NewTaskFunc(func(ctx context.Context) error {
+			return assert.AnError
+		}, task.WithMetrics("push", sink))
+
+		assert.Error(t, fn(context.Background()))
+		assert.Error(t, fn(context.Background()))
+
+		assert.Equal(t, map[string]int{"push": 2}, sink.attempts)
+		assert.Equal(t, []bool{false, false}, sink.succeeded)
+	})
+}
@@ -0,0 +1,233 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Key is a key of a value passed between pipeline stages, that holds values of a single type.
+type Key struct {
+	name      string
+	valueType reflect.Type
+}
+
+// NewKey creates a Key holding values of the type of `example`,
+// e.g `imageDigest := task.NewKey("image-digest", "")` holds strings.
+// A nil `example` means any type.
+func NewKey(name string, example interface{}) Key {
+	return Key{
+		name:      name,
+		valueType: reflect.TypeOf(example),
+	}
+}
+
+// Name returns the key name.
+func (k Key) Name() string {
+	return k.name
+}
+
+func (k Key) check(value interface{}) error {
+	if k.valueType == nil || reflect.TypeOf(value) == k.valueType {
+		return nil
+	}
+
+	return &ValueTypeError{key: k.name, expected: k.valueType, actual: reflect.TypeOf(value)}
+}
+
+// Values holds the values produced by the pipeline stages.
+type Values struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+}
+
+// NewValues creates empty Values instance.
+func NewValues() *Values {
+	return &Values{
+		values: make(map[string]interface{}),
+	}
+}
+
+// Set sets the value of key. It returns ValueTypeError when the value is not of the key type.
+func (v *Values) Set(key Key, value interface{}) error {
+	err := key.check(value)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.values[key.name] = value
+
+	return nil
+}
+
+// Get returns the value of key and whether it is set.
+// With Go 1.18 or later, the package level Get returns the value typed, instead.
+func (v *Values) Get(key Key) (interface{}, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	value, ok := v.values[key.name]
+
+	return value, ok
+}
+
+// ValueTypeError is returned when a stage produces a value that is not of the key type.
+type ValueTypeError struct {
+	key      string
+	expected reflect.Type
+	actual   reflect.Type
+}
+
+// Error returns the error message.
+func (err *ValueTypeError) Error() string {
+	return fmt.Sprintf("pipeline value %s must be %v, not %v", err.key, err.expected, err.actual)
+}
+
+// MissingValueError is returned when a stage consumes a value that no previous stage produced.
+type MissingValueError struct {
+	key string
+}
+
+// Error returns the error message.
+func (err *MissingValueError) Error() string {
+	return fmt.Sprintf("pipeline value %s is not set", err.key)
+}
+
+// StageError is returned by Pipeline when a stage fails.
+type StageError struct {
+	stage string
+	err   error
+}
+
+// Error returns the error message.
+func (err *StageError) Error() string {
+	return fmt.Sprintf("pipeline stage %s failed: %v", err.stage, err.err)
+}
+
+// Stage returns the name of the failed stage.
+func (err *StageError) Stage() string {
+	return err.stage
+}
+
+// Cause returns the stage error.
+func (err *StageError) Cause() error {
+	return err.err
+}
+
+// Unwrap returns the stage error.
+func (err *StageError) Unwrap() error {
+	return err.err
+}
+
+// StageFunc is a pipeline stage that reads and writes the pipeline values.
+type StageFunc func(ctx context.Context, values *Values) error
+
+// Produce creates a stage that sets the value of key to the result of fn.
+// With Go 1.18 or later, ProduceOf takes fn with a typed result, instead.
+func Produce(key Key, fn func(ctx context.Context) (interface{}, error)) StageFunc {
+	return func(ctx context.Context, values *Values) error {
+		value, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+
+		return values.Set(key, value)
+	}
+}
+
+// Consume creates a stage that runs fn with the value of key.
+func Consume(key Key, fn func(ctx context.Context, value interface{}) error) StageFunc {
+	return func(ctx context.Context, values *Values) error {
+		value, ok := values.Get(key)
+		if !ok {
+			return &MissingValueError{key: key.name}
+		}
+
+		return fn(ctx, value)
+	}
+}
+
+// Transform creates a stage that sets the value of `out` to the result of fn run with the value of `in`.
+func Transform(in, out Key, fn func(ctx context.Context, value interface{}) (interface{}, error)) StageFunc {
+	return func(ctx context.Context, values *Values) error {
+		value, ok := values.Get(in)
+		if !ok {
+			return &MissingValueError{key: in.name}
+		}
+
+		result, err := fn(ctx, value)
+		if err != nil {
+			return err
+		}
+
+		return values.Set(out, result)
+	}
+}
+
+type pipelineStage struct {
+	name string
+	fn   StageFunc
+}
+
+// Pipeline runs stages sequentially, passing the values produced by every stage to the next ones,
+// e.g the image digest from the build stage to the apply stage.
+type Pipeline struct {
+	stages []*pipelineStage
+}
+
+// NewPipeline creates new pipeline instance.
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Stage appends a stage to the pipeline.
+func (p *Pipeline) Stage(name string, fn StageFunc) *Pipeline {
+	p.stages = append(p.stages, &pipelineStage{name: name, fn: fn})
+
+	return p
+}
+
+// Run runs the stages until one of them fails, returning StageError, or ctx is done.
+// The values produced so far are returned also on error.
+func (p *Pipeline) Run(ctx context.Context) (*Values, error) {
+	values := NewValues()
+
+	for _, stage := range p.stages {
+		if ctx.Err() != nil {
+			return values, ctx.Err()
+		}
+
+		err := stage.fn(ctx, values)
+		if err != nil {
+			return values, &StageError{stage: stage.name, err: err}
+		}
+	}
+
+	return values, nil
+}
+
+// Task adapts the pipeline to TaskFunc, e.g to run it in a Group or a Graph.
+func (p *Pipeline) Task() TaskFunc {
+	return func(ctx context.Context) error {
+		_, err := p.Run(ctx)
+		return err
+	}
+}
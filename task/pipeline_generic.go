@@ -0,0 +1,54 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package task
+
+import (
+	"context"
+	"reflect"
+)
+
+// Get returns the value of key as T, e.g `digest, err := task.Get[string](values, imageDigest)`.
+// It returns MissingValueError when the value is not set and ValueTypeError when it's not T.
+func Get[T any](values *Values, key Key) (T, error) {
+	var zero T
+
+	value, ok := values.Get(key)
+	if !ok {
+		return zero, &MissingValueError{key: key.name}
+	}
+
+	typed, ok := value.(T)
+	if !ok {
+		return zero, &ValueTypeError{key: key.name, expected: reflect.TypeOf(&zero).Elem(), actual: reflect.TypeOf(value)}
+	}
+
+	return typed, nil
+}
+
+// ProduceOf is Produce with the result of fn typed as T.
+// NOTE: It's not named Produce, since Go has no overloading and Produce takes the untyped fn.
+func ProduceOf[T any](key Key, fn func(ctx context.Context) (T, error)) StageFunc {
+	return func(ctx context.Context, values *Values) error {
+		value, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+
+		return values.Set(key, value)
+	}
+}
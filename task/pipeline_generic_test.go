@@ -0,0 +1,106 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package task_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+func TestGet(t *testing.T) {
+	t.Run("it returns the typed values passed between the stages", func(t *testing.T) {
+		t.Parallel()
+
+		imageDigest := task.NewKey("image-digest", "")
+
+		var applied string
+		pipeline := task.NewPipeline().
+			Stage("build", task.ProduceOf(imageDigest, func(ctx context.Context) (string, error) {
+				return "sha256:abc", nil
+			})).
+			Stage("apply", func(ctx context.Context, values *task.Values) error {
+				digest, err := task.Get[string](values, imageDigest)
+				applied = digest
+
+				return err
+			})
+
+		values, err := pipeline.Run(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, "sha256:abc", applied)
+
+		actual, err := task.Get[string](values, imageDigest)
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:abc", actual)
+	})
+
+	t.Run("when the value is missing, it returns error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := task.Get[string](task.NewValues(), task.NewKey("image-digest", ""))
+
+		var missingErr *task.MissingValueError
+		assert.True(t, errors.As(err, &missingErr))
+	})
+
+	t.Run("when the value is not of the type, it returns error", func(t *testing.T) {
+		t.Parallel()
+
+		revision := task.NewKey("revision", nil)
+
+		values := task.NewValues()
+		require.NoError(t, values.Set(revision, "3"))
+
+		actual, err := task.Get[int](values, revision)
+		assert.Equal(t, 0, actual)
+		assert.EqualError(t, err, "pipeline value revision must be int, not string")
+	})
+}
+
+func TestProduceOf(t *testing.T) {
+	t.Run("when fn fails, it returns its error", func(t *testing.T) {
+		t.Parallel()
+
+		values := task.NewValues()
+		err := task.ProduceOf(task.NewKey("revision", 0), func(ctx context.Context) (int, error) {
+			return 0, assert.AnError
+		})(context.Background(), values)
+		assert.Equal(t, assert.AnError, err)
+
+		_, ok := values.Get(task.NewKey("revision", 0))
+		assert.False(t, ok)
+	})
+
+	t.Run("when the value is not of the key type, it returns error", func(t *testing.T) {
+		t.Parallel()
+
+		err := task.ProduceOf(task.NewKey("revision", 0), func(ctx context.Context) (string, error) {
+			return "3", nil
+		})(context.Background(), task.NewValues())
+
+		var typeErr *task.ValueTypeError
+		assert.True(t, errors.As(err, &typeErr))
+	})
+}
@@ -0,0 +1,113 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+func TestPipeline_Run(t *testing.T) {
+	t.Run("it passes the values between the stages", func(t *testing.T) {
+		t.Parallel()
+
+		imageDigest := task.NewKey("image-digest", "")
+		revision := task.NewKey("revision", 0)
+
+		var applied interface{}
+		pipeline := task.NewPipeline().
+			Stage("build", task.Produce(imageDigest, func(ctx context.Context) (interface{}, error) {
+				return "sha256:abc", nil
+			})).
+			Stage("apply", task.Transform(
+				imageDigest,
+				revision,
+				func(ctx context.Context, digest interface{}) (interface{}, error) {
+					applied = digest
+					return 3, nil
+				},
+			))
+
+		values, err := pipeline.Run(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, "sha256:abc", applied)
+
+		value, ok := values.Get(revision)
+		assert.True(t, ok)
+		assert.Equal(t, 3, value)
+	})
+
+	t.Run("when a stage fails, it stops and returns StageError", func(t *testing.T) {
+		t.Parallel()
+
+		imageDigest := task.NewKey("image-digest", "")
+
+		applied := false
+		err := task.NewPipeline().
+			Stage("build", task.Produce(imageDigest, func(ctx context.Context) (interface{}, error) {
+				return "", assert.AnError
+			})).
+			Stage("apply", task.Consume(imageDigest, func(ctx context.Context, digest interface{}) error {
+				applied = true
+				return nil
+			})).
+			Task()(context.Background())
+
+		var stageErr *task.StageError
+		require.True(t, errors.As(err, &stageErr))
+		assert.Equal(t, "build", stageErr.Stage())
+		assert.True(t, errors.Is(err, assert.AnError))
+		assert.False(t, applied)
+	})
+
+	t.Run("when a consumed value is missing, it returns error", func(t *testing.T) {
+		t.Parallel()
+
+		imageDigest := task.NewKey("image-digest", "")
+
+		_, err := task.NewPipeline().
+			Stage("apply", task.Consume(imageDigest, func(ctx context.Context, digest interface{}) error {
+				return nil
+			})).
+			Run(context.Background())
+
+		var missingErr *task.MissingValueError
+		assert.True(t, errors.As(err, &missingErr))
+		assert.EqualError(t, err, "pipeline stage apply failed: pipeline value image-digest is not set")
+	})
+
+	t.Run("when a produced value is not of the key type, it returns error", func(t *testing.T) {
+		t.Parallel()
+
+		revision := task.NewKey("revision", 0)
+
+		_, err := task.NewPipeline().
+			Stage("build", task.Produce(revision, func(ctx context.Context) (interface{}, error) {
+				return "3", nil
+			})).
+			Run(context.Background())
+
+		var typeErr *task.ValueTypeError
+		assert.True(t, errors.As(err, &typeErr))
+		assert.EqualError(t, err, "pipeline stage build failed: pipeline value revision must be int, not string")
+	})
+}
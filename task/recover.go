@@ -0,0 +1,43 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/sumup-oss/go-pkgs/concurrency"
+)
+
+// PanicError is returned by the tasks that panicked, when run with Recover or by a Group or a Graph.
+type PanicError = concurrency.PanicError
+
+// NewPanicError creates PanicError instance.
+func NewPanicError(value interface{}, stack []byte) error {
+	return concurrency.NewPanicError(value, stack)
+}
+
+// Recover is a TaskFuncDecorator converting the panics of the decorated task into PanicError.
+func Recover(fn TaskFunc) TaskFunc {
+	return func(ctx context.Context) (err error) {
+		defer func() {
+			if value := recover(); value != nil {
+				err = NewPanicError(value, debug.Stack())
+			}
+		}()
+
+		return fn(ctx)
+	}
+}
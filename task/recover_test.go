@@ -0,0 +1,115 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+func TestRecover(t *testing.T) {
+	t.Run("it converts the panic into PanicError with stack trace", func(t *testing.T) {
+		t.Parallel()
+
+		fn := task.NewTaskFunc(func(ctx context.Context) error {
+			panic("namespace cleanup failed")
+		}, task.Recover)
+
+		err := fn(context.Background())
+		require.IsType(t, &task.PanicError{}, err)
+
+		panicErr := err.(*task.PanicError)
+		assert.Equal(t, "namespace cleanup failed", panicErr.Value())
+		assert.Contains(t, string(panicErr.Stack()), "recover_test.go")
+		assert.Contains(t, panicErr.Error(), "recovered panic: namespace cleanup failed")
+	})
+}
+
+func TestGroup_SetPolicy(t *testing.T) {
+	t.Run("when a task panics with continue policy, it keeps the other tasks running", func(t *testing.T) {
+		t.Parallel()
+
+		group := task.NewGroup()
+		group.SetPolicy(task.ContinueOnError)
+
+		var cleaned int32
+		cleanup := func(ctx context.Context) error {
+			atomic.AddInt32(&cleaned, 1)
+			return nil
+		}
+
+		foo := NewTestTask(nil)
+		group.Go(foo.Run)
+		<-foo.RunReady
+
+		group.Go(func(ctx context.Context) error {
+			panic("boom")
+		}, cleanup, cleanup)
+
+		// NOTE: The panic does not cancel the running task.
+		foo.RunUntil <- nil
+
+		err := group.Wait(context.Background())
+		require.IsType(t, &task.PanicError{}, err)
+		assert.Equal(t, 0, foo.StopCount)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&cleaned))
+		assert.Len(t, group.Errors(), 1)
+	})
+
+	t.Run("when a task panics with fail fast policy, it cancels the other tasks", func(t *testing.T) {
+		t.Parallel()
+
+		group := task.NewGroup()
+		foo := NewTestTask(nil)
+
+		group.Go(foo.Run)
+		<-foo.RunReady
+
+		group.Go(func(ctx context.Context) error {
+			panic("boom")
+		})
+
+		err := group.Wait(context.Background())
+		require.IsType(t, &task.PanicError{}, err)
+		assert.Equal(t, 1, foo.StopCount)
+	})
+}
+
+func TestGraph_Run_Panic(t *testing.T) {
+	t.Run("it reports the panicked task as failed", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := &runRecorder{}
+		graph := task.NewGraph(task.ContinueOnError)
+
+		require.NoError(t, graph.Add("tenant-a", func(ctx context.Context) error {
+			panic("boom")
+		}))
+		require.NoError(t, graph.Add("tenant-b", recorder.task("tenant-b", nil)))
+
+		summary, err := graph.Run(context.Background())
+		assert.Error(t, err)
+
+		assert.Equal(t, []string{"tenant-b"}, recorder.runs)
+		assert.Equal(t, task.TaskFailed, summary.Result("tenant-a").Status)
+		assert.IsType(t, &task.PanicError{}, summary.Result("tenant-a").Err)
+	})
+}
@@ -0,0 +1,238 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sumup-oss/go-pkgs/clock"
+)
+
+// ScheduledTask is a task run by the Scheduler.
+type ScheduledTask struct {
+	Name     string
+	Schedule Schedule
+	Task     TaskFunc
+	// Jitter delays every run by a random duration up to Jitter,
+	// e.g to spread the runs of the same task across replicas.
+	Jitter time.Duration
+}
+
+// Scheduler runs tasks periodically, on cron expressions or fixed intervals.
+//
+// A run is skipped when the previous run of the same task is still running.
+type Scheduler struct {
+	mu      sync.Mutex
+	tasks   []*ScheduledTask
+	started bool
+
+	scheduleCtx    context.Context
+	cancelSchedule context.CancelFunc
+	runCtx         context.Context
+	cancelRuns     context.CancelFunc
+	schedulers     sync.WaitGroup
+	runs           sync.WaitGroup
+
+	errorHandler func(name string, err error)
+	skipHandler  func(name string)
+	clock        clock.Clock
+}
+
+// NewScheduler creates new scheduler instance.
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		errorHandler: func(string, error) {},
+		skipHandler:  func(string) {},
+		clock:        clock.NewRealClock(),
+	}
+}
+
+// SetClock sets the clock the runs are scheduled with, e.g clocktest.FakeClock in tests.
+// It must be called before the Scheduler.Start() method.
+func (s *Scheduler) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// OnError sets the handler of the task errors, e.g for logging.
+// It must be called before the Scheduler.Start() method.
+func (s *Scheduler) OnError(handler func(name string, err error)) {
+	s.errorHandler = handler
+}
+
+// OnSkip sets the handler of the runs skipped due to overlap with the previous run.
+// It must be called before the Scheduler.Start() method.
+func (s *Scheduler) OnSkip(handler func(name string)) {
+	s.skipHandler = handler
+}
+
+// Add schedules the task. The tasks added after the Scheduler.Start() method are scheduled immediately.
+func (s *Scheduler) Add(task *ScheduledTask) error {
+	if task.Name == "" || task.Schedule == nil || task.Task == nil {
+		return fmt.Errorf("scheduled task must have name, schedule and task")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, scheduled := range s.tasks {
+		if scheduled.Name == task.Name {
+			return fmt.Errorf("task %s is already scheduled", task.Name)
+		}
+	}
+
+	s.tasks = append(s.tasks, task)
+
+	if s.started {
+		s.schedule(task)
+	}
+
+	return nil
+}
+
+// AddCron schedules the task per the cron expression, see ParseCron.
+func (s *Scheduler) AddCron(name, expr string, fn TaskFunc) error {
+	schedule, err := ParseCron(expr)
+	if err != nil {
+		return err
+	}
+
+	return s.Add(&ScheduledTask{Name: name, Schedule: schedule, Task: fn})
+}
+
+// AddInterval schedules the task every interval.
+func (s *Scheduler) AddInterval(name string, interval time.Duration, fn TaskFunc) error {
+	return s.Add(&ScheduledTask{Name: name, Schedule: Every(interval), Task: fn})
+}
+
+// Start starts scheduling the tasks. The task runs are canceled when ctx is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+
+	s.started = true
+	s.scheduleCtx, s.cancelSchedule = context.WithCancel(ctx)
+	s.runCtx, s.cancelRuns = context.WithCancel(ctx)
+
+	for _, task := range s.tasks {
+		s.schedule(task)
+	}
+}
+
+// Stop stops scheduling new runs and waits for the running tasks to finish.
+// If ctx is done before that, the running tasks are canceled and the context error is returned.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.started = false
+	s.cancelSchedule()
+	s.mu.Unlock()
+
+	s.schedulers.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancelRuns()
+		return nil
+	case <-ctx.Done():
+		s.cancelRuns()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Run runs the scheduler as a task, i.e. until ctx is done, then stops it gracefully.
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.Start(context.Background())
+	<-ctx.Done()
+
+	return s.Stop(context.Background())
+}
+
+func (s *Scheduler) schedule(task *ScheduledTask) {
+	s.schedulers.Add(1)
+
+	go func() {
+		defer s.schedulers.Done()
+
+		var mu sync.Mutex
+		running := false
+
+		for {
+			now := s.clock.Now()
+			next := task.Schedule.Next(now)
+			if next.IsZero() {
+				return
+			}
+
+			delay := next.Sub(now)
+			if task.Jitter > 0 {
+				//nolint:gosec
+				delay += time.Duration(rand.Int63n(int64(task.Jitter)))
+			}
+
+			timer := s.clock.NewTimer(delay)
+			select {
+			case <-s.scheduleCtx.Done():
+				timer.Stop()
+				return
+			case <-timer.C():
+			}
+
+			mu.Lock()
+			if running {
+				mu.Unlock()
+				s.skipHandler(task.Name)
+
+				continue
+			}
+
+			running = true
+			mu.Unlock()
+
+			s.runs.Add(1)
+
+			go func() {
+				defer s.runs.Done()
+
+				err := task.Task(s.runCtx)
+				if err != nil {
+					s.errorHandler(task.Name, err)
+				}
+
+				mu.Lock()
+				running = false
+				mu.Unlock()
+			}()
+		}
+	}()
+}
@@ -0,0 +1,149 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/clock/clocktest"
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+func newTestScheduler() (*task.Scheduler, *clocktest.FakeClock) {
+	fakeClock := clocktest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	scheduler := task.NewScheduler()
+	scheduler.SetClock(fakeClock)
+
+	return scheduler, fakeClock
+}
+
+// tick waits for the scheduler to wait for the next run and advances the clock to it.
+func tick(t *testing.T, fakeClock *clocktest.FakeClock, interval time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, fakeClock.BlockUntil(ctx, 1))
+	fakeClock.Advance(interval)
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("it runs the tasks at intervals and reports their errors", func(t *testing.T) {
+		t.Parallel()
+
+		scheduler, fakeClock := newTestScheduler()
+
+		var runs int32
+		errs := make(chan string, 10)
+		scheduler.OnError(func(name string, err error) {
+			errs <- name
+		})
+
+		require.NoError(t, scheduler.AddInterval("cleanup", 5*time.Millisecond, func(ctx context.Context) error {
+			if atomic.AddInt32(&runs, 1) == 1 {
+				return assert.AnError
+			}
+
+			return nil
+		}))
+
+		scheduler.Start(context.Background())
+
+		tick(t, fakeClock, 5*time.Millisecond)
+		assert.Equal(t, "cleanup", <-errs)
+
+		// NOTE: The ticks overlapping with a finishing run are skipped, so tick until the runs are observed.
+		for atomic.LoadInt32(&runs) < 3 {
+			tick(t, fakeClock, 5*time.Millisecond)
+		}
+
+		assert.NoError(t, scheduler.Stop(context.Background()))
+	})
+
+	t.Run("it skips the runs overlapping with the previous run", func(t *testing.T) {
+		t.Parallel()
+
+		scheduler, fakeClock := newTestScheduler()
+
+		skipped := make(chan string, 10)
+		scheduler.OnSkip(func(name string) {
+			select {
+			case skipped <- name:
+			default:
+			}
+		})
+
+		var runs int32
+		started := make(chan struct{}, 10)
+		release := make(chan struct{})
+		require.NoError(t, scheduler.AddInterval("rotate-certs", time.Millisecond, func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			started <- struct{}{}
+			<-release
+
+			return nil
+		}))
+
+		scheduler.Start(context.Background())
+
+		tick(t, fakeClock, time.Millisecond)
+		<-started
+		tick(t, fakeClock, time.Millisecond)
+
+		assert.Equal(t, "rotate-certs", <-skipped)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+
+		close(release)
+		assert.NoError(t, scheduler.Stop(context.Background()))
+	})
+
+	t.Run("when stop deadline is exceeded, it cancels the running tasks", func(t *testing.T) {
+		t.Parallel()
+
+		scheduler, fakeClock := newTestScheduler()
+		longTask := NewTestTask(nil)
+
+		require.NoError(t, scheduler.AddInterval("long", time.Millisecond, longTask.Run))
+
+		scheduler.Start(context.Background())
+		tick(t, fakeClock, time.Millisecond)
+		<-longTask.RunReady
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+
+		err := scheduler.Stop(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, 1, longTask.StopCount)
+	})
+
+	t.Run("it returns error for duplicate or invalid tasks", func(t *testing.T) {
+		t.Parallel()
+
+		scheduler := task.NewScheduler()
+		noop := func(ctx context.Context) error { return nil }
+
+		require.NoError(t, scheduler.AddCron("cleanup", "@daily", noop))
+		assert.Error(t, scheduler.AddCron("cleanup", "@daily", noop))
+		assert.Error(t, scheduler.AddCron("backup", "* *", noop))
+		assert.Error(t, scheduler.Add(&task.ScheduledTask{Name: "backup"}))
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTaskTimeout matches the errors returned by NewTimeout tasks that exceeded their timeout,
+// i.e. `errors.Is(err, task.ErrTaskTimeout)`.
+var ErrTaskTimeout = errors.New("task timeout exceeded")
+
+// TimeoutError is returned when a NewTimeout task exceeds its timeout.
+type TimeoutError struct {
+	timeout time.Duration
+	taskErr error
+}
+
+// NewTimeoutError creates TimeoutError instance.
+func NewTimeoutError(timeout time.Duration, taskErr error) error {
+	return &TimeoutError{
+		timeout: timeout,
+		taskErr: taskErr,
+	}
+}
+
+// Error returns the error message.
+func (err *TimeoutError) Error() string {
+	if err.taskErr == nil {
+		return fmt.Sprintf("task timeout %v exceeded", err.timeout)
+	}
+
+	return fmt.Sprintf("task timeout %v exceeded, task err: %v", err.timeout, err.taskErr)
+}
+
+// Is makes TimeoutError match ErrTaskTimeout.
+func (err *TimeoutError) Is(target error) bool {
+	return target == ErrTaskTimeout
+}
+
+// Cause returns the error returned by the task after its context deadline, if any.
+func (err *TimeoutError) Cause() error {
+	return err.taskErr
+}
+
+// NewTimeout runs a task with a context that has the timeout deadline.
+// When the timeout is exceeded, the task returns TimeoutError without waiting for the task to stop,
+// so that a hung task that ignores its context does not block the caller.
+// The panics of the task are recovered as PanicError, since it runs in its own goroutine.
+//
+// NOTE: when the parent context is done, the task waits for the inner task to stop and returns its error.
+func NewTimeout(fn TaskFunc, timeout time.Duration) TaskFunc {
+	return func(ctx context.Context) error {
+		timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		done := make(chan error, 1)
+		go func() {
+			done <- Recover(fn)(timeoutCtx)
+		}()
+
+		select {
+		case err := <-done:
+			if ctx.Err() == nil && timeoutCtx.Err() == context.DeadlineExceeded {
+				return NewTimeoutError(timeout, err)
+			}
+
+			return err
+		case <-timeoutCtx.Done():
+			if ctx.Err() != nil {
+				return <-done
+			}
+
+			return NewTimeoutError(timeout, nil)
+		}
+	}
+}
+
+// TimeoutDecorator creates a TaskFuncDecorator limiting the decorated task with NewTimeout.
+func TimeoutDecorator(timeout time.Duration) TaskFuncDecorator {
+	return func(fn TaskFunc) TaskFunc {
+		return NewTimeout(fn, timeout)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/errors"
+	"github.com/sumup-oss/go-pkgs/task"
+)
+
+func TestNewTimeout(t *testing.T) {
+	t.Run("it returns the task result within the timeout", func(t *testing.T) {
+		t.Parallel()
+
+		fn := task.NewTimeout(func(ctx context.Context) error {
+			return assert.AnError
+		}, time.Second)
+
+		err := fn(context.Background())
+		assert.Equal(t, assert.AnError, err)
+		assert.False(t, errors.Is(err, task.ErrTaskTimeout))
+	})
+
+	t.Run("when the timeout is exceeded, it returns ErrTaskTimeout", func(t *testing.T) {
+		t.Parallel()
+
+		fn := task.NewTaskFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, task.TimeoutDecorator(time.Millisecond))
+
+		err := fn(context.Background())
+		assert.True(t, errors.Is(err, task.ErrTaskTimeout))
+		assert.Contains(t, err.Error(), "task timeout 1ms exceeded")
+	})
+
+	t.Run("when the task ignores its context, it does not wait for the task", func(t *testing.T) {
+		t.Parallel()
+
+		hung := make(chan struct{})
+		defer close(hung)
+
+		fn := task.NewTimeout(func(ctx context.Context) error {
+			<-hung
+			return nil
+		}, time.Millisecond)
+
+		err := fn(context.Background())
+		assert.True(t, errors.Is(err, task.ErrTaskTimeout))
+	})
+
+	t.Run("when the parent context is canceled, it returns the task error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		fn := task.NewTimeout(func(ctx context.Context) error {
+			cancel()
+			<-ctx.Done()
+
+			return nil
+		}, time.Hour)
+
+		err := fn(ctx)
+		assert.NoError(t, err)
+	})
+	t.Run("when the task panics in a group, it fails the task instead of crashing", func(t *testing.T) {
+		t.Parallel()
+
+		group := task.NewGroup()
+		group.Go(task.NewTimeout(func(ctx context.Context) error {
+			panic("boom")
+		}, time.Second))
+
+		err := group.Wait(context.Background())
+		require.IsType(t, &task.PanicError{}, err)
+		assert.Equal(t, "boom", err.(*task.PanicError).Value())
+	})
+}
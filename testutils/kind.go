@@ -0,0 +1,167 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build e2e
+// +build e2e
+
+package testutils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sumup-oss/go-pkgs/executor"
+	pkgOs "github.com/sumup-oss/go-pkgs/os"
+)
+
+const (
+	DefaultKindClusterNamePrefix = "go-pkgs-e2e"
+)
+
+// KindCluster is an end-to-end test harness running the real `Kubectl` executor against a kind cluster.
+//
+// When `TEST_KIND_CLUSTER_NAME` is set, the existing cluster with that name is reused and never deleted,
+// otherwise a new cluster is created and deleted by the teardown func.
+// `TEST_KIND_NODE_IMAGE` selects the node image (kubernetes version) of the created cluster.
+type KindCluster struct {
+	name       string
+	kubeconfig string
+	reused     bool
+	osExecutor pkgOs.OsExecutor
+}
+
+// NewKindClusterFromEnv creates or reuses a kind cluster. It skips the test when `kind` or `kubectl`
+// binaries are not available.
+// The returned teardown func deletes the created cluster and must be deferred by the caller.
+func NewKindClusterFromEnv(t *testing.T) (*KindCluster, func()) {
+	t.Helper()
+
+	osExecutor := &pkgOs.RealOsExecutor{}
+
+	_, _, err := osExecutor.Execute("kind", []string{"version"}, nil, "")
+	if err != nil {
+		t.Skipf("kind binary is not available: %s", err)
+	}
+
+	_, _, err = osExecutor.Execute("kubectl", []string{"version", "--client"}, nil, "")
+	if err != nil {
+		t.Skipf("kubectl binary is not available: %s", err)
+	}
+
+	cluster := &KindCluster{
+		name:       os.Getenv("TEST_KIND_CLUSTER_NAME"),
+		osExecutor: osExecutor,
+	}
+
+	if cluster.name != "" {
+		cluster.reused = true
+	} else {
+		cluster.name = fmt.Sprintf("%s-%s", DefaultKindClusterNamePrefix, strings.ToLower(RandString(6)))
+	}
+
+	kubeconfigDir := TestDir(t, "kind-kubeconfig")
+	cluster.kubeconfig = filepath.Join(kubeconfigDir, "config")
+
+	teardown := func() {
+		if !cluster.reused {
+			_, stderr, err := osExecutor.Execute("kind", []string{"delete", "cluster", "--name", cluster.name}, nil, "")
+			if err != nil {
+				t.Logf("deleting kind cluster %s failed: %s. Stderr: %s", cluster.name, err, stderr)
+			}
+		}
+
+		_ = os.RemoveAll(kubeconfigDir)
+	}
+
+	if !cluster.reused {
+		args := []string{"create", "cluster", "--name", cluster.name, "--kubeconfig", cluster.kubeconfig}
+
+		nodeImage := os.Getenv("TEST_KIND_NODE_IMAGE")
+		if nodeImage != "" {
+			args = append(args, "--image", nodeImage)
+		}
+
+		_, stderr, err := osExecutor.Execute("kind", args, nil, "")
+		if err != nil {
+			// NOTE: Delete the partially created cluster, since the caller defers teardown only on success.
+			teardown()
+			t.Fatalf("creating kind cluster %s failed: %s. Stderr: %s", cluster.name, err, stderr)
+		}
+
+		return cluster, teardown
+	}
+
+	stdout, stderr, err := osExecutor.Execute("kind", []string{"get", "kubeconfig", "--name", cluster.name}, nil, "")
+	if err != nil {
+		teardown()
+		t.Fatalf("getting kubeconfig of kind cluster %s failed: %s. Stderr: %s", cluster.name, err, stderr)
+	}
+
+	err = osExecutor.WriteFile(cluster.kubeconfig, stdout, 0600)
+	if err != nil {
+		teardown()
+		t.Fatalf("writing kubeconfig of kind cluster %s failed: %s", cluster.name, err)
+	}
+
+	return cluster, teardown
+}
+
+// Name returns the kind cluster name.
+func (c *KindCluster) Name() string {
+	return c.name
+}
+
+// Kubeconfig returns the path to the kubeconfig of the kind cluster.
+func (c *KindCluster) Kubeconfig() string {
+	return c.kubeconfig
+}
+
+// Kubectl returns a real `Kubectl` executor configured for the kind cluster.
+func (c *KindCluster) Kubectl() *executor.Kubectl {
+	kubectl := executor.NewKubectl(c.osExecutor, "", "svc.cluster.local")
+	kubectl.GlobalOptions["kubeconfig"] = c.kubeconfig
+
+	return kubectl
+}
+
+// CreateNamespace provisions a random namespace.
+// The returned teardown func deletes the namespace and must be deferred by the caller.
+func (c *KindCluster) CreateNamespace(t *testing.T) (string, func()) {
+	t.Helper()
+
+	namespace := fmt.Sprintf("e2e-%s", strings.ToLower(RandString(8)))
+	kubeconfigArg := fmt.Sprintf("--kubeconfig=%s", c.kubeconfig)
+
+	_, stderr, err := c.osExecutor.Execute("kubectl", []string{"create", "namespace", namespace, kubeconfigArg}, nil, "")
+	if err != nil {
+		t.Fatalf("creating namespace %s failed: %s. Stderr: %s", namespace, err, stderr)
+	}
+
+	teardown := func() {
+		_, stderr, err := c.osExecutor.Execute(
+			"kubectl",
+			[]string{"delete", "namespace", namespace, "--wait=false", kubeconfigArg},
+			nil,
+			"",
+		)
+		if err != nil {
+			t.Logf("deleting namespace %s failed: %s. Stderr: %s", namespace, err, stderr)
+		}
+	}
+
+	return namespace, teardown
+}
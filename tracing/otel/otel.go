@@ -0,0 +1,121 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otel adapts an OpenTelemetry tracer to tracing.Tracer, e.g for executor.TracingExecutor,
+// so that the command spans are children of the OpenTelemetry spans of the application.
+// It's a separate module, so that go-pkgs does not depend on the OpenTelemetry API.
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sumup-oss/go-pkgs/tracing"
+)
+
+var (
+	_ tracing.Tracer = (*Tracer)(nil)
+	_ tracing.Span   = (*span)(nil)
+)
+
+// Tracer is tracing.Tracer starting the spans with an OpenTelemetry tracer.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer creates Tracer instance, e.g `otel.NewTracer(otelapi.Tracer("deployer"))`.
+func NewTracer(tracer trace.Tracer) *Tracer {
+	return &Tracer{tracer: tracer}
+}
+
+// Start starts an OpenTelemetry span, which is a child of the OpenTelemetry span of ctx, if any.
+// The returned ctx carries the span for both tracing.SpanFromContext and `trace.SpanFromContext`.
+func (t *Tracer) Start(ctx context.Context, name string, attributes ...tracing.Attribute) (context.Context, tracing.Span) {
+	ctx, otelSpan := t.tracer.Start(ctx, name, trace.WithAttributes(convertAttributes(attributes)...))
+	s := &span{span: otelSpan}
+
+	return tracing.ContextWithSpan(ctx, s), s
+}
+
+// IDsFromContext returns the trace and span IDs of the OpenTelemetry span of ctx, e.g of an HTTP server middleware.
+// `ok` is false when there is no valid span. It's a logger.TraceExtractor, e.g
+// `logger.SetTraceExtractor(otel.IDsFromContext)`.
+func IDsFromContext(ctx context.Context) (traceID, spanID string, ok bool) {
+	spanContext := convertSpanContext(trace.SpanContextFromContext(ctx))
+
+	return spanContext.TraceID, spanContext.SpanID, spanContext.IsValid()
+}
+
+type span struct {
+	span trace.Span
+}
+
+func (s *span) SpanContext() tracing.SpanContext {
+	return convertSpanContext(s.span.SpanContext())
+}
+
+func (s *span) SetAttributes(attributes ...tracing.Attribute) {
+	s.span.SetAttributes(convertAttributes(attributes)...)
+}
+
+func (s *span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *span) End() {
+	s.span.End()
+}
+
+func convertSpanContext(spanContext trace.SpanContext) tracing.SpanContext {
+	if !spanContext.IsValid() {
+		return tracing.SpanContext{}
+	}
+
+	return tracing.SpanContext{
+		TraceID: spanContext.TraceID().String(),
+		SpanID:  spanContext.SpanID().String(),
+	}
+}
+
+func convertAttributes(attributes []tracing.Attribute) []attribute.KeyValue {
+	converted := make([]attribute.KeyValue, len(attributes))
+
+	for i, item := range attributes {
+		switch value := item.Value.(type) {
+		case string:
+			converted[i] = attribute.String(item.Key, value)
+		case []string:
+			converted[i] = attribute.StringSlice(item.Key, value)
+		case bool:
+			converted[i] = attribute.Bool(item.Key, value)
+		case int:
+			converted[i] = attribute.Int(item.Key, value)
+		case float64:
+			converted[i] = attribute.Float64(item.Key, value)
+		default:
+			converted[i] = attribute.String(item.Key, fmt.Sprint(value))
+		}
+	}
+
+	return converted
+}
@@ -0,0 +1,126 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sumup-oss/go-pkgs/tracing"
+)
+
+func TestTracer_Start(t *testing.T) {
+	t.Run(
+		"it starts the span as a child of the OpenTelemetry span of the context",
+		func(t *testing.T) {
+			t.Parallel()
+
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			otelTracer := provider.Tracer("test")
+
+			ctx, parent := otelTracer.Start(context.Background(), "deploy")
+
+			ctx, span := NewTracer(otelTracer).Start(
+				ctx,
+				"exec kubectl",
+				tracing.String("process.executable.name", "kubectl"),
+				tracing.Strings("process.command_args", []string{"apply"}),
+			)
+			span.SetAttributes(tracing.Int("process.exit_code", 1))
+			span.RecordError(errors.New("exit status 1"))
+			span.End()
+			parent.End()
+
+			assert.Equal(t, span, tracing.SpanFromContext(ctx))
+			assert.Equal(t, parent.SpanContext().TraceID().String(), span.SpanContext().TraceID)
+			assert.Equal(t, trace.SpanContextFromContext(ctx).SpanID().String(), span.SpanContext().SpanID)
+
+			ended := recorder.Ended()
+			require.Len(t, ended, 2)
+			assert.Equal(t, "exec kubectl", ended[0].Name())
+			assert.Equal(t, parent.SpanContext().SpanID(), ended[0].Parent().SpanID())
+			assert.Equal(
+				t,
+				[]attribute.KeyValue{
+					attribute.String("process.executable.name", "kubectl"),
+					attribute.StringSlice("process.command_args", []string{"apply"}),
+					attribute.Int("process.exit_code", 1),
+				},
+				ended[0].Attributes(),
+			)
+			assert.Equal(t, codes.Error, ended[0].Status().Code)
+			assert.Len(t, ended[0].Events(), 1)
+		},
+	)
+
+	t.Run(
+		"with nil error, it does not mark the span as failed",
+		func(t *testing.T) {
+			t.Parallel()
+
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+			_, span := NewTracer(provider.Tracer("test")).Start(context.Background(), "exec git")
+			span.RecordError(nil)
+			span.End()
+
+			ended := recorder.Ended()
+			require.Len(t, ended, 1)
+			assert.Equal(t, codes.Unset, ended[0].Status().Code)
+			assert.Empty(t, ended[0].Events())
+		},
+	)
+}
+
+func TestIDsFromContext(t *testing.T) {
+	t.Run(
+		"it returns the IDs of the OpenTelemetry span of the context",
+		func(t *testing.T) {
+			t.Parallel()
+
+			provider := sdktrace.NewTracerProvider()
+			ctx, span := provider.Tracer("test").Start(context.Background(), "handle request")
+			defer span.End()
+
+			traceID, spanID, ok := IDsFromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, span.SpanContext().TraceID().String(), traceID)
+			assert.Equal(t, span.SpanContext().SpanID().String(), spanID)
+		},
+	)
+
+	t.Run(
+		"without span, it returns not ok",
+		func(t *testing.T) {
+			t.Parallel()
+
+			traceID, spanID, ok := IDsFromContext(context.Background())
+			assert.False(t, ok)
+			assert.Empty(t, traceID)
+			assert.Empty(t, spanID)
+		},
+	)
+}
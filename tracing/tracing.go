@@ -0,0 +1,124 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing is a minimal tracing API shaped after OpenTelemetry, so that an OpenTelemetry tracer
+// is adapted with a thin wrapper, without go-pkgs depending on the OpenTelemetry SDK.
+// The `github.com/sumup-oss/go-pkgs/tracing/otel` module is such an adapter.
+package tracing
+
+import "context"
+
+var (
+	_ Tracer = (*NopTracer)(nil)
+	_ Span   = (*nopSpan)(nil)
+)
+
+// Tracer starts spans.
+type Tracer interface {
+	// Start starts a span, which is a child of the span of ctx, if any, and returns ctx with the span.
+	Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span)
+}
+
+// Span is a traced operation. It must be ended by the caller.
+type Span interface {
+	// SpanContext returns the IDs of the span, e.g to correlate logs with traces.
+	SpanContext() SpanContext
+	SetAttributes(attributes ...Attribute)
+	// RecordError records err and marks the span as failed. Nil errors are ignored.
+	RecordError(err error)
+	End()
+}
+
+// SpanContext identifies a span. The IDs are hex encoded, as in the W3C `traceparent` header.
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+}
+
+// IsValid reports whether both IDs are present.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != "" && sc.SpanID != ""
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying the span. Tracers call it from Start.
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// SpanFromContext returns the active span of ctx, or nil when there is none.
+func SpanFromContext(ctx context.Context) Span {
+	span, _ := ctx.Value(spanContextKey{}).(Span)
+	return span
+}
+
+// IDsFromContext returns the trace and span IDs of the active span of ctx.
+// `ok` is false when there is no active span with valid IDs.
+// It matches logger.TraceExtractor, which uses it by default.
+func IDsFromContext(ctx context.Context) (traceID, spanID string, ok bool) {
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return "", "", false
+	}
+
+	spanContext := span.SpanContext()
+
+	return spanContext.TraceID, spanContext.SpanID, spanContext.IsValid()
+}
+
+// Attribute is a key-value span attribute. The values are string, bool, int, float64 or []string.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Strings(key string, value []string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Float64(key string, value float64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// NopTracer is a Tracer that discards the spans.
+type NopTracer struct{}
+
+func (t *NopTracer) Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span) {
+	return ctx, &nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (s *nopSpan) SpanContext() SpanContext {
+	return SpanContext{}
+}
+
+func (s *nopSpan) SetAttributes(attributes ...Attribute) {}
+
+func (s *nopSpan) RecordError(err error) {}
+
+func (s *nopSpan) End() {}
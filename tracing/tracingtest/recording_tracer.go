@@ -0,0 +1,133 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracingtest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+
+	"github.com/sumup-oss/go-pkgs/tracing"
+)
+
+var (
+	_ tracing.Tracer = (*RecordingTracer)(nil)
+	_ tracing.Span   = (*RecordedSpan)(nil)
+)
+
+// RecordingTracer captures the started spans for assertions.
+type RecordingTracer struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+func (t *RecordingTracer) Start(
+	ctx context.Context,
+	name string,
+	attributes ...tracing.Attribute,
+) (context.Context, tracing.Span) {
+	span := &RecordedSpan{
+		Name:       name,
+		Attributes: make(map[string]interface{}),
+		Context: tracing.SpanContext{
+			TraceID: randomHex(16),
+			SpanID:  randomHex(8),
+		},
+	}
+
+	if parent, ok := tracing.SpanFromContext(ctx).(*RecordedSpan); ok {
+		span.Parent = parent
+		span.Context.TraceID = parent.Context.TraceID
+	}
+
+	span.SetAttributes(attributes...)
+
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+
+	return tracing.ContextWithSpan(ctx, span), span
+}
+
+// Spans returns the captured spans in the order they were started.
+func (t *RecordingTracer) Spans() []*RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	spans := make([]*RecordedSpan, len(t.spans))
+	copy(spans, t.spans)
+
+	return spans
+}
+
+// RecordedSpan is a span captured by RecordingTracer.
+type RecordedSpan struct {
+	mu sync.Mutex
+
+	Name   string
+	Parent *RecordedSpan
+	// Context has random IDs, with the trace ID of the parent, if any.
+	Context    tracing.SpanContext
+	Attributes map[string]interface{}
+	Err        error
+	Ended      bool
+}
+
+func (s *RecordedSpan) SpanContext() tracing.SpanContext {
+	return s.Context
+}
+
+func (s *RecordedSpan) SetAttributes(attributes ...tracing.Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, attribute := range attributes {
+		s.Attributes[attribute.Key] = attribute.Value
+	}
+}
+
+func (s *RecordedSpan) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Err = err
+}
+
+func (s *RecordedSpan) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Ended = true
+}
+
+func randomHex(size int) string {
+	id := make([]byte, size)
+
+	_, err := rand.Read(id)
+	if err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 SumUp Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracingtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumup-oss/go-pkgs/tracing"
+)
+
+func TestRecordingTracer_Start(t *testing.T) {
+	t.Run(
+		"it records the spans with their parents, attributes and errors",
+		func(t *testing.T) {
+			t.Parallel()
+
+			tracer := NewRecordingTracer()
+
+			ctx, parent := tracer.Start(context.Background(), "deploy", tracing.String("env", "staging"))
+			_, child := tracer.Start(ctx, "apply")
+			child.SetAttributes(tracing.Int("attempt", 2))
+			child.RecordError(errors.New("timeout"))
+			child.RecordError(nil)
+			child.End()
+
+			spans := tracer.Spans()
+			require.Len(t, spans, 2)
+			assert.Equal(t, parent, spans[0])
+			assert.Nil(t, spans[0].Parent)
+			assert.Equal(t, map[string]interface{}{"env": "staging"}, spans[0].Attributes)
+			assert.False(t, spans[0].Ended)
+			assert.Equal(t, spans[0], spans[1].Parent)
+			assert.Equal(t, map[string]interface{}{"attempt": 2}, spans[1].Attributes)
+			assert.EqualError(t, spans[1].Err, "timeout")
+			assert.True(t, spans[1].Ended)
+		},
+	)
+
+	t.Run(
+		"it assigns span IDs, with the trace ID of the parent, and carries the span in the context",
+		func(t *testing.T) {
+			t.Parallel()
+
+			tracer := NewRecordingTracer()
+
+			ctx, parent := tracer.Start(context.Background(), "deploy")
+			childCtx, child := tracer.Start(ctx, "apply")
+
+			assert.True(t, parent.SpanContext().IsValid())
+			assert.Equal(t, parent.SpanContext().TraceID, child.SpanContext().TraceID)
+			assert.NotEqual(t, parent.SpanContext().SpanID, child.SpanContext().SpanID)
+
+			traceID, spanID, ok := tracing.IDsFromContext(childCtx)
+			assert.True(t, ok)
+			assert.Equal(t, child.SpanContext().TraceID, traceID)
+			assert.Equal(t, child.SpanContext().SpanID, spanID)
+
+			_, _, ok = tracing.IDsFromContext(context.Background())
+			assert.False(t, ok)
+		},
+	)
+}